@@ -0,0 +1,141 @@
+package gogram
+
+import (
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/jwillp/gogram/internal/utils"
+)
+
+// testDCs are the built in addresses of the test servers
+var testDCs = map[int]string{
+	1: "149.154.175.10:443",
+	2: "149.154.167.40:443",
+	3: "149.154.175.117:443",
+}
+
+// testDCOffset is added to the dc id sent to proxies and in the obfuscated header for test servers
+const testDCOffset = 10000
+
+// DCOption is an address of a data center, as listed by help.getConfig
+type DCOption struct {
+	ID        int
+	IP        string
+	Port      int
+	IPv6      bool
+	MediaOnly bool // for file transfers only
+	TCPOOnly  bool // accepts obfuscated connections only
+	CDN       bool
+	Static    bool // should be used when connecting through a proxy
+	Secret    []byte
+}
+
+func (o DCOption) Addr() string {
+	return net.JoinHostPort(o.IP, strconv.Itoa(o.Port))
+}
+
+// DCTable holds the addresses of data centers, it's filled with the built in addresses first and replaced
+// by the list from help.getConfig once the client is connected. It's shared by the client and its senders.
+type DCTable struct {
+	mutex   sync.RWMutex
+	test    bool
+	options []DCOption
+}
+
+// NewDCTable returns a table with the built in production or test addresses
+func NewDCTable(test bool) *DCTable {
+	builtin := utils.DcList
+	if test {
+		builtin = testDCs
+	}
+	t := &DCTable{test: test}
+	for id, addr := range builtin {
+		host, port, _ := net.SplitHostPort(addr)
+		p, _ := strconv.Atoi(port)
+		t.options = append(t.options, DCOption{ID: id, IP: host, Port: p})
+	}
+	return t
+}
+
+// Test reports whether the table is of the test servers
+func (t *DCTable) Test() bool {
+	return t.test
+}
+
+// Update replaces the options with the ones from help.getConfig, built in addresses stay
+// for data centers missing in the list
+func (t *DCTable) Update(options []DCOption) {
+	if len(options) == 0 {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	listed := make(map[int]bool)
+	for _, o := range options {
+		listed[o.ID] = true
+	}
+	updated := append([]DCOption{}, options...)
+	for _, o := range t.options {
+		if !listed[o.ID] {
+			updated = append(updated, o)
+		}
+	}
+	t.options = updated
+}
+
+// Options returns a copy of all known options
+func (t *DCTable) Options() []DCOption {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return append([]DCOption{}, t.options...)
+}
+
+// DCPreference tells which of the addresses of a data center is wanted
+type DCPreference struct {
+	Media      bool // prefer media_only addresses, for downloads and uploads
+	IPv6       bool // prefer ipv6 addresses
+	Obfuscated bool // tcpo_only addresses may be used
+	Proxy      bool // prefer static addresses
+}
+
+// Lookup returns the best address of the data center, cdn addresses are never picked
+func (t *DCTable) Lookup(dc int, pref DCPreference) (DCOption, bool) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	var (
+		best      DCOption
+		bestScore = -1
+	)
+	for _, o := range t.options {
+		if o.ID != dc || o.CDN || (o.TCPOOnly && !pref.Obfuscated) || (o.MediaOnly && !pref.Media) {
+			continue
+		}
+		score := 0
+		if o.IPv6 == pref.IPv6 {
+			score += 4
+		}
+		if o.MediaOnly {
+			score += 2
+		}
+		if o.Static == pref.Proxy {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = o, score
+		}
+	}
+	return best, bestScore >= 0
+}
+
+// Find returns the option with the address, which tells the data center it belongs to
+func (t *DCTable) Find(addr string) (DCOption, bool) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	for _, o := range t.options {
+		if o.Addr() == addr {
+			return o, true
+		}
+	}
+	return DCOption{}, false
+}
@@ -0,0 +1,99 @@
+package gogram
+
+import "testing"
+
+// dcTable returns a table with the built in addresses, updated with the options of dc 2
+func dcTable(test bool) *DCTable {
+	t := NewDCTable(test)
+	t.Update([]DCOption{
+		{ID: 2, IP: "10.0.0.1", Port: 443},
+		{ID: 2, IP: "2001:db8::1", Port: 443, IPv6: true},
+		{ID: 2, IP: "10.0.0.2", Port: 443, MediaOnly: true},
+		{ID: 2, IP: "10.0.0.3", Port: 443, Static: true},
+		{ID: 2, IP: "10.0.0.4", Port: 443, CDN: true},
+		{ID: 2, IP: "2001:db8::5", Port: 443, TCPOOnly: true, IPv6: true, MediaOnly: true},
+		{ID: 5, IP: "10.0.0.6", Port: 443, CDN: true},
+		{ID: 7, IP: "10.0.0.7", Port: 443, TCPOOnly: true},
+		{ID: 8, IP: "10.0.0.8", Port: 443, MediaOnly: true},
+	})
+	return t
+}
+
+func TestDCTableLookup(t *testing.T) {
+	table := dcTable(false)
+	for _, tc := range []struct {
+		name string
+		dc   int
+		pref DCPreference
+		want string // empty when there is no address
+	}{
+		{"ipv4", 2, DCPreference{}, "10.0.0.1:443"},
+		{"ipv6", 2, DCPreference{IPv6: true}, "[2001:db8::1]:443"},
+		{"media", 2, DCPreference{Media: true}, "10.0.0.2:443"},
+		{"media over ipv6", 2, DCPreference{Media: true, IPv6: true}, "[2001:db8::1]:443"},
+		{"obfuscated media over ipv6", 2, DCPreference{Media: true, IPv6: true, Obfuscated: true}, "[2001:db8::5]:443"},
+		{"proxy", 2, DCPreference{Proxy: true}, "10.0.0.3:443"},
+		{"proxy over ipv6", 2, DCPreference{Proxy: true, IPv6: true}, "[2001:db8::1]:443"},
+		{"cdn only", 5, DCPreference{Media: true, Obfuscated: true}, ""},
+		{"tcpo_only without obfuscation", 7, DCPreference{}, ""},
+		{"tcpo_only with obfuscation", 7, DCPreference{Obfuscated: true}, "10.0.0.7:443"},
+		{"media_only without media", 8, DCPreference{}, ""},
+		{"media_only with media", 8, DCPreference{Media: true}, "10.0.0.8:443"},
+		{"built in", 4, DCPreference{}, "149.154.167.91:443"},
+		{"unknown", 42, DCPreference{}, ""},
+	} {
+		option, ok := table.Lookup(tc.dc, tc.pref)
+		switch {
+		case tc.want == "" && ok:
+			t.Errorf("%s: picked %s, want none", tc.name, option.Addr())
+		case tc.want != "" && option.Addr() != tc.want:
+			t.Errorf("%s: picked %q, want %s", tc.name, option.Addr(), tc.want)
+		}
+	}
+}
+
+func TestDCTableUpdate(t *testing.T) {
+	table := dcTable(false)
+	for _, o := range table.Options() {
+		if o.ID == 2 && o.IP == "149.154.167.50" {
+			t.Error("the built in address of dc 2 is kept while the config lists dc 2")
+		}
+	}
+	// the built in addresses of the data centers missing in the config stay
+	for _, dc := range []int{1, 3, 4} {
+		if _, ok := table.Lookup(dc, DCPreference{}); !ok {
+			t.Errorf("dc %d has no address", dc)
+		}
+	}
+
+	table.Update(nil)
+	if _, ok := table.Lookup(2, DCPreference{}); !ok {
+		t.Error("an empty config removed the addresses")
+	}
+
+	if o, ok := table.Find("10.0.0.2:443"); !ok || o.ID != 2 || !o.MediaOnly {
+		t.Errorf("found %+v for 10.0.0.2:443, want the media address of dc 2", o)
+	}
+	if _, ok := table.Find("10.0.0.99:443"); ok {
+		t.Error("found an unknown address")
+	}
+}
+
+func TestWireDC(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		test bool
+		addr string
+		want int16
+	}{
+		{"production", false, "10.0.0.1:443", 2},
+		{"media", false, "10.0.0.2:443", -2},
+		{"test", true, "10.0.0.1:443", 10002},
+		{"test media", true, "10.0.0.2:443", -10002},
+	} {
+		m := &MTProto{dcs: dcTable(tc.test), Addr: tc.addr}
+		if got := m.wireDC(); got != tc.want {
+			t.Errorf("%s: dc %d on the wire, want %d", tc.name, got, tc.want)
+		}
+	}
+}
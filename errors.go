@@ -8,10 +8,14 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/jwillp/gogram/internal/encoding/tl"
 	"github.com/jwillp/gogram/internal/mtproto/objects"
 )
 
+//go:generate go run ./internal/cmd/errgen errors.go errors_gen.go
+
 type ErrResponseCode struct {
 	Code           int
 	Message        string
@@ -46,6 +50,7 @@ type prefixSuffix struct {
 }
 
 var specificErrors = []prefixSuffix{
+	{"2FA_CONFIRM_WAIT_", "", reflect.Int},
 	{"EMAIL_UNCONFIRMED_", "", reflect.Int},
 	{"FILE_MIGRATE_", "", reflect.Int},
 	{"FILE_PART_", "_MISSING", reflect.Int},
@@ -103,6 +108,188 @@ func (e *ErrResponseCode) Error() string {
 	return fmt.Sprintf("[%s] %s (code %d)", e.Message, e.Description, e.Code)
 }
 
+// Is reports whether the error has the same message as target (one of the Err* sentinels),
+// errors with additional data are matched by their X form, e.g. ErrFloodWait matches FLOOD_WAIT_42
+func (e *ErrResponseCode) Is(target error) bool {
+	t, ok := target.(*ErrResponseCode)
+	if !ok {
+		return false
+	}
+	return e.Message == t.Message && (t.Code == 0 || t.Code == e.Code)
+}
+
+// As converts errors with additional data to their typed form (*FloodWaitError, *MigrateError, etc.),
+// all the errors telling how long to wait are also a *WaitError
+func (e *ErrResponseCode) As(target any) bool {
+	typed := e.typed()
+	if typed == nil {
+		return false
+	}
+	if w, ok := typed.(interface{ As(any) bool }); ok && w.As(target) {
+		return true
+	}
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Type().Elem().Kind() != reflect.Ptr {
+		return false
+	}
+	if !reflect.TypeOf(typed).AssignableTo(v.Type().Elem()) {
+		return false
+	}
+	v.Elem().Set(reflect.ValueOf(typed))
+	return true
+}
+
+// typed returns the typed form of an error with additional data, nil for common errors
+func (e *ErrResponseCode) typed() error {
+	n, _ := e.AdditionalInfo.(int)
+	wait := WaitError{Wait: time.Duration(n) * time.Second, Err: e}
+	switch e.Message {
+	case "FLOOD_WAIT_X", "FLOOD_TEST_PHONE_WAIT_X":
+		return &FloodWaitError{wait}
+	case "SLOWMODE_WAIT_X":
+		return &SlowmodeWaitError{wait}
+	case "PASSWORD_TOO_FRESH_X":
+		return &PasswordTooFreshError{wait}
+	case "SESSION_TOO_FRESH_X":
+		return &SessionTooFreshError{wait}
+	case "TAKEOUT_INIT_DELAY_X":
+		return &TakeoutInitDelayError{wait}
+	case "PREVIOUS_CHAT_IMPORT_ACTIVE_WAIT_XMIN":
+		wait.Wait = time.Duration(n) * time.Minute
+		return &ChatImportWaitError{wait}
+	case "PHONE_MIGRATE_X", "FILE_MIGRATE_X", "USER_MIGRATE_X", "NETWORK_MIGRATE_X", "STATS_MIGRATE_X":
+		return &MigrateError{Kind: strings.TrimSuffix(e.Message, "_MIGRATE_X"), DC: n, Err: e}
+	case "INTERDC_X_CALL_ERROR", "INTERDC_X_CALL_RICH_ERROR":
+		return &InterDCError{DC: n, Err: e}
+	case "FILE_PART_X_MISSING":
+		return &FilePartMissingError{Part: n, Err: e}
+	case "EMAIL_UNCONFIRMED_X":
+		return &EmailUnconfirmedError{CodeLength: n, Err: e}
+	}
+	return nil
+}
+
+// newRpcSentinel returns an error to compare rpc errors against with errors.Is
+func newRpcSentinel(message string) *ErrResponseCode {
+	return &ErrResponseCode{Message: message, Description: errorMessages[message]}
+}
+
+// WaitError is an rpc error which tells how long to wait before the request may succeed
+type WaitError struct {
+	Wait time.Duration
+	Err  *ErrResponseCode
+}
+
+func (e *WaitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *WaitError) Unwrap() error {
+	return e.Err
+}
+
+// As lets errors.As find the WaitError embedded in *FloodWaitError, *SlowmodeWaitError, etc.
+func (e *WaitError) As(target any) bool {
+	t, ok := target.(**WaitError)
+	if ok {
+		*t = e
+	}
+	return ok
+}
+
+type (
+	// SlowmodeWaitError is SLOWMODE_WAIT_X, the chat has slow mode enabled
+	SlowmodeWaitError struct{ WaitError }
+	// PasswordTooFreshError is PASSWORD_TOO_FRESH_X, the 2fa password was set too recently
+	PasswordTooFreshError struct{ WaitError }
+	// SessionTooFreshError is SESSION_TOO_FRESH_X, the session was created too recently
+	SessionTooFreshError struct{ WaitError }
+	// TakeoutInitDelayError is TAKEOUT_INIT_DELAY_X
+	TakeoutInitDelayError struct{ WaitError }
+	// ChatImportWaitError is PREVIOUS_CHAT_IMPORT_ACTIVE_WAIT_XMIN
+	ChatImportWaitError struct{ WaitError }
+)
+
+// MigrateError is one of PHONE_MIGRATE_X, FILE_MIGRATE_X, USER_MIGRATE_X, NETWORK_MIGRATE_X
+// and STATS_MIGRATE_X, the request must be repeated in another data center
+type MigrateError struct {
+	Kind string // PHONE, FILE, USER, NETWORK or STATS
+	DC   int
+	Err  *ErrResponseCode
+}
+
+func (e *MigrateError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *MigrateError) Unwrap() error {
+	return e.Err
+}
+
+// InterDCError is INTERDC_X_CALL_ERROR or INTERDC_X_CALL_RICH_ERROR, usually worth a retry
+type InterDCError struct {
+	DC  int
+	Err *ErrResponseCode
+}
+
+func (e *InterDCError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *InterDCError) Unwrap() error {
+	return e.Err
+}
+
+// FilePartMissingError is FILE_PART_X_MISSING, the part must be uploaded again
+type FilePartMissingError struct {
+	Part int
+	Err  *ErrResponseCode
+}
+
+func (e *FilePartMissingError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FilePartMissingError) Unwrap() error {
+	return e.Err
+}
+
+// EmailUnconfirmedError is EMAIL_UNCONFIRMED_X
+type EmailUnconfirmedError struct {
+	CodeLength int
+	Err        *ErrResponseCode
+}
+
+func (e *EmailUnconfirmedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *EmailUnconfirmedError) Unwrap() error {
+	return e.Err
+}
+
+// UnexpectedResponseError is returned by generated methods when the server answers with a type the method
+// doesn't expect, e.g. a constructor added in a newer layer
+type UnexpectedResponseError struct {
+	Method   string // the generated method, e.g. MessagesSendMessage
+	Expected string
+	Got      string
+	CRC      uint32 // of the response, zero if it isn't a tl object
+}
+
+// NewUnexpectedResponseError describes the response a method didn't expect
+func NewUnexpectedResponseError(method, expected string, response any) *UnexpectedResponseError {
+	e := &UnexpectedResponseError{Method: method, Expected: expected, Got: fmt.Sprintf("%T", response)}
+	if obj, ok := response.(tl.Object); ok {
+		e.CRC = obj.CRC()
+	}
+	return e
+}
+
+func (e *UnexpectedResponseError) Error() string {
+	return fmt.Sprintf("%s: got invalid response type %s (crc 0x%08x), expected %s", e.Method, e.Got, e.CRC, e.Expected)
+}
+
 // gathered all errors from all methods. don't have reference in docs at all
 var errorMessages = map[string]string{
 	"ABOUT_TOO_LONG":                      "The provided bio is too long",
@@ -527,7 +714,7 @@ var errorMessages = map[string]string{
 	"YOU_BLOCKED_USER":                    "You blocked this user",
 
 	// errors with additional data
-	"2FA_CONFIRM_WAIT_X":                    "You'll be able to reset your account in X seconds. If not, account will be deleted in 1 week for security reasons",
+	"2FA_CONFIRM_WAIT_X":                    "You'll be able to reset your account in %v seconds. If not, account will be deleted in 1 week for security reasons",
 	"EMAIL_UNCONFIRMED_X":                   "Email unconfirmed, the length of the code must be %v",
 	"FILE_MIGRATE_X":                        "The file to be accessed is currently stored in DC %v",
 	"FILE_PART_X_MISSING":                   "Part %v of the file is missing from storage",
@@ -613,3 +800,15 @@ func (*errorSessionConfigsChanged) Error() string {
 func (*errorSessionConfigsChanged) CRC() uint32 {
 	return 0x00000000
 }
+
+// errorConnectionReset is a pseudo response of a request which couldn't be sent cause the connection
+// was closed, the connection is already reestablished and the request needs to be repeated
+type errorConnectionReset struct{}
+
+func (*errorConnectionReset) Error() string {
+	return "connection was reset, need to repeat request"
+}
+
+func (*errorConnectionReset) CRC() uint32 {
+	return 0x00000000
+}
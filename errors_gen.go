@@ -0,0 +1,444 @@
+// Code generated by errgen; DO NOT EDIT.
+
+package gogram
+
+// sentinel rpc errors, compare with errors.Is(err, ErrFloodWait)
+var (
+	Err2faConfirmWait                   = newRpcSentinel("2FA_CONFIRM_WAIT_X")
+	ErrAboutTooLong                     = newRpcSentinel("ABOUT_TOO_LONG")
+	ErrAccessTokenExpired               = newRpcSentinel("ACCESS_TOKEN_EXPIRED")
+	ErrAccessTokenInvalid               = newRpcSentinel("ACCESS_TOKEN_INVALID")
+	ErrActiveUserRequired               = newRpcSentinel("ACTIVE_USER_REQUIRED")
+	ErrAdminsTooMuch                    = newRpcSentinel("ADMINS_TOO_MUCH")
+	ErrAdminRankEmojiNotAllowed         = newRpcSentinel("ADMIN_RANK_EMOJI_NOT_ALLOWED")
+	ErrAdminRankInvalid                 = newRpcSentinel("ADMIN_RANK_INVALID")
+	ErrAlbumPhotosTooMany               = newRpcSentinel("ALBUM_PHOTOS_TOO_MANY")
+	ErrApiIdInvalid                     = newRpcSentinel("API_ID_INVALID")
+	ErrApiIdPublishedFlood              = newRpcSentinel("API_ID_PUBLISHED_FLOOD")
+	ErrArticleTitleEmpty                = newRpcSentinel("ARTICLE_TITLE_EMPTY")
+	ErrAudioContentUrlEmpty             = newRpcSentinel("AUDIO_CONTENT_URL_EMPTY")
+	ErrAudioTitleEmpty                  = newRpcSentinel("AUDIO_TITLE_EMPTY")
+	ErrAuthBytesInvalid                 = newRpcSentinel("AUTH_BYTES_INVALID")
+	ErrAuthKeyDuplicated                = newRpcSentinel("AUTH_KEY_DUPLICATED")
+	ErrAuthKeyInvalid                   = newRpcSentinel("AUTH_KEY_INVALID")
+	ErrAuthKeyPermEmpty                 = newRpcSentinel("AUTH_KEY_PERM_EMPTY")
+	ErrAuthKeyUnregistered              = newRpcSentinel("AUTH_KEY_UNREGISTERED")
+	ErrAuthRestart                      = newRpcSentinel("AUTH_RESTART")
+	ErrAuthTokenAlreadyAccepted         = newRpcSentinel("AUTH_TOKEN_ALREADY_ACCEPTED")
+	ErrAuthTokenExpired                 = newRpcSentinel("AUTH_TOKEN_EXPIRED")
+	ErrAuthTokenInvalid                 = newRpcSentinel("AUTH_TOKEN_INVALID")
+	ErrAutoarchiveNotAvailable          = newRpcSentinel("AUTOARCHIVE_NOT_AVAILABLE")
+	ErrBankCardNumberInvalid            = newRpcSentinel("BANK_CARD_NUMBER_INVALID")
+	ErrBannedRightsInvalid              = newRpcSentinel("BANNED_RIGHTS_INVALID")
+	ErrBasePortLocInvalid               = newRpcSentinel("BASE_PORT_LOC_INVALID")
+	ErrBotsTooMuch                      = newRpcSentinel("BOTS_TOO_MUCH")
+	ErrBotChannelsNa                    = newRpcSentinel("BOT_CHANNELS_NA")
+	ErrBotCommandDescriptionInvalid     = newRpcSentinel("BOT_COMMAND_DESCRIPTION_INVALID")
+	ErrBotCommandInvalid                = newRpcSentinel("BOT_COMMAND_INVALID")
+	ErrBotDomainInvalid                 = newRpcSentinel("BOT_DOMAIN_INVALID")
+	ErrBotGamesDisabled                 = newRpcSentinel("BOT_GAMES_DISABLED")
+	ErrBotGroupsBlocked                 = newRpcSentinel("BOT_GROUPS_BLOCKED")
+	ErrBotInlineDisabled                = newRpcSentinel("BOT_INLINE_DISABLED")
+	ErrBotInvalid                       = newRpcSentinel("BOT_INVALID")
+	ErrBotMethodInvalid                 = newRpcSentinel("BOT_METHOD_INVALID")
+	ErrBotMissing                       = newRpcSentinel("BOT_MISSING")
+	ErrBotOnesideNotAvail               = newRpcSentinel("BOT_ONESIDE_NOT_AVAIL")
+	ErrBotPaymentsDisabled              = newRpcSentinel("BOT_PAYMENTS_DISABLED")
+	ErrBotPollsDisabled                 = newRpcSentinel("BOT_POLLS_DISABLED")
+	ErrBotResponseTimeout               = newRpcSentinel("BOT_RESPONSE_TIMEOUT")
+	ErrBotScoreNotModified              = newRpcSentinel("BOT_SCORE_NOT_MODIFIED")
+	ErrBroadcastCallsDisabled           = newRpcSentinel("BROADCAST_CALLS_DISABLED")
+	ErrBroadcastForbidden               = newRpcSentinel("BROADCAST_FORBIDDEN")
+	ErrBroadcastIdInvalid               = newRpcSentinel("BROADCAST_ID_INVALID")
+	ErrBroadcastPublicVotersForbidden   = newRpcSentinel("BROADCAST_PUBLIC_VOTERS_FORBIDDEN")
+	ErrBroadcastRequired                = newRpcSentinel("BROADCAST_REQUIRED")
+	ErrButtonDataInvalid                = newRpcSentinel("BUTTON_DATA_INVALID")
+	ErrButtonTypeInvalid                = newRpcSentinel("BUTTON_TYPE_INVALID")
+	ErrButtonUrlInvalid                 = newRpcSentinel("BUTTON_URL_INVALID")
+	ErrCallAlreadyAccepted              = newRpcSentinel("CALL_ALREADY_ACCEPTED")
+	ErrCallAlreadyDeclined              = newRpcSentinel("CALL_ALREADY_DECLINED")
+	ErrCallOccupyFailed                 = newRpcSentinel("CALL_OCCUPY_FAILED")
+	ErrCallPeerInvalid                  = newRpcSentinel("CALL_PEER_INVALID")
+	ErrCallProtocolFlagsInvalid         = newRpcSentinel("CALL_PROTOCOL_FLAGS_INVALID")
+	ErrCdnMethodInvalid                 = newRpcSentinel("CDN_METHOD_INVALID")
+	ErrChannelsAdminLocatedTooMuch      = newRpcSentinel("CHANNELS_ADMIN_LOCATED_TOO_MUCH")
+	ErrChannelsAdminPublicTooMuch       = newRpcSentinel("CHANNELS_ADMIN_PUBLIC_TOO_MUCH")
+	ErrChannelsTooMuch                  = newRpcSentinel("CHANNELS_TOO_MUCH")
+	ErrChannelAddInvalid                = newRpcSentinel("CHANNEL_ADD_INVALID")
+	ErrChannelBanned                    = newRpcSentinel("CHANNEL_BANNED")
+	ErrChannelInvalid                   = newRpcSentinel("CHANNEL_INVALID")
+	ErrChannelPrivate                   = newRpcSentinel("CHANNEL_PRIVATE")
+	ErrChannelPublicGroupNa             = newRpcSentinel("CHANNEL_PUBLIC_GROUP_NA")
+	ErrChannelTooLarge                  = newRpcSentinel("CHANNEL_TOO_LARGE")
+	ErrChatAboutNotModified             = newRpcSentinel("CHAT_ABOUT_NOT_MODIFIED")
+	ErrChatAboutTooLong                 = newRpcSentinel("CHAT_ABOUT_TOO_LONG")
+	ErrChatAdminInviteRequired          = newRpcSentinel("CHAT_ADMIN_INVITE_REQUIRED")
+	ErrChatAdminRequired                = newRpcSentinel("CHAT_ADMIN_REQUIRED")
+	ErrChatForbidden                    = newRpcSentinel("CHAT_FORBIDDEN")
+	ErrChatForwardsRestricted           = newRpcSentinel("CHAT_FORWARDS_RESTRICTED")
+	ErrChatIdEmpty                      = newRpcSentinel("CHAT_ID_EMPTY")
+	ErrChatIdInvalid                    = newRpcSentinel("CHAT_ID_INVALID")
+	ErrChatInvalid                      = newRpcSentinel("CHAT_INVALID")
+	ErrChatLinkExists                   = newRpcSentinel("CHAT_LINK_EXISTS")
+	ErrChatNotModified                  = newRpcSentinel("CHAT_NOT_MODIFIED")
+	ErrChatRestricted                   = newRpcSentinel("CHAT_RESTRICTED")
+	ErrChatSendGifsForbidden            = newRpcSentinel("CHAT_SEND_GIFS_FORBIDDEN")
+	ErrChatSendInlineForbidden          = newRpcSentinel("CHAT_SEND_INLINE_FORBIDDEN")
+	ErrChatSendMediaForbidden           = newRpcSentinel("CHAT_SEND_MEDIA_FORBIDDEN")
+	ErrChatSendStickersForbidden        = newRpcSentinel("CHAT_SEND_STICKERS_FORBIDDEN")
+	ErrChatTitleEmpty                   = newRpcSentinel("CHAT_TITLE_EMPTY")
+	ErrChatTooBig                       = newRpcSentinel("CHAT_TOO_BIG")
+	ErrChatWriteForbidden               = newRpcSentinel("CHAT_WRITE_FORBIDDEN")
+	ErrChpCallFail                      = newRpcSentinel("CHP_CALL_FAIL")
+	ErrCodeEmpty                        = newRpcSentinel("CODE_EMPTY")
+	ErrCodeHashInvalid                  = newRpcSentinel("CODE_HASH_INVALID")
+	ErrCodeInvalid                      = newRpcSentinel("CODE_INVALID")
+	ErrConnectionApiIdInvalid           = newRpcSentinel("CONNECTION_API_ID_INVALID")
+	ErrConnectionDeviceModelEmpty       = newRpcSentinel("CONNECTION_DEVICE_MODEL_EMPTY")
+	ErrConnectionLangPackInvalid        = newRpcSentinel("CONNECTION_LANG_PACK_INVALID")
+	ErrConnectionLayerInvalid           = newRpcSentinel("CONNECTION_LAYER_INVALID")
+	ErrConnectionNotInited              = newRpcSentinel("CONNECTION_NOT_INITED")
+	ErrConnectionSystemEmpty            = newRpcSentinel("CONNECTION_SYSTEM_EMPTY")
+	ErrConnectionSystemLangCodeEmpty    = newRpcSentinel("CONNECTION_SYSTEM_LANG_CODE_EMPTY")
+	ErrContactIdInvalid                 = newRpcSentinel("CONTACT_ID_INVALID")
+	ErrContactNameEmpty                 = newRpcSentinel("CONTACT_NAME_EMPTY")
+	ErrCurrencyTotalAmountInvalid       = newRpcSentinel("CURRENCY_TOTAL_AMOUNT_INVALID")
+	ErrDataInvalid                      = newRpcSentinel("DATA_INVALID")
+	ErrDataJsonInvalid                  = newRpcSentinel("DATA_JSON_INVALID")
+	ErrDateEmpty                        = newRpcSentinel("DATE_EMPTY")
+	ErrDcIdInvalid                      = newRpcSentinel("DC_ID_INVALID")
+	ErrDhGAInvalid                      = newRpcSentinel("DH_G_A_INVALID")
+	ErrDocumentInvalid                  = newRpcSentinel("DOCUMENT_INVALID")
+	ErrEmailHashExpired                 = newRpcSentinel("EMAIL_HASH_EXPIRED")
+	ErrEmailInvalid                     = newRpcSentinel("EMAIL_INVALID")
+	ErrEmailUnconfirmed                 = newRpcSentinel("EMAIL_UNCONFIRMED_X")
+	ErrEmojiInvalid                     = newRpcSentinel("EMOJI_INVALID")
+	ErrEmojiNotModified                 = newRpcSentinel("EMOJI_NOT_MODIFIED")
+	ErrEmoticonEmpty                    = newRpcSentinel("EMOTICON_EMPTY")
+	ErrEmoticonInvalid                  = newRpcSentinel("EMOTICON_INVALID")
+	ErrEmoticonStickerpackMissing       = newRpcSentinel("EMOTICON_STICKERPACK_MISSING")
+	ErrEncryptedMessageInvalid          = newRpcSentinel("ENCRYPTED_MESSAGE_INVALID")
+	ErrEncryptionAlreadyAccepted        = newRpcSentinel("ENCRYPTION_ALREADY_ACCEPTED")
+	ErrEncryptionAlreadyDeclined        = newRpcSentinel("ENCRYPTION_ALREADY_DECLINED")
+	ErrEncryptionDeclined               = newRpcSentinel("ENCRYPTION_DECLINED")
+	ErrEncryptionIdInvalid              = newRpcSentinel("ENCRYPTION_ID_INVALID")
+	ErrEncryptionOccupyFailed           = newRpcSentinel("ENCRYPTION_OCCUPY_FAILED")
+	ErrEntitiesTooLong                  = newRpcSentinel("ENTITIES_TOO_LONG")
+	ErrEntityMentionUserInvalid         = newRpcSentinel("ENTITY_MENTION_USER_INVALID")
+	ErrErrorTextEmpty                   = newRpcSentinel("ERROR_TEXT_EMPTY")
+	ErrExpireDateInvalid                = newRpcSentinel("EXPIRE_DATE_INVALID")
+	ErrExpireForbidden                  = newRpcSentinel("EXPIRE_FORBIDDEN")
+	ErrExportCardInvalid                = newRpcSentinel("EXPORT_CARD_INVALID")
+	ErrExternalUrlInvalid               = newRpcSentinel("EXTERNAL_URL_INVALID")
+	ErrFieldNameEmpty                   = newRpcSentinel("FIELD_NAME_EMPTY")
+	ErrFieldNameInvalid                 = newRpcSentinel("FIELD_NAME_INVALID")
+	ErrFilerefUpgradeNeeded             = newRpcSentinel("FILEREF_UPGRADE_NEEDED")
+	ErrFileContentTypeInvalid           = newRpcSentinel("FILE_CONTENT_TYPE_INVALID")
+	ErrFileIdInvalid                    = newRpcSentinel("FILE_ID_INVALID")
+	ErrFileMigrate                      = newRpcSentinel("FILE_MIGRATE_X")
+	ErrFilePartsInvalid                 = newRpcSentinel("FILE_PARTS_INVALID")
+	ErrFilePartEmpty                    = newRpcSentinel("FILE_PART_EMPTY")
+	ErrFilePartInvalid                  = newRpcSentinel("FILE_PART_INVALID")
+	ErrFilePartLengthInvalid            = newRpcSentinel("FILE_PART_LENGTH_INVALID")
+	ErrFilePartSizeChanged              = newRpcSentinel("FILE_PART_SIZE_CHANGED")
+	ErrFilePartSizeInvalid              = newRpcSentinel("FILE_PART_SIZE_INVALID")
+	ErrFilePartMissing                  = newRpcSentinel("FILE_PART_X_MISSING")
+	ErrFileReferenceEmpty               = newRpcSentinel("FILE_REFERENCE_EMPTY")
+	ErrFileReferenceExpired             = newRpcSentinel("FILE_REFERENCE_EXPIRED")
+	ErrFileReferenceInvalid             = newRpcSentinel("FILE_REFERENCE_INVALID")
+	ErrFileTitleEmpty                   = newRpcSentinel("FILE_TITLE_EMPTY")
+	ErrFilterNotSupported               = newRpcSentinel("FILTER_NOT_SUPPORTED")
+	ErrFirstnameInvalid                 = newRpcSentinel("FIRSTNAME_INVALID")
+	ErrFloodTestPhoneWait               = newRpcSentinel("FLOOD_TEST_PHONE_WAIT_X")
+	ErrFloodWait                        = newRpcSentinel("FLOOD_WAIT_X")
+	ErrFolderIdEmpty                    = newRpcSentinel("FOLDER_ID_EMPTY")
+	ErrFolderIdInvalid                  = newRpcSentinel("FOLDER_ID_INVALID")
+	ErrFreshChangeAdminsForbidden       = newRpcSentinel("FRESH_CHANGE_ADMINS_FORBIDDEN")
+	ErrFreshChangePhoneForbidden        = newRpcSentinel("FRESH_CHANGE_PHONE_FORBIDDEN")
+	ErrFreshResetAuthorisationForbidden = newRpcSentinel("FRESH_RESET_AUTHORISATION_FORBIDDEN")
+	ErrFromPeerInvalid                  = newRpcSentinel("FROM_PEER_INVALID")
+	ErrGameBotInvalid                   = newRpcSentinel("GAME_BOT_INVALID")
+	ErrGifContentTypeInvalid            = newRpcSentinel("GIF_CONTENT_TYPE_INVALID")
+	ErrGifIdInvalid                     = newRpcSentinel("GIF_ID_INVALID")
+	ErrGraphInvalidReload               = newRpcSentinel("GRAPH_INVALID_RELOAD")
+	ErrGraphOutdatedReload              = newRpcSentinel("GRAPH_OUTDATED_RELOAD")
+	ErrGroupcallAddParticipantsFailed   = newRpcSentinel("GROUPCALL_ADD_PARTICIPANTS_FAILED")
+	ErrGroupcallAlreadyDiscarded        = newRpcSentinel("GROUPCALL_ALREADY_DISCARDED")
+	ErrGroupcallForbidden               = newRpcSentinel("GROUPCALL_FORBIDDEN")
+	ErrGroupcallInvalid                 = newRpcSentinel("GROUPCALL_INVALID")
+	ErrGroupcallJoinMissing             = newRpcSentinel("GROUPCALL_JOIN_MISSING")
+	ErrGroupcallNotModified             = newRpcSentinel("GROUPCALL_NOT_MODIFIED")
+	ErrGroupcallSsrcDuplicateMuch       = newRpcSentinel("GROUPCALL_SSRC_DUPLICATE_MUCH")
+	ErrGroupedMediaInvalid              = newRpcSentinel("GROUPED_MEDIA_INVALID")
+	ErrGroupCallInvalid                 = newRpcSentinel("GROUP_CALL_INVALID")
+	ErrHashInvalid                      = newRpcSentinel("HASH_INVALID")
+	ErrHistoryGetFailed                 = newRpcSentinel("HISTORY_GET_FAILED")
+	ErrImageProcessFailed               = newRpcSentinel("IMAGE_PROCESS_FAILED")
+	ErrImportFileInvalid                = newRpcSentinel("IMPORT_FILE_INVALID")
+	ErrImportFormatUnrecognized         = newRpcSentinel("IMPORT_FORMAT_UNRECOGNIZED")
+	ErrImportIdInvalid                  = newRpcSentinel("IMPORT_ID_INVALID")
+	ErrInlineBotRequired                = newRpcSentinel("INLINE_BOT_REQUIRED")
+	ErrInlineResultExpired              = newRpcSentinel("INLINE_RESULT_EXPIRED")
+	ErrInputConstructorInvalid          = newRpcSentinel("INPUT_CONSTRUCTOR_INVALID")
+	ErrInputFetchError                  = newRpcSentinel("INPUT_FETCH_ERROR")
+	ErrInputFetchFail                   = newRpcSentinel("INPUT_FETCH_FAIL")
+	ErrInputFilterInvalid               = newRpcSentinel("INPUT_FILTER_INVALID")
+	ErrInputLayerInvalid                = newRpcSentinel("INPUT_LAYER_INVALID")
+	ErrInputMethodInvalid               = newRpcSentinel("INPUT_METHOD_INVALID")
+	ErrInputRequestTooLong              = newRpcSentinel("INPUT_REQUEST_TOO_LONG")
+	ErrInputUserDeactivated             = newRpcSentinel("INPUT_USER_DEACTIVATED")
+	ErrInterdcCallError                 = newRpcSentinel("INTERDC_X_CALL_ERROR")
+	ErrInterdcCallRichError             = newRpcSentinel("INTERDC_X_CALL_RICH_ERROR")
+	ErrInviteForbiddenWithJoinas        = newRpcSentinel("INVITE_FORBIDDEN_WITH_JOINAS")
+	ErrInviteHashEmpty                  = newRpcSentinel("INVITE_HASH_EMPTY")
+	ErrInviteHashExpired                = newRpcSentinel("INVITE_HASH_EXPIRED")
+	ErrInviteHashInvalid                = newRpcSentinel("INVITE_HASH_INVALID")
+	ErrLangCodeInvalid                  = newRpcSentinel("LANG_CODE_INVALID")
+	ErrLangPackInvalid                  = newRpcSentinel("LANG_PACK_INVALID")
+	ErrLastnameInvalid                  = newRpcSentinel("LASTNAME_INVALID")
+	ErrLimitInvalid                     = newRpcSentinel("LIMIT_INVALID")
+	ErrLinkNotModified                  = newRpcSentinel("LINK_NOT_MODIFIED")
+	ErrLocationInvalid                  = newRpcSentinel("LOCATION_INVALID")
+	ErrMaxIdInvalid                     = newRpcSentinel("MAX_ID_INVALID")
+	ErrMaxQtsInvalid                    = newRpcSentinel("MAX_QTS_INVALID")
+	ErrMd5ChecksumInvalid               = newRpcSentinel("MD5_CHECKSUM_INVALID")
+	ErrMediaCaptionTooLong              = newRpcSentinel("MEDIA_CAPTION_TOO_LONG")
+	ErrMediaEmpty                       = newRpcSentinel("MEDIA_EMPTY")
+	ErrMediaGroupedInvalid              = newRpcSentinel("MEDIA_GROUPED_INVALID")
+	ErrMediaInvalid                     = newRpcSentinel("MEDIA_INVALID")
+	ErrMediaNewInvalid                  = newRpcSentinel("MEDIA_NEW_INVALID")
+	ErrMediaPrevInvalid                 = newRpcSentinel("MEDIA_PREV_INVALID")
+	ErrMediaTtlInvalid                  = newRpcSentinel("MEDIA_TTL_INVALID")
+	ErrMegagroupIdInvalid               = newRpcSentinel("MEGAGROUP_ID_INVALID")
+	ErrMegagroupPrehistoryHidden        = newRpcSentinel("MEGAGROUP_PREHISTORY_HIDDEN")
+	ErrMegagroupRequired                = newRpcSentinel("MEGAGROUP_REQUIRED")
+	ErrMemberNoLocation                 = newRpcSentinel("MEMBER_NO_LOCATION")
+	ErrMemberOccupyPrimaryLocFailed     = newRpcSentinel("MEMBER_OCCUPY_PRIMARY_LOC_FAILED")
+	ErrMessageAuthorRequired            = newRpcSentinel("MESSAGE_AUTHOR_REQUIRED")
+	ErrMessageDeleteForbidden           = newRpcSentinel("MESSAGE_DELETE_FORBIDDEN")
+	ErrMessageEditTimeExpired           = newRpcSentinel("MESSAGE_EDIT_TIME_EXPIRED")
+	ErrMessageEmpty                     = newRpcSentinel("MESSAGE_EMPTY")
+	ErrMessageIdsEmpty                  = newRpcSentinel("MESSAGE_IDS_EMPTY")
+	ErrMessageIdInvalid                 = newRpcSentinel("MESSAGE_ID_INVALID")
+	ErrMessageNotModified               = newRpcSentinel("MESSAGE_NOT_MODIFIED")
+	ErrMessagePollClosed                = newRpcSentinel("MESSAGE_POLL_CLOSED")
+	ErrMessageTooLong                   = newRpcSentinel("MESSAGE_TOO_LONG")
+	ErrMethodInvalid                    = newRpcSentinel("METHOD_INVALID")
+	ErrMsgidDecreaseRetry               = newRpcSentinel("MSGID_DECREASE_RETRY")
+	ErrMsgIdInvalid                     = newRpcSentinel("MSG_ID_INVALID")
+	ErrMsgWaitFailed                    = newRpcSentinel("MSG_WAIT_FAILED")
+	ErrMtSendQueueTooLong               = newRpcSentinel("MT_SEND_QUEUE_TOO_LONG")
+	ErrMultiMediaTooLong                = newRpcSentinel("MULTI_MEDIA_TOO_LONG")
+	ErrNeedChatInvalid                  = newRpcSentinel("NEED_CHAT_INVALID")
+	ErrNeedMemberInvalid                = newRpcSentinel("NEED_MEMBER_INVALID")
+	ErrNetworkMigrate                   = newRpcSentinel("NETWORK_MIGRATE_X")
+	ErrNewSaltInvalid                   = newRpcSentinel("NEW_SALT_INVALID")
+	ErrNewSettingsInvalid               = newRpcSentinel("NEW_SETTINGS_INVALID")
+	ErrNextOffsetInvalid                = newRpcSentinel("NEXT_OFFSET_INVALID")
+	ErrOffsetInvalid                    = newRpcSentinel("OFFSET_INVALID")
+	ErrOffsetPeerIdInvalid              = newRpcSentinel("OFFSET_PEER_ID_INVALID")
+	ErrOptionsTooMuch                   = newRpcSentinel("OPTIONS_TOO_MUCH")
+	ErrOptionInvalid                    = newRpcSentinel("OPTION_INVALID")
+	ErrPackShortNameInvalid             = newRpcSentinel("PACK_SHORT_NAME_INVALID")
+	ErrPackShortNameOccupied            = newRpcSentinel("PACK_SHORT_NAME_OCCUPIED")
+	ErrParticipantsTooFew               = newRpcSentinel("PARTICIPANTS_TOO_FEW")
+	ErrParticipantCallFailed            = newRpcSentinel("PARTICIPANT_CALL_FAILED")
+	ErrParticipantIdInvalid             = newRpcSentinel("PARTICIPANT_ID_INVALID")
+	ErrParticipantJoinMissing           = newRpcSentinel("PARTICIPANT_JOIN_MISSING")
+	ErrParticipantVersionOutdated       = newRpcSentinel("PARTICIPANT_VERSION_OUTDATED")
+	ErrPasswordEmpty                    = newRpcSentinel("PASSWORD_EMPTY")
+	ErrPasswordHashInvalid              = newRpcSentinel("PASSWORD_HASH_INVALID")
+	ErrPasswordMissing                  = newRpcSentinel("PASSWORD_MISSING")
+	ErrPasswordRecoveryExpired          = newRpcSentinel("PASSWORD_RECOVERY_EXPIRED")
+	ErrPasswordRecoveryNa               = newRpcSentinel("PASSWORD_RECOVERY_NA")
+	ErrPasswordRequired                 = newRpcSentinel("PASSWORD_REQUIRED")
+	ErrPasswordTooFresh                 = newRpcSentinel("PASSWORD_TOO_FRESH_X")
+	ErrPaymentProviderInvalid           = newRpcSentinel("PAYMENT_PROVIDER_INVALID")
+	ErrPeerFlood                        = newRpcSentinel("PEER_FLOOD")
+	ErrPeerIdInvalid                    = newRpcSentinel("PEER_ID_INVALID")
+	ErrPeerIdNotSupported               = newRpcSentinel("PEER_ID_NOT_SUPPORTED")
+	ErrPersistentTimestampEmpty         = newRpcSentinel("PERSISTENT_TIMESTAMP_EMPTY")
+	ErrPersistentTimestampInvalid       = newRpcSentinel("PERSISTENT_TIMESTAMP_INVALID")
+	ErrPersistentTimestampOutdated      = newRpcSentinel("PERSISTENT_TIMESTAMP_OUTDATED")
+	ErrPhoneCodeEmpty                   = newRpcSentinel("PHONE_CODE_EMPTY")
+	ErrPhoneCodeExpired                 = newRpcSentinel("PHONE_CODE_EXPIRED")
+	ErrPhoneCodeHashEmpty               = newRpcSentinel("PHONE_CODE_HASH_EMPTY")
+	ErrPhoneCodeInvalid                 = newRpcSentinel("PHONE_CODE_INVALID")
+	ErrPhoneMigrate                     = newRpcSentinel("PHONE_MIGRATE_X")
+	ErrPhoneNotOccupied                 = newRpcSentinel("PHONE_NOT_OCCUPIED")
+	ErrPhoneNumberAppSignupForbidden    = newRpcSentinel("PHONE_NUMBER_APP_SIGNUP_FORBIDDEN")
+	ErrPhoneNumberBanned                = newRpcSentinel("PHONE_NUMBER_BANNED")
+	ErrPhoneNumberFlood                 = newRpcSentinel("PHONE_NUMBER_FLOOD")
+	ErrPhoneNumberInvalid               = newRpcSentinel("PHONE_NUMBER_INVALID")
+	ErrPhoneNumberOccupied              = newRpcSentinel("PHONE_NUMBER_OCCUPIED")
+	ErrPhoneNumberUnoccupied            = newRpcSentinel("PHONE_NUMBER_UNOCCUPIED")
+	ErrPhonePasswordFlood               = newRpcSentinel("PHONE_PASSWORD_FLOOD")
+	ErrPhonePasswordProtected           = newRpcSentinel("PHONE_PASSWORD_PROTECTED")
+	ErrPhotoContentTypeInvalid          = newRpcSentinel("PHOTO_CONTENT_TYPE_INVALID")
+	ErrPhotoContentUrlEmpty             = newRpcSentinel("PHOTO_CONTENT_URL_EMPTY")
+	ErrPhotoCropSizeSmall               = newRpcSentinel("PHOTO_CROP_SIZE_SMALL")
+	ErrPhotoExtInvalid                  = newRpcSentinel("PHOTO_EXT_INVALID")
+	ErrPhotoFileMissing                 = newRpcSentinel("PHOTO_FILE_MISSING")
+	ErrPhotoIdInvalid                   = newRpcSentinel("PHOTO_ID_INVALID")
+	ErrPhotoInvalid                     = newRpcSentinel("PHOTO_INVALID")
+	ErrPhotoInvalidDimensions           = newRpcSentinel("PHOTO_INVALID_DIMENSIONS")
+	ErrPhotoSaveFileInvalid             = newRpcSentinel("PHOTO_SAVE_FILE_INVALID")
+	ErrPhotoThumbUrlEmpty               = newRpcSentinel("PHOTO_THUMB_URL_EMPTY")
+	ErrPinnedDialogsTooMuch             = newRpcSentinel("PINNED_DIALOGS_TOO_MUCH")
+	ErrPinRestricted                    = newRpcSentinel("PIN_RESTRICTED")
+	ErrPollAnswersInvalid               = newRpcSentinel("POLL_ANSWERS_INVALID")
+	ErrPollAnswerInvalid                = newRpcSentinel("POLL_ANSWER_INVALID")
+	ErrPollOptionDuplicate              = newRpcSentinel("POLL_OPTION_DUPLICATE")
+	ErrPollOptionInvalid                = newRpcSentinel("POLL_OPTION_INVALID")
+	ErrPollQuestionInvalid              = newRpcSentinel("POLL_QUESTION_INVALID")
+	ErrPollUnsupported                  = newRpcSentinel("POLL_UNSUPPORTED")
+	ErrPollVoteRequired                 = newRpcSentinel("POLL_VOTE_REQUIRED")
+	ErrPreviousChatImportActiveWaitMin  = newRpcSentinel("PREVIOUS_CHAT_IMPORT_ACTIVE_WAIT_XMIN")
+	ErrPrivacyKeyInvalid                = newRpcSentinel("PRIVACY_KEY_INVALID")
+	ErrPrivacyTooLong                   = newRpcSentinel("PRIVACY_TOO_LONG")
+	ErrPrivacyValueInvalid              = newRpcSentinel("PRIVACY_VALUE_INVALID")
+	ErrPtsChangeEmpty                   = newRpcSentinel("PTS_CHANGE_EMPTY")
+	ErrPublicKeyRequired                = newRpcSentinel("PUBLIC_KEY_REQUIRED")
+	ErrQueryIdEmpty                     = newRpcSentinel("QUERY_ID_EMPTY")
+	ErrQueryIdInvalid                   = newRpcSentinel("QUERY_ID_INVALID")
+	ErrQueryTooShort                    = newRpcSentinel("QUERY_TOO_SHORT")
+	ErrQuizAnswerMissing                = newRpcSentinel("QUIZ_ANSWER_MISSING")
+	ErrQuizCorrectAnswersEmpty          = newRpcSentinel("QUIZ_CORRECT_ANSWERS_EMPTY")
+	ErrQuizCorrectAnswersTooMuch        = newRpcSentinel("QUIZ_CORRECT_ANSWERS_TOO_MUCH")
+	ErrQuizCorrectAnswerInvalid         = newRpcSentinel("QUIZ_CORRECT_ANSWER_INVALID")
+	ErrQuizMultipleInvalid              = newRpcSentinel("QUIZ_MULTIPLE_INVALID")
+	ErrRandomIdDuplicate                = newRpcSentinel("RANDOM_ID_DUPLICATE")
+	ErrRandomIdInvalid                  = newRpcSentinel("RANDOM_ID_INVALID")
+	ErrRandomLengthInvalid              = newRpcSentinel("RANDOM_LENGTH_INVALID")
+	ErrRangesInvalid                    = newRpcSentinel("RANGES_INVALID")
+	ErrReactionEmpty                    = newRpcSentinel("REACTION_EMPTY")
+	ErrReactionInvalid                  = newRpcSentinel("REACTION_INVALID")
+	ErrReflectorNotAvailable            = newRpcSentinel("REFLECTOR_NOT_AVAILABLE")
+	ErrRegIdGenerateFailed              = newRpcSentinel("REG_ID_GENERATE_FAILED")
+	ErrReplyMarkupGameEmpty             = newRpcSentinel("REPLY_MARKUP_GAME_EMPTY")
+	ErrReplyMarkupInvalid               = newRpcSentinel("REPLY_MARKUP_INVALID")
+	ErrReplyMarkupTooLong               = newRpcSentinel("REPLY_MARKUP_TOO_LONG")
+	ErrResetRequestMissing              = newRpcSentinel("RESET_REQUEST_MISSING")
+	ErrResultsTooMuch                   = newRpcSentinel("RESULTS_TOO_MUCH")
+	ErrResultIdDuplicate                = newRpcSentinel("RESULT_ID_DUPLICATE")
+	ErrResultIdInvalid                  = newRpcSentinel("RESULT_ID_INVALID")
+	ErrResultTypeInvalid                = newRpcSentinel("RESULT_TYPE_INVALID")
+	ErrRevoteNotAllowed                 = newRpcSentinel("REVOTE_NOT_ALLOWED")
+	ErrRightForbidden                   = newRpcSentinel("RIGHT_FORBIDDEN")
+	ErrRpcCallFail                      = newRpcSentinel("RPC_CALL_FAIL")
+	ErrRpcMcgetFail                     = newRpcSentinel("RPC_MCGET_FAIL")
+	ErrRsaDecryptFailed                 = newRpcSentinel("RSA_DECRYPT_FAILED")
+	ErrScheduleBotNotAllowed            = newRpcSentinel("SCHEDULE_BOT_NOT_ALLOWED")
+	ErrScheduleDateInvalid              = newRpcSentinel("SCHEDULE_DATE_INVALID")
+	ErrScheduleDateTooLate              = newRpcSentinel("SCHEDULE_DATE_TOO_LATE")
+	ErrScheduleStatusPrivate            = newRpcSentinel("SCHEDULE_STATUS_PRIVATE")
+	ErrScheduleTooMuch                  = newRpcSentinel("SCHEDULE_TOO_MUCH")
+	ErrScoreInvalid                     = newRpcSentinel("SCORE_INVALID")
+	ErrSearchQueryEmpty                 = newRpcSentinel("SEARCH_QUERY_EMPTY")
+	ErrSecondsInvalid                   = newRpcSentinel("SECONDS_INVALID")
+	ErrSendAsPeerInvalid                = newRpcSentinel("SEND_AS_PEER_INVALID")
+	ErrSendCodeUnavailable              = newRpcSentinel("SEND_CODE_UNAVAILABLE")
+	ErrSendMessageMediaInvalid          = newRpcSentinel("SEND_MESSAGE_MEDIA_INVALID")
+	ErrSendMessageTypeInvalid           = newRpcSentinel("SEND_MESSAGE_TYPE_INVALID")
+	ErrSensitiveChangeForbidden         = newRpcSentinel("SENSITIVE_CHANGE_FORBIDDEN")
+	ErrSessionExpired                   = newRpcSentinel("SESSION_EXPIRED")
+	ErrSessionPasswordNeeded            = newRpcSentinel("SESSION_PASSWORD_NEEDED")
+	ErrSessionRevoked                   = newRpcSentinel("SESSION_REVOKED")
+	ErrSessionTooFresh                  = newRpcSentinel("SESSION_TOO_FRESH_X")
+	ErrSha256HashInvalid                = newRpcSentinel("SHA256_HASH_INVALID")
+	ErrShortnameOccupyFailed            = newRpcSentinel("SHORTNAME_OCCUPY_FAILED")
+	ErrShortNameInvalid                 = newRpcSentinel("SHORT_NAME_INVALID")
+	ErrShortNameOccupied                = newRpcSentinel("SHORT_NAME_OCCUPIED")
+	ErrSlowmodeMultiMsgsDisabled        = newRpcSentinel("SLOWMODE_MULTI_MSGS_DISABLED")
+	ErrSlowmodeWait                     = newRpcSentinel("SLOWMODE_WAIT_X")
+	ErrSrpIdInvalid                     = newRpcSentinel("SRP_ID_INVALID")
+	ErrStartParamEmpty                  = newRpcSentinel("START_PARAM_EMPTY")
+	ErrStartParamInvalid                = newRpcSentinel("START_PARAM_INVALID")
+	ErrStatsMigrate                     = newRpcSentinel("STATS_MIGRATE_X")
+	ErrStickerpackStickersTooMuch       = newRpcSentinel("STICKERPACK_STICKERS_TOO_MUCH")
+	ErrStickersetInvalid                = newRpcSentinel("STICKERSET_INVALID")
+	ErrStickersetOwnerAnonymous         = newRpcSentinel("STICKERSET_OWNER_ANONYMOUS")
+	ErrStickersEmpty                    = newRpcSentinel("STICKERS_EMPTY")
+	ErrStickersTooMuch                  = newRpcSentinel("STICKERS_TOO_MUCH")
+	ErrStickerDocumentInvalid           = newRpcSentinel("STICKER_DOCUMENT_INVALID")
+	ErrStickerEmojiInvalid              = newRpcSentinel("STICKER_EMOJI_INVALID")
+	ErrStickerFileInvalid               = newRpcSentinel("STICKER_FILE_INVALID")
+	ErrStickerGifDimensions             = newRpcSentinel("STICKER_GIF_DIMENSIONS")
+	ErrStickerIdInvalid                 = newRpcSentinel("STICKER_ID_INVALID")
+	ErrStickerInvalid                   = newRpcSentinel("STICKER_INVALID")
+	ErrStickerPngDimensions             = newRpcSentinel("STICKER_PNG_DIMENSIONS")
+	ErrStickerPngNopng                  = newRpcSentinel("STICKER_PNG_NOPNG")
+	ErrStickerTgsNodoc                  = newRpcSentinel("STICKER_TGS_NODOC")
+	ErrStickerTgsNotgs                  = newRpcSentinel("STICKER_TGS_NOTGS")
+	ErrStickerThumbPngNopng             = newRpcSentinel("STICKER_THUMB_PNG_NOPNG")
+	ErrStickerThumbTgsNotgs             = newRpcSentinel("STICKER_THUMB_TGS_NOTGS")
+	ErrStickerVideoBig                  = newRpcSentinel("STICKER_VIDEO_BIG")
+	ErrStickerVideoNowebm               = newRpcSentinel("STICKER_VIDEO_NOWEBM")
+	ErrStorageCheckFailed               = newRpcSentinel("STORAGE_CHECK_FAILED")
+	ErrStoreInvalidScalarType           = newRpcSentinel("STORE_INVALID_SCALAR_TYPE")
+	ErrTakeoutInitDelay                 = newRpcSentinel("TAKEOUT_INIT_DELAY_X")
+	ErrTakeoutInvalid                   = newRpcSentinel("TAKEOUT_INVALID")
+	ErrTakeoutRequired                  = newRpcSentinel("TAKEOUT_REQUIRED")
+	ErrTempAuthKeyEmpty                 = newRpcSentinel("TEMP_AUTH_KEY_EMPTY")
+	ErrThemeInvalid                     = newRpcSentinel("THEME_INVALID")
+	ErrThemeMimeInvalid                 = newRpcSentinel("THEME_MIME_INVALID")
+	ErrTimeout                          = newRpcSentinel("TIMEOUT")
+	ErrTitleInvalid                     = newRpcSentinel("TITLE_INVALID")
+	ErrTmpPasswordDisabled              = newRpcSentinel("TMP_PASSWORD_DISABLED")
+	ErrTmpPasswordInvalid               = newRpcSentinel("TMP_PASSWORD_INVALID")
+	ErrTokenInvalid                     = newRpcSentinel("TOKEN_INVALID")
+	ErrTtlDaysInvalid                   = newRpcSentinel("TTL_DAYS_INVALID")
+	ErrTtlMediaInvalid                  = newRpcSentinel("TTL_MEDIA_INVALID")
+	ErrTtlPeriodInvalid                 = newRpcSentinel("TTL_PERIOD_INVALID")
+	ErrTypesEmpty                       = newRpcSentinel("TYPES_EMPTY")
+	ErrTypeConstructorInvalid           = newRpcSentinel("TYPE_CONSTRUCTOR_INVALID")
+	ErrUnknownError                     = newRpcSentinel("UNKNOWN_ERROR")
+	ErrUnknownMethod                    = newRpcSentinel("UNKNOWN_METHOD")
+	ErrUntilDateInvalid                 = newRpcSentinel("UNTIL_DATE_INVALID")
+	ErrUpdateAppToLogin                 = newRpcSentinel("UPDATE_APP_TO_LOGIN")
+	ErrUrlInvalid                       = newRpcSentinel("URL_INVALID")
+	ErrUsageLimitInvalid                = newRpcSentinel("USAGE_LIMIT_INVALID")
+	ErrUsernameInvalid                  = newRpcSentinel("USERNAME_INVALID")
+	ErrUsernameNotModified              = newRpcSentinel("USERNAME_NOT_MODIFIED")
+	ErrUsernameNotOccupied              = newRpcSentinel("USERNAME_NOT_OCCUPIED")
+	ErrUsernameOccupied                 = newRpcSentinel("USERNAME_OCCUPIED")
+	ErrUserpicUploadRequired            = newRpcSentinel("USERPIC_UPLOAD_REQUIRED")
+	ErrUsersTooFew                      = newRpcSentinel("USERS_TOO_FEW")
+	ErrUsersTooMuch                     = newRpcSentinel("USERS_TOO_MUCH")
+	ErrUserAdminInvalid                 = newRpcSentinel("USER_ADMIN_INVALID")
+	ErrUserAlreadyInvited               = newRpcSentinel("USER_ALREADY_INVITED")
+	ErrUserAlreadyParticipant           = newRpcSentinel("USER_ALREADY_PARTICIPANT")
+	ErrUserBannedInChannel              = newRpcSentinel("USER_BANNED_IN_CHANNEL")
+	ErrUserBlocked                      = newRpcSentinel("USER_BLOCKED")
+	ErrUserBot                          = newRpcSentinel("USER_BOT")
+	ErrUserBotInvalid                   = newRpcSentinel("USER_BOT_INVALID")
+	ErrUserBotRequired                  = newRpcSentinel("USER_BOT_REQUIRED")
+	ErrUserChannelsTooMuch              = newRpcSentinel("USER_CHANNELS_TOO_MUCH")
+	ErrUserCreator                      = newRpcSentinel("USER_CREATOR")
+	ErrUserDeactivated                  = newRpcSentinel("USER_DEACTIVATED")
+	ErrUserDeactivatedBan               = newRpcSentinel("USER_DEACTIVATED_BAN")
+	ErrUserIdInvalid                    = newRpcSentinel("USER_ID_INVALID")
+	ErrUserInvalid                      = newRpcSentinel("USER_INVALID")
+	ErrUserIsBlocked                    = newRpcSentinel("USER_IS_BLOCKED")
+	ErrUserIsBot                        = newRpcSentinel("USER_IS_BOT")
+	ErrUserKicked                       = newRpcSentinel("USER_KICKED")
+	ErrUserMigrate                      = newRpcSentinel("USER_MIGRATE_X")
+	ErrUserNotMutualContact             = newRpcSentinel("USER_NOT_MUTUAL_CONTACT")
+	ErrUserNotParticipant               = newRpcSentinel("USER_NOT_PARTICIPANT")
+	ErrUserPrivacyRestricted            = newRpcSentinel("USER_PRIVACY_RESTRICTED")
+	ErrUserRestricted                   = newRpcSentinel("USER_RESTRICTED")
+	ErrUserVolumeInvalid                = newRpcSentinel("USER_VOLUME_INVALID")
+	ErrVideoContentTypeInvalid          = newRpcSentinel("VIDEO_CONTENT_TYPE_INVALID")
+	ErrVideoFileInvalid                 = newRpcSentinel("VIDEO_FILE_INVALID")
+	ErrVideoTitleEmpty                  = newRpcSentinel("VIDEO_TITLE_EMPTY")
+	ErrWallpaperFileInvalid             = newRpcSentinel("WALLPAPER_FILE_INVALID")
+	ErrWallpaperInvalid                 = newRpcSentinel("WALLPAPER_INVALID")
+	ErrWallpaperMimeInvalid             = newRpcSentinel("WALLPAPER_MIME_INVALID")
+	ErrWcConvertUrlInvalid              = newRpcSentinel("WC_CONVERT_URL_INVALID")
+	ErrWebdocumentMimeInvalid           = newRpcSentinel("WEBDOCUMENT_MIME_INVALID")
+	ErrWebdocumentUrlInvalid            = newRpcSentinel("WEBDOCUMENT_URL_INVALID")
+	ErrWebpageCurlFailed                = newRpcSentinel("WEBPAGE_CURL_FAILED")
+	ErrWebpageMediaEmpty                = newRpcSentinel("WEBPAGE_MEDIA_EMPTY")
+	ErrWorkerBusyTooLongRetry           = newRpcSentinel("WORKER_BUSY_TOO_LONG_RETRY")
+	ErrYouBlockedUser                   = newRpcSentinel("YOU_BLOCKED_USER")
+)
@@ -0,0 +1,93 @@
+package gogram_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	mtproto "github.com/jwillp/gogram"
+	"github.com/jwillp/gogram/internal/mtproto/objects"
+)
+
+func rpcError(code int32, message string) error {
+	return mtproto.RpcErrorToNative(&objects.RpcError{ErrorCode: code, ErrorMessage: message})
+}
+
+func TestErrorIsSentinel(t *testing.T) {
+	for _, tc := range []struct {
+		err      error
+		sentinel error
+		want     bool
+	}{
+		{rpcError(420, "FLOOD_WAIT_42"), mtproto.ErrFloodWait, true},
+		{rpcError(420, "SLOWMODE_WAIT_5"), mtproto.ErrSlowmodeWait, true},
+		{rpcError(303, "USER_MIGRATE_4"), mtproto.ErrUserMigrate, true},
+		{rpcError(400, "PEER_ID_INVALID"), mtproto.ErrPeerIdInvalid, true},
+		{rpcError(420, "2FA_CONFIRM_WAIT_604800"), mtproto.Err2faConfirmWait, true},
+		{errors.Wrap(rpcError(400, "PEER_ID_INVALID"), "resolving peer"), mtproto.ErrPeerIdInvalid, true},
+		{rpcError(420, "FLOOD_WAIT_42"), mtproto.ErrSlowmodeWait, false},
+		{rpcError(400, "MESSAGE_NOT_MODIFIED"), mtproto.ErrPeerIdInvalid, false},
+	} {
+		if got := errors.Is(tc.err, tc.sentinel); got != tc.want {
+			t.Errorf("errors.Is(%v, %v) = %v, want %v", tc.err, tc.sentinel, got, tc.want)
+		}
+	}
+}
+
+func TestErrorAsWaitError(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want time.Duration
+	}{
+		{rpcError(420, "FLOOD_WAIT_42"), 42 * time.Second},
+		{rpcError(420, "SLOWMODE_WAIT_5"), 5 * time.Second},
+		{rpcError(400, "PREVIOUS_CHAT_IMPORT_ACTIVE_WAIT_3MIN"), 3 * time.Minute},
+		{errors.Wrap(rpcError(420, "FLOOD_WAIT_42"), "sending message"), 42 * time.Second},
+		// returned by the client when the wait is over the flood sleep threshold
+		{&mtproto.FloodWaitError{WaitError: mtproto.WaitError{Wait: time.Minute, Err: mtproto.ErrFloodWait}}, time.Minute},
+	} {
+		var waitErr *mtproto.WaitError
+		if !errors.As(tc.err, &waitErr) {
+			t.Errorf("%v is not a *WaitError", tc.err)
+			continue
+		}
+		if waitErr.Wait != tc.want {
+			t.Errorf("%v waits %s, want %s", tc.err, waitErr.Wait, tc.want)
+		}
+	}
+
+	var waitErr *mtproto.WaitError
+	if errors.As(rpcError(303, "USER_MIGRATE_4"), &waitErr) {
+		t.Error("USER_MIGRATE_4 is a *WaitError")
+	}
+	if errors.As(rpcError(400, "PEER_ID_INVALID"), &waitErr) {
+		t.Error("PEER_ID_INVALID is a *WaitError")
+	}
+}
+
+func TestErrorAsTyped(t *testing.T) {
+	var floodErr *mtproto.FloodWaitError
+	if !errors.As(rpcError(420, "FLOOD_WAIT_42"), &floodErr) || floodErr.Wait != 42*time.Second {
+		t.Errorf("FLOOD_WAIT_42 as *FloodWaitError: %v", floodErr)
+	}
+	if !errors.Is(floodErr, mtproto.ErrFloodWait) {
+		t.Error("*FloodWaitError doesn't unwrap to ErrFloodWait")
+	}
+
+	var slowmodeErr *mtproto.SlowmodeWaitError
+	if !errors.As(rpcError(420, "SLOWMODE_WAIT_5"), &slowmodeErr) || slowmodeErr.Wait != 5*time.Second {
+		t.Errorf("SLOWMODE_WAIT_5 as *SlowmodeWaitError: %v", slowmodeErr)
+	}
+	if errors.As(rpcError(420, "SLOWMODE_WAIT_5"), new(*mtproto.FloodWaitError)) {
+		t.Error("SLOWMODE_WAIT_5 is a *FloodWaitError")
+	}
+
+	var migrateErr *mtproto.MigrateError
+	if !errors.As(rpcError(303, "USER_MIGRATE_4"), &migrateErr) || migrateErr.Kind != "USER" || migrateErr.DC != 4 {
+		t.Errorf("USER_MIGRATE_4 as *MigrateError: %v", migrateErr)
+	}
+	if errors.As(rpcError(400, "PEER_ID_INVALID"), &migrateErr) {
+		t.Error("PEER_ID_INVALID is a *MigrateError")
+	}
+}
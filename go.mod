@@ -3,3 +3,5 @@ module github.com/jwillp/gogram
 go 1.19 // -> 1.20
 
 require github.com/pkg/errors v0.9.1
+
+require github.com/mattn/go-sqlite3 v1.14.22
@@ -4,12 +4,13 @@ package gogram
 
 import (
 	"bytes"
+	"crypto/rsa"
 	"crypto/sha1"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
-	"log"
 	"math/big"
+	"time"
 
 	ige "github.com/jwillp/gogram/internal/aes_ige"
 	"github.com/jwillp/gogram/internal/encoding/tl"
@@ -19,27 +20,54 @@ import (
 	"github.com/pkg/errors"
 )
 
-// https://core.telegram.org/mtproto/auth_key
+// publicKeyFor returns the first of the keys the server listed fingerprints of
+func (m *MTProto) publicKeyFor(fingerprints []int64) (*rsa.PublicKey, int64, bool) {
+	for _, fingerprint := range fingerprints {
+		for _, key := range m.publicKeys {
+			if int64(binary.LittleEndian.Uint64(keys.RSAFingerprint(key))) == fingerprint {
+				return key, fingerprint, true
+			}
+		}
+	}
+	return nil, 0, false
+}
+
+// makeAuthKey creates the permanent auth key and saves it to the session
 func (m *MTProto) makeAuthKey() error {
+	authKey, salt, err := m.exchangeKey(0)
+	if err != nil {
+		return err
+	}
+	m.SetAuthKey(authKey)
+	m.serverSalt = salt
+	m.encrypted = true
+	if !m.memorySession {
+		err = m.SaveSession()
+		if err != nil {
+			m.Logger.Error("saving session", "error", err)
+		}
+	}
+	return err
+}
+
+// exchangeKey runs the diffie-hellman exchange and returns the new key with its server salt,
+// expiresIn makes it a temporary key for perfect forward secrecy
+// https://core.telegram.org/mtproto/auth_key
+func (m *MTProto) exchangeKey(expiresIn int32) ([]byte, int64, error) {
 	m.serviceModeActivated = true
+	defer func() { m.serviceModeActivated = false }()
 	nonceFirst := tl.RandomInt128()
 	res, err := m.reqPQ(nonceFirst)
 	if err != nil {
-		return fmt.Errorf("reqPQ: %w", err)
+		return nil, 0, fmt.Errorf("reqPQ: %w", err)
 	}
 
 	if nonceFirst.Cmp(res.Nonce.Int) != 0 {
-		return fmt.Errorf("reqPQ: nonce mismatch")
-	}
-	found := false
-	for _, b := range res.Fingerprints {
-		if uint64(b) == binary.LittleEndian.Uint64(keys.RSAFingerprint(m.PublicKey)) {
-			found = true
-			break
-		}
+		return nil, 0, fmt.Errorf("reqPQ: nonce mismatch")
 	}
+	publicKey, keyFingerprint, found := m.publicKeyFor(res.Fingerprints)
 	if !found {
-		return fmt.Errorf("reqPQ: no matching fingerprint")
+		return nil, 0, fmt.Errorf("reqPQ: no public key matches the server fingerprints %v", res.Fingerprints)
 	}
 
 	// (encoding) p_q_inner_data
@@ -48,83 +76,83 @@ func (m *MTProto) makeAuthKey() error {
 	nonceSecond := tl.RandomInt256()
 	nonceServer := res.ServerNonce
 
-	message, err := tl.Marshal(&objects.PQInnerData{
+	var innerData tl.Object = &objects.PQInnerDataDc{
 		Pq:          res.Pq,
 		P:           p.Bytes(),
 		Q:           q.Bytes(),
 		Nonce:       nonceFirst,
 		ServerNonce: nonceServer,
 		NewNonce:    nonceSecond,
-	})
+		Dc:          int32(m.wireDC()),
+	}
+	if expiresIn > 0 {
+		innerData = &objects.PQInnerDataTempDc{
+			Pq:          res.Pq,
+			P:           p.Bytes(),
+			Q:           q.Bytes(),
+			Nonce:       nonceFirst,
+			ServerNonce: nonceServer,
+			NewNonce:    nonceSecond,
+			Dc:          int32(m.wireDC()),
+			ExpiresIn:   expiresIn,
+		}
+	}
+	message, err := tl.Marshal(innerData)
 	if err != nil {
-		m.Logger.Warn("makeAuthKey: failed to marshal pq inner data")
-		return err
+		m.Logger.Warn("makeAuthKey: failed to marshal pq inner data", "error", err)
+		return nil, 0, err
 	}
 
-	hashAndMsg := make([]byte, 255)
-	copy(hashAndMsg, append(Sha1(string(message)), message...))
-
-	encryptedMessage := math.DoRSAencrypt(hashAndMsg, m.PublicKey)
+	encryptedMessage, err := ige.RSAPad(message, publicKey)
+	if err != nil {
+		return nil, 0, fmt.Errorf("reqDHParams: %w", err)
+	}
 
-	keyFingerprint := int64(binary.LittleEndian.Uint64(keys.RSAFingerprint(m.PublicKey)))
 	dhResponse, err := m.reqDHParams(nonceFirst, nonceServer, p.Bytes(), q.Bytes(), keyFingerprint, encryptedMessage)
 	if err != nil {
-		return fmt.Errorf("reqDHParams: %w", err)
+		return nil, 0, fmt.Errorf("reqDHParams: %w", err)
 	}
 	dhParams, ok := dhResponse.(*objects.ServerDHParamsOk)
 	if !ok {
-		return fmt.Errorf("reqDHParams: invalid response")
+		return nil, 0, fmt.Errorf("reqDHParams: invalid response")
 	}
 
 	if nonceFirst.Cmp(dhParams.Nonce.Int) != 0 {
-		return fmt.Errorf("reqDHParams: nonce mismatch")
+		return nil, 0, fmt.Errorf("reqDHParams: nonce mismatch")
 	}
 	if nonceServer.Cmp(dhParams.ServerNonce.Int) != 0 {
-		return fmt.Errorf("reqDHParams: server nonce mismatch")
+		return nil, 0, fmt.Errorf("reqDHParams: server nonce mismatch")
 	}
 
 	// check of hash, random bytes trail removing occurs in this func already
 	decodedMessage, err := ige.DecryptMessageWithTempKeys(dhParams.EncryptedAnswer, nonceSecond.Int, nonceServer.Int)
 	if err != nil {
-		m.Logger.Warn(err.Error() + " - <retrying>")
-		return m.makeAuthKey()
+		m.Logger.Warn("decrypting server_DH_inner_data failed, retrying", "error", err)
+		return m.exchangeKey(expiresIn)
 	}
 
 	data, err := tl.DecodeUnknownObject(decodedMessage)
 	if err != nil {
-		return fmt.Errorf("decode: %w", err)
+		return nil, 0, fmt.Errorf("decode: %w", err)
 	}
 
 	dhi, ok := data.(*objects.ServerDHInnerData)
 	if !ok {
-		return fmt.Errorf("decode: invalid response")
+		return nil, 0, fmt.Errorf("decode: invalid response")
 	}
 	if nonceFirst.Cmp(dhi.Nonce.Int) != 0 {
-		return fmt.Errorf("decode: nonce mismatch")
+		return nil, 0, fmt.Errorf("decode: nonce mismatch")
 	}
 	if nonceServer.Cmp(dhi.ServerNonce.Int) != 0 {
-		return fmt.Errorf("decode: server nonce mismatch")
+		return nil, 0, fmt.Errorf("decode: server nonce mismatch")
 	}
 
+	m.setTimeOffset(time.Until(time.Unix(int64(dhi.ServerTime), 0)))
+
 	// this apparently is just part of diffie hellman, so just leave it as it is, hope that it will just work
 	_, gB, gAB := math.MakeGAB(dhi.G, big.NewInt(0).SetBytes(dhi.GA), big.NewInt(0).SetBytes(dhi.DhPrime))
 
-	authKey := gAB.Bytes()
-	if authKey[0] == 0 {
-		authKey = authKey[1:]
-	}
-
-	m.SetAuthKey(authKey)
-
-	t4 := make([]byte, 32+1+8)
-	copy(t4[0:], nonceSecond.Bytes())
-	t4[32] = 1
-	copy(t4[33:], Sha1Byte(m.GetAuthKey())[0:8])
-	nonceHash1 := Sha1Byte(t4)[4:20]
-	salt := make([]byte, tl.LongLen)
-	copy(salt, nonceSecond.Bytes()[:8])
-	math.Xor(salt, nonceServer.Bytes()[:8])
-	m.serverSalt = int64(binary.LittleEndian.Uint64(salt))
+	authKey, nonceHash1, serverSalt := authKeyData(gAB, nonceSecond.Int, nonceServer.Int)
 
 	// (encoding) client_DH_inner_data
 	clientDHData, err := tl.Marshal(&objects.ClientDHInnerData{
@@ -134,47 +162,56 @@ func (m *MTProto) makeAuthKey() error {
 		GB:          gB.Bytes(),
 	})
 	if err != nil {
-		log.Printf("TgCrypto - dh: %s", err)
-		return err
+		m.Logger.Warn("makeAuthKey: failed to marshal client dh inner data", "error", err)
+		return nil, 0, err
 	}
 
 	encryptedMessage, err = ige.EncryptMessageWithTempKeys(clientDHData, nonceSecond.Int, nonceServer.Int)
 	if err != nil {
-		return errors.New("dh: " + err.Error())
+		return nil, 0, errors.New("dh: " + err.Error())
 	}
 
 	dhGenStatus, err := m.setClientDHParams(nonceFirst, nonceServer, encryptedMessage)
 	if err != nil {
-		return errors.New("dh: " + err.Error())
+		return nil, 0, errors.New("dh: " + err.Error())
 	}
 
 	dhg, ok := dhGenStatus.(*objects.DHGenOk)
 	if !ok {
-		return fmt.Errorf("invalid response")
+		return nil, 0, fmt.Errorf("invalid response")
 	}
 	if nonceFirst.Cmp(dhg.Nonce.Int) != 0 {
-		return fmt.Errorf("handshake: Wrong nonce: %v, %v", nonceFirst, dhg.Nonce)
+		return nil, 0, fmt.Errorf("handshake: Wrong nonce: %v, %v", nonceFirst, dhg.Nonce)
 	}
 	if nonceServer.Cmp(dhg.ServerNonce.Int) != 0 {
-		return fmt.Errorf("handshake: Wrong server_nonce: %v, %v", nonceServer, dhg.ServerNonce)
+		return nil, 0, fmt.Errorf("handshake: Wrong server_nonce: %v, %v", nonceServer, dhg.ServerNonce)
 	}
-	if !bytes.Equal(nonceHash1, dhg.NewNonceHash1.Bytes()) {
-		return fmt.Errorf(
+	if !bytes.Equal(nonceHash1, dhg.NewNonceHash1.FillBytes(make([]byte, 16))) {
+		return nil, 0, fmt.Errorf(
 			"handshake: Wrong new_nonce_hash1: %v, %v",
 			hex.EncodeToString(nonceHash1),
-			hex.EncodeToString(dhg.NewNonceHash1.Bytes()),
+			hex.EncodeToString(dhg.NewNonceHash1.FillBytes(make([]byte, 16))),
 		)
 	}
 
-	m.serviceModeActivated = false
-	m.encrypted = true
-	if !m.memorySession {
-		err = m.SaveSession()
-		if err != nil {
-			m.Logger.Error("Saving session: %s", err)
-		}
-	}
-	return err
+	return authKey, serverSalt, nil
+}
+
+// authKeyData returns the auth key made of g_ab, the new_nonce_hash1 the server must answer with
+// and the first server salt. The key and the nonces are fixed size, leading zero bytes must be kept
+func authKeyData(gAB, newNonce, serverNonce *big.Int) (authKey, nonceHash1 []byte, serverSalt int64) {
+	authKey = gAB.FillBytes(make([]byte, 256))
+	nonce := newNonce.FillBytes(make([]byte, 32))
+
+	t4 := make([]byte, 32+1+8)
+	copy(t4[0:], nonce)
+	t4[32] = 1
+	copy(t4[33:], Sha1Byte(authKey)[0:8])
+	nonceHash1 = Sha1Byte(t4)[4:20]
+	salt := make([]byte, tl.LongLen)
+	copy(salt, nonce[:8])
+	math.Xor(salt, serverNonce.FillBytes(make([]byte, 16))[:8])
+	return authKey, nonceHash1, int64(binary.LittleEndian.Uint64(salt))
 }
 
 func Sha1(input string) []byte {
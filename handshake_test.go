@@ -0,0 +1,39 @@
+package gogram
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"math/big"
+	"testing"
+)
+
+// filled returns n bytes of b after zeros leading zero bytes
+func filled(n, zeros int, b byte) []byte {
+	data := bytes.Repeat([]byte{b}, n)
+	for i := 0; i < zeros; i++ {
+		data[i] = 0
+	}
+	return data
+}
+
+func TestAuthKeyDataKeepsLeadingZeros(t *testing.T) {
+	key := filled(256, 1, 0xab)
+	newNonce := filled(32, 2, 0xcd)
+	serverNonce := filled(16, 1, 0xef)
+
+	authKey, nonceHash1, salt := authKeyData(new(big.Int).SetBytes(key), new(big.Int).SetBytes(newNonce), new(big.Int).SetBytes(serverNonce))
+	if !bytes.Equal(authKey, key) {
+		t.Errorf("auth key lost its leading zero, %d bytes", len(authKey))
+	}
+
+	keyHash := sha1.Sum(key)
+	hash := sha1.Sum(append(append(append([]byte{}, newNonce...), 1), keyHash[:8]...))
+	if !bytes.Equal(nonceHash1, hash[4:20]) {
+		t.Errorf("new_nonce_hash1 is %x, want %x", nonceHash1, hash[4:20])
+	}
+	wantSalt := binary.LittleEndian.Uint64(newNonce[:8]) ^ binary.LittleEndian.Uint64(serverNonce[:8])
+	if uint64(salt) != wantSalt {
+		t.Errorf("server salt is %#x, want %#x", uint64(salt), wantSalt)
+	}
+}
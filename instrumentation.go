@@ -0,0 +1,458 @@
+package gogram
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Instrumentation gets the events of a connection, to export metrics or traces, see NewMetrics and
+// NewTracing. It's called from the connection goroutines, so it must be safe for concurrent use and
+// shouldn't block
+type Instrumentation interface {
+	// RequestStarted is called when a request is made, the returned function when it's done, after the retries
+	RequestStarted(ctx context.Context, req RequestInfo) func(RequestResult)
+	// FloodWait is called when the server asks to wait before the request is repeated
+	FloodWait(req RequestInfo, wait time.Duration)
+	// PacketSent is called for every write to the connection, with the size of the payload before encryption
+	PacketSent(dc, bytes int)
+	// PacketReceived is called for every message read from the connection, with the size of the decrypted payload
+	PacketReceived(dc, bytes int)
+	// Reconnected is called after every reconnect, err tells whether it failed
+	Reconnected(dc int, took time.Duration, err error)
+}
+
+// RequestInfo describes a request made with MakeRequest
+type RequestInfo struct {
+	Method string // name of the request, e.g. MessagesSendMessage
+	DC     int
+}
+
+// RequestResult describes how a request ended
+type RequestResult struct {
+	Duration time.Duration // from the first attempt until the response, retries included
+	Err      error         // rpc errors are *ErrResponseCode, or *FloodWaitError
+	Retries  int
+}
+
+// rpcErrorCode returns the rpc error inside err, flood wait errors wrap one too
+func rpcErrorCode(err error) (*ErrResponseCode, bool) {
+	var rpcErr *ErrResponseCode
+	return rpcErr, errors.As(err, &rpcErr)
+}
+
+type nopInstrumentation struct{}
+
+func (nopInstrumentation) RequestStarted(context.Context, RequestInfo) func(RequestResult) {
+	return func(RequestResult) {}
+}
+func (nopInstrumentation) FloodWait(RequestInfo, time.Duration)  {}
+func (nopInstrumentation) PacketSent(int, int)                   {}
+func (nopInstrumentation) PacketReceived(int, int)               {}
+func (nopInstrumentation) Reconnected(int, time.Duration, error) {}
+
+// instrumentation returns the instrumentation of the config, or one doing nothing
+func (c *Config) instrumentation() Instrumentation {
+	if c.Instrumentation != nil {
+		return c.Instrumentation
+	}
+	return nopInstrumentation{}
+}
+
+// MultiInstrumentation passes the events to every one of list, e.g. to both metrics and tracing
+func MultiInstrumentation(list ...Instrumentation) Instrumentation {
+	m := make(multiInstrumentation, 0, len(list))
+	for _, i := range list {
+		if i != nil {
+			m = append(m, i)
+		}
+	}
+	return m
+}
+
+type multiInstrumentation []Instrumentation
+
+func (m multiInstrumentation) RequestStarted(ctx context.Context, req RequestInfo) func(RequestResult) {
+	ends := make([]func(RequestResult), len(m))
+	for i, in := range m {
+		ends[i] = in.RequestStarted(ctx, req)
+	}
+	return func(res RequestResult) {
+		for _, end := range ends {
+			end(res)
+		}
+	}
+}
+
+func (m multiInstrumentation) FloodWait(req RequestInfo, wait time.Duration) {
+	for _, in := range m {
+		in.FloodWait(req, wait)
+	}
+}
+
+func (m multiInstrumentation) PacketSent(dc, bytes int) {
+	for _, in := range m {
+		in.PacketSent(dc, bytes)
+	}
+}
+
+func (m multiInstrumentation) PacketReceived(dc, bytes int) {
+	for _, in := range m {
+		in.PacketReceived(dc, bytes)
+	}
+}
+
+func (m multiInstrumentation) Reconnected(dc int, took time.Duration, err error) {
+	for _, in := range m {
+		in.Reconnected(dc, took, err)
+	}
+}
+
+// RequestDurationBuckets are the upper bounds, in seconds, of the request duration histogram of Metrics
+var RequestDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Metrics is an Instrumentation counting requests, their errors and durations, flood waits, reconnects and
+// traffic per data center. WriteTo writes them in the Prometheus text format, and as an http.Handler it
+// serves them, so it can be mounted at /metrics
+type Metrics struct {
+	mutex      sync.Mutex
+	requests   map[requestKey]*requestStats
+	inFlight   map[int]int
+	floodWaits map[requestKey]*floodWaitStats
+	reconnects map[reconnectKey]uint64
+	sent       map[int]*trafficStats
+	received   map[int]*trafficStats
+}
+
+type requestKey struct {
+	dc     int
+	method string
+}
+
+type requestStats struct {
+	results map[string]uint64 // by code: ok, the rpc error code or error
+	errors  map[rpcErrorKey]uint64
+	buckets []uint64 // not cumulative, the last one is +Inf
+	sum     float64
+	count   uint64
+}
+
+type rpcErrorKey struct {
+	code    int
+	message string
+}
+
+type floodWaitStats struct {
+	count   uint64
+	seconds float64
+}
+
+type reconnectKey struct {
+	dc     int
+	result string
+}
+
+type trafficStats struct {
+	packets uint64
+	bytes   uint64
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requests:   make(map[requestKey]*requestStats),
+		inFlight:   make(map[int]int),
+		floodWaits: make(map[requestKey]*floodWaitStats),
+		reconnects: make(map[reconnectKey]uint64),
+		sent:       make(map[int]*trafficStats),
+		received:   make(map[int]*trafficStats),
+	}
+}
+
+func (m *Metrics) RequestStarted(_ context.Context, req RequestInfo) func(RequestResult) {
+	m.mutex.Lock()
+	m.inFlight[req.DC]++
+	m.mutex.Unlock()
+	return func(res RequestResult) {
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		m.inFlight[req.DC]--
+		key := requestKey{req.DC, req.Method}
+		stats, ok := m.requests[key]
+		if !ok {
+			stats = &requestStats{results: make(map[string]uint64), errors: make(map[rpcErrorKey]uint64), buckets: make([]uint64, len(RequestDurationBuckets)+1)}
+			m.requests[key] = stats
+		}
+		code := "ok"
+		if rpcErr, ok := rpcErrorCode(res.Err); ok {
+			code = strconv.Itoa(rpcErr.Code)
+			stats.errors[rpcErrorKey{rpcErr.Code, rpcErr.Message}]++
+		} else if res.Err != nil {
+			code = "error"
+		}
+		stats.results[code]++
+		seconds := res.Duration.Seconds()
+		stats.buckets[sort.SearchFloat64s(RequestDurationBuckets, seconds)]++
+		stats.sum += seconds
+		stats.count++
+	}
+}
+
+func (m *Metrics) FloodWait(req RequestInfo, wait time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	key := requestKey{req.DC, req.Method}
+	stats, ok := m.floodWaits[key]
+	if !ok {
+		stats = &floodWaitStats{}
+		m.floodWaits[key] = stats
+	}
+	stats.count++
+	stats.seconds += wait.Seconds()
+}
+
+func (m *Metrics) PacketSent(dc, bytes int) {
+	m.countTraffic(m.sent, dc, bytes)
+}
+
+func (m *Metrics) PacketReceived(dc, bytes int) {
+	m.countTraffic(m.received, dc, bytes)
+}
+
+func (m *Metrics) countTraffic(traffic map[int]*trafficStats, dc, bytes int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	stats, ok := traffic[dc]
+	if !ok {
+		stats = &trafficStats{}
+		traffic[dc] = stats
+	}
+	stats.packets++
+	stats.bytes += uint64(bytes)
+}
+
+func (m *Metrics) Reconnected(dc int, _ time.Duration, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.reconnects[reconnectKey{dc, result}]++
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	var b bytes.Buffer
+	m.mutex.Lock()
+	m.write(&b)
+	m.mutex.Unlock()
+	n, err := w.Write(b.Bytes())
+	return int64(n), errors.Wrap(err, "writing metrics")
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+func (m *Metrics) write(b *bytes.Buffer) {
+	requests := sortedRequestKeys(m.requests)
+
+	metricHeader(b, "gogram_requests_total", "counter", "Requests made, by result: ok, the rpc error code or error.")
+	for _, key := range requests {
+		stats := m.requests[key]
+		codes := make([]string, 0, len(stats.results))
+		for code := range stats.results {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			fmt.Fprintf(b, "gogram_requests_total{dc=\"%d\",method=%s,code=%s} %d\n", key.dc, quoteLabel(key.method), quoteLabel(code), stats.results[code])
+		}
+	}
+
+	metricHeader(b, "gogram_rpc_errors_total", "counter", "Rpc errors returned by requests, by code and message.")
+	for _, key := range requests {
+		stats := m.requests[key]
+		errs := make([]rpcErrorKey, 0, len(stats.errors))
+		for e := range stats.errors {
+			errs = append(errs, e)
+		}
+		sort.Slice(errs, func(i, j int) bool {
+			if errs[i].code != errs[j].code {
+				return errs[i].code < errs[j].code
+			}
+			return errs[i].message < errs[j].message
+		})
+		for _, e := range errs {
+			fmt.Fprintf(b, "gogram_rpc_errors_total{dc=\"%d\",method=%s,code=\"%d\",message=%s} %d\n", key.dc, quoteLabel(key.method), e.code, quoteLabel(e.message), stats.errors[e])
+		}
+	}
+
+	metricHeader(b, "gogram_request_duration_seconds", "histogram", "Duration of requests, retries included.")
+	for _, key := range requests {
+		stats := m.requests[key]
+		labels := fmt.Sprintf("dc=\"%d\",method=%s", key.dc, quoteLabel(key.method))
+		var cumulative uint64
+		for i, count := range stats.buckets {
+			cumulative += count
+			le := "+Inf"
+			if i < len(RequestDurationBuckets) {
+				le = strconv.FormatFloat(RequestDurationBuckets[i], 'g', -1, 64)
+			}
+			fmt.Fprintf(b, "gogram_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, le, cumulative)
+		}
+		fmt.Fprintf(b, "gogram_request_duration_seconds_sum{%s} %s\n", labels, formatFloat(stats.sum))
+		fmt.Fprintf(b, "gogram_request_duration_seconds_count{%s} %d\n", labels, stats.count)
+	}
+
+	metricHeader(b, "gogram_requests_in_flight", "gauge", "Requests waiting for their response.")
+	for _, dc := range sortedDCs(m.inFlight) {
+		fmt.Fprintf(b, "gogram_requests_in_flight{dc=\"%d\"} %d\n", dc, m.inFlight[dc])
+	}
+
+	floodWaits := sortedRequestKeys(m.floodWaits)
+	metricHeader(b, "gogram_flood_waits_total", "counter", "Flood waits the server asked for.")
+	for _, key := range floodWaits {
+		fmt.Fprintf(b, "gogram_flood_waits_total{dc=\"%d\",method=%s} %d\n", key.dc, quoteLabel(key.method), m.floodWaits[key].count)
+	}
+	metricHeader(b, "gogram_flood_wait_seconds_total", "counter", "Sum of the flood waits the server asked for.")
+	for _, key := range floodWaits {
+		fmt.Fprintf(b, "gogram_flood_wait_seconds_total{dc=\"%d\",method=%s} %s\n", key.dc, quoteLabel(key.method), formatFloat(m.floodWaits[key].seconds))
+	}
+
+	reconnects := make([]reconnectKey, 0, len(m.reconnects))
+	for key := range m.reconnects {
+		reconnects = append(reconnects, key)
+	}
+	sort.Slice(reconnects, func(i, j int) bool {
+		if reconnects[i].dc != reconnects[j].dc {
+			return reconnects[i].dc < reconnects[j].dc
+		}
+		return reconnects[i].result < reconnects[j].result
+	})
+	metricHeader(b, "gogram_reconnects_total", "counter", "Reconnects, by result: ok or error.")
+	for _, key := range reconnects {
+		fmt.Fprintf(b, "gogram_reconnects_total{dc=\"%d\",result=\"%s\"} %d\n", key.dc, key.result, m.reconnects[key])
+	}
+
+	for _, t := range []struct {
+		direction string
+		stats     map[int]*trafficStats
+	}{{"sent", m.sent}, {"received", m.received}} {
+		metricHeader(b, "gogram_"+t.direction+"_packets_total", "counter", "Packets "+t.direction+".")
+		for _, dc := range sortedDCs(t.stats) {
+			fmt.Fprintf(b, "gogram_%s_packets_total{dc=\"%d\"} %d\n", t.direction, dc, t.stats[dc].packets)
+		}
+		metricHeader(b, "gogram_"+t.direction+"_bytes_total", "counter", "Bytes "+t.direction+", before encryption.")
+		for _, dc := range sortedDCs(t.stats) {
+			fmt.Fprintf(b, "gogram_%s_bytes_total{dc=\"%d\"} %d\n", t.direction, dc, t.stats[dc].bytes)
+		}
+	}
+}
+
+func metricHeader(b *bytes.Buffer, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quoteLabel(value string) string {
+	return `"` + labelEscaper.Replace(value) + `"`
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func sortedRequestKeys[V any](m map[requestKey]V) []requestKey {
+	keys := make([]requestKey, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].dc != keys[j].dc {
+			return keys[i].dc < keys[j].dc
+		}
+		return keys[i].method < keys[j].method
+	})
+	return keys
+}
+
+func sortedDCs[V any](m map[int]V) []int {
+	dcs := make([]int, 0, len(m))
+	for dc := range m {
+		dcs = append(dcs, dc)
+	}
+	sort.Ints(dcs)
+	return dcs
+}
+
+// Tracer starts spans, it has the shape of the Start method of an OpenTelemetry trace.Tracer, which is
+// adapted with a few lines:
+//
+//	type otelTracer struct{ trace.Tracer }
+//
+//	func (t otelTracer) Start(ctx context.Context, name string) (context.Context, gogram.Span) {
+//		ctx, span := t.Tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+//		return ctx, otelSpan{span}
+//	}
+//
+//	type otelSpan struct{ trace.Span }
+//
+//	func (s otelSpan) SetAttribute(key string, value any) {
+//		s.Span.SetAttributes(attribute.String(key, fmt.Sprint(value)))
+//	}
+//	func (s otelSpan) RecordError(err error) { s.Span.RecordError(err); s.Span.SetStatus(codes.Error, err.Error()) }
+//	func (s otelSpan) End()                  { s.Span.End() }
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer
+type Span interface {
+	SetAttribute(key string, value any)
+	RecordError(err error)
+	End()
+}
+
+// NewTracing returns an Instrumentation making a span of every request, named mtproto/<method>, with the
+// attributes rpc.system, rpc.method, mtproto.dc, mtproto.retries and mtproto.error_code following the
+// OpenTelemetry rpc conventions. The span is a child of the span in the context of the request
+func NewTracing(t Tracer) Instrumentation {
+	return &tracing{t: t}
+}
+
+type tracing struct {
+	nopInstrumentation
+	t Tracer
+}
+
+func (t *tracing) RequestStarted(ctx context.Context, req RequestInfo) func(RequestResult) {
+	_, span := t.t.Start(ctx, "mtproto/"+req.Method)
+	span.SetAttribute("rpc.system", "mtproto")
+	span.SetAttribute("rpc.method", req.Method)
+	span.SetAttribute("mtproto.dc", req.DC)
+	return func(res RequestResult) {
+		if res.Retries > 0 {
+			span.SetAttribute("mtproto.retries", res.Retries)
+		}
+		if res.Err != nil {
+			if rpcErr, ok := rpcErrorCode(res.Err); ok {
+				span.SetAttribute("mtproto.error_code", rpcErr.Code)
+			}
+			span.RecordError(res.Err)
+		}
+		span.End()
+	}
+}
@@ -75,6 +75,16 @@ func encrypt(msg, authKey []byte, decode bool) (out, msgKey []byte, _ error) {
 	return out, msgKey, nil
 }
 
+// EncryptAsServer encrypts a message the way the server does, for fake servers in tests
+func EncryptAsServer(msg, authKey []byte) (out, msgKey []byte, _ error) {
+	return encrypt(msg, authKey, true)
+}
+
+// DecryptAsServer decrypts a message sent by a client, for fake servers in tests
+func DecryptAsServer(msg, authKey, msgKey []byte) ([]byte, error) {
+	return decrypt(msg, authKey, msgKey, false)
+}
+
 // checkData это msgkey в понятиях мтпрото, нужно что бы проверить, успешно ли прошла расшифровка
 func Decrypt(msg, authKey, checkData []byte) ([]byte, error) {
 	return decrypt(msg, authKey, checkData, true)
@@ -176,17 +186,21 @@ func generateTempKeys(nonceSecond, nonceServer *big.Int) (key []byte, iv []byte,
 		return nil, nil, errors.New("nonceServer is nil")
 	}
 
+	// the nonces are fixed size, leading zero bytes must be kept
+	newNonce := nonceSecond.FillBytes(make([]byte, 32))
+	serverNonce := nonceServer.FillBytes(make([]byte, 16))
+
 	// nonceSecond + nonceServer
 	t1 := make([]byte, 48)
-	copy(t1[0:], nonceSecond.Bytes())
-	copy(t1[32:], nonceServer.Bytes())
+	copy(t1[0:], newNonce)
+	copy(t1[32:], serverNonce)
 	// SHA1 of nonceSecond + nonceServer
 	hash1 := utils.Sha1Byte(t1)
 
 	// nonceServer + nonceSecond
 	t2 := make([]byte, 48)
-	copy(t2[0:], nonceServer.Bytes())
-	copy(t2[16:], nonceSecond.Bytes())
+	copy(t2[0:], serverNonce)
+	copy(t2[16:], newNonce)
 	// SHA1 of nonceServer + nonceSecond
 	hash2 := utils.Sha1Byte(t2)
 
@@ -198,8 +212,8 @@ func generateTempKeys(nonceSecond, nonceServer *big.Int) (key []byte, iv []byte,
 	copy(tmpAESKey[20:], hash2[0:12])
 
 	t3 := make([]byte, 64) // nonceSecond + nonceSecond
-	copy(t3[0:], nonceSecond.Bytes())
-	copy(t3[32:], nonceSecond.Bytes())
+	copy(t3[0:], newNonce)
+	copy(t3[32:], newNonce)
 	hash3 := utils.Sha1Byte(t3) // SHA1 of nonceSecond + nonceSecond
 
 	// substr (SHA1(server_nonce + new_nonce), 12, 8) + SHA1(new_nonce + new_nonce) + substr (new_nonce, 0, 4);
@@ -209,7 +223,7 @@ func generateTempKeys(nonceSecond, nonceServer *big.Int) (key []byte, iv []byte,
 	// SHA1 of nonceSecond + nonceSecond
 	copy(tmpAESIV[8:], hash3)
 	// substr (nonceSecond, 0, 4)
-	copy(tmpAESIV[28:], nonceSecond.Bytes()[0:4])
+	copy(tmpAESIV[28:], newNonce[0:4])
 
 	return tmpAESKey, tmpAESIV, nil
 }
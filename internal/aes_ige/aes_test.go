@@ -0,0 +1,48 @@
+package ige
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"math/big"
+	"testing"
+)
+
+func concat(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+// the temporary key and iv of the key exchange, as the spec writes them
+// https://core.telegram.org/mtproto/auth_key#dh-exchange-initiation
+func TestTempKeysKeepLeadingZeros(t *testing.T) {
+	newNonce := bytes.Repeat([]byte{0xcd}, 32)
+	newNonce[0], newNonce[1] = 0, 0
+	serverNonce := bytes.Repeat([]byte{0xef}, 16)
+	serverNonce[0] = 0
+
+	newServer := sha1.Sum(concat(newNonce, serverNonce))
+	serverNew := sha1.Sum(concat(serverNonce, newNonce))
+	newNew := sha1.Sum(concat(newNonce, newNonce))
+	wantKey := concat(newServer[:], serverNew[:12])
+	wantIV := concat(serverNew[12:20], newNew[:], newNonce[:4])
+
+	key, iv, err := generateTempKeys(new(big.Int).SetBytes(newNonce), new(big.Int).SetBytes(serverNonce))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(key, wantKey) {
+		t.Errorf("key is %x, want %x", key, wantKey)
+	}
+	if !bytes.Equal(iv, wantIV) {
+		t.Errorf("iv is %x, want %x", iv, wantIV)
+	}
+
+	msg := []byte("server_DH_inner_data")
+	encrypted, err := EncryptMessageWithTempKeys(msg, new(big.Int).SetBytes(newNonce), new(big.Int).SetBytes(serverNonce))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decrypted, err := DecryptMessageWithTempKeys(encrypted, new(big.Int).SetBytes(newNonce), new(big.Int).SetBytes(serverNonce))
+	if err != nil || !bytes.Equal(decrypted, msg) {
+		t.Errorf("decrypted %q, %v, want %q", decrypted, err, msg)
+	}
+}
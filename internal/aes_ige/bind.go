@@ -0,0 +1,85 @@
+package ige
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+
+	"github.com/jwillp/gogram/internal/utils"
+	"github.com/pkg/errors"
+)
+
+// EncryptBindMessage encrypts bind_auth_key_inner with the permanent key for auth.bindTempAuthKey,
+// it uses the MTProto 1.0 scheme with random salt and session id, msgID must be the id of the bind request
+// https://core.telegram.org/method/auth.bindTempAuthKey
+func EncryptBindMessage(msg, permKey []byte, msgID int64) ([]byte, error) {
+	plain := make([]byte, 32, 32+len(msg)+16)
+	if _, err := rand.Read(plain[:16]); err != nil {
+		return nil, errors.Wrap(err, "generating salt and session id")
+	}
+	binary.LittleEndian.PutUint64(plain[16:], uint64(msgID))
+	binary.LittleEndian.PutUint32(plain[28:], uint32(len(msg))) // seqno stays 0
+	plain = append(plain, msg...)
+
+	sum := sha1.Sum(plain)
+	msgKey := sum[4:20]
+	if rest := len(plain) % 16; rest != 0 {
+		padding := make([]byte, 16-rest)
+		if _, err := rand.Read(padding); err != nil {
+			return nil, errors.Wrap(err, "generating padding")
+		}
+		plain = append(plain, padding...)
+	}
+
+	aesKey, aesIV := aesKeysV1(msgKey, permKey)
+	encrypted := make([]byte, len(plain))
+	if err := doAES256IGEencrypt(plain, encrypted, aesKey, aesIV); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, 8+len(msgKey)+len(encrypted))
+	out = append(out, utils.AuthKeyHash(permKey)...)
+	out = append(out, msgKey...)
+	return append(out, encrypted...), nil
+}
+
+// DecryptBindMessage is the server side of EncryptBindMessage, it returns the msg_id and the bind_auth_key_inner
+// of a message encrypted with permKey
+func DecryptBindMessage(data, permKey []byte) (int64, []byte, error) {
+	if len(data) < 8+16+32 || (len(data)-8-16)%16 != 0 {
+		return 0, nil, errors.Errorf("encrypted message has a wrong length of %d bytes", len(data))
+	}
+	msgKey := data[8:24]
+	aesKey, aesIV := aesKeysV1(msgKey, permKey)
+	plain := make([]byte, len(data)-24)
+	if err := doAES256IGEdecrypt(data[24:], plain, aesKey, aesIV); err != nil {
+		return 0, nil, err
+	}
+	size := int(binary.LittleEndian.Uint32(plain[28:]))
+	if size > len(plain)-32 {
+		return 0, nil, errors.Errorf("message length %d is larger than the message", size)
+	}
+	if sum := sha1.Sum(plain[:32+size]); !bytes.Equal(sum[4:20], msgKey) {
+		return 0, nil, errors.New("wrong msg_key")
+	}
+	return int64(binary.LittleEndian.Uint64(plain[16:])), plain[32 : 32+size], nil
+}
+
+// aesKeysV1 derives the key and iv of a client message the MTProto 1.0 way
+func aesKeysV1(msgKey, authKey []byte) (key, iv []byte) {
+	concat := func(parts ...[]byte) []byte {
+		var b []byte
+		for _, p := range parts {
+			b = append(b, p...)
+		}
+		return b
+	}
+	a := sha1.Sum(concat(msgKey, authKey[0:32]))
+	b := sha1.Sum(concat(authKey[32:48], msgKey, authKey[48:64]))
+	c := sha1.Sum(concat(authKey[64:96], msgKey))
+	d := sha1.Sum(concat(msgKey, authKey[96:128]))
+	key = concat(a[0:8], b[8:20], c[4:16])
+	iv = concat(a[8:20], b[0:8], c[16:20], d[0:8])
+	return key, iv
+}
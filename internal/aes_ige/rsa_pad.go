@@ -0,0 +1,90 @@
+package ige
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	rsaPadDataLen = 192
+	rsaPadKeyLen  = 32
+)
+
+// RSAPad encrypts the inner data of req_DH_params with RSA_PAD, data must be at most 144 bytes
+// https://core.telegram.org/mtproto/auth_key#presenting-proof-of-work-server-authentication
+func RSAPad(data []byte, key *rsa.PublicKey) ([]byte, error) {
+	if len(data) > 144 {
+		return nil, errors.Errorf("rsa_pad: data is too long, %d bytes", len(data))
+	}
+	padded := make([]byte, rsaPadDataLen)
+	copy(padded, data)
+	if _, err := rand.Read(padded[len(data):]); err != nil {
+		return nil, errors.Wrap(err, "rsa_pad: generating padding")
+	}
+	reversed := make([]byte, rsaPadDataLen)
+	for i, b := range padded {
+		reversed[rsaPadDataLen-1-i] = b
+	}
+
+	tempKey := make([]byte, rsaPadKeyLen)
+	for {
+		if _, err := rand.Read(tempKey); err != nil {
+			return nil, errors.Wrap(err, "rsa_pad: generating temp key")
+		}
+		hash := sha256.Sum256(append(append([]byte{}, tempKey...), padded...))
+		withHash := append(append([]byte{}, reversed...), hash[:]...)
+
+		encrypted := make([]byte, len(withHash))
+		if err := doAES256IGEencrypt(withHash, encrypted, tempKey, make([]byte, 32)); err != nil {
+			return nil, errors.Wrap(err, "rsa_pad: aes")
+		}
+		encryptedHash := sha256.Sum256(encrypted)
+		keyEncrypted := make([]byte, 0, rsaPadKeyLen+len(encrypted))
+		for i := range tempKey {
+			keyEncrypted = append(keyEncrypted, tempKey[i]^encryptedHash[i])
+		}
+		keyEncrypted = append(keyEncrypted, encrypted...)
+
+		// the number must be less than the modulus, otherwise another temp key is tried
+		z := new(big.Int).SetBytes(keyEncrypted)
+		if z.Cmp(key.N) >= 0 {
+			continue
+		}
+		c := new(big.Int).Exp(z, big.NewInt(int64(key.E)), key.N)
+		return c.FillBytes(make([]byte, 256)), nil
+	}
+}
+
+// RSAUnpad reverses RSAPad with the private key, as the server does, the random padding is left at the end
+func RSAUnpad(encrypted []byte, key *rsa.PrivateKey) ([]byte, error) {
+	c := new(big.Int).SetBytes(encrypted)
+	if c.Cmp(key.N) >= 0 {
+		return nil, errors.New("rsa_pad: data is larger than the modulus")
+	}
+	keyEncrypted := new(big.Int).Exp(c, key.D, key.N).FillBytes(make([]byte, 256))
+	encrypted = keyEncrypted[rsaPadKeyLen:]
+	encryptedHash := sha256.Sum256(encrypted)
+	tempKey := make([]byte, rsaPadKeyLen)
+	for i := range tempKey {
+		tempKey[i] = keyEncrypted[i] ^ encryptedHash[i]
+	}
+
+	withHash := make([]byte, len(encrypted))
+	if err := doAES256IGEdecrypt(encrypted, withHash, tempKey, make([]byte, 32)); err != nil {
+		return nil, errors.Wrap(err, "rsa_pad: aes")
+	}
+	padded := make([]byte, rsaPadDataLen)
+	for i, b := range withHash[:rsaPadDataLen] {
+		padded[rsaPadDataLen-1-i] = b
+	}
+	hash := sha256.Sum256(append(append([]byte{}, tempKey...), padded...))
+	if !bytes.Equal(hash[:], withHash[rsaPadDataLen:]) {
+		return nil, errors.New("rsa_pad: hash mismatch")
+	}
+	return padded, nil
+}
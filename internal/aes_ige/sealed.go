@@ -0,0 +1,172 @@
+// Copyright (c) 2023 RoseLoverX
+
+package ige
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// sealed files layout (version 1):
+//
+//	magic (4) | version (1) | salt (16) | nonce (12) | AES-256-GCM ciphertext + tag
+//
+// key is derived from the passphrase with PBKDF2-HMAC-SHA256, the header is authenticated as additional data.
+const (
+	sealedVersion    byte = 1
+	sealedSaltLen         = 16
+	sealedIterations      = 100000
+	sealedKeyLen          = 32
+)
+
+var sealedMagic = [...]byte{'G', 'G', 'S', 'F'}
+
+var (
+	ErrNotSealed          = errors.New("data is not sealed")
+	ErrUnsupportedVersion = errors.New("unsupported sealed data version")
+	ErrPassphraseMismatch = errors.New("wrong passphrase or corrupted data")
+	ErrPassphraseNotGiven = errors.New("data is sealed, but no passphrase provided")
+	sealedHeaderLen       = len(sealedMagic) + 1 + sealedSaltLen
+)
+
+// IsSealed reports whether data was produced by Sealer.Seal (of any version).
+func IsSealed(data []byte) bool {
+	return len(data) > len(sealedMagic) && bytes.Equal(data[:len(sealedMagic)], sealedMagic[:])
+}
+
+// Sealer encrypts and decrypts small files (sessions, caches) with a key derived from a passphrase. Key
+// derivation is slow on purpose, so the sealer remembers the last derived key and reuses its salt.
+type Sealer struct {
+	passphrase string
+
+	mutex sync.Mutex
+	salt  []byte
+	key   []byte
+}
+
+func NewSealer(passphrase string) *Sealer {
+	return &Sealer{passphrase: passphrase}
+}
+
+func (s *Sealer) keyFor(salt []byte) []byte {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.key == nil || !bytes.Equal(s.salt, salt) {
+		s.salt = append([]byte{}, salt...)
+		s.key = pbkdf2SHA256([]byte(s.passphrase), salt, sealedIterations, sealedKeyLen)
+	}
+	return s.key
+}
+
+func (s *Sealer) currentSalt() ([]byte, error) {
+	s.mutex.Lock()
+	salt := s.salt
+	s.mutex.Unlock()
+	if salt != nil {
+		return salt, nil
+	}
+	salt = make([]byte, sealedSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, errors.Wrap(err, "generating salt")
+	}
+	return salt, nil
+}
+
+// Seal encrypts data, output always starts with versioned header.
+func (s *Sealer) Seal(data []byte) ([]byte, error) {
+	salt, err := s.currentSalt()
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(s.keyFor(salt))
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, sealedHeaderLen)
+	header = append(header, sealedMagic[:]...)
+	header = append(header, sealedVersion)
+	header = append(header, salt...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "generating nonce")
+	}
+
+	out := append(header, nonce...)
+	return aead.Seal(out, nonce, data, header), nil
+}
+
+// Open decrypts data produced by Seal.
+func (s *Sealer) Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return nil, ErrNotSealed
+	}
+	if data[len(sealedMagic)] != sealedVersion {
+		return nil, ErrUnsupportedVersion
+	}
+	if len(data) < sealedHeaderLen {
+		return nil, ErrDataTooSmall
+	}
+	header, rest := data[:sealedHeaderLen], data[sealedHeaderLen:]
+
+	aead, err := newGCM(s.keyFor(header[len(sealedMagic)+1:]))
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrDataTooSmall
+	}
+
+	plain, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], header)
+	if err != nil {
+		return nil, ErrPassphraseMismatch
+	}
+	return plain, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2SHA256 is RFC 8018 PBKDF2 with HMAC-SHA256 as pseudorandom function
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLen := prf.Size()
+	blocks := (keyLen + hashLen - 1) / hashLen
+
+	counter := make([]byte, 4)
+	u := make([]byte, hashLen)
+	derived := make([]byte, 0, blocks*hashLen)
+	for block := 1; block <= blocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(counter, uint32(block))
+		prf.Write(counter)
+		derived = prf.Sum(derived)
+
+		t := derived[len(derived)-hashLen:]
+		copy(u, t)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for x := range u {
+				t[x] ^= u[x]
+			}
+		}
+	}
+	return derived[:keyLen]
+}
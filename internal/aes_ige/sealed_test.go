@@ -0,0 +1,57 @@
+package ige
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// test vectors of PBKDF2-HMAC-SHA256 from RFC 7914, section 11
+func TestPBKDF2SHA256(t *testing.T) {
+	for _, tc := range []struct {
+		password, salt string
+		iterations     int
+		want           string
+	}{
+		{"passwd", "salt", 1, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+		{"Password", "NaCl", 80000, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"},
+	} {
+		want, _ := hex.DecodeString(tc.want)
+		if got := pbkdf2SHA256([]byte(tc.password), []byte(tc.salt), tc.iterations, len(want)); !bytes.Equal(got, want) {
+			t.Errorf("pbkdf2(%q, %q, %d) = %x, want %x", tc.password, tc.salt, tc.iterations, got, want)
+		}
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	data := []byte(`{"key":"secret"}`)
+	sealed, err := NewSealer("correct horse").Seal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsSealed(sealed) || bytes.Contains(sealed, data) {
+		t.Fatalf("sealed data %x isn't marked or holds the plaintext", sealed)
+	}
+
+	// a new sealer derives the key again from the salt in the header
+	opened, err := NewSealer("correct horse").Open(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, data) {
+		t.Errorf("opened %q, want %q", opened, data)
+	}
+
+	if _, err := NewSealer("wrong").Open(sealed); !errors.Is(err, ErrPassphraseMismatch) {
+		t.Errorf("opening with a wrong passphrase: %v, want ErrPassphraseMismatch", err)
+	}
+	tampered := append([]byte{}, sealed...)
+	tampered[len(sealedMagic)+1] ^= 1 // the salt is authenticated
+	if _, err := NewSealer("correct horse").Open(tampered); !errors.Is(err, ErrPassphraseMismatch) {
+		t.Errorf("opening a tampered header: %v, want ErrPassphraseMismatch", err)
+	}
+	if _, err := NewSealer("correct horse").Open(data); !errors.Is(err, ErrNotSealed) {
+		t.Errorf("opening plain data: %v, want ErrNotSealed", err)
+	}
+}
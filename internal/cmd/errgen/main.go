@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const helpMsg = `errgen
+usage: errgen errors.go errors_gen.go
+generates sentinel errors for every rpc error of the errorMessages table
+`
+
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Print(helpMsg)
+		return
+	}
+
+	if err := root(os.Args[1], os.Args[2]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+}
+
+func root(infile, outfile string) error {
+	messages, err := parseErrorMessages(infile)
+	if err != nil {
+		return err
+	}
+
+	code, err := generate(messages)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(outfile, code, 0644)
+}
+
+// parseErrorMessages returns the keys of the errorMessages map literal
+func parseErrorMessages(file string) ([]string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", file, err)
+	}
+
+	var keys []string
+	ast.Inspect(f, func(n ast.Node) bool {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok || len(spec.Names) != 1 || spec.Names[0].Name != "errorMessages" || len(spec.Values) != 1 {
+			return true
+		}
+		lit, ok := spec.Values[0].(*ast.CompositeLit)
+		if !ok {
+			return false
+		}
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			if key, ok := kv.Key.(*ast.BasicLit); ok && key.Kind == token.STRING {
+				if s, err := strconv.Unquote(key.Value); err == nil {
+					keys = append(keys, s)
+				}
+			}
+		}
+		return false
+	})
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("errorMessages not found in %s", file)
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// goName converts an rpc error name to a go identifier: FILE_PART_X_MISSING -> ErrFilePartMissing
+func goName(message string) string {
+	name := "Err"
+	for _, word := range strings.Split(message, "_") {
+		switch word {
+		case "", "X":
+			continue
+		case "XMIN":
+			word = "MIN"
+		}
+		name += word[:1] + strings.ToLower(word[1:])
+	}
+	return name
+}
+
+func generate(messages []string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteString("// Code generated by errgen; DO NOT EDIT.\n\npackage gogram\n\n")
+	buf.WriteString("// sentinel rpc errors, compare with errors.Is(err, ErrFloodWait)\nvar (\n")
+
+	seen := make(map[string]string, len(messages))
+	for _, message := range messages {
+		name := goName(message)
+		if other, ok := seen[name]; ok {
+			return nil, fmt.Errorf("%s and %s are both named %s", other, message, name)
+		}
+		seen[name] = message
+		fmt.Fprintf(buf, "\t%s = newRpcSentinel(%q)\n", name, message)
+	}
+	buf.WriteString(")\n")
+
+	return format.Source(buf.Bytes())
+}
@@ -85,6 +85,7 @@ func (g *Generator) Generate() error {
 
 func (*Generator) generateFile(f func(file *jen.File), filename string) error {
 	file := jen.NewFile("telegram")
+	file.ImportAlias(mtprotoPackagePath, "mtproto")
 	file.HeaderComment("Code generated by generate-tl-files; DO NOT EDIT.")
 	f(file)
 
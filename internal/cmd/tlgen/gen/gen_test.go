@@ -0,0 +1,48 @@
+package gen
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jwillp/gogram/internal/cmd/tlgen/tlparser"
+)
+
+var update = flag.Bool("update", false, "rewrite the expected files of the golden tests")
+
+var generatedFiles = []string{"enums_gen.go", "types_gen.go", "interfaces_gen.go", "methods_gen.go", "init_gen.go"}
+
+// testGolden generates the code of testdata/<name>/schema.tl and compares it with testdata/<name>/expected
+func testGolden(t *testing.T, name string) {
+	dir := filepath.Join("testdata", name)
+	source, err := os.ReadFile(filepath.Join(dir, "schema.tl"))
+	require.NoError(t, err)
+	schema, err := tlparser.ParseSchema(string(source))
+	require.NoError(t, err)
+
+	outdir := t.TempDir()
+	g, err := NewGenerator(schema, "", outdir)
+	require.NoError(t, err)
+	require.NoError(t, g.Generate())
+
+	for _, file := range generatedFiles {
+		got, err := os.ReadFile(filepath.Join(outdir, file))
+		require.NoError(t, err)
+		expectedPath := filepath.Join(dir, "expected", file)
+		if *update {
+			require.NoError(t, os.WriteFile(expectedPath, got, 0644))
+			continue
+		}
+		expected, err := os.ReadFile(expectedPath)
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(got), file)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	testGolden(t, "basic")
+}
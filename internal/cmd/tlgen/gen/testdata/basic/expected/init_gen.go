@@ -5,7 +5,7 @@ package telegram
 import tl "github.com/jwillp/gogram/internal/encoding/tl"
 
 func init() {
-	tl.RegisterObjects(&InputPeerUserFromMessage{})
+	tl.RegisterObjects(&AccountCheckUsernameParams{}, &InputPeerUserFromMessage{}, &MessagesGetMessagePeerParams{})
 
 	tl.RegisterEnums(StorageFileGif, StorageFileJpeg, StorageFileMov, StorageFileMp3, StorageFileMp4, StorageFilePartial, StorageFilePdf, StorageFilePng, StorageFileUnknown, StorageFileWebp)
 }
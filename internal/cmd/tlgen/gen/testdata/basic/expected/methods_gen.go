@@ -1,3 +1,58 @@
 // Code generated by generate-tl-files; DO NOT EDIT.
 
 package telegram
+
+import (
+	mtproto "github.com/jwillp/gogram"
+	errors "github.com/pkg/errors"
+)
+
+// Validates a username and checks availability.
+type AccountCheckUsernameParams struct {
+	Username string // The username
+}
+
+func (*AccountCheckUsernameParams) CRC() uint32 {
+	return 0x2714d86c
+}
+
+// Validates a username and checks availability.
+func (c *Client) AccountCheckUsername(username string) (bool, error) {
+	responseData, err := c.MakeRequest(&AccountCheckUsernameParams{Username: username})
+	if err != nil {
+		return false, errors.Wrap(err, "sending AccountCheckUsername")
+	}
+
+	resp, ok := responseData.(bool)
+	if !ok {
+		return false, mtproto.NewUnexpectedResponseError("AccountCheckUsername", "bool", responseData)
+	}
+	return resp, nil
+}
+
+// Returns the peer of a message.
+type MessagesGetMessagePeerParams struct {
+	Peer  *InputPeerUserFromMessage // The chat of the message
+	MsgID int32                     // The message ID
+}
+
+func (*MessagesGetMessagePeerParams) CRC() uint32 {
+	return 0x9e2d18a1
+}
+
+// Returns the peer of a message.
+func (c *Client) MessagesGetMessagePeer(peer *InputPeerUserFromMessage, msgID int32) (*InputPeerUserFromMessage, error) {
+	responseData, err := c.MakeRequest(&MessagesGetMessagePeerParams{
+		MsgID: msgID,
+		Peer:  peer,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "sending MessagesGetMessagePeer")
+	}
+
+	resp, ok := responseData.(*InputPeerUserFromMessage)
+	if !ok {
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetMessagePeer", "*InputPeerUserFromMessage", responseData)
+	}
+	return resp, nil
+}
@@ -8,6 +8,7 @@ import (
 
 var tlPackagePath = "github.com/jwillp/gogram/internal/encoding/tl"
 var errorsPackagePath = "github.com/pkg/errors"
+var mtprotoPackagePath = "github.com/jwillp/gogram"
 
 func (g *Generator) generateInit(file *jen.File) {
 	structs, enums := g.getAllConstructors()
@@ -1,6 +1,7 @@
 package gen
 
 import (
+	"fmt"
 	"sort"
 
 	"github.com/dave/jennifer/jen"
@@ -52,11 +53,10 @@ func (g *Generator) generateMethodFunction(obj *tlparser.Method) jen.Code {
 		resp = jen.Index().Add(resp)
 	}
 
-	// еще одно злоебучее исключение. проблема в том, что bool это вот как бы и объект, да вот как бы и нет
-	// трабла только в том, что нельзя просто так взять, и получить bool из MakeRequest. так что
-	// возвращаем tl.Bool
-	if obj.Response.Type == "Bool" {
-		resp = jen.Op("*").Qual(tlPackagePath, "bool")
+	// bool responses come from MakeRequest as plain bools, so false is returned along with errors
+	zero := jen.Nil()
+	if obj.Response.Type == "Bool" && !obj.Response.IsList {
+		zero = jen.False()
 	}
 
 	responses := []jen.Code{resp, jen.Error()}
@@ -68,19 +68,19 @@ func (g *Generator) generateMethodFunction(obj *tlparser.Method) jen.Code {
 	//*
 	//*	resp, ok := data.(*AuthSentCode)
 	//*	if !ok {
-	//*		panic("got invalid response type: " + reflect.TypeOf(data).String())
+	//*		return nil, mtproto.NewUnexpectedResponseError("AuthSendCode", "*AuthSentCode", data)
 	//*	}
 	//*
 	//*	return resp, nil
 	method := jen.Func().Params(jen.Id("c").Op("*").Id("Client")).Id(goify(obj.Name, true)).Params(g.generateArgumentsForMethod(obj)...).Params(responses...).Block(
 		jen.List(jen.Id("responseData"), jen.Id("err")).Op(":=").Id("c").Dot("MakeRequest").Call(g.generateMethodArgumentForMakingRequest(obj)),
 		jen.If(jen.Err().Op("!=").Nil()).Block(
-			jen.Return(jen.Nil(), jen.Qual(errorsPackagePath, "Wrap").Call(jen.Err(), jen.Lit("sending "+goify(obj.Name, true)))),
+			jen.Return(zero, jen.Qual(errorsPackagePath, "Wrap").Call(jen.Err(), jen.Lit("sending "+goify(obj.Name, true)))),
 		),
 		jen.Line(),
 		jen.List(jen.Id("resp"), jen.Id("ok")).Op(":=").Id("responseData").Assert(resp),
 		jen.If(jen.Op("!").Id("ok")).Block(
-			jen.Panic(jen.Lit("got invalid response type: ").Op("+").Qual("reflect", "TypeOf").Call(jen.Id("responseData")).Dot("String").Call()),
+			jen.Return(zero, jen.Qual(mtprotoPackagePath, "NewUnexpectedResponseError").Call(jen.Lit(goify(obj.Name, true)), jen.Lit(fmt.Sprintf("%#v", resp)), jen.Id("responseData"))),
 		),
 		jen.Return(jen.Id("resp"), jen.Nil()),
 	)
@@ -99,7 +99,7 @@ func (g *Generator) generateArgumentsForMethod(obj *tlparser.Method) []jen.Code
 	items := make([]jen.Code, 0)
 
 	for i, p := range obj.Parameters {
-		item := jen.Id(goify(p.Name, false))
+		item := jen.Id(argumentName(p.Name))
 		if i == len(obj.Parameters)-1 || p.Type != obj.Parameters[i+1].Type || p.IsVector != obj.Parameters[i+1].IsVector {
 			if p.Type == "bitflags" {
 				continue // ну а зачем?
@@ -117,6 +117,17 @@ func (g *Generator) generateArgumentsForMethod(obj *tlparser.Method) []jen.Code
 	return items
 }
 
+// shadowedPackages are the packages the methods use, arguments can't have their names
+var shadowedPackages = map[string]string{"errors": "errs", "mtproto": "mtprotoArg"}
+
+func argumentName(name string) string {
+	arg := goify(name, false)
+	if renamed, ok := shadowedPackages[arg]; ok {
+		return renamed
+	}
+	return arg
+}
+
 func (*Generator) generateMethodArgumentForMakingRequest(obj *tlparser.Method) *jen.Statement {
 	if len(obj.Parameters) > maximumPositionalArguments {
 		return jen.Id("params")
@@ -128,7 +139,7 @@ func (*Generator) generateMethodArgumentForMakingRequest(obj *tlparser.Method) *
 			continue // ну а зачем?
 		}
 
-		dict[jen.Id(goify(p.Name, true))] = jen.Id(goify(p.Name, false))
+		dict[jen.Id(goify(p.Name, true))] = jen.Id(argumentName(p.Name))
 	}
 
 	return jen.Op("&").Id(goify(obj.Name, true) + "Params").Values(dict)
@@ -22,7 +22,7 @@ See https://github.com/xelaj/mtproto/blob/master/LICENSE for details
 
 func main() {
 	if len(os.Args) != 3 {
-		fmt.Println(helpMsg)
+		fmt.Print(helpMsg)
 		return
 	}
 
@@ -33,7 +33,11 @@ func RSAFingerprint(key *rsa.PublicKey) []byte {
 }
 
 func GetRSAKeys() ([]*rsa.PublicKey, error) {
-	data := []byte(RsaKeys)
+	return ParseRSAKeys([]byte(RsaKeys))
+}
+
+// ParseRSAKeys parses PEM encoded keys, in PKCS1 or PKIX format
+func ParseRSAKeys(data []byte) ([]*rsa.PublicKey, error) {
 	keys := make([]*rsa.PublicKey, 0)
 	for {
 		block, rest := pem.Decode(data)
@@ -2,7 +2,6 @@ package mode
 
 import (
 	"encoding/binary"
-	"fmt"
 	"io"
 
 	"github.com/jwillp/gogram/internal/encoding/tl"
@@ -45,57 +44,36 @@ func (m *abridged) WriteMsg(msg []byte) error {
 		size = []byte{magicValueSizeMoreThanSingleByte, b1, b2, b3}
 	}
 
-	if _, err := m.conn.Write(size); err != nil {
-		return err
-	}
-	if _, err := m.conn.Write(msg); err != nil {
-		return err
-	}
-
-	return nil
+	// single write, so concurrent messages are never interleaved
+	_, err := m.conn.Write(append(size, msg...))
+	return err
 }
 
 func (m *abridged) ReadMsg() ([]byte, error) {
-	fmt.Println("im here")
-	sizeBuf := make([]byte, 1)
-	n, err := m.conn.Read(sizeBuf)
-	if err != nil {
+	sizeBuf := make([]byte, 4)
+	if _, err := io.ReadFull(m.conn, sizeBuf[:1]); err != nil {
 		return nil, err
 	}
-	if n != 1 {
-		return nil, fmt.Errorf("need to read at least 1 byte")
-	}
-	fmt.Println("im here 2")
 
 	size := 0
 
 	if sizeBuf[0] == magicValueSizeMoreThanSingleByte {
-		sizeBuf = make([]byte, 4)
-		n, err := m.conn.Read(sizeBuf[:3])
-		if err != nil {
+		if _, err := io.ReadFull(m.conn, sizeBuf[:3]); err != nil {
 			return nil, err
 		}
-		if n != 3 {
-			return nil, fmt.Errorf("need to read 3 bytes, got %v", n)
-		}
+		sizeBuf[3] = 0
 
 		size = int(binary.LittleEndian.Uint32(sizeBuf))
 	} else {
 		size = int(sizeBuf[0])
 	}
-	fmt.Println("im here 3")
 
 	size *= tl.WordLen
 
 	msg := make([]byte, size)
-
-	n, err = m.conn.Read(msg)
-	if err != nil {
+	if _, err := io.ReadFull(m.conn, msg); err != nil {
 		return nil, err
 	}
-	if n != int(size) {
-		return nil, fmt.Errorf("expected to read %d bytes, got %d", size, n)
-	}
 
 	return msg, nil
 }
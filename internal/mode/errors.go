@@ -10,6 +10,7 @@ var (
 	ErrInterfaceIsNil        = errors.New("interface is nil")
 	ErrModeNotSupported      = errors.New("mode is not supported")
 	ErrAmbiguousModeAnnounce = errors.New("ambiguous mode announce, expected other byte sequence")
+	ErrChecksumMismatch      = errors.New("packet checksum mismatch")
 )
 
 type ErrNotMultiple struct {
@@ -0,0 +1,78 @@
+package mode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sync"
+
+	"github.com/jwillp/gogram/internal/encoding/tl"
+)
+
+// full is the only mode without announcement, every packet carries its length, a sequence number
+// and a crc32 checksum
+// https://core.telegram.org/mtproto/mtproto-transports#full
+type full struct {
+	conn io.ReadWriter
+
+	writeMutex sync.Mutex
+	writeSeqNo uint32
+	readSeqNo  uint32
+}
+
+var _ Mode = (*full)(nil)
+
+func (*full) getModeAnnouncement() []byte {
+	return nil
+}
+
+// length, seqno and crc32
+const fullOverhead = 3 * tl.WordLen
+
+func (m *full) WriteMsg(msg []byte) error {
+	m.writeMutex.Lock()
+	defer m.writeMutex.Unlock()
+
+	buf := make([]byte, len(msg)+fullOverhead)
+	binary.LittleEndian.PutUint32(buf, uint32(len(buf)))
+	binary.LittleEndian.PutUint32(buf[tl.WordLen:], m.writeSeqNo)
+	copy(buf[2*tl.WordLen:], msg)
+	crcOffset := len(buf) - tl.WordLen
+	binary.LittleEndian.PutUint32(buf[crcOffset:], crc32.ChecksumIEEE(buf[:crcOffset]))
+
+	if _, err := m.conn.Write(buf); err != nil {
+		return err
+	}
+	m.writeSeqNo++
+
+	return nil
+}
+
+func (m *full) ReadMsg() ([]byte, error) {
+	sizeBuf := make([]byte, tl.WordLen)
+	if _, err := io.ReadFull(m.conn, sizeBuf); err != nil {
+		return nil, err
+	}
+
+	size := binary.LittleEndian.Uint32(sizeBuf)
+	if size < fullOverhead || size > maxPacketSize {
+		return nil, fmt.Errorf("invalid packet size: %d bytes", size)
+	}
+	buf := make([]byte, size)
+	copy(buf, sizeBuf)
+	if _, err := io.ReadFull(m.conn, buf[tl.WordLen:]); err != nil {
+		return nil, err
+	}
+
+	crcOffset := len(buf) - tl.WordLen
+	if crc := binary.LittleEndian.Uint32(buf[crcOffset:]); crc != crc32.ChecksumIEEE(buf[:crcOffset]) {
+		return nil, ErrChecksumMismatch
+	}
+	if seqNo := binary.LittleEndian.Uint32(buf[tl.WordLen:]); seqNo != m.readSeqNo {
+		return nil, fmt.Errorf("unexpected packet seqno: want %d, got %d", m.readSeqNo, seqNo)
+	}
+	m.readSeqNo++
+
+	return buf[2*tl.WordLen : crcOffset], nil
+}
@@ -21,37 +21,33 @@ func (*intermediate) getModeAnnouncement() []byte {
 }
 
 func (m *intermediate) WriteMsg(msg []byte) error {
-	size := make([]byte, tl.WordLen)
-	binary.LittleEndian.PutUint32(size, uint32(len(msg)))
-	if _, err := m.conn.Write(size); err != nil {
-		return err
-	}
-	if _, err := m.conn.Write(msg); err != nil {
-		return err
-	}
-
-	return nil
+	// single write, so concurrent messages are never interleaved
+	buf := make([]byte, tl.WordLen+len(msg))
+	binary.LittleEndian.PutUint32(buf, uint32(len(msg)))
+	copy(buf[tl.WordLen:], msg)
+	_, err := m.conn.Write(buf)
+	return err
 }
 
 func (m *intermediate) ReadMsg() ([]byte, error) {
+	return readIntermediate(m.conn)
+}
+
+// readIntermediate reads a packet prefixed with its 4 byte length
+func readIntermediate(conn io.Reader) ([]byte, error) {
 	sizeBuf := make([]byte, tl.WordLen)
-	n, err := m.conn.Read(sizeBuf)
-	if err != nil {
+	if _, err := io.ReadFull(conn, sizeBuf); err != nil {
 		return nil, err
 	}
-	if n != tl.WordLen {
-		return nil, fmt.Errorf("size is not length of int32, expected 4 bytes, got %d", n)
-	}
 
 	size := binary.LittleEndian.Uint32(sizeBuf)
+	if size > maxPacketSize {
+		return nil, fmt.Errorf("packet too big: %d bytes", size)
+	}
 	msg := make([]byte, int(size))
-	n, err = m.conn.Read(msg)
-	if err != nil {
+	if _, err := io.ReadFull(conn, msg); err != nil {
 		return nil, err
 	}
-	if n != int(size) {
-		return nil, fmt.Errorf("expected to read %d bytes, got %d", size, n)
-	}
 
 	return msg, nil
 }
@@ -3,6 +3,7 @@ package mode
 import (
 	"bytes"
 	"io"
+	"strconv"
 
 	"github.com/pkg/errors"
 )
@@ -30,6 +31,24 @@ const (
 	Full
 )
 
+// biggest packet telegram may send is a bit larger than a 512kb file part
+const maxPacketSize = 16 * 1024 * 1024
+
+func (v Variant) String() string {
+	switch v {
+	case Abridged:
+		return "Abridged"
+	case Intermediate:
+		return "Intermediate"
+	case PaddedIntermediate:
+		return "PaddedIntermediate"
+	case Full:
+		return "Full"
+	default:
+		return "Variant(" + strconv.Itoa(int(v)) + ")"
+	}
+}
+
 func New(v Variant, conn io.ReadWriter) (Mode, error) {
 	if conn == nil {
 		return nil, ErrInterfaceIsNil
@@ -39,19 +58,46 @@ func New(v Variant, conn io.ReadWriter) (Mode, error) {
 	if err != nil {
 		return nil, err
 	}
-	announcement := m.getModeAnnouncement()
-	_, err = conn.Write(announcement)
-	if err != nil {
-		return nil, errors.Wrap(err, "can't setup connection")
+	if announcement := m.getModeAnnouncement(); len(announcement) > 0 {
+		_, err = conn.Write(announcement)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't setup connection")
+		}
 	}
 
 	return m, nil
 }
 
+// NewAnnounced returns mode for a connection where the mode was already announced another way,
+// e.g. in the init header of an obfuscated connection
+func NewAnnounced(v Variant, conn io.ReadWriter) (Mode, error) {
+	if conn == nil {
+		return nil, ErrInterfaceIsNil
+	}
+	return initMode(v, conn)
+}
+
+// ObfuscationTag returns the protocol tag which is put into the init header of obfuscated connections,
+// full mode can't be obfuscated
+func ObfuscationTag(v Variant) ([]byte, error) {
+	switch v {
+	case Abridged:
+		return []byte{0xef, 0xef, 0xef, 0xef}, nil
+	case Intermediate:
+		return transportModeIntermediate[:], nil
+	case PaddedIntermediate:
+		return transportModePaddedIntermediate[:], nil
+	default:
+		return nil, ErrModeNotSupported
+	}
+}
+
 func initMode(v Variant, conn io.ReadWriter) (Mode, error) {
 	switch v {
-	case PaddedIntermediate, Full:
-		panic("not supported yet")
+	case PaddedIntermediate:
+		return &paddedIntermediate{conn: conn}, nil
+	case Full:
+		return &full{conn: conn}, nil
 	case Abridged:
 		return &abridged{conn: conn}, nil
 	case Intermediate:
@@ -87,6 +133,17 @@ func Detect(conn io.ReadWriter) (Mode, error) {
 			return nil, ErrAmbiguousModeAnnounce
 		}
 		detectedMode = Intermediate
+	case transportModePaddedIntermediate[0]:
+		modeAnnounce := make([]byte, 4)
+		copy(modeAnnounce, b)
+		_, err = io.ReadFull(conn, modeAnnounce[1:])
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(modeAnnounce, transportModePaddedIntermediate[:]) {
+			return nil, ErrAmbiguousModeAnnounce
+		}
+		detectedMode = PaddedIntermediate
 	default:
 		return nil, ErrModeNotSupported
 	}
@@ -100,6 +157,10 @@ func GetVariant(m Mode) (Variant, error) {
 		return Abridged, nil
 	case *intermediate:
 		return Intermediate, nil
+	case *paddedIntermediate:
+		return PaddedIntermediate, nil
+	case *full:
+		return Full, nil
 	default:
 		return Variant(0xff), errors.New("using custom mode, cant't detect")
 	}
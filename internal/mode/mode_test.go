@@ -0,0 +1,148 @@
+package mode_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"testing"
+
+	"github.com/jwillp/gogram/internal/mode"
+)
+
+// packets shaped like what telegram sends, padded intermediate relies on that to cut the padding off
+var packets = [][]byte{
+	// unencrypted: auth_key_id 0, msg_id, length, body
+	append([]byte{0, 0, 0, 0, 0, 0, 0, 0, 1, 2, 3, 4, 5, 6, 7, 8, 8, 0, 0, 0}, bytes.Repeat([]byte{9}, 8)...),
+	// encrypted: auth_key_id, msg_key, 16 byte blocks
+	bytes.Repeat([]byte{7}, 8+16+32),
+	// transport error -404
+	{0x6c, 0xfe, 0xff, 0xff},
+	// a packet longer than 127 words, abridged spends 4 bytes on its length
+	bytes.Repeat([]byte{5}, 8+16+16*64),
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, v := range []mode.Variant{mode.Abridged, mode.Intermediate, mode.PaddedIntermediate, mode.Full} {
+		t.Run(v.String(), func(t *testing.T) {
+			var wire bytes.Buffer
+			client, err := mode.New(v, &wire)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, packet := range packets {
+				if err := client.WriteMsg(packet); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// full mode has no announcement, the other side has to know it
+			var server mode.Mode
+			if v == mode.Full {
+				server, err = mode.NewAnnounced(v, &wire)
+			} else {
+				server, err = mode.Detect(&wire)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := mode.GetVariant(server); got != v {
+				t.Fatalf("detected %s", got)
+			}
+			for i, packet := range packets {
+				msg, err := server.ReadMsg()
+				if err != nil {
+					t.Fatalf("packet %d: %v", i, err)
+				}
+				if !bytes.Equal(msg, packet) {
+					t.Errorf("packet %d is %x, want %x", i, msg, packet)
+				}
+			}
+			if wire.Len() != 0 {
+				t.Errorf("%d bytes left unread", wire.Len())
+			}
+		})
+	}
+}
+
+func TestFullFraming(t *testing.T) {
+	var wire bytes.Buffer
+	m, _ := mode.New(mode.Full, &wire)
+	for i := 0; i < 2; i++ {
+		if err := m.WriteMsg([]byte{1, 2, 3, 4}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	packet := wire.Bytes()[:16]
+	if size := binary.LittleEndian.Uint32(packet); size != 16 {
+		t.Errorf("length is %d, want 16", size)
+	}
+	if crc := binary.LittleEndian.Uint32(packet[12:]); crc != crc32.ChecksumIEEE(packet[:12]) {
+		t.Errorf("crc32 is %#x, want %#x", crc, crc32.ChecksumIEEE(packet[:12]))
+	}
+	for i, packet := range [][]byte{wire.Bytes()[:16], wire.Bytes()[16:]} {
+		if seqNo := binary.LittleEndian.Uint32(packet[4:]); seqNo != uint32(i) {
+			t.Errorf("packet %d has seq_no %d", i, seqNo)
+		}
+	}
+}
+
+// fullPacket frames msg the way full mode does, with the given seq_no
+func fullPacket(seqNo uint32, msg []byte) []byte {
+	buf := make([]byte, 12+len(msg))
+	binary.LittleEndian.PutUint32(buf, uint32(len(buf)))
+	binary.LittleEndian.PutUint32(buf[4:], seqNo)
+	copy(buf[8:], msg)
+	binary.LittleEndian.PutUint32(buf[len(buf)-4:], crc32.ChecksumIEEE(buf[:len(buf)-4]))
+	return buf
+}
+
+func TestFullRejects(t *testing.T) {
+	corrupted := fullPacket(0, []byte{1, 2, 3, 4})
+	corrupted[9] ^= 0xff
+	tooShort := fullPacket(0, nil)
+	binary.LittleEndian.PutUint32(tooShort, 8)
+
+	for _, tc := range []struct {
+		name string
+		wire []byte
+		want error
+	}{
+		{"crc32", corrupted, mode.ErrChecksumMismatch},
+		{"seq_no", append(fullPacket(0, []byte{1, 2, 3, 4}), fullPacket(2, []byte{1, 2, 3, 4})...), nil},
+		{"length", tooShort, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m, _ := mode.NewAnnounced(mode.Full, bytes.NewBuffer(tc.wire))
+			var err error
+			for err == nil {
+				_, err = m.ReadMsg()
+			}
+			if tc.want != nil && !errors.Is(err, tc.want) {
+				t.Errorf("got %v, want %v", err, tc.want)
+			}
+			if errors.Is(err, io.EOF) {
+				t.Error("the broken packet was accepted")
+			}
+		})
+	}
+}
+
+func TestDetectRejects(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		wire []byte
+		want error
+	}{
+		{"unknown", []byte{0x00, 0x00, 0x00, 0x00}, mode.ErrModeNotSupported},
+		{"intermediate", []byte{0xee, 0xee, 0xee, 0xdd}, mode.ErrAmbiguousModeAnnounce},
+		{"padded intermediate", []byte{0xdd, 0xdd, 0xee, 0xdd}, mode.ErrAmbiguousModeAnnounce},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := mode.Detect(bytes.NewBuffer(tc.wire)); !errors.Is(err, tc.want) {
+				t.Errorf("got %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
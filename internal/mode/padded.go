@@ -0,0 +1,77 @@
+package mode
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"github.com/jwillp/gogram/internal/encoding/tl"
+)
+
+// paddedIntermediate is intermediate with 0-15 random bytes appended to every packet, which makes
+// packet lengths useless for traffic analysis
+// https://core.telegram.org/mtproto/mtproto-transports#padded-intermediate
+type paddedIntermediate struct {
+	conn io.ReadWriter
+}
+
+var _ Mode = (*paddedIntermediate)(nil)
+
+var transportModePaddedIntermediate = [...]byte{0xdd, 0xdd, 0xdd, 0xdd} // meta:immutable
+
+func (*paddedIntermediate) getModeAnnouncement() []byte {
+	return transportModePaddedIntermediate[:]
+}
+
+func (m *paddedIntermediate) WriteMsg(msg []byte) error {
+	var pad [1]byte
+	if _, err := rand.Read(pad[:]); err != nil {
+		return err
+	}
+	padding := int(pad[0] % 16)
+
+	buf := make([]byte, tl.WordLen+len(msg)+padding)
+	binary.LittleEndian.PutUint32(buf, uint32(len(msg)+padding))
+	copy(buf[tl.WordLen:], msg)
+	if _, err := rand.Read(buf[tl.WordLen+len(msg):]); err != nil {
+		return err
+	}
+
+	_, err := m.conn.Write(buf)
+	return err
+}
+
+func (m *paddedIntermediate) ReadMsg() ([]byte, error) {
+	msg, err := readIntermediate(m.conn)
+	if err != nil {
+		return nil, err
+	}
+	return trimPadding(msg), nil
+}
+
+// trimPadding cuts the random padding off, the real length is known from the mtproto message layout:
+// unencrypted messages carry their length, encrypted ones are auth_key_id + msg_key + 16 byte blocks
+func trimPadding(msg []byte) []byte {
+	const (
+		authKeyIDLen   = tl.DoubleLen
+		unencryptedLen = authKeyIDLen + tl.DoubleLen + tl.WordLen // auth_key_id, msg_id, length
+		encryptedLen   = authKeyIDLen + 16                        // auth_key_id, msg_key
+	)
+	switch {
+	case len(msg) < unencryptedLen:
+		// transport error code
+		if len(msg) >= tl.WordLen {
+			return msg[:tl.WordLen]
+		}
+		return msg
+	case binary.LittleEndian.Uint64(msg) == 0:
+		size := unencryptedLen + int(binary.LittleEndian.Uint32(msg[unencryptedLen-tl.WordLen:]))
+		if size <= len(msg) {
+			return msg[:size]
+		}
+		return msg
+	case len(msg) >= encryptedLen:
+		return msg[:encryptedLen+(len(msg)-encryptedLen)/16*16]
+	}
+	return msg
+}
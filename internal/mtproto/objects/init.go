@@ -12,8 +12,13 @@ func init() {
 		&ReqDHParamsParams{},
 		&SetClientDHParamsParams{},
 		&PingParams{},
+		&PingDelayDisconnectParams{},
+		&HttpWaitParams{},
 		&ResPQ{},
 		&PQInnerData{},
+		&PQInnerDataDc{},
+		&PQInnerDataTempDc{},
+		&BindAuthKeyInner{},
 		&ServerDHParamsFail{},
 		&ServerDHParamsOk{},
 		&ServerDHInnerData{},
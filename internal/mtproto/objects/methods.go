@@ -128,9 +128,43 @@ func Ping(m requester, pingID int64) (*Pong, error) {
 	return resp, nil
 }
 
-// ping_delay_disconnect
+// PingDelayDisconnectParams is a ping which also makes the server close the connection when no other
+// ping arrives within DisconnectDelay seconds
+type PingDelayDisconnectParams struct {
+	PingID          int64
+	DisconnectDelay int32
+}
+
+func (*PingDelayDisconnectParams) CRC() uint32 {
+	return 0xf3427b8c //nolint:gomnd not magic
+}
+
 // destroy_session
-// http_wait
+
+// HttpWaitParams makes the server hold the http request open until it has something to send,
+// it's the long polling of the http transport and has no response
+type HttpWaitParams struct {
+	MaxDelay  int32 // ms to wait for more messages after the first one is ready
+	WaitAfter int32 // ms to wait after the last message was received from the client
+	MaxWait   int32 // ms to hold the request when there is nothing to send
+}
+
+func (*HttpWaitParams) CRC() uint32 {
+	return 0x9299359f //nolint:gomnd not magic
+}
+
+// BindTempAuthKeyParams is auth.bindTempAuthKey, it's sent encrypted with the temporary key, the telegram
+// package has the same method registered, so this one isn't
+type BindTempAuthKeyParams struct {
+	PermAuthKeyID    int64
+	Nonce            int64
+	ExpiresAt        int32
+	EncryptedMessage []byte
+}
+
+func (*BindTempAuthKeyParams) CRC() uint32 {
+	return 0xcdd42a05 //nolint:gomnd not magic
+}
 
 // set_client_DH_params#f5045f1f nonce:int128 server_nonce:int128 encrypted_data:bytes = Set_client_DH_params_answer;
 
@@ -48,6 +48,50 @@ func (*PQInnerData) CRC() uint32 {
 	return 0x83c95aec //nolint:gomnd not magic
 }
 
+// PQInnerDataDc is p_q_inner_data_dc, it binds the key to the data center, required with RSA_PAD
+type PQInnerDataDc struct {
+	Pq          []byte
+	P           []byte
+	Q           []byte
+	Nonce       *tl.Int128
+	ServerNonce *tl.Int128
+	NewNonce    *tl.Int256
+	Dc          int32
+}
+
+func (*PQInnerDataDc) CRC() uint32 {
+	return 0xa9f55f95 //nolint:gomnd not magic
+}
+
+// PQInnerDataTempDc is p_q_inner_data_temp_dc, for temporary keys which expire after ExpiresIn seconds
+type PQInnerDataTempDc struct {
+	Pq          []byte
+	P           []byte
+	Q           []byte
+	Nonce       *tl.Int128
+	ServerNonce *tl.Int128
+	NewNonce    *tl.Int256
+	Dc          int32
+	ExpiresIn   int32
+}
+
+func (*PQInnerDataTempDc) CRC() uint32 {
+	return 0x56fddf88 //nolint:gomnd not magic
+}
+
+// BindAuthKeyInner is encrypted with the permanent key and sent in auth.bindTempAuthKey
+type BindAuthKeyInner struct {
+	Nonce         int64
+	TempAuthKeyID int64
+	PermAuthKeyID int64
+	TempSessionID int64
+	ExpiresAt     int32
+}
+
+func (*BindAuthKeyInner) CRC() uint32 {
+	return 0x75a3f765 //nolint:gomnd not magic
+}
+
 type ServerDHParams interface {
 	tl.Object
 	ImplementsServerDHParams()
@@ -281,8 +325,7 @@ func (t *MessageContainer) MarshalTL(e *tl.Encoder) error {
 	for _, msg := range *t {
 		e.PutLong(msg.MsgID)
 		e.PutInt(msg.SeqNo)
-		//       msgID        seqNo        len                object
-		e.PutInt(tl.LongLen + tl.WordLen + tl.WordLen + int32(len(msg.Msg)))
+		e.PutInt(int32(len(msg.Msg)))
 		e.PutRawBytes(msg.Msg)
 	}
 	return e.CheckErr()
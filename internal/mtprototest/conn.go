@@ -0,0 +1,365 @@
+package mtprototest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"sync"
+
+	ige "github.com/jwillp/gogram/internal/aes_ige"
+	"github.com/jwillp/gogram/internal/encoding/tl"
+	"github.com/jwillp/gogram/internal/mode"
+	"github.com/jwillp/gogram/internal/mtproto/objects"
+	"github.com/jwillp/gogram/internal/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	errAuthKeyNotFound = int32(-404) // transport error sent for unknown auth keys
+)
+
+// packetWriter sends packets to the client, a mode.Mode or the outbox of the http session
+type packetWriter interface {
+	WriteMsg(msg []byte) error
+}
+
+// conn is a connection of a client
+type conn struct {
+	srv  *Server
+	nc   net.Conn // nil for the http session
+	mode packetWriter
+	dh   *exchange
+
+	mutex     sync.Mutex // guards the fields below and writes
+	authKey   []byte
+	sessionID int64
+	salt      int64
+	seqNo     int32 // content messages sent
+	closed    bool
+}
+
+// run reads the messages of the client until the connection is closed
+func (c *conn) run() error {
+	r := bufio.NewReader(c.nc)
+	start, err := r.Peek(4)
+	if err != nil {
+		return nil
+	}
+	if string(start) == http.MethodPost {
+		return c.srv.serveHTTP(c.nc, r)
+	}
+
+	m, err := mode.Detect(struct {
+		io.Reader
+		io.Writer
+	}{r, c.nc})
+	if err != nil {
+		return errors.Wrap(err, "detecting mode")
+	}
+	c.mode = m
+	for {
+		data, err := m.ReadMsg()
+		if err != nil {
+			// the client or the test closed the connection
+			return nil
+		}
+		if err := c.read(data); err != nil {
+			return err
+		}
+	}
+}
+
+// read handles a packet of the client
+func (c *conn) read(data []byte) error {
+	if len(data) < tl.DoubleLen {
+		return errors.Errorf("message is too short, %d bytes", len(data))
+	}
+	keyID := int64(binary.LittleEndian.Uint64(data))
+	if keyID == 0 {
+		return c.readPlain(data)
+	}
+	return c.readEncrypted(keyID, data)
+}
+
+func (c *conn) close() {
+	c.mutex.Lock()
+	c.closed = true
+	c.mutex.Unlock()
+	if c.nc != nil {
+		c.nc.Close()
+	}
+}
+
+func (c *conn) isClosed() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.closed
+}
+
+// established reports whether the client sent an encrypted message, so the server knows its session
+func (c *conn) established() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.authKey != nil
+}
+
+func (c *conn) readPlain(data []byte) error {
+	// auth_key_id, msg_id, length, body
+	body, err := payload(data, 16)
+	if err != nil {
+		return errors.Wrap(err, "reading unencrypted message")
+	}
+	return c.handshake(body)
+}
+
+func (c *conn) writePlain(obj tl.Object) error {
+	body, err := tl.Marshal(obj)
+	if err != nil {
+		return errors.Wrapf(err, "marshaling %T", obj)
+	}
+	buf := bytes.NewBuffer(nil)
+	e := tl.NewEncoder(buf)
+	e.PutLong(0)
+	e.PutLong(c.srv.nextMessageID(true))
+	e.PutInt(int32(len(body)))
+	e.PutRawBytes(body)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.mode.WriteMsg(buf.Bytes())
+}
+
+func (c *conn) readEncrypted(keyID int64, data []byte) error {
+	msgID, body, err := c.decrypt(keyID, data)
+	if err != nil || body == nil {
+		return err
+	}
+	return c.process(msgID, body)
+}
+
+// decrypt returns the message of the client and remembers its session, the body is nil
+// when the auth key is unknown or the msg_id is off, the client was told so already
+func (c *conn) decrypt(keyID int64, data []byte) (int64, []byte, error) {
+	key, ok := c.srv.authKey(keyID)
+	if !ok {
+		code, buf := errAuthKeyNotFound, make([]byte, tl.WordLen)
+		binary.LittleEndian.PutUint32(buf, uint32(code))
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		return 0, nil, c.mode.WriteMsg(buf)
+	}
+	if len(data) < tl.DoubleLen+tl.Int128Len {
+		return 0, nil, errors.New("encrypted message is too short")
+	}
+	msgKey := data[tl.DoubleLen : tl.DoubleLen+tl.Int128Len]
+	plain, err := ige.DecryptAsServer(data[tl.DoubleLen+tl.Int128Len:], key, msgKey)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "decrypting")
+	}
+	if !bytes.Equal(ige.MessageKey(key, plain, false), msgKey) {
+		return 0, nil, errors.New("wrong msg_key")
+	}
+
+	// salt, session_id, msg_id, seq_no, length, body and padding
+	body, err := payload(plain, 28)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "reading encrypted message")
+	}
+	salt := int64(binary.LittleEndian.Uint64(plain))
+	sessionID := int64(binary.LittleEndian.Uint64(plain[8:]))
+	msgID := int64(binary.LittleEndian.Uint64(plain[16:]))
+	if msgID&3 != 0 {
+		return 0, nil, errors.Errorf("client msg_id %d isn't divisible by 4", msgID)
+	}
+
+	c.mutex.Lock()
+	if c.sessionID != sessionID {
+		c.seqNo = 0
+	}
+	c.authKey, c.sessionID, c.salt = key, sessionID, salt
+	c.mutex.Unlock()
+
+	c.srv.recordContainer(body)
+	seqNo := int32(binary.LittleEndian.Uint32(plain[24:]))
+	if code := c.srv.checkMsgID(msgID); code != 0 {
+		return 0, nil, c.write(&objects.BadMsgNotification{BadMsgID: msgID, BadMsgSeqNo: seqNo, Code: code}, false, false)
+	}
+	if valid, ok := c.srv.checkSalt(salt); !ok {
+		return 0, nil, c.write(&objects.BadServerSalt{BadMsgID: msgID, BadMsgSeqNo: seqNo, ErrorCode: 48, NewSalt: valid}, false, false)
+	}
+	return msgID, body, nil
+}
+
+// process answers a message of the client, requests are answered concurrently, so handlers may block
+func (c *conn) process(msgID int64, body []byte) error {
+	if len(body) < tl.WordLen {
+		return errors.New("message body is too short")
+	}
+	crc := binary.LittleEndian.Uint32(body)
+	if _, ok := c.srv.handler(crc); !ok {
+		switch crc {
+		case (&objects.MessageContainer{}).CRC():
+			obj, err := tl.DecodeUnknownObject(body)
+			if err != nil {
+				return errors.Wrap(err, "decoding container")
+			}
+			for _, msg := range *obj.(*objects.MessageContainer) {
+				if err := c.process(msg.MsgID, msg.Msg); err != nil {
+					return err
+				}
+			}
+			return nil
+
+		case (&objects.PingParams{}).CRC(), (&objects.PingDelayDisconnectParams{}).CRC():
+			if c.srv.droppingPongs() {
+				return nil
+			}
+			obj, err := tl.DecodeUnknownObject(body)
+			if err != nil {
+				return errors.Wrap(err, "decoding ping")
+			}
+			pingID := reflect.ValueOf(obj).Elem().FieldByName("PingID").Int()
+			return c.write(&objects.Pong{MsgID: msgID, PingID: pingID}, false, false)
+
+		case (&objects.MsgsAck{}).CRC(), (&objects.HttpWaitParams{}).CRC(), (&objects.MsgsStateInfo{}).CRC(), (&objects.MsgResendReq{}).CRC():
+			return nil
+		}
+	}
+
+	c.mutex.Lock()
+	keyID, sessionID := authKeyID(c.authKey), c.sessionID
+	c.mutex.Unlock()
+	go func() {
+		var err error
+		if result := c.answer(msgID, keyID, sessionID, body); result != nil {
+			err = c.write(&objects.RpcResult{ReqMsgID: msgID, Obj: result}, true, true)
+		} else {
+			err = c.write(&objects.MsgResendReq{MsgIDs: []int64{msgID}}, false, true)
+		}
+		// the client may close the connection before the answer, e.g. when it moves to another dc
+		if err != nil && !c.isClosed() && !errors.Is(err, io.ErrClosedPipe) && !errors.Is(err, net.ErrClosed) {
+			c.srv.tb.Errorf("mtprototest: answering %#x: %v", crc, err)
+		}
+	}()
+	return nil
+}
+
+// answer returns the result of the request, wrappers are answered by the handlers of their queries,
+// nil if the handler asked for the request again, keyID and sessionID are the ones it was sent with
+func (c *conn) answer(msgID, keyID, sessionID int64, body []byte) tl.Object {
+	crc := binary.LittleEndian.Uint32(body)
+	if crc == (&objects.BindTempAuthKeyParams{}).CRC() {
+		// decoded by hand, the telegram package registers its own type for it
+		bind := &objects.BindTempAuthKeyParams{}
+		if err := tl.Decode(body, bind); err != nil {
+			return &objects.RpcError{ErrorCode: 400, ErrorMessage: "INPUT_METHOD_INVALID"}
+		}
+		c.srv.record(bind)
+		if err := c.srv.bind(bind, msgID, keyID, sessionID); err != nil {
+			c.srv.tb.Logf("mtprototest: auth.bindTempAuthKey: %v", err)
+			return &objects.RpcError{ErrorCode: 400, ErrorMessage: "ENCRYPTED_MESSAGE_INVALID"}
+		}
+		return &tl.PseudoTrue{}
+	}
+	request, err := tl.DecodeUnknownObject(body)
+	if err != nil {
+		return &objects.RpcError{ErrorCode: 400, ErrorMessage: fmt.Sprintf("INPUT_METHOD_INVALID_%d", crc)}
+	}
+	if !c.srv.countTempKeyRequest(keyID) {
+		return &objects.RpcError{ErrorCode: 401, ErrorMessage: "AUTH_KEY_PERM_EMPTY"}
+	}
+	for {
+		if handler, ok := c.srv.handler(request.CRC()); ok {
+			c.srv.record(request)
+			result, err := handler(request)
+			if err == nil {
+				return result
+			}
+			if errors.Is(err, ErrResend) {
+				return nil
+			}
+			if rpcErr, ok := err.(*RPCError); ok {
+				return &objects.RpcError{ErrorCode: rpcErr.Code, ErrorMessage: rpcErr.Message}
+			}
+			c.srv.tb.Errorf("mtprototest: handler of %T: %v", request, err)
+			return &objects.RpcError{ErrorCode: 500, ErrorMessage: "INTERNAL"}
+		}
+		query, ok := wrappedQuery(request)
+		if !ok {
+			break
+		}
+		request = query
+	}
+	c.srv.record(request)
+	return &objects.RpcError{ErrorCode: 400, ErrorMessage: fmt.Sprintf("NOT_HANDLED_%T", request)}
+}
+
+// payload returns the body whose length is written at offset, it must fit in data
+func payload(data []byte, offset int) ([]byte, error) {
+	if len(data) < offset+tl.WordLen {
+		return nil, errors.New("message is too short")
+	}
+	size := int(binary.LittleEndian.Uint32(data[offset:]))
+	start := offset + tl.WordLen
+	if size > len(data)-start {
+		return nil, errors.Errorf("message length %d is larger than the message", size)
+	}
+	return data[start : start+size], nil
+}
+
+// wrappedQuery returns the query of wrappers like invokeWithLayer, initConnection or invokeWithoutUpdates
+func wrappedQuery(request tl.Object) (tl.Object, bool) {
+	v := reflect.ValueOf(request)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, false
+	}
+	field := v.Elem().FieldByName("Query")
+	if !field.IsValid() || field.IsNil() {
+		return nil, false
+	}
+	query, ok := field.Interface().(tl.Object)
+	return query, ok
+}
+
+// write sends obj encrypted, response tells the kind of its message id, content ones must be acknowledged
+func (c *conn) write(obj tl.Object, response, content bool) error {
+	body, err := tl.Marshal(obj)
+	if err != nil {
+		return errors.Wrapf(err, "marshaling %T", obj)
+	}
+	return c.writeEncrypted(body, c.srv.nextMessageID(response), content)
+}
+
+func (c *conn) writeEncrypted(body []byte, msgID int64, content bool) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	seqNo := c.seqNo * 2
+	if content {
+		seqNo++
+		c.seqNo++
+	}
+
+	buf := bytes.NewBuffer(nil)
+	e := tl.NewEncoder(buf)
+	e.PutLong(c.salt)
+	e.PutLong(c.sessionID)
+	e.PutLong(msgID)
+	e.PutInt(seqNo)
+	e.PutInt(int32(len(body)))
+	e.PutRawBytes(body)
+	encrypted, msgKey, err := ige.EncryptAsServer(buf.Bytes(), c.authKey)
+	if err != nil {
+		return errors.Wrap(err, "encrypting")
+	}
+
+	out := make([]byte, 0, tl.DoubleLen+len(msgKey)+len(encrypted))
+	out = append(out, utils.AuthKeyHash(c.authKey)...)
+	out = append(out, msgKey...)
+	out = append(out, encrypted...)
+	return c.mode.WriteMsg(out)
+}
@@ -0,0 +1,151 @@
+package mtprototest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"math/big"
+
+	ige "github.com/jwillp/gogram/internal/aes_ige"
+	"github.com/jwillp/gogram/internal/encoding/tl"
+	"github.com/jwillp/gogram/internal/mtproto/objects"
+	"github.com/pkg/errors"
+)
+
+// the server side of the key exchange
+// https://core.telegram.org/mtproto/auth_key
+
+var (
+	// pq is the product of p and q the client factorizes, the one from the documentation
+	pq, p, q = big.NewInt(0x17ED48941A08F981), big.NewInt(0x494C553B), big.NewInt(0x53911073)
+
+	// dhPrime is the 2048 bit safe prime of RFC 3526, group 14, with generator 2
+	dhPrime, _ = new(big.Int).SetString("FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD129024E088A67CC74020BBEA63B139B22"+
+		"514A08798E3404DDEF9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C245E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F"+
+		"406B7EDEE386BFB5A899FA5AE9F24117C4B1FE649286651ECE45B3DC2007CB8A163BF0598DA48361C55D39A69163FA8FD24CF5F8"+
+		"3655D23DCA3AD961C62F356208552BB9ED529077096966D670C354E4ABC9804F1746C08CA18217C32905E462E36CE3BE39E772C1"+
+		"80E86039B2783A2EC07A28FB5C55DF06F4C52C9DE2BCBF6955817183995497CEA956AE515D2261898FA051015728E5A8AACAA68FF"+
+		"FFFFFFFFFFFFFF", 16)
+	dhG int32 = 2
+)
+
+// exchange is the state of a key exchange in progress
+type exchange struct {
+	nonce       *tl.Int128
+	serverNonce *tl.Int128
+	newNonce    *tl.Int256
+	a           *big.Int
+	temp        bool // p_q_inner_data_temp_dc, the key is a temporary one
+}
+
+// handshake answers an unencrypted message of the key exchange
+func (c *conn) handshake(body []byte) error {
+	obj, err := tl.DecodeUnknownObject(body)
+	if err != nil {
+		return errors.Wrap(err, "decoding handshake message")
+	}
+	switch req := obj.(type) {
+	case *objects.ReqPQParams:
+		c.dh = &exchange{nonce: req.Nonce, serverNonce: tl.RandomInt128()}
+		return c.writePlain(&objects.ResPQ{
+			Nonce:        req.Nonce,
+			ServerNonce:  c.dh.serverNonce,
+			Pq:           pq.Bytes(),
+			Fingerprints: []int64{c.srv.fingerprint},
+		})
+
+	case *objects.ReqDHParamsParams:
+		if err := c.checkNonces(req.Nonce, req.ServerNonce); err != nil {
+			return errors.Wrap(err, "req_DH_params")
+		}
+		if req.PublicKeyFingerprint != c.srv.fingerprint {
+			return errors.Errorf("req_DH_params: unknown key fingerprint %d", req.PublicKeyFingerprint)
+		}
+		if !bytes.Equal(req.P, p.Bytes()) || !bytes.Equal(req.Q, q.Bytes()) {
+			return errors.New("req_DH_params: wrong factorization of pq")
+		}
+		data, err := ige.RSAUnpad(req.EncryptedData, c.srv.key)
+		if err != nil {
+			return errors.Wrap(err, "req_DH_params")
+		}
+		inner, err := tl.DecodeUnknownObject(data)
+		if err != nil {
+			return errors.Wrap(err, "decoding p_q_inner_data")
+		}
+		switch inner := inner.(type) {
+		case *objects.PQInnerDataDc:
+			c.dh.newNonce = inner.NewNonce
+		case *objects.PQInnerDataTempDc:
+			c.dh.newNonce, c.dh.temp = inner.NewNonce, true
+		default:
+			return errors.Errorf("req_DH_params: unexpected inner data %T", inner)
+		}
+
+		if c.dh.a, err = rand.Int(rand.Reader, dhPrime); err != nil {
+			return errors.Wrap(err, "generating a")
+		}
+		answer, err := tl.Marshal(&objects.ServerDHInnerData{
+			Nonce:       c.dh.nonce,
+			ServerNonce: c.dh.serverNonce,
+			G:           dhG,
+			DhPrime:     dhPrime.Bytes(),
+			GA:          new(big.Int).Exp(big.NewInt(int64(dhG)), c.dh.a, dhPrime).Bytes(),
+			ServerTime:  int32(c.srv.now().Unix()),
+		})
+		if err != nil {
+			return errors.Wrap(err, "marshaling server_DH_inner_data")
+		}
+		encrypted, err := ige.EncryptMessageWithTempKeys(answer, c.dh.newNonce.Int, c.dh.serverNonce.Int)
+		if err != nil {
+			return errors.Wrap(err, "encrypting server_DH_inner_data")
+		}
+		return c.writePlain(&objects.ServerDHParamsOk{Nonce: c.dh.nonce, ServerNonce: c.dh.serverNonce, EncryptedAnswer: encrypted})
+
+	case *objects.SetClientDHParamsParams:
+		if err := c.checkNonces(req.Nonce, req.ServerNonce); err != nil {
+			return errors.Wrap(err, "set_client_DH_params")
+		}
+		if c.dh.newNonce == nil {
+			return errors.New("set_client_DH_params: req_DH_params wasn't sent")
+		}
+		data, err := ige.DecryptMessageWithTempKeys(req.EncryptedData, c.dh.newNonce.Int, c.dh.serverNonce.Int)
+		if err != nil {
+			return errors.Wrap(err, "decrypting client_DH_inner_data")
+		}
+		inner, err := tl.DecodeUnknownObject(data)
+		if err != nil {
+			return errors.Wrap(err, "decoding client_DH_inner_data")
+		}
+		clientDH, ok := inner.(*objects.ClientDHInnerData)
+		if !ok {
+			return errors.Errorf("set_client_DH_params: unexpected inner data %T", inner)
+		}
+		gB := new(big.Int).SetBytes(clientDH.GB)
+		authKey := new(big.Int).Exp(gB, c.dh.a, dhPrime).FillBytes(make([]byte, 256))
+		c.srv.addAuthKey(authKey, c.dh.temp)
+
+		keyHash := sha1.Sum(authKey)
+		hashed := append(c.dh.newNonce.FillBytes(make([]byte, 32)), 1)
+		hash := sha1.Sum(append(hashed, keyHash[:8]...))
+		nonce, serverNonce := c.dh.nonce, c.dh.serverNonce
+		c.dh = nil
+		return c.writePlain(&objects.DHGenOk{
+			Nonce:         nonce,
+			ServerNonce:   serverNonce,
+			NewNonceHash1: &tl.Int128{Int: new(big.Int).SetBytes(hash[4:20])},
+		})
+
+	default:
+		return errors.Errorf("unexpected unencrypted message %T", obj)
+	}
+}
+
+func (c *conn) checkNonces(nonce, serverNonce *tl.Int128) error {
+	if c.dh == nil {
+		return errors.New("req_pq wasn't sent")
+	}
+	if c.dh.nonce.Cmp(nonce.Int) != 0 || c.dh.serverNonce.Cmp(serverNonce.Int) != 0 {
+		return errors.New("nonce mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package mtprototest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/jwillp/gogram/internal/encoding/tl"
+	"github.com/jwillp/gogram/internal/mtproto/objects"
+	"github.com/pkg/errors"
+)
+
+// the http transport: every POST carries a packet of the client and the response body one packet
+// of the server, requests holding http_wait are answered once the server has something to send
+
+const httpMaxWait = 25 * time.Second
+
+// httpOutbox keeps the packets of the http session until a request of the client takes them
+type httpOutbox chan []byte
+
+func (o httpOutbox) WriteMsg(msg []byte) error {
+	select {
+	case o <- msg:
+		return nil
+	default:
+		return errors.New("no http request takes the packets")
+	}
+}
+
+// httpSession returns the conn all http requests go through, the http connections of a client
+// come and go, while its session stays
+func (s *Server) httpSession() *conn {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.http == nil || s.http.isClosed() {
+		s.http = &conn{srv: s, mode: make(httpOutbox, 64)}
+		s.conns[s.http] = struct{}{}
+	}
+	return s.http
+}
+
+// serveHTTP answers the requests coming over nc until it's closed
+func (s *Server) serveHTTP(nc net.Conn, r *bufio.Reader) error {
+	for {
+		req, err := http.ReadRequest(r)
+		if err != nil {
+			return nil
+		}
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil
+		}
+
+		c := s.httpSession()
+		wait, err := c.readHTTP(data)
+		if err != nil {
+			return err
+		}
+		body := c.nextPacket(wait)
+		resp := &http.Response{
+			StatusCode:    http.StatusOK,
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			ContentLength: int64(len(body)),
+			Body:          io.NopCloser(bytes.NewReader(body)),
+		}
+		if err := resp.Write(nc); err != nil {
+			return nil
+		}
+	}
+}
+
+// readHTTP handles a packet of the client and reports whether it holds http_wait
+func (c *conn) readHTTP(data []byte) (bool, error) {
+	if len(data) < tl.DoubleLen {
+		return false, errors.Errorf("message is too short, %d bytes", len(data))
+	}
+	keyID := int64(binary.LittleEndian.Uint64(data))
+	if keyID == 0 {
+		return false, c.readPlain(data)
+	}
+	msgID, body, err := c.decrypt(keyID, data)
+	if err != nil || body == nil {
+		return false, err
+	}
+	return hasHTTPWait(body), c.process(msgID, body)
+}
+
+func hasHTTPWait(body []byte) bool {
+	if len(body) < tl.WordLen {
+		return false
+	}
+	switch binary.LittleEndian.Uint32(body) {
+	case (&objects.HttpWaitParams{}).CRC():
+		return true
+	case (&objects.MessageContainer{}).CRC():
+		obj, err := tl.DecodeUnknownObject(body)
+		if err != nil {
+			return false
+		}
+		for _, msg := range *obj.(*objects.MessageContainer) {
+			if hasHTTPWait(msg.Msg) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nextPacket returns the packet to answer a request with, nil if there is none,
+// requests holding http_wait wait for one
+func (c *conn) nextPacket(wait bool) []byte {
+	outbox := c.mode.(httpOutbox)
+	if !wait {
+		select {
+		case packet := <-outbox:
+			return packet
+		default:
+			return nil
+		}
+	}
+	timer := time.NewTimer(httpMaxWait)
+	defer timer.Stop()
+	select {
+	case packet := <-outbox:
+		return packet
+	case <-timer.C:
+	case <-c.srv.done:
+	}
+	return nil
+}
@@ -0,0 +1,449 @@
+// Package mtprototest runs an in-process MTProto server, so the client can be tested without network access.
+//
+// The server creates auth keys with clients, answers pings, and answers requests with the responses tests
+// registered for their types, wrappers like invokeWithLayer and initConnection are unwrapped first.
+// Clients may use any of the tcp modes or the http transport, whose long polling requests are held until
+// there is something to send.
+//
+//	srv := mtprototest.NewServer(t)
+//	srv.Respond(&telegram.HelpGetConfigParams{}, &telegram.Config{ThisDc: 2})
+//	client, _ := telegram.NewClient(telegram.ClientConfig{Dialer: srv.Dial, PublicKeys: srv.PublicKeys(), ...})
+package mtprototest
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	ige "github.com/jwillp/gogram/internal/aes_ige"
+	"github.com/jwillp/gogram/internal/encoding/tl"
+	"github.com/jwillp/gogram/internal/keys"
+	"github.com/jwillp/gogram/internal/mtproto/objects"
+	"github.com/jwillp/gogram/internal/utils"
+	"github.com/pkg/errors"
+)
+
+// HandlerFunc answers a request, returning an *RPCError answers it with rpc_error
+type HandlerFunc func(request tl.Object) (tl.Object, error)
+
+// RPCError is returned by handlers to answer with rpc_error
+type RPCError struct {
+	Code    int32
+	Message string
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// ErrResend is returned by handlers to leave the request unanswered and ask the client
+// to send it again with msg_resend_req
+var ErrResend = errors.New("resend requested")
+
+// TempKey is a temporary auth key a client created for perfect forward secrecy
+type TempKey struct {
+	ID       int64 // auth key id
+	PermID   int64 // id of the permanent key it was bound to with auth.bindTempAuthKey, 0 until then
+	Requests int   // requests encrypted with it, the bind excluded
+}
+
+// Server is a fake MTProto server, see the package doc
+type Server struct {
+	tb          testing.TB
+	key         *rsa.PrivateKey
+	fingerprint int64
+
+	mutex     sync.Mutex
+	handlers  map[uint32]HandlerFunc
+	authKeys  map[int64][]byte // auth key id -> key
+	tempKeys  []*TempKey       // in the order they were created
+	conns     map[*conn]struct{}
+	http      *conn // the session of http clients
+	listeners []net.Listener
+	requests  []tl.Object
+	batches   [][]tl.Object // contents of the received containers
+	lastMsgID int64
+	salt      int64 // messages with another salt are rejected, 0 accepts any
+	dropPongs bool
+	clock     time.Duration // how far the server clock is ahead of the local one
+	closed    bool
+	done      chan struct{} // closed with the server
+	wg        sync.WaitGroup
+}
+
+// NewServer starts a server, it's closed when the test ends
+func NewServer(tb testing.TB) *Server {
+	tb.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		tb.Fatalf("generating rsa key: %v", err)
+	}
+	s := &Server{
+		tb:          tb,
+		key:         key,
+		fingerprint: int64(binary.LittleEndian.Uint64(keys.RSAFingerprint(&key.PublicKey))),
+		handlers:    make(map[uint32]HandlerFunc),
+		authKeys:    make(map[int64][]byte),
+		conns:       make(map[*conn]struct{}),
+		done:        make(chan struct{}),
+	}
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// PublicKeys returns the key clients must know to create auth keys with the server
+func (s *Server) PublicKeys() []*rsa.PublicKey {
+	return []*rsa.PublicKey{&s.key.PublicKey}
+}
+
+// Dial connects to the server over net.Pipe, it has the signature of net.Dialer.DialContext,
+// so it can be used as the Dialer of the client, the address is ignored
+func (s *Server) Dial(_ context.Context, _, _ string) (net.Conn, error) {
+	client, server := net.Pipe()
+	if !s.serve(server) {
+		client.Close()
+		return nil, errors.New("server is closed")
+	}
+	return client, nil
+}
+
+// Listen accepts connections on a loopback port and returns its address
+func (s *Server) Listen() (string, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", errors.Wrap(err, "listening")
+	}
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		l.Close()
+		return "", errors.New("server is closed")
+	}
+	s.listeners = append(s.listeners, l)
+	s.wg.Add(1)
+	s.mutex.Unlock()
+
+	go func() {
+		defer s.wg.Done()
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			if !s.serve(c) {
+				return
+			}
+		}
+	}()
+	return l.Addr().String(), nil
+}
+
+// Handle makes handler answer the requests of the type of request, which is used only for its constructor id
+func (s *Server) Handle(request tl.Object, handler HandlerFunc) {
+	s.mutex.Lock()
+	s.handlers[request.CRC()] = handler
+	s.mutex.Unlock()
+}
+
+// Respond answers the requests of the type of request with response
+func (s *Server) Respond(request, response tl.Object) {
+	s.Handle(request, func(tl.Object) (tl.Object, error) {
+		return response, nil
+	})
+}
+
+// Requests returns the requests received so far, without wrappers like invokeWithLayer
+func (s *Server) Requests() []tl.Object {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]tl.Object(nil), s.requests...)
+}
+
+// Containers returns the contents of the msg_containers received so far, rejected ones included
+func (s *Server) Containers() [][]tl.Object {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([][]tl.Object(nil), s.batches...)
+}
+
+// SetSalt makes salt the only valid server salt, encrypted messages with another one are rejected
+// with bad_server_salt, which tells the client the new salt, 0 accepts any salt again
+func (s *Server) SetSalt(salt int64) {
+	s.mutex.Lock()
+	s.salt = salt
+	s.mutex.Unlock()
+}
+
+// DropPongs makes the server ignore pings while drop is true, as a connection which silently died
+func (s *Server) DropPongs(drop bool) {
+	s.mutex.Lock()
+	s.dropPongs = drop
+	s.mutex.Unlock()
+}
+
+func (s *Server) droppingPongs() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.dropPongs
+}
+
+// SetClock moves the server clock by skew from the local one, as a server whose clock differs from the
+// client's, encrypted messages whose ids are more than 300s behind it or 30s ahead of it are rejected
+// with bad_msg_notification 16 or 17
+func (s *Server) SetClock(skew time.Duration) {
+	s.mutex.Lock()
+	s.clock = skew
+	s.mutex.Unlock()
+}
+
+// TempKeys returns the temporary keys created so far, oldest first, requests under a temporary key
+// which isn't bound are answered with AUTH_KEY_PERM_EMPTY
+func (s *Server) TempKeys() []TempKey {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	keys := make([]TempKey, len(s.tempKeys))
+	for i, k := range s.tempKeys {
+		keys[i] = *k
+	}
+	return keys
+}
+
+// Push sends obj, an update usually, to every connected client
+func (s *Server) Push(obj tl.Object) error {
+	body, err := tl.Marshal(obj)
+	if err != nil {
+		return errors.Wrap(err, "marshaling")
+	}
+	sent := 0
+	for _, c := range s.connections() {
+		if !c.established() {
+			continue
+		}
+		if err := c.writeEncrypted(body, s.nextMessageID(false), true); err != nil {
+			return errors.Wrap(err, "writing")
+		}
+		sent++
+	}
+	if sent == 0 {
+		return errors.New("no client is connected")
+	}
+	return nil
+}
+
+// DropConnections closes the connections of all clients, auth keys are kept, so they reconnect without a handshake
+func (s *Server) DropConnections() {
+	for _, c := range s.connections() {
+		c.close()
+	}
+}
+
+// Close closes the listeners and connections and waits for them to finish
+func (s *Server) Close() {
+	s.mutex.Lock()
+	if !s.closed {
+		close(s.done)
+	}
+	s.closed = true
+	listeners := s.listeners
+	s.listeners = nil
+	s.mutex.Unlock()
+	for _, l := range listeners {
+		l.Close()
+	}
+	s.DropConnections()
+	s.wg.Wait()
+}
+
+// serve handles the connection until it's closed, false if the server is closed already
+func (s *Server) serve(nc net.Conn) bool {
+	c := &conn{srv: s, nc: nc}
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		nc.Close()
+		return false
+	}
+	s.conns[c] = struct{}{}
+	s.wg.Add(1)
+	s.mutex.Unlock()
+
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.mutex.Lock()
+			delete(s.conns, c)
+			s.mutex.Unlock()
+		}()
+		if err := c.run(); err != nil && !c.isClosed() {
+			s.tb.Errorf("mtprototest: %v", err)
+		}
+		c.close()
+	}()
+	return true
+}
+
+func (s *Server) connections() []*conn {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	conns := make([]*conn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	return conns
+}
+
+func (s *Server) handler(crc uint32) (HandlerFunc, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	h, ok := s.handlers[crc]
+	return h, ok
+}
+
+func (s *Server) record(request tl.Object) {
+	s.mutex.Lock()
+	s.requests = append(s.requests, request)
+	s.mutex.Unlock()
+}
+
+// recordContainer remembers the contents of body if it's a container, rejected ones too
+func (s *Server) recordContainer(body []byte) {
+	if len(body) < tl.WordLen || binary.LittleEndian.Uint32(body) != (&objects.MessageContainer{}).CRC() {
+		return
+	}
+	obj, err := tl.DecodeUnknownObject(body)
+	if err != nil {
+		return
+	}
+	container := *obj.(*objects.MessageContainer)
+	batch := make([]tl.Object, 0, len(container))
+	for _, msg := range container {
+		if inner, err := tl.DecodeUnknownObject(msg.Msg); err == nil {
+			batch = append(batch, inner)
+		}
+	}
+	s.mutex.Lock()
+	s.batches = append(s.batches, batch)
+	s.mutex.Unlock()
+}
+
+func (s *Server) addAuthKey(key []byte, temp bool) {
+	s.mutex.Lock()
+	s.authKeys[authKeyID(key)] = key
+	if temp {
+		s.tempKeys = append(s.tempKeys, &TempKey{ID: authKeyID(key)})
+	}
+	s.mutex.Unlock()
+}
+
+// bind checks auth.bindTempAuthKey, sent with the message msgID, the temporary key keyID and sessionID,
+// and binds the key to the permanent one
+func (s *Server) bind(req *objects.BindTempAuthKeyParams, msgID, keyID, sessionID int64) error {
+	permKey, ok := s.authKey(req.PermAuthKeyID)
+	if !ok {
+		return errors.Errorf("unknown permanent key %d", req.PermAuthKeyID)
+	}
+	innerMsgID, data, err := ige.DecryptBindMessage(req.EncryptedMessage, permKey)
+	if err != nil {
+		return errors.Wrap(err, "decrypting")
+	}
+	obj, err := tl.DecodeUnknownObject(data)
+	if err != nil {
+		return errors.Wrap(err, "decoding bind_auth_key_inner")
+	}
+	inner, ok := obj.(*objects.BindAuthKeyInner)
+	switch {
+	case !ok:
+		return errors.Errorf("encrypted message is a %T", obj)
+	case innerMsgID != msgID:
+		return errors.New("msg_id differs from the one of the request")
+	case inner.Nonce != req.Nonce || inner.PermAuthKeyID != req.PermAuthKeyID || inner.ExpiresAt != req.ExpiresAt:
+		return errors.New("bind_auth_key_inner differs from the request")
+	case inner.TempAuthKeyID != keyID || inner.TempSessionID != sessionID:
+		return errors.New("bind_auth_key_inner isn't about the key and session it was sent with")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	temp := s.tempKey(keyID)
+	if temp == nil {
+		return errors.New("the request wasn't encrypted with a temporary key")
+	}
+	temp.PermID = req.PermAuthKeyID
+	return nil
+}
+
+// countTempKeyRequest counts a request sent with the key keyID, false if it's a temporary key which isn't bound
+func (s *Server) countTempKeyRequest(keyID int64) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	temp := s.tempKey(keyID)
+	if temp == nil {
+		return true
+	}
+	temp.Requests++
+	return temp.PermID != 0
+}
+
+// tempKey returns the temporary key with the id, nil for permanent keys, it's guarded by the mutex
+func (s *Server) tempKey(id int64) *TempKey {
+	for _, k := range s.tempKeys {
+		if k.ID == id {
+			return k
+		}
+	}
+	return nil
+}
+
+func (s *Server) authKey(id int64) ([]byte, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	key, ok := s.authKeys[id]
+	return key, ok
+}
+
+// now is the time by the server clock
+func (s *Server) now() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return time.Now().Add(s.clock)
+}
+
+// checkMsgID returns the bad_msg_notification code of a client msg_id whose time is off, 0 if it's fine
+func (s *Server) checkMsgID(msgID int64) int32 {
+	sent, now := msgID>>32, s.now().Unix()
+	switch {
+	case sent < now-300:
+		return 16
+	case sent > now+30:
+		return 17
+	}
+	return 0
+}
+
+// checkSalt returns the valid salt if the client used another one
+func (s *Server) checkSalt(salt int64) (int64, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.salt, s.salt == 0 || s.salt == salt
+}
+
+// nextMessageID returns an id of a server message, responses have ids of 1 mod 4, other messages 3 mod 4
+func (s *Server) nextMessageID(response bool) int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.lastMsgID = utils.GenerateMessageId(s.lastMsgID, s.clock)
+	if response {
+		return s.lastMsgID | 1
+	}
+	return s.lastMsgID | 3
+}
+
+func authKeyID(key []byte) int64 {
+	return int64(binary.LittleEndian.Uint64(utils.AuthKeyHash(key)))
+}
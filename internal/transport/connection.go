@@ -11,7 +11,7 @@ import (
 
 type tcpConn struct {
 	cancelReader *CancelableReader
-	conn         *net.TCPConn
+	conn         net.Conn
 	timeout      time.Duration
 }
 
@@ -19,18 +19,12 @@ type TCPConnConfig struct {
 	Ctx     context.Context
 	Host    string
 	Timeout time.Duration
-	Socks   *Socks
+	Proxy   *Proxy
+	Dialer  Dialer // net.Dialer if nil, proxies are dialed with it too
 }
 
 func NewTCP(cfg TCPConnConfig) (Conn, error) {
-	if cfg.Socks != nil && cfg.Socks.Host != "" {
-		return newSocksTCP(cfg)
-	}
-	tcpAddr, err := net.ResolveTCPAddr("tcp", cfg.Host)
-	if err != nil {
-		return nil, errors.Wrap(err, "resolving tcp")
-	}
-	conn, err := net.DialTCP("tcp", nil, tcpAddr)
+	conn, err := dial(cfg.Ctx, cfg.Dialer, cfg.Proxy, cfg.Host, cfg.Timeout)
 	if err != nil {
 		return nil, errors.Wrap(err, "dialing tcp")
 	}
@@ -42,18 +36,6 @@ func NewTCP(cfg TCPConnConfig) (Conn, error) {
 	}, nil
 }
 
-func newSocksTCP(cfg TCPConnConfig) (Conn, error) {
-	conn, err := DialProxy(cfg.Socks, "tcp", cfg.Host)
-	if err != nil {
-		return nil, err
-	}
-	return &tcpConn{
-		cancelReader: NewCancelableReader(cfg.Ctx, conn),
-		conn:         conn.(*net.TCPConn),
-		timeout:      cfg.Timeout,
-	}, nil
-}
-
 func (t *tcpConn) Close() error {
 	return t.conn.Close()
 }
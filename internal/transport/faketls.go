@@ -0,0 +1,275 @@
+package transport
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// fake-TLS makes the connection to an ee MTProxy look like TLS 1.3 to the secret's domain,
+// the client hello is signed with the secret and the traffic is sent as application data records
+
+const (
+	tlsRecordHandshake        = 0x16
+	tlsRecordChangeCipherSpec = 0x14
+	tlsRecordApplicationData  = 0x17
+
+	tlsRecordHeaderLen = 5
+	tlsMaxRecordLen    = 16384
+	tlsClientHelloLen  = 517
+	tlsRandomOffset    = tlsRecordHeaderLen + 4 + 2 // record header, handshake header, version
+)
+
+var errFakeTLSDigest = errors.New("fake tls handshake: server digest mismatch, check the proxy secret")
+
+type fakeTLSConn struct {
+	conn Conn
+
+	writeMutex sync.Mutex
+	sentCCS    bool
+	readBuf    bytes.Buffer
+}
+
+// newFakeTLSConn performs the fake-TLS handshake and returns a connection which wraps
+// everything into TLS application data records
+func newFakeTLSConn(conn Conn, key []byte, domain string) (Conn, error) {
+	hello, err := clientHello(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(hello)
+	digest := mac.Sum(nil)
+	// last 4 bytes of the random carry the time, so the proxy can reject replayed hellos
+	timestamp := binary.LittleEndian.Uint32(digest[28:]) ^ uint32(time.Now().Unix())
+	binary.LittleEndian.PutUint32(digest[28:], timestamp)
+	copy(hello[tlsRandomOffset:], digest)
+
+	if _, err := conn.Write(hello); err != nil {
+		return nil, errors.Wrap(err, "sending client hello")
+	}
+
+	// server hello, change cipher spec and a random application data record
+	var response []byte
+	for _, want := range []byte{tlsRecordHandshake, tlsRecordChangeCipherSpec, tlsRecordApplicationData} {
+		typ, record, err := readTLSRecord(conn)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading server hello")
+		}
+		if typ != want {
+			return nil, errors.Errorf("fake tls handshake: unexpected record type %#x", typ)
+		}
+		response = append(response, record...)
+	}
+	if len(response) < tlsRandomOffset+32 {
+		return nil, errors.New("fake tls handshake: server hello is too short")
+	}
+
+	serverDigest := make([]byte, 32)
+	copy(serverDigest, response[tlsRandomOffset:])
+	copy(response[tlsRandomOffset:tlsRandomOffset+32], make([]byte, 32))
+	mac = hmac.New(sha256.New, key)
+	mac.Write(hello[tlsRandomOffset : tlsRandomOffset+32])
+	mac.Write(response)
+	if !hmac.Equal(mac.Sum(nil), serverDigest) {
+		return nil, errFakeTLSDigest
+	}
+
+	return &fakeTLSConn{conn: conn}, nil
+}
+
+// readTLSRecord reads a whole record, header included
+func readTLSRecord(r io.Reader) (byte, []byte, error) {
+	header := make([]byte, tlsRecordHeaderLen)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+	size := int(binary.BigEndian.Uint16(header[3:]))
+	record := make([]byte, tlsRecordHeaderLen+size)
+	copy(record, header)
+	if _, err := io.ReadFull(r, record[tlsRecordHeaderLen:]); err != nil {
+		return 0, nil, err
+	}
+	return header[0], record, nil
+}
+
+func (c *fakeTLSConn) Write(b []byte) (int, error) {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	buf := make([]byte, 0, len(b)+(len(b)/tlsMaxRecordLen+2)*tlsRecordHeaderLen+1)
+	if !c.sentCCS {
+		// browsers send change cipher spec before the first encrypted record
+		buf = append(buf, tlsRecordChangeCipherSpec, 0x03, 0x03, 0x00, 0x01, 0x01)
+		c.sentCCS = true
+	}
+	for rest := b; len(rest) > 0; {
+		chunk := rest
+		if len(chunk) > tlsMaxRecordLen {
+			chunk = chunk[:tlsMaxRecordLen]
+		}
+		buf = append(buf, tlsRecordApplicationData, 0x03, 0x03, byte(len(chunk)>>8), byte(len(chunk)))
+		buf = append(buf, chunk...)
+		rest = rest[len(chunk):]
+	}
+	if _, err := c.conn.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (c *fakeTLSConn) Read(b []byte) (int, error) {
+	for c.readBuf.Len() == 0 {
+		typ, record, err := readTLSRecord(c.conn)
+		if err != nil {
+			return 0, err
+		}
+		switch typ {
+		case tlsRecordApplicationData:
+			c.readBuf.Write(record[tlsRecordHeaderLen:])
+		case tlsRecordChangeCipherSpec:
+		default:
+			return 0, errors.Errorf("fake tls: unexpected record type %#x", typ)
+		}
+	}
+	return c.readBuf.Read(b)
+}
+
+func (c *fakeTLSConn) Close() error {
+	return c.conn.Close()
+}
+
+// clientHello returns a client hello looking like one of a browser, with zeroed random
+func clientHello(domain string) ([]byte, error) {
+	random := make([]byte, 32+32+7)
+	if _, err := rand.Read(random); err != nil {
+		return nil, errors.Wrap(err, "generating client hello")
+	}
+	sessionID, keyShare, grease := random[:32], random[32:64], random[64:]
+	for i := range grease {
+		grease[i] = grease[i]&0xf0 | 0x0a
+	}
+	greaseValue := func(i int) []byte { return []byte{grease[i], grease[i]} }
+
+	b := &helloBuilder{}
+	b.bytes(tlsRecordHandshake, 0x03, 0x01)
+	b.prefixed16(func() {
+		b.bytes(0x01) // client hello
+		b.prefixed24(func() {
+			b.bytes(0x03, 0x03)
+			b.bytes(make([]byte, 32)...) // random, filled with the digest later
+			b.bytes(byte(len(sessionID)))
+			b.bytes(sessionID...)
+			b.prefixed16(func() {
+				b.bytes(greaseValue(0)...)
+				b.bytes(
+					0x13, 0x01, 0x13, 0x02, 0x13, 0x03, 0xc0, 0x2b, 0xc0, 0x2f, 0xc0, 0x2c, 0xc0, 0x30,
+					0xcc, 0xa9, 0xcc, 0xa8, 0xc0, 0x13, 0xc0, 0x14, 0x00, 0x9c, 0x00, 0x9d, 0x00, 0x2f, 0x00, 0x35,
+				)
+			})
+			b.bytes(0x01, 0x00) // no compression
+			b.prefixed16(func() {
+				b.bytes(greaseValue(2)...)
+				b.bytes(0x00, 0x00)
+				// server name
+				b.extension(0x0000, func() {
+					b.prefixed16(func() {
+						b.bytes(0x00)
+						b.prefixed16(func() { b.bytes([]byte(domain)...) })
+					})
+				})
+				b.extension(0x0017, func() {})                // extended master secret
+				b.extension(0xff01, func() { b.bytes(0x00) }) // renegotiation info
+				// supported groups
+				b.extension(0x000a, func() {
+					b.prefixed16(func() {
+						b.bytes(greaseValue(4)...)
+						b.bytes(0x00, 0x1d, 0x00, 0x17, 0x00, 0x18)
+					})
+				})
+				b.extension(0x000b, func() { b.bytes(0x01, 0x00) }) // ec point formats
+				b.extension(0x0023, func() {})                      // session ticket
+				// alpn
+				b.extension(0x0010, func() {
+					b.prefixed16(func() {
+						b.bytes(0x02, 'h', '2')
+						b.bytes(0x08, 'h', 't', 't', 'p', '/', '1', '.', '1')
+					})
+				})
+				b.extension(0x0005, func() { b.bytes(0x01, 0x00, 0x00, 0x00, 0x00) }) // status request
+				// signature algorithms
+				b.extension(0x000d, func() {
+					b.prefixed16(func() {
+						b.bytes(0x04, 0x03, 0x08, 0x04, 0x04, 0x01, 0x05, 0x03, 0x08, 0x05, 0x05, 0x01, 0x08, 0x06, 0x06, 0x01)
+					})
+				})
+				b.extension(0x0012, func() {}) // signed certificate timestamp
+				// key share
+				b.extension(0x0033, func() {
+					b.prefixed16(func() {
+						b.bytes(greaseValue(4)...)
+						b.bytes(0x00, 0x01, 0x00)
+						b.bytes(0x00, 0x1d)
+						b.prefixed16(func() { b.bytes(keyShare...) })
+					})
+				})
+				b.extension(0x002d, func() { b.bytes(0x01, 0x01) }) // psk key exchange modes
+				// supported versions
+				b.extension(0x002b, func() {
+					b.bytes(0x0a)
+					b.bytes(greaseValue(6)...)
+					b.bytes(0x03, 0x04, 0x03, 0x03, 0x03, 0x02, 0x03, 0x01)
+				})
+				b.extension(0x001b, func() { b.bytes(0x02, 0x00, 0x02) }) // compress certificate
+				b.bytes(greaseValue(3)...)
+				b.bytes(0x00, 0x01, 0x00)
+				// padding up to the usual size of a browser hello
+				if padding := tlsClientHelloLen - b.len() - 4; padding >= 0 {
+					b.extension(0x0015, func() { b.bytes(make([]byte, padding)...) })
+				}
+			})
+		})
+	})
+	return b.buf, nil
+}
+
+// helloBuilder writes tls structures with length prefixes
+type helloBuilder struct {
+	buf []byte
+}
+
+func (b *helloBuilder) len() int {
+	return len(b.buf)
+}
+
+func (b *helloBuilder) bytes(v ...byte) {
+	b.buf = append(b.buf, v...)
+}
+
+func (b *helloBuilder) prefixed16(body func()) {
+	start := len(b.buf)
+	b.buf = append(b.buf, 0, 0)
+	body()
+	binary.BigEndian.PutUint16(b.buf[start:], uint16(len(b.buf)-start-2))
+}
+
+func (b *helloBuilder) prefixed24(body func()) {
+	start := len(b.buf)
+	b.buf = append(b.buf, 0, 0, 0)
+	body()
+	size := len(b.buf) - start - 3
+	b.buf[start], b.buf[start+1], b.buf[start+2] = byte(size>>16), byte(size>>8), byte(size)
+}
+
+func (b *helloBuilder) extension(typ uint16, body func()) {
+	b.buf = append(b.buf, byte(typ>>8), byte(typ))
+	b.prefixed16(body)
+}
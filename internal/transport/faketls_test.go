@@ -0,0 +1,114 @@
+package transport
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// acceptFakeTLS is the proxy side of the handshake: it checks the digest of the client hello
+// and the time in it, then answers with a server hello signed with serverKey
+func acceptFakeTLS(conn net.Conn, key, serverKey []byte, domain string) error {
+	typ, hello, err := readTLSRecord(conn)
+	if err != nil {
+		return err
+	}
+	if typ != tlsRecordHandshake || len(hello) != tlsClientHelloLen {
+		return errors.Errorf("client hello is a record of type %#x and %d bytes", typ, len(hello))
+	}
+	if !bytes.Contains(hello, []byte(domain)) {
+		return errors.New("client hello has no server name")
+	}
+
+	random := append([]byte{}, hello[tlsRandomOffset:tlsRandomOffset+32]...)
+	copy(hello[tlsRandomOffset:], make([]byte, 32))
+	mac := hmac.New(sha256.New, key)
+	mac.Write(hello)
+	digest := mac.Sum(nil)
+	for i := range digest {
+		digest[i] ^= random[i]
+	}
+	// the first 28 bytes are the digest, the xor leaves zeros there and the time in the last 4
+	if !bytes.Equal(digest[:28], make([]byte, 28)) {
+		return errors.New("client hello digest mismatch")
+	}
+	if sent := time.Unix(int64(binary.LittleEndian.Uint32(digest[28:])), 0); time.Since(sent).Abs() > time.Minute {
+		return errors.Errorf("client hello was sent at %s", sent)
+	}
+
+	serverHello := []byte{tlsRecordHandshake, 0x03, 0x03, 0, 0, 0x02, 0, 0, 0, 0x03, 0x03}
+	serverHello = append(serverHello, make([]byte, 32+40)...)
+	binary.BigEndian.PutUint16(serverHello[3:], uint16(len(serverHello)-tlsRecordHeaderLen))
+	response := append(serverHello, tlsRecordChangeCipherSpec, 0x03, 0x03, 0x00, 0x01, 0x01)
+	response = append(response, tlsRecordApplicationData, 0x03, 0x03, 0x00, 0x04, 1, 2, 3, 4)
+	mac = hmac.New(sha256.New, serverKey)
+	mac.Write(random)
+	mac.Write(response)
+	copy(response[tlsRandomOffset:], mac.Sum(nil))
+	_, err = conn.Write(response)
+	return err
+}
+
+func TestFakeTLSConn(t *testing.T) {
+	key := bytes.Repeat([]byte{0x5a}, 16)
+	clientSide, serverSide := net.Pipe()
+	defer clientSide.Close()
+	defer serverSide.Close()
+
+	accepted := make(chan error, 1)
+	go func() { accepted <- acceptFakeTLS(serverSide, key, key, "example.com") }()
+	client, err := newFakeTLSConn(clientSide, key, "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-accepted; err != nil {
+		t.Fatal(err)
+	}
+
+	// bigger messages are split into several records
+	msg := bytes.Repeat([]byte{7}, tlsMaxRecordLen+100)
+	go client.Write(msg)
+	var got []byte
+	for _, want := range []byte{tlsRecordChangeCipherSpec, tlsRecordApplicationData, tlsRecordApplicationData} {
+		typ, record, err := readTLSRecord(serverSide)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ != want {
+			t.Fatalf("got a record of type %#x, want %#x", typ, want)
+		}
+		if typ == tlsRecordApplicationData {
+			got = append(got, record[tlsRecordHeaderLen:]...)
+		}
+	}
+	if !bytes.Equal(got, msg) {
+		t.Errorf("the proxy got %d bytes, want %d", len(got), len(msg))
+	}
+
+	go serverSide.Write([]byte{tlsRecordApplicationData, 0x03, 0x03, 0x00, 0x03, 'a', 'b', 'c'})
+	answer := make([]byte, 3)
+	if _, err := io.ReadFull(client, answer); err != nil {
+		t.Fatal(err)
+	}
+	if string(answer) != "abc" {
+		t.Errorf("read %q, want abc", answer)
+	}
+}
+
+func TestFakeTLSWrongSecret(t *testing.T) {
+	clientSide, serverSide := net.Pipe()
+	defer clientSide.Close()
+	defer serverSide.Close()
+
+	go acceptFakeTLS(serverSide, []byte("client key"), []byte("another key"), "example.com")
+	if _, err := newFakeTLSConn(clientSide, []byte("client key"), "example.com"); !errors.Is(err, errFakeTLSDigest) {
+		t.Errorf("got %v, want the digest error", err)
+	}
+}
@@ -0,0 +1,115 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// HTTPConnConfig uses the mtproto http transport, every packet is POSTed to http://<Host>/api
+// and the response body carries messages the server had queued. The server holds the request open
+// when it contains http_wait, which is how incoming updates get delivered.
+type HTTPConnConfig struct {
+	Ctx    context.Context
+	Host   string
+	Client *http.Client // http.DefaultClient if nil, or a client using Proxy and Dialer when they are set
+	Proxy  *Proxy
+	Dialer Dialer
+}
+
+// httpConn is both connection and mode, http requests already have a length, so there is no framing
+type httpConn struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	url    string
+	client *http.Client
+
+	incoming chan []byte
+}
+
+var _ Mode = (*httpConn)(nil)
+
+func newHTTP(cfg HTTPConnConfig) (*httpConn, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("http host is empty")
+	}
+	client := cfg.Client
+	if client == nil && (cfg.Dialer != nil || cfg.Proxy != nil) {
+		client = &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, addr string) (net.Conn, error) {
+				return dial(ctx, cfg.Dialer, cfg.Proxy, addr, 0)
+			},
+		}}
+	} else if client == nil {
+		client = http.DefaultClient
+	}
+	ctx, cancel := context.WithCancel(cfg.Ctx)
+	return &httpConn{
+		ctx:      ctx,
+		cancel:   cancel,
+		url:      "http://" + cfg.Host + "/api",
+		client:   client,
+		incoming: make(chan []byte, 16),
+	}, nil
+}
+
+// WriteMsg posts the packet and queues the response body, it blocks until the server answers,
+// which is up to max_wait of http_wait
+func (c *httpConn) WriteMsg(msg []byte) error {
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodPost, c.url, bytes.NewReader(msg))
+	if err != nil {
+		return errors.Wrap(err, "creating request")
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "posting packet")
+	}
+	defer resp.Body.Close()
+
+	var body []byte
+	if resp.StatusCode == http.StatusOK {
+		if body, err = io.ReadAll(resp.Body); err != nil {
+			return errors.Wrap(err, "reading response")
+		}
+	} else {
+		// transport errors come as http statuses, pass them on like the tcp transports do, e.g. -404
+		body = binary.LittleEndian.AppendUint32(nil, uint32(-int32(resp.StatusCode)))
+	}
+	if len(body) == 0 {
+		return nil
+	}
+
+	select {
+	case c.incoming <- body:
+		return nil
+	case <-c.ctx.Done():
+		return c.ctx.Err()
+	}
+}
+
+func (c *httpConn) ReadMsg() ([]byte, error) {
+	select {
+	case msg := <-c.incoming:
+		return msg, nil
+	case <-c.ctx.Done():
+		return nil, context.Canceled
+	}
+}
+
+func (c *httpConn) Read([]byte) (int, error) {
+	return 0, errors.New("http transport reads whole packets only")
+}
+
+func (c *httpConn) Write([]byte) (int, error) {
+	return 0, errors.New("http transport writes whole packets only")
+}
+
+func (c *httpConn) Close() error {
+	c.cancel()
+	return nil
+}
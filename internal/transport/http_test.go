@@ -0,0 +1,73 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newHTTPConn(t *testing.T, handler http.HandlerFunc) *httpConn {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	c, err := newHTTP(HTTPConnConfig{Ctx: context.Background(), Host: strings.TrimPrefix(srv.URL, "http://")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestHTTPStatusIsTransportError(t *testing.T) {
+	c := newHTTPConn(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "auth key not found", http.StatusNotFound)
+	})
+	if err := c.WriteMsg([]byte{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	msg, err := c.ReadMsg()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msg) != 4 || int32(binary.LittleEndian.Uint32(msg)) != -404 {
+		t.Errorf("got %x, want the transport error -404", msg)
+	}
+}
+
+func TestHTTPHeldRequest(t *testing.T) {
+	pushed := make(chan []byte)
+	c := newHTTPConn(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || r.URL.Path != "/api" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		if string(body) == "http_wait" {
+			// held until the server has something to send
+			w.Write(<-pushed)
+		}
+	})
+
+	// requests answered with an empty body deliver nothing
+	if err := c.WriteMsg([]byte("ack")); err != nil {
+		t.Fatal(err)
+	}
+	waited := make(chan error, 1)
+	go func() { waited <- c.WriteMsg([]byte("http_wait")) }()
+	pushed <- []byte("update")
+	if err := <-waited; err != nil {
+		t.Fatal(err)
+	}
+	msg, err := c.ReadMsg()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(msg, []byte("update")) {
+		t.Errorf("read %q, want the update", msg)
+	}
+}
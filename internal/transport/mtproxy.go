@@ -0,0 +1,50 @@
+package transport
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const mtproxyKeyLen = 16
+
+// MTProxySecret is a parsed MTProxy secret
+type MTProxySecret struct {
+	Key    []byte // 16 bytes mixed into the obfuscation keys
+	Padded bool   // dd secret, the proxy expects padded intermediate mode
+	Domain string // ee secret, the connection is disguised as TLS to this domain
+}
+
+// ParseMTProxySecret parses a hex or base64 encoded secret, in one of three formats:
+// plain 16 bytes, dd + 16 bytes (padded) and ee + 16 bytes + domain (fake-TLS)
+func ParseMTProxySecret(secret string) (*MTProxySecret, error) {
+	raw, err := decodeMTProxySecret(strings.TrimSpace(secret))
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(raw) == mtproxyKeyLen:
+		return &MTProxySecret{Key: raw}, nil
+	case len(raw) == mtproxyKeyLen+1 && raw[0] == 0xdd:
+		return &MTProxySecret{Key: raw[1:], Padded: true}, nil
+	case len(raw) > mtproxyKeyLen+1 && raw[0] == 0xee:
+		return &MTProxySecret{Key: raw[1 : mtproxyKeyLen+1], Padded: true, Domain: string(raw[mtproxyKeyLen+1:])}, nil
+	default:
+		return nil, errors.New("invalid mtproxy secret")
+	}
+}
+
+func decodeMTProxySecret(secret string) ([]byte, error) {
+	if raw, err := hex.DecodeString(secret); err == nil {
+		return raw, nil
+	}
+	for _, enc := range []*base64.Encoding{base64.RawURLEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.StdEncoding} {
+		if raw, err := enc.DecodeString(secret); err == nil {
+			return raw, nil
+		}
+	}
+	return nil, errors.New("mtproxy secret is neither hex nor base64")
+}
@@ -0,0 +1,47 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+)
+
+func TestParseMTProxySecret(t *testing.T) {
+	key := bytes.Repeat([]byte{0xab}, 16)
+	hexKey := hex.EncodeToString(key)
+	eeSecret := append(append([]byte{0xee}, key...), "example.com"...)
+
+	for _, tc := range []struct {
+		name   string
+		secret string
+		want   *MTProxySecret
+	}{
+		{"plain", hexKey, &MTProxySecret{Key: key}},
+		{"dd", "dd" + hexKey, &MTProxySecret{Key: key, Padded: true}},
+		{"ee", hex.EncodeToString(eeSecret), &MTProxySecret{Key: key, Padded: true, Domain: "example.com"}},
+		{"ee base64", base64.RawURLEncoding.EncodeToString(eeSecret), &MTProxySecret{Key: key, Padded: true, Domain: "example.com"}},
+		{"ee padded base64", base64.StdEncoding.EncodeToString(eeSecret), &MTProxySecret{Key: key, Padded: true, Domain: "example.com"}},
+		{"surrounding spaces", " dd" + hexKey + "\n", &MTProxySecret{Key: key, Padded: true}},
+		{"short", hexKey[:30], nil},
+		{"dd with a domain", "dd" + hexKey + "00", nil},
+		{"ee without a domain", "ee" + hexKey, nil},
+		{"unknown prefix", "aa" + hexKey + "00", nil},
+		{"not encoded", "not a secret!", nil},
+	} {
+		got, err := ParseMTProxySecret(tc.secret)
+		if tc.want == nil {
+			if err == nil {
+				t.Errorf("%s: parsed %+v, want an error", tc.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if !bytes.Equal(got.Key, tc.want.Key) || got.Padded != tc.want.Padded || got.Domain != tc.want.Domain {
+			t.Errorf("%s: parsed %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
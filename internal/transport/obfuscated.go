@@ -0,0 +1,142 @@
+package transport
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Obfuscation enables the obfuscated2 protocol, which makes mtproto traffic look like random bytes,
+// so it passes DPI which blocks plain mtproto
+// https://core.telegram.org/mtproto/mtproto-transports#transport-obfuscation
+type Obfuscation struct {
+	// Secret is mixed into the keys, only MTProxy connections use it
+	Secret []byte
+	// DC is put into the init header, proxies route the connection by it
+	DC int16
+	// FakeTLS is the domain of an ee MTProxy secret, the connection is disguised as TLS to it
+	FakeTLS string
+}
+
+const obfuscatedHeaderLen = 64
+
+// header starts which must not appear in the init header, cause the server would take the
+// connection for http, tls or a not obfuscated transport
+var reservedHeaderStarts = [][]byte{
+	[]byte("HEAD"),
+	[]byte("POST"),
+	[]byte("GET "),
+	[]byte("OPTI"),
+	{0x16, 0x03, 0x01, 0x02},
+	{0xdd, 0xdd, 0xdd, 0xdd},
+	{0xee, 0xee, 0xee, 0xee},
+}
+
+type obfuscatedConn struct {
+	conn Conn
+
+	writeMutex sync.Mutex
+	encryptor  cipher.Stream
+	decryptor  cipher.Stream
+}
+
+// newObfuscatedConn sends the init header with the protocol tag of the mode and returns a connection
+// which encrypts and decrypts everything passing through it with AES-CTR
+func newObfuscatedConn(conn Conn, tag []byte, obf *Obfuscation) (Conn, error) {
+	header, err := obfuscatedHeader()
+	if err != nil {
+		return nil, err
+	}
+	copy(header[56:60], tag)
+	binary.LittleEndian.PutUint16(header[60:62], uint16(obf.DC))
+
+	reversed := make([]byte, 48)
+	for i := range reversed {
+		reversed[i] = header[55-i]
+	}
+
+	encryptor, err := obfuscationCipher(header[8:40], header[40:56], obf.Secret)
+	if err != nil {
+		return nil, err
+	}
+	decryptor, err := obfuscationCipher(reversed[:32], reversed[32:48], obf.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	// only the tail of the encrypted header is sent, the beginning stays random
+	encrypted := make([]byte, obfuscatedHeaderLen)
+	encryptor.XORKeyStream(encrypted, header)
+	copy(header[56:], encrypted[56:])
+	if _, err := conn.Write(header); err != nil {
+		return nil, errors.Wrap(err, "sending obfuscated header")
+	}
+
+	return &obfuscatedConn{conn: conn, encryptor: encryptor, decryptor: decryptor}, nil
+}
+
+// obfuscatedHeader returns random init header which can't be confused with another protocol
+func obfuscatedHeader() ([]byte, error) {
+	header := make([]byte, obfuscatedHeaderLen)
+	for {
+		if _, err := rand.Read(header); err != nil {
+			return nil, errors.Wrap(err, "generating obfuscated header")
+		}
+		if !reservedHeader(header) {
+			return header, nil
+		}
+	}
+}
+
+// reservedHeader reports whether the server would take the init header for another protocol:
+// abridged starts with 0xef, full with a length followed by seqno 0
+func reservedHeader(header []byte) bool {
+	if header[0] == 0xef || bytes.Equal(header[4:8], []byte{0, 0, 0, 0}) {
+		return true
+	}
+	for _, start := range reservedHeaderStarts {
+		if bytes.Equal(header[:4], start) {
+			return true
+		}
+	}
+	return false
+}
+
+func obfuscationCipher(key, iv, secret []byte) (cipher.Stream, error) {
+	if len(secret) > 0 {
+		sum := sha256.Sum256(append(append([]byte{}, key...), secret...))
+		key = sum[:]
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating obfuscation cipher")
+	}
+	return cipher.NewCTR(block, iv), nil
+}
+
+func (c *obfuscatedConn) Write(b []byte) (int, error) {
+	// keystream position must follow the order of bytes on the wire
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	encrypted := make([]byte, len(b))
+	c.encryptor.XORKeyStream(encrypted, b)
+	return c.conn.Write(encrypted)
+}
+
+func (c *obfuscatedConn) Read(b []byte) (int, error) {
+	n, err := c.conn.Read(b)
+	if n > 0 {
+		c.decryptor.XORKeyStream(b[:n], b[:n])
+	}
+	return n, err
+}
+
+func (c *obfuscatedConn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,113 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestReservedHeader(t *testing.T) {
+	header := func(start []byte, second []byte) []byte {
+		h := bytes.Repeat([]byte{0x42}, obfuscatedHeaderLen)
+		copy(h, start)
+		copy(h[4:], second)
+		return h
+	}
+	for _, tc := range []struct {
+		name   string
+		header []byte
+		want   bool
+	}{
+		{"random", header(nil, nil), false},
+		{"abridged", header([]byte{0xef}, nil), true},
+		{"intermediate", header([]byte{0xee, 0xee, 0xee, 0xee}, nil), true},
+		{"padded intermediate", header([]byte{0xdd, 0xdd, 0xdd, 0xdd}, nil), true},
+		{"tls", header([]byte{0x16, 0x03, 0x01, 0x02}, nil), true},
+		{"http", header([]byte("POST"), nil), true},
+		{"http get", header([]byte("GET "), nil), true},
+		{"full", header(nil, []byte{0, 0, 0, 0}), true},
+		{"partly like intermediate", header([]byte{0xee, 0xee, 0xee, 0x42}, nil), false},
+		{"zero byte in the second word", header(nil, []byte{0, 0, 0, 1}), false},
+	} {
+		if got := reservedHeader(tc.header); got != tc.want {
+			t.Errorf("%s: reserved is %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+// acceptObfuscated is the server side of the init header, it returns the tag, the dc and the connection
+// which decrypts what the client sends and encrypts the answers with the reversed key and iv
+func acceptObfuscated(conn net.Conn, secret []byte) ([]byte, int16, Conn, error) {
+	header := make([]byte, obfuscatedHeaderLen)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, 0, nil, err
+	}
+	reversed := make([]byte, 48)
+	for i := range reversed {
+		reversed[i] = header[55-i]
+	}
+	decryptor, err := obfuscationCipher(header[8:40], header[40:56], secret)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	encryptor, err := obfuscationCipher(reversed[:32], reversed[32:48], secret)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	decrypted := make([]byte, obfuscatedHeaderLen)
+	decryptor.XORKeyStream(decrypted, header)
+	// the server's streams are the client's ones swapped
+	return decrypted[56:60], int16(binary.LittleEndian.Uint16(decrypted[60:62])), &obfuscatedConn{conn: conn, encryptor: encryptor, decryptor: decryptor}, nil
+}
+
+func TestObfuscatedConn(t *testing.T) {
+	for _, secret := range [][]byte{nil, bytes.Repeat([]byte{0x11}, 16)} {
+		clientSide, serverSide := net.Pipe()
+		tag := []byte{0xdd, 0xdd, 0xdd, 0xdd}
+
+		type accepted struct {
+			tag    []byte
+			dc     int16
+			server Conn
+			err    error
+		}
+		done := make(chan accepted, 1)
+		go func() {
+			tag, dc, server, err := acceptObfuscated(serverSide, secret)
+			done <- accepted{tag, dc, server, err}
+		}()
+		client, err := newObfuscatedConn(clientSide, tag, &Obfuscation{Secret: secret, DC: -2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		a := <-done
+		if a.err != nil {
+			t.Fatal(a.err)
+		}
+		if !bytes.Equal(a.tag, tag) || a.dc != -2 {
+			t.Fatalf("secret %x: the server got tag %x and dc %d", secret, a.tag, a.dc)
+		}
+
+		for _, tc := range []struct {
+			from, to Conn
+			msg      string
+		}{
+			{client, a.server, "request"},
+			{a.server, client, "response"},
+			{client, a.server, "second request"},
+		} {
+			go tc.from.Write([]byte(tc.msg))
+			got := make([]byte, len(tc.msg))
+			if _, err := io.ReadFull(tc.to, got); err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.msg {
+				t.Errorf("secret %x: got %q, want %q", secret, got, tc.msg)
+			}
+		}
+		client.Close()
+		a.server.Close()
+	}
+}
@@ -0,0 +1,262 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Dialer opens the raw connection, to a proxy if one is set or to the data center otherwise,
+// it has the signature of net.Dialer.DialContext
+type Dialer func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// ProxyType is the protocol spoken to a proxy
+type ProxyType uint8
+
+const (
+	ProxySocks5 ProxyType = iota // the default, username/password auth if Username is set
+	ProxySocks4                  // socks4a, Username is sent as the user id
+	ProxyHTTP                    // http CONNECT, basic auth if Username is set
+)
+
+func (t ProxyType) String() string {
+	switch t {
+	case ProxySocks4:
+		return "socks4"
+	case ProxyHTTP:
+		return "http"
+	default:
+		return "socks5"
+	}
+}
+
+// Proxy is a socks or http proxy the connection is tunneled through
+type Proxy struct {
+	Type     ProxyType
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+func (p *Proxy) addr() string {
+	return net.JoinHostPort(p.Host, strconv.Itoa(p.Port))
+}
+
+// dial connects to addr, through the proxy when it's set, timeout limits the proxy handshake too
+func dial(ctx context.Context, dialer Dialer, proxy *Proxy, addr string, timeout time.Duration) (net.Conn, error) {
+	if dialer == nil {
+		dialer = (&net.Dialer{Timeout: timeout}).DialContext
+	}
+	if proxy == nil || proxy.Host == "" {
+		return dialer(ctx, "tcp", addr)
+	}
+	return DialProxy(ctx, dialer, proxy, addr, timeout)
+}
+
+// DialProxy connects to the proxy with dialer and asks it to connect to addr
+func DialProxy(ctx context.Context, dialer Dialer, p *Proxy, addr string, timeout time.Duration) (net.Conn, error) {
+	conn, err := dialer(ctx, "tcp", p.addr())
+	if err != nil {
+		return nil, errors.Wrap(err, "dialing "+p.Type.String()+" proxy")
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	switch p.Type {
+	case ProxySocks5:
+		err = socks5Connect(conn, p, addr)
+	case ProxySocks4:
+		err = socks4Connect(conn, p, addr)
+	case ProxyHTTP:
+		conn, err = httpConnect(conn, p, addr)
+	default:
+		err = errors.Errorf("unsupported proxy type %d", p.Type)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, p.Type.String()+" proxy")
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+func splitHostPort(addr string) (string, int, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, err
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return "", 0, errors.Wrap(err, "parsing port")
+	}
+	return host, p, nil
+}
+
+// https://www.rfc-editor.org/rfc/rfc1928, https://www.rfc-editor.org/rfc/rfc1929
+func socks5Connect(conn net.Conn, p *Proxy, addr string) error {
+	method := byte(0x00)
+	if p.Username != "" {
+		method = 0x02
+	}
+	if _, err := conn.Write([]byte{5, 1, method}); err != nil {
+		return err
+	}
+	buf := make([]byte, 2)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return err
+	}
+	if buf[0] != 5 {
+		return errors.New("socks version not supported")
+	}
+	switch buf[1] {
+	case 0x00:
+	case 0x02:
+		if len(p.Username) > 255 || len(p.Password) > 255 {
+			return errors.New("username or password is too long")
+		}
+		auth := append([]byte{1, byte(len(p.Username))}, p.Username...)
+		auth = append(append(auth, byte(len(p.Password))), p.Password...)
+		if _, err := conn.Write(auth); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return err
+		}
+		if buf[1] != 0 {
+			return errors.New("authentication failed")
+		}
+	case 0xff:
+		return errors.New("no acceptable authentication method")
+	default:
+		return errors.Errorf("unsupported authentication method %d", buf[1])
+	}
+
+	host, port, err := splitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	req := []byte{5, 1, 0}
+	if ip := net.ParseIP(host); ip == nil {
+		if len(host) > 255 {
+			return errors.New("host name is too long")
+		}
+		req = append(append(req, 3, byte(len(host))), host...)
+	} else if ip4 := ip.To4(); ip4 != nil {
+		req = append(append(req, 1), ip4...)
+	} else {
+		req = append(append(req, 4), ip.To16()...)
+	}
+	req = append(req, byte(port>>8), byte(port))
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	if header[1] != 0 {
+		return errors.Errorf("connect failed with code %d", header[1])
+	}
+	// skip the bound address and port
+	var size int
+	switch header[3] {
+	case 1:
+		size = net.IPv4len
+	case 4:
+		size = net.IPv6len
+	case 3:
+		if _, err := io.ReadFull(conn, header[:1]); err != nil {
+			return err
+		}
+		size = int(header[0])
+	default:
+		return errors.Errorf("unsupported address type %d", header[3])
+	}
+	_, err = io.ReadFull(conn, make([]byte, size+2))
+	return err
+}
+
+// socks4a, host names are resolved by the proxy
+func socks4Connect(conn net.Conn, p *Proxy, addr string) error {
+	host, port, err := splitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	ip := net.IPv4(0, 0, 0, 1).To4()
+	if parsed := net.ParseIP(host); parsed != nil {
+		if ip = parsed.To4(); ip == nil {
+			return errors.New("socks4 supports ipv4 addresses only")
+		}
+		host = ""
+	}
+	req := append([]byte{4, 1, byte(port >> 8), byte(port)}, ip...)
+	req = append(append(req, p.Username...), 0)
+	if host != "" {
+		req = append(append(req, host...), 0)
+	}
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	resp := make([]byte, 8)
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return err
+	}
+	if resp[1] != 90 {
+		return errors.Errorf("connect failed with code %d", resp[1])
+	}
+	return nil
+}
+
+func httpConnect(conn net.Conn, p *Proxy, addr string) (net.Conn, error) {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	if p.Username != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(p.Username + ":" + p.Password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		return conn, err
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return conn, err
+	}
+	// the body of a CONNECT response lasts until the tunnel closes, so it's never read
+	if resp.StatusCode != http.StatusOK {
+		return conn, errors.Errorf("connect failed: %s", resp.Status)
+	}
+	if reader.Buffered() > 0 {
+		// the tunnel is already sending data, don't lose what the reader took
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
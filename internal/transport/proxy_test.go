@@ -0,0 +1,245 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// proxyHandshake is the proxy side of a protocol, it returns the address the client asked for
+type proxyHandshake func(conn net.Conn, r *bufio.Reader) (string, error)
+
+// newProxy accepts one connection on a loopback port, runs handshake and echoes what comes through
+// the tunnel, the address the client asked for is sent to the returned channel
+func newProxy(t *testing.T, handshake proxyHandshake) (*Proxy, chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	targets := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		target, err := handshake(conn, r)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		targets <- target
+		io.Copy(conn, r)
+	}()
+	host, port, _ := splitHostPort(l.Addr().String())
+	return &Proxy{Host: host, Port: port}, targets
+}
+
+// https://www.rfc-editor.org/rfc/rfc1928, https://www.rfc-editor.org/rfc/rfc1929
+func socks5Handshake(username, password string) proxyHandshake {
+	return func(conn net.Conn, r *bufio.Reader) (string, error) {
+		greeting := make([]byte, 3)
+		if _, err := io.ReadFull(r, greeting); err != nil {
+			return "", err
+		}
+		if greeting[0] != 5 || greeting[2] != 0x02 {
+			return "", errors.Errorf("greeting %v doesn't offer username/password auth", greeting)
+		}
+		conn.Write([]byte{5, 0x02})
+		auth := make([]byte, 2)
+		if _, err := io.ReadFull(r, auth); err != nil {
+			return "", err
+		}
+		user := make([]byte, auth[1])
+		io.ReadFull(r, user)
+		size, _ := r.ReadByte()
+		pass := make([]byte, size)
+		io.ReadFull(r, pass)
+		if string(user) != username || string(pass) != password {
+			conn.Write([]byte{1, 1})
+			return "", nil
+		}
+		conn.Write([]byte{1, 0})
+
+		req := make([]byte, 5)
+		if _, err := io.ReadFull(r, req); err != nil {
+			return "", err
+		}
+		if req[1] != 1 || req[3] != 3 {
+			return "", errors.Errorf("request %v isn't a connect to a host name", req)
+		}
+		host := make([]byte, req[4])
+		port := make([]byte, 2)
+		io.ReadFull(r, host)
+		io.ReadFull(r, port)
+		// succeeded, bound to 0.0.0.0:0
+		conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+		return net.JoinHostPort(string(host), strconv.Itoa(int(binary.BigEndian.Uint16(port)))), nil
+	}
+}
+
+// socks4aHandshake accepts the connection when the user id is userID
+func socks4aHandshake(userID string) proxyHandshake {
+	return func(conn net.Conn, r *bufio.Reader) (string, error) {
+		req := make([]byte, 8)
+		if _, err := io.ReadFull(r, req); err != nil {
+			return "", err
+		}
+		if req[0] != 4 || req[1] != 1 {
+			return "", errors.Errorf("request %v isn't a socks4 connect", req)
+		}
+		user, err := r.ReadString(0)
+		if err != nil {
+			return "", err
+		}
+		host := net.IP(req[4:8]).String()
+		if req[4] == 0 && req[5] == 0 && req[6] == 0 && req[7] != 0 {
+			// socks4a, the host name follows
+			if host, err = r.ReadString(0); err != nil {
+				return "", err
+			}
+			host = host[:len(host)-1]
+		}
+		if user[:len(user)-1] != userID {
+			conn.Write([]byte{0, 91, 0, 0, 0, 0, 0, 0})
+			return "", nil
+		}
+		conn.Write([]byte{0, 90, 0, 0, 0, 0, 0, 0})
+		return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(req[2:])))), nil
+	}
+}
+
+// httpConnectHandshake accepts CONNECT requests with the credentials, greeting is sent through the
+// tunnel right after the response, in the same write
+func httpConnectHandshake(username, password, greeting string) proxyHandshake {
+	return func(conn net.Conn, r *bufio.Reader) (string, error) {
+		req, err := http.ReadRequest(r)
+		if err != nil {
+			return "", err
+		}
+		if req.Method != http.MethodConnect {
+			return "", errors.Errorf("got a %s request", req.Method)
+		}
+		want := "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+		if req.Header.Get("Proxy-Authorization") != want {
+			conn.Write([]byte("HTTP/1.1 407 Proxy Authentication Required\r\n\r\n"))
+			return "", nil
+		}
+		conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n" + greeting))
+		return req.Host, nil
+	}
+}
+
+func dialProxy(p *Proxy, dialer Dialer) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return dial(ctx, dialer, p, "dc.example.com:443", 5*time.Second)
+}
+
+// roundTrip checks that what's written to conn comes back through the tunnel
+func roundTrip(t *testing.T, conn net.Conn, greeting string) {
+	t.Helper()
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, len(greeting)+4)
+	if _, err := io.ReadFull(conn, got); err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != greeting+"ping" {
+		t.Errorf("read %q through the tunnel, want %q", got, greeting+"ping")
+	}
+}
+
+func TestProxies(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		typ       ProxyType
+		handshake proxyHandshake
+		greeting  string
+	}{
+		{"socks5", ProxySocks5, socks5Handshake("user", "secret"), ""},
+		{"socks4a", ProxySocks4, socks4aHandshake("user"), ""},
+		{"http", ProxyHTTP, httpConnectHandshake("user", "secret", ""), ""},
+		{"http with data after the response", ProxyHTTP, httpConnectHandshake("user", "secret", "hello"), "hello"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			proxy, targets := newProxy(t, tc.handshake)
+			proxy.Type, proxy.Username, proxy.Password = tc.typ, "user", "secret"
+			conn, err := dialProxy(proxy, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			if target := <-targets; target != "dc.example.com:443" {
+				t.Errorf("the proxy was asked for %s", target)
+			}
+			roundTrip(t, conn, tc.greeting)
+		})
+	}
+}
+
+func TestProxyRejectsCredentials(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		typ       ProxyType
+		handshake proxyHandshake
+	}{
+		{"socks5", ProxySocks5, socks5Handshake("user", "secret")},
+		{"socks4a", ProxySocks4, socks4aHandshake("user")},
+		{"http", ProxyHTTP, httpConnectHandshake("user", "secret", "")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			proxy, _ := newProxy(t, tc.handshake)
+			proxy.Type, proxy.Username, proxy.Password = tc.typ, "someone else", "wrong"
+			if conn, err := dialProxy(proxy, nil); err == nil {
+				conn.Close()
+				t.Error("connected with wrong credentials")
+			}
+		})
+	}
+}
+
+func TestDialer(t *testing.T) {
+	proxy, targets := newProxy(t, socks5Handshake("user", "secret"))
+	proxy.Username, proxy.Password = "user", "secret"
+	var dialed []string
+	dialer := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		if addr != proxy.addr() {
+			return nil, errors.New("only the proxy is reachable")
+		}
+		return (&net.Dialer{}).DialContext(ctx, network, addr)
+	}
+
+	// the proxy is dialed with the dialer, the data center through the proxy
+	conn, err := dialProxy(proxy, dialer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	<-targets
+	if len(dialed) != 1 || dialed[0] != proxy.addr() {
+		t.Errorf("dialed %v, want the proxy only", dialed)
+	}
+
+	// without a proxy the dialer gets the address of the data center
+	dialed = nil
+	if _, err := dialProxy(nil, dialer); err == nil {
+		t.Error("dc.example.com was reached")
+	}
+	if len(dialed) != 1 || dialed[0] != "dc.example.com:443" {
+		t.Errorf("dialed %v, want the data center", dialed)
+	}
+}
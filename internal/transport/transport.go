@@ -25,7 +25,8 @@ type transport struct {
 	m    messages.MessageInformator
 }
 
-func NewTransport(m messages.MessageInformator, conn ConnConfig, modeVariant mode.Variant) (Transport, error) {
+// NewTransport connects and announces the mode, obf enables the obfuscated2 protocol when not nil
+func NewTransport(m messages.MessageInformator, conn ConnConfig, modeVariant mode.Variant, obf *Obfuscation) (Transport, error) {
 	t := &transport{
 		m: m,
 	}
@@ -34,6 +35,18 @@ func NewTransport(m messages.MessageInformator, conn ConnConfig, modeVariant mod
 	switch cfg := conn.(type) {
 	case TCPConnConfig:
 		t.conn, err = NewTCP(cfg)
+	case WebSocketConnConfig:
+		t.conn, err = NewWebSocket(cfg)
+	case HTTPConnConfig:
+		if obf != nil {
+			return nil, errors.New("http transport can't be obfuscated")
+		}
+		c, err := newHTTP(cfg)
+		if err != nil {
+			return nil, errors.Wrap(err, "setup connection")
+		}
+		t.conn, t.mode = c, c
+		return t, nil
 	default:
 		return nil, fmt.Errorf("unsupported connection type %v", reflect.TypeOf(conn).String())
 	}
@@ -41,7 +54,24 @@ func NewTransport(m messages.MessageInformator, conn ConnConfig, modeVariant mod
 		return nil, errors.Wrap(err, "setup connection")
 	}
 
-	t.mode, err = mode.New(modeVariant, t.conn)
+	if obf != nil {
+		var tag []byte
+		if tag, err = mode.ObfuscationTag(modeVariant); err != nil {
+			t.conn.Close()
+			return nil, errors.Wrap(err, modeVariant.String()+" mode can't be obfuscated")
+		}
+		if obf.FakeTLS != "" {
+			if t.conn, err = newFakeTLSConn(t.conn, obf.Secret, obf.FakeTLS); err != nil {
+				return nil, errors.Wrap(err, "setup fake tls")
+			}
+		}
+		if t.conn, err = newObfuscatedConn(t.conn, tag, obf); err != nil {
+			return nil, errors.Wrap(err, "setup obfuscation")
+		}
+		t.mode, err = mode.NewAnnounced(modeVariant, t.conn)
+	} else {
+		t.mode, err = mode.New(modeVariant, t.conn)
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "setup mode")
 	}
@@ -0,0 +1,248 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WebSocketConnConfig connects to the websocket endpoint of a data center, e.g. wss://venus.web.telegram.org/apiws,
+// telegram accepts only obfuscated traffic there
+type WebSocketConnConfig struct {
+	Ctx     context.Context
+	URL     string
+	Timeout time.Duration
+	Proxy   *Proxy
+	Dialer  Dialer
+}
+
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xa
+
+	wsAcceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+)
+
+// wsConn is a minimal websocket client, binary messages are exposed as a byte stream
+type wsConn struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	timeout time.Duration
+
+	writeMutex sync.Mutex
+	readBuf    []byte
+}
+
+func NewWebSocket(cfg WebSocketConnConfig) (Conn, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing websocket url")
+	}
+	secure := u.Scheme == "wss"
+	host := u.Host
+	if u.Port() == "" {
+		if secure {
+			host = net.JoinHostPort(u.Hostname(), "443")
+		} else {
+			host = net.JoinHostPort(u.Hostname(), "80")
+		}
+	}
+
+	conn, err := dial(cfg.Ctx, cfg.Dialer, cfg.Proxy, host, cfg.Timeout)
+	if err != nil {
+		return nil, errors.Wrap(err, "dialing websocket")
+	}
+	if secure {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.HandshakeContext(cfg.Ctx); err != nil {
+			conn.Close()
+			return nil, errors.Wrap(err, "tls handshake")
+		}
+		conn = tlsConn
+	}
+
+	ws := &wsConn{conn: conn, reader: bufio.NewReader(conn), timeout: cfg.Timeout}
+	if err := ws.handshake(u); err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "websocket handshake")
+	}
+	return ws, nil
+}
+
+func (c *wsConn) handshake(u *url.URL) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	req := &http.Request{
+		Method: http.MethodGet,
+		URL:    u,
+		Host:   u.Host,
+		Header: http.Header{
+			"Upgrade":                {"websocket"},
+			"Connection":             {"Upgrade"},
+			"Sec-WebSocket-Key":      {key},
+			"Sec-WebSocket-Version":  {"13"},
+			"Sec-WebSocket-Protocol": {"binary"},
+		},
+	}
+	if err := req.Write(c.conn); err != nil {
+		return err
+	}
+
+	resp, err := http.ReadResponse(c.reader, req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return errors.Errorf("unexpected status %s", resp.Status)
+	}
+	sum := sha1.Sum([]byte(key + wsAcceptGUID))
+	if resp.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(sum[:]) {
+		return errors.New("invalid Sec-WebSocket-Accept")
+	}
+	return nil
+}
+
+func (c *wsConn) Write(b []byte) (int, error) {
+	if err := c.writeFrame(wsOpBinary, b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// writeFrame writes a single masked frame, clients must mask everything they send
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode, 0x80}
+	switch {
+	case len(payload) < 126:
+		header[1] |= byte(len(payload))
+	case len(payload) <= 0xffff:
+		header[1] |= 126
+		header = binary.BigEndian.AppendUint16(header, uint16(len(payload)))
+	default:
+		header[1] |= 127
+		header = binary.BigEndian.AppendUint64(header, uint64(len(payload)))
+	}
+	mask := make([]byte, 4)
+	if _, err := rand.Read(mask); err != nil {
+		return err
+	}
+	frame := append(header, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+func (c *wsConn) Read(b []byte) (int, error) {
+	for len(c.readBuf) == 0 {
+		if err := c.readFrame(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(b, c.readBuf)
+	c.readBuf = c.readBuf[n:]
+	return n, nil
+}
+
+// readFrame reads the next frame, data frames are appended to the read buffer
+func (c *wsConn) readFrame() error {
+	if c.timeout > 0 {
+		if err := c.conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+			return errors.Wrap(err, "setting read deadline")
+		}
+	}
+
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		return wsReadError(err)
+	}
+	opcode := header[0] & 0x0f
+	masked := header[1]&0x80 != 0
+	size := uint64(header[1] & 0x7f)
+	switch size {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err := io.ReadFull(c.reader, ext); err != nil {
+			return wsReadError(err)
+		}
+		size = uint64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		if _, err := io.ReadFull(c.reader, ext); err != nil {
+			return wsReadError(err)
+		}
+		size = binary.BigEndian.Uint64(ext)
+	}
+	var mask []byte
+	if masked {
+		mask = make([]byte, 4)
+		if _, err := io.ReadFull(c.reader, mask); err != nil {
+			return wsReadError(err)
+		}
+	}
+	if size > 16*1024*1024 {
+		return errors.Errorf("websocket frame too big: %d bytes", size)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return wsReadError(err)
+	}
+	for i := range payload {
+		if masked {
+			payload[i] ^= mask[i%4]
+		}
+	}
+
+	switch opcode {
+	case wsOpBinary, wsOpText, wsOpContinuation:
+		c.readBuf = append(c.readBuf, payload...)
+	case wsOpPing:
+		return c.writeFrame(wsOpPong, payload)
+	case wsOpPong:
+	case wsOpClose:
+		return io.EOF
+	default:
+		return errors.Errorf("unexpected websocket opcode %#x", opcode)
+	}
+	return nil
+}
+
+func wsReadError(err error) error {
+	if e, ok := err.(net.Error); ok && e.Timeout() {
+		return errors.Wrap(err, "required to reconnect!")
+	}
+	if err == io.ErrUnexpectedEOF {
+		return io.EOF
+	}
+	return err
+}
+
+func (c *wsConn) Close() error {
+	c.writeFrame(wsOpClose, nil)
+	return c.conn.Close()
+}
@@ -0,0 +1,193 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// wsFrame is a frame as the server sees it
+type wsFrame struct {
+	opcode  byte
+	masked  bool
+	length  byte // 7 bit length of the header, 126 and 127 announce 16 and 64 bit lengths
+	payload []byte
+}
+
+func readWSFrame(r io.Reader) (*wsFrame, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	f := &wsFrame{opcode: header[0] & 0x0f, masked: header[1]&0x80 != 0, length: header[1] & 0x7f}
+	size := uint64(f.length)
+	switch f.length {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err := io.ReadFull(r, ext); err != nil {
+			return nil, err
+		}
+		size = uint64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		if _, err := io.ReadFull(r, ext); err != nil {
+			return nil, err
+		}
+		size = binary.BigEndian.Uint64(ext)
+	}
+	mask := make([]byte, 4)
+	if f.masked {
+		if _, err := io.ReadFull(r, mask); err != nil {
+			return nil, err
+		}
+	}
+	f.payload = make([]byte, size)
+	if _, err := io.ReadFull(r, f.payload); err != nil {
+		return nil, err
+	}
+	for i := range f.payload {
+		f.payload[i] ^= mask[i%4]
+	}
+	return f, nil
+}
+
+// writeWSFrame writes an unmasked frame, like servers do
+func writeWSFrame(w io.Writer, opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode, 0}
+	switch {
+	case len(payload) < 126:
+		header[1] = byte(len(payload))
+	case len(payload) <= 0xffff:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(len(payload)))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(len(payload)))
+	}
+	_, err := w.Write(append(header, payload...))
+	return err
+}
+
+// newWSServer accepts websocket connections and passes them to serve, accept overrides the
+// Sec-WebSocket-Accept header when it's not empty
+func newWSServer(t *testing.T, accept string, serve func(conn net.Conn, r *bufio.Reader) error) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" || r.Header.Get("Sec-WebSocket-Version") != "13" {
+			http.Error(w, "not a websocket request", http.StatusBadRequest)
+			return
+		}
+		if accept == "" {
+			sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + wsAcceptGUID))
+			accept = base64.StdEncoding.EncodeToString(sum[:])
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+		rw.WriteString("Sec-WebSocket-Accept: " + accept + "\r\n\r\n")
+		if err := rw.Flush(); err != nil {
+			t.Error(err)
+			return
+		}
+		if err := serve(conn, rw.Reader); err != nil {
+			t.Error(err)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return "ws://" + srv.Listener.Addr().String() + "/apiws"
+}
+
+func dialWS(url string) (Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return NewWebSocket(WebSocketConnConfig{Ctx: ctx, URL: url, Timeout: 5 * time.Second})
+}
+
+func TestWebSocketFrames(t *testing.T) {
+	sizes := []struct {
+		size   int
+		length byte
+	}{{10, 10}, {300, 126}, {70000, 127}}
+	message := func(size int) []byte {
+		return bytes.Repeat([]byte{byte(size)}, size)
+	}
+
+	url := newWSServer(t, "", func(conn net.Conn, r *bufio.Reader) error {
+		for _, s := range sizes {
+			f, err := readWSFrame(r)
+			if err != nil {
+				return err
+			}
+			if !f.masked || f.opcode != wsOpBinary || f.length != s.length {
+				return errors.Errorf("got frame %#x masked %v with length %d, want a masked binary frame with length %d", f.opcode, f.masked, f.length, s.length)
+			}
+			if !bytes.Equal(f.payload, message(s.size)) {
+				return errors.Errorf("got %d bytes, want %d", len(f.payload), s.size)
+			}
+		}
+
+		// the client answers pings by itself, while reading
+		if err := writeWSFrame(conn, wsOpPing, []byte("ping")); err != nil {
+			return err
+		}
+		for _, s := range sizes {
+			if err := writeWSFrame(conn, wsOpBinary, message(s.size)); err != nil {
+				return err
+			}
+		}
+		f, err := readWSFrame(r)
+		if err != nil {
+			return err
+		}
+		if f.opcode != wsOpPong || !f.masked || string(f.payload) != "ping" {
+			return errors.Errorf("got frame %#x with %q, want a masked pong", f.opcode, f.payload)
+		}
+		// close frame of the client
+		_, err = readWSFrame(r)
+		return err
+	})
+
+	conn, err := dialWS(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range sizes {
+		if _, err := conn.Write(message(s.size)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, s := range sizes {
+		got := make([]byte, s.size)
+		if _, err := io.ReadFull(conn, got); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, message(s.size)) {
+			t.Errorf("read a wrong message of %d bytes", s.size)
+		}
+	}
+	conn.Close()
+}
+
+func TestWebSocketAccept(t *testing.T) {
+	url := newWSServer(t, base64.StdEncoding.EncodeToString(make([]byte, 20)), func(net.Conn, *bufio.Reader) error {
+		return nil
+	})
+	if _, err := dialWS(url); err == nil || !strings.Contains(err.Error(), "Sec-WebSocket-Accept") {
+		t.Errorf("got %v, want the invalid Sec-WebSocket-Accept error", err)
+	}
+}
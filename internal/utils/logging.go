@@ -4,11 +4,15 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"sync/atomic"
 )
 
+// LogLevel is the severity of a log message
+type LogLevel int8
+
 const (
 	// DebugLevel is the lowest level of logging
-	DebugLevel = iota
+	DebugLevel LogLevel = iota
 	// InfoLevel is the second lowest level of logging
 	InfoLevel
 	// WarnLevel is the third highest level of logging
@@ -19,14 +23,8 @@ const (
 	NoLevel
 )
 
-// Logger is the logging struct.
-type Logger struct {
-	Level  int
-	Prefix string
-}
-
-func (l *Logger) Lev() string {
-	switch l.Level {
+func (l LogLevel) String() string {
+	switch l {
 	case DebugLevel:
 		return "debug"
 	case InfoLevel:
@@ -42,63 +40,152 @@ func (l *Logger) Lev() string {
 	}
 }
 
-// SetLevelString sets the level string
-func (l *Logger) SetLevel(level string) *Logger {
+// ParseLogLevel parses debug, info, warn, error or disabled, anything else is info
+func ParseLogLevel(level string) LogLevel {
 	switch level {
 	case "debug":
-		l.Level = DebugLevel
-	case "info":
-		l.Level = InfoLevel
+		return DebugLevel
 	case "warn":
-		l.Level = WarnLevel
+		return WarnLevel
 	case "error":
-		l.Level = ErrorLevel
+		return ErrorLevel
 	case "disabled":
-		l.Level = NoLevel
+		return NoLevel
 	default:
-		l.Level = InfoLevel
+		return InfoLevel
 	}
-	return l
 }
 
-// Log logs a message at the given level.
-func (l *Logger) Error(v ...any) {
-	if l.Level <= ErrorLevel {
-		log.Println(l.Prefix, "- <error> -", getVariable(v...))
-	}
+// LogHandler writes log messages, fields are key value pairs
+type LogHandler interface {
+	// Enabled reports whether messages of the level are written, so expensive ones can be skipped
+	Enabled(level LogLevel) bool
+	// Log writes a message with its fields, like "dc", 2, "msg_id", 7234...
+	Log(level LogLevel, msg string, fields ...any)
+}
+
+// ComponentKey is the field naming the part of the library which logged the message, e.g. mtproto or updates
+const ComponentKey = "component"
+
+// Logger passes messages to its handler along with its component and fields
+type Logger struct {
+	handler   LogHandler
+	component string
+	fields    []any
 }
 
-func (l *Logger) Warn(v ...any) {
-	if l.Level <= WarnLevel {
-		log.Println(l.Prefix, "- <warn> -", getVariable(v...))
+// NewLogger returns a logger of the component, writing to handler, or to a StdLogger at info level if nil
+func NewLogger(handler LogHandler, component string) *Logger {
+	if handler == nil {
+		handler = NewStdLogger(InfoLevel)
 	}
+	return &Logger{handler: handler, component: component}
+}
+
+// Handler returns the handler the logger writes to
+func (l *Logger) Handler() LogHandler {
+	return l.handler
+}
+
+// Named returns a logger of another component, with the same handler and fields
+func (l *Logger) Named(component string) *Logger {
+	return &Logger{handler: l.handler, component: component, fields: l.fields}
+}
+
+// With returns a logger adding the fields to every message, a value of type func() any is called for every
+// message, for values which change
+func (l *Logger) With(fields ...any) *Logger {
+	return &Logger{handler: l.handler, component: l.component, fields: append(l.fields[:len(l.fields):len(l.fields)], fields...)}
 }
 
-func (l *Logger) Info(v ...any) {
-	if l.Level <= InfoLevel {
-		log.Println(l.Prefix, "- <info> -", getVariable(v...))
+// SetLevel changes the level of the handler, if it has one which can be changed, like StdLogger
+func (l *Logger) SetLevel(level string) *Logger {
+	if h, ok := l.handler.(interface{ SetLevel(LogLevel) }); ok {
+		h.SetLevel(ParseLogLevel(level))
 	}
+	return l
 }
 
-func (l *Logger) Debug(v ...any) {
-	if l.Level <= DebugLevel {
-		log.Println(l.Prefix, "- <debug> -", getVariable(v...))
+// Lev returns the level of the handler, the lowest level it writes
+func (l *Logger) Lev() string {
+	for level := DebugLevel; level < NoLevel; level++ {
+		if l.handler.Enabled(level) {
+			return level.String()
+		}
 	}
+	return NoLevel.String()
+}
+
+func (l *Logger) Error(msg string, fields ...any) {
+	l.log(ErrorLevel, msg, fields)
+}
+
+func (l *Logger) Warn(msg string, fields ...any) {
+	l.log(WarnLevel, msg, fields)
 }
 
-// NewLogger returns a new Logger instance.
-func NewLogger(prefix string) *Logger {
-	return &Logger{
-		Prefix: prefix,
+func (l *Logger) Info(msg string, fields ...any) {
+	l.log(InfoLevel, msg, fields)
+}
+
+func (l *Logger) Debug(msg string, fields ...any) {
+	l.log(DebugLevel, msg, fields)
+}
+
+func (l *Logger) log(level LogLevel, msg string, fields []any) {
+	if !l.handler.Enabled(level) {
+		return
+	}
+	all := make([]any, 0, 2+len(l.fields)+len(fields))
+	if l.component != "" {
+		all = append(all, ComponentKey, l.component)
 	}
+	for _, field := range l.fields {
+		if value, ok := field.(func() any); ok {
+			field = value()
+		}
+		all = append(all, field)
+	}
+	l.handler.Log(level, msg, append(all, fields...)...)
+}
+
+// StdLogger writes messages with the standard log package, as "gogram - <component> - <level> - msg key=value"
+type StdLogger struct {
+	level atomic.Int32
+}
+
+func NewStdLogger(level LogLevel) *StdLogger {
+	l := &StdLogger{}
+	l.SetLevel(level)
+	return l
+}
+
+func (l *StdLogger) SetLevel(level LogLevel) {
+	l.level.Store(int32(level))
+}
+
+func (l *StdLogger) Enabled(level LogLevel) bool {
+	return level < NoLevel && level >= LogLevel(l.level.Load())
 }
 
-func getVariable(v ...any) string {
-	if len(v) == 0 {
-		return ""
+func (l *StdLogger) Log(level LogLevel, msg string, fields ...any) {
+	if !l.Enabled(level) {
+		return
 	}
-	if len(v) == 1 {
-		return fmt.Sprint(v[0])
+	prefix := "gogram"
+	var b strings.Builder
+	b.WriteString(msg)
+	for i := 0; i < len(fields); i += 2 {
+		key := fmt.Sprint(fields[i])
+		if i+1 == len(fields) {
+			fmt.Fprintf(&b, " %v", fields[i])
+			break
+		}
+		if key == ComponentKey {
+			prefix += " - " + fmt.Sprint(fields[i+1])
+			continue
+		}
+		fmt.Fprintf(&b, " %s=%v", key, fields[i+1])
 	}
-	return strings.Trim(fmt.Sprint(v...), "[]")
+	log.Println(prefix, "- <"+level.String()+"> -", b.String())
 }
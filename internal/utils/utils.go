@@ -31,14 +31,17 @@ func (*PingParams) CRC() uint32 {
 	return 0x7abe77ec
 }
 
-func GenerateMessageId(prevID int64) int64 {
+// GenerateMessageId returns an id for a message sent now, offset is added to the local clock to match the
+// server one, the id is always greater than prevID
+func GenerateMessageId(prevID int64, offset time.Duration) int64 {
 	const billion = 1000 * 1000 * 1000
-	unixnano := time.Now().UnixNano()
+	unixnano := time.Now().Add(offset).UnixNano()
 	seconds := unixnano / billion
 	nanoseconds := unixnano % billion
 	newID := (seconds << 32) | (nanoseconds & -4)
 	if newID <= prevID {
-		return GenerateMessageId(prevID)
+		// the offset went back, or ids are made faster than the clock ticks
+		return prevID + 4
 	}
 	return newID
 }
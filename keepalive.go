@@ -0,0 +1,165 @@
+package gogram
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/jwillp/gogram/internal/encoding/tl"
+	"github.com/jwillp/gogram/internal/mtproto/objects"
+	"github.com/pkg/errors"
+)
+
+// pings with ping_delay_disconnect keep the connection open, and a missing pong tells it silently died
+// https://core.telegram.org/mtproto/service_messages#deferred-connection-closure-ping
+
+const (
+	pingInterval        = 60 * time.Second
+	pingDisconnectDelay = 15 * time.Second // after the next ping is due the server closes the connection
+	pongTimeout         = 20 * time.Second // without a pong in time the connection is considered dead
+)
+
+// ConnectionState is the state of the connection to the data center
+type ConnectionState int32
+
+const (
+	StateDisconnected ConnectionState = iota
+	StateConnecting
+	StateConnected
+	StateReconnecting
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateReconnecting:
+		return "reconnecting"
+	default:
+		return "disconnected"
+	}
+}
+
+// State returns the current state of the connection
+func (m *MTProto) State() ConnectionState {
+	return ConnectionState(m.state.Load())
+}
+
+// OnStateChange sets a function called whenever the connection state changes,
+// it's called from the connection goroutines, so it must not block
+func (m *MTProto) OnStateChange(handler func(ConnectionState)) {
+	m.stateHandler.Store(&handler)
+}
+
+func (m *MTProto) setState(state ConnectionState) {
+	if ConnectionState(m.state.Swap(int32(state))) == state {
+		return
+	}
+	m.Logger.Debug("connection state changed", "state", state)
+	if handler := m.stateHandler.Load(); handler != nil {
+		(*handler)(state)
+	}
+}
+
+// RTT returns the round trip time measured by the latest ping, zero before the first pong
+func (m *MTProto) RTT() time.Duration {
+	return time.Duration(m.rtt.Load())
+}
+
+// Ping sends a ping and returns the round trip time, zero if no pong came back in time
+func (m *MTProto) Ping() time.Duration {
+	ctx, cancel := context.WithTimeout(context.Background(), pongTimeout)
+	defer cancel()
+	rtt, _ := m.PingCtx(ctx)
+	return rtt
+}
+
+// PingCtx sends a ping and waits for its pong until ctx is done
+func (m *MTProto) PingCtx(ctx context.Context) (time.Duration, error) {
+	return m.ping(ctx, &objects.PingParams{PingID: rand.Int63()})
+}
+
+func (m *MTProto) ping(ctx context.Context, request tl.Object) (time.Duration, error) {
+	pong := make(chan struct{}, 1)
+	msgID := m.nextMessageID()
+	m.pingsMutex.Lock()
+	m.pings[msgID] = pong
+	m.pingsMutex.Unlock()
+	defer func() {
+		m.pingsMutex.Lock()
+		delete(m.pings, msgID)
+		m.pingsMutex.Unlock()
+	}()
+
+	start := time.Now()
+	if _, _, err := m.sendPacketWithID(request, msgID); err != nil {
+		return 0, errors.Wrap(err, "sending ping")
+	}
+	select {
+	case <-pong:
+		rtt := time.Since(start)
+		m.rtt.Store(int64(rtt))
+		return rtt, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// pongReceived wakes up whoever waits for the pong of the ping with the message id
+func (m *MTProto) pongReceived(pingMsgID int64) {
+	m.pingsMutex.Lock()
+	defer m.pingsMutex.Unlock()
+	if pong, ok := m.pings[pingMsgID]; ok {
+		select {
+		case pong <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// pongTimeout returns how long the pong of a keepalive ping may take, no longer than the interval
+func (m *MTProto) pongTimeout() time.Duration {
+	if m.pingInterval < pongTimeout {
+		return m.pingInterval
+	}
+	return pongTimeout
+}
+
+// startKeepalive pings the server until ctx is done, and reconnects when a pong doesn't come back in time
+func (m *MTProto) startKeepalive(ctx context.Context) {
+	m.routineswg.Add(1)
+	go func() {
+		defer m.routineswg.Done()
+		for {
+			if err := sleepCtx(ctx, m.pingInterval); err != nil {
+				return
+			}
+			if m.serviceModeActivated {
+				continue
+			}
+			var request tl.Object = &objects.PingDelayDisconnectParams{
+				PingID:          rand.Int63(),
+				DisconnectDelay: int32((m.pingInterval + pingDisconnectDelay).Seconds()),
+			}
+			if m.connection == ConnectionHTTP {
+				// there is no connection to close, every request is a new one
+				request = &objects.PingParams{PingID: rand.Int63()}
+			}
+			pingCtx, cancel := context.WithTimeout(ctx, m.pongTimeout())
+			_, err := m.ping(pingCtx, request)
+			cancel()
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				m.Logger.Info("no pong, reconnecting", "addr", m.Addr, "error", err)
+				if err := m.Reconnect(false); err != nil {
+					m.Logger.Error("reconnecting", "error", err)
+				}
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,40 @@
+package gogram
+
+import "github.com/jwillp/gogram/internal/utils"
+
+// Logger receives the log messages of the library, see Config.Logger. Fields are key value pairs, the library
+// uses the keys component, dc, msg_id, method, peer and error, so the logs of many clients can be told apart
+// by giving each of them a logger with its own fields, e.g. an slog logger made with With("account", name)
+type Logger = utils.LogHandler
+
+// LogLevel is the severity of a log message
+type LogLevel = utils.LogLevel
+
+const (
+	LevelDebug    = utils.DebugLevel
+	LevelInfo     = utils.InfoLevel
+	LevelWarn     = utils.WarnLevel
+	LevelError    = utils.ErrorLevel
+	LevelDisabled = utils.NoLevel // a StdLogger at this level writes nothing
+)
+
+// StdLogger is the default Logger, it writes with the standard log package, its level can be changed any time
+type StdLogger = utils.StdLogger
+
+// NewStdLogger returns a logger writing the messages of the level and above with the standard log package
+func NewStdLogger(level LogLevel) *StdLogger {
+	return utils.NewStdLogger(level)
+}
+
+// ParseLogLevel parses debug, info, warn, error or disabled, anything else is info
+func ParseLogLevel(level string) LogLevel {
+	return utils.ParseLogLevel(level)
+}
+
+// logHandler returns the logger of the config, or a StdLogger at its level
+func (c *Config) logHandler() Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	return NewStdLogger(ParseLogLevel(c.LogLevel))
+}
@@ -0,0 +1,114 @@
+//go:build go1.21
+
+package gogram
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// NewSlogLogger returns a Logger writing to l, fields become attributes
+func NewSlogLogger(l *slog.Logger) Logger {
+	return &slogLogger{l: l}
+}
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+func (s *slogLogger) Enabled(level LogLevel) bool {
+	return level < LevelDisabled && s.l.Enabled(context.Background(), toSlogLevel(level))
+}
+
+func (s *slogLogger) Log(level LogLevel, msg string, fields ...any) {
+	if level >= LevelDisabled {
+		return
+	}
+	s.l.Log(context.Background(), toSlogLevel(level), msg, fields...)
+}
+
+func toSlogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func fromSlogLevel(level slog.Level) LogLevel {
+	switch {
+	case level < slog.LevelInfo:
+		return LevelDebug
+	case level < slog.LevelWarn:
+		return LevelInfo
+	case level < slog.LevelError:
+		return LevelWarn
+	default:
+		return LevelError
+	}
+}
+
+// NewSlogHandler returns an slog handler writing to l, so code using slog logs the same way as the library
+func NewSlogHandler(l Logger) slog.Handler {
+	return &slogHandler{l: l}
+}
+
+type slogHandler struct {
+	l      Logger
+	attrs  []any
+	prefix string // of the keys, made of the open groups
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.l.Enabled(fromSlogLevel(level))
+}
+
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	fields := make([]any, len(h.attrs), len(h.attrs)+2*r.NumAttrs())
+	copy(fields, h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, h.prefix, a)
+		return true
+	})
+	h.l.Log(fromSlogLevel(r.Level), r.Message, fields...)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := append([]any(nil), h.attrs...)
+	for _, a := range attrs {
+		fields = appendAttr(fields, h.prefix, a)
+	}
+	return &slogHandler{l: h.l, attrs: fields, prefix: h.prefix}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{l: h.l, attrs: h.attrs, prefix: h.prefix + name + "."}
+}
+
+// appendAttr appends the attribute as key value pairs, the keys of groups are joined with dots
+func appendAttr(fields []any, prefix string, a slog.Attr) []any {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, inner := range a.Value.Group() {
+			fields = appendAttr(fields, prefix, inner)
+		}
+		return fields
+	}
+	return append(fields, strings.TrimSuffix(prefix+a.Key, "."), a.Value.Any())
+}
@@ -7,35 +7,63 @@ import (
 	"crypto/rsa"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	ige "github.com/jwillp/gogram/internal/aes_ige"
 	"github.com/jwillp/gogram/internal/encoding/tl"
 	"github.com/jwillp/gogram/internal/mode"
 	"github.com/jwillp/gogram/internal/mtproto/messages"
 	"github.com/jwillp/gogram/internal/mtproto/objects"
-	"github.com/jwillp/gogram/internal/session"
 	"github.com/jwillp/gogram/internal/transport"
 	"github.com/jwillp/gogram/internal/utils"
+	"github.com/jwillp/gogram/session"
 	"github.com/pkg/errors"
 )
 
 const defaultTimeout = 65 * time.Second
 
 type MTProto struct {
-	Addr          string
-	appID         int32
-	socksProxy    *transport.Socks
-	transport     transport.Transport
-	stopRoutines  context.CancelFunc
-	routineswg    sync.WaitGroup
-	memorySession bool
-	tcpActive     bool
+	Addr           string
+	appID          int32
+	proxy          *Proxy
+	dialer         Dialer
+	dcs            *DCTable
+	dcID           atomic.Int32 // fallback when the address isn't in the table
+	ipv6           bool
+	transportMode  TransportMode
+	obfuscated     bool
+	mtProxy        *MTProxy
+	mtProxySecret  *transport.MTProxySecret
+	connection     ConnectionType
+	transport      transport.Transport // replaced on reconnects, guarded by transportMutex
+	transportMutex sync.Mutex
+	stopRoutines   context.CancelFunc
+	routineswg     sync.WaitGroup
+	memorySession  bool
+	passphrase     string
+	retryPolicy    *RetryPolicy
+	tcpActive      atomic.Bool
+
+	pfs            bool
+	tempKeyTTL     time.Duration
+	tempKey        atomic.Pointer[tempAuthKey]
+	tempKeyHandler atomic.Pointer[func()]
+	tempKeyBound   bool // a key was bound before, the next one replaces it
+
+	state        atomic.Int32 // ConnectionState
+	stateHandler atomic.Pointer[func(ConnectionState)]
+	pingInterval time.Duration
+	pingsMutex   sync.Mutex
+	pings        map[int64]chan struct{} // ping msg id -> waiter for the pong
+	rtt          atomic.Int64
 
 	authKey []byte
 
@@ -48,15 +76,19 @@ type MTProto struct {
 	mutex            sync.Mutex
 	responseChannels *utils.SyncIntObjectChan
 	expectedTypes    *utils.SyncIntReflectTypes
+	sendQueue        *sendQueue
+	received         *receivedMessages
 
 	seqNoMutex         sync.Mutex
 	seqNo              int32
 	lastMessageIDMutex sync.Mutex
 	lastMessageID      int64
+	timeOffset         atomic.Int64 // server clock minus the local one, in nanoseconds
 
 	sessionStorage session.SessionLoader
 
-	PublicKey *rsa.PublicKey
+	PublicKey  *rsa.PublicKey   // kept for compatibility, the first of publicKeys
+	publicKeys []*rsa.PublicKey // the handshake uses the one whose fingerprint the server lists
 
 	serviceChannel       chan tl.Object
 	serviceModeActivated bool
@@ -64,6 +96,8 @@ type MTProto struct {
 	Logger *utils.Logger
 
 	serverRequestHandlers []func(i any) bool
+	recorder              TrafficRecorder
+	instrumentation       Instrumentation
 }
 
 type customHandles struct {
@@ -75,19 +109,146 @@ type Config struct {
 	StringSession  string
 	SessionStorage session.SessionLoader
 	MemorySession  bool
+	Passphrase     string
 	AppID          int32
+	RetryPolicy    *RetryPolicy
 
 	ServerHost string
-	PublicKey  *rsa.PublicKey
+	PublicKey  *rsa.PublicKey   // deprecated, added to PublicKeys
+	PublicKeys []*rsa.PublicKey // server keys, the server tells which one to use by fingerprint
 	DataCenter int
-	LogLevel   string
-	SocksProxy *transport.Socks
+	DCs        *DCTable // shared address table, a new one with the built in addresses if nil
+	TestMode   bool     // connect to the test servers, ignored when DCs is set
+	IPv6       bool     // prefer ipv6 addresses of data centers
+	LogLevel   string   // level of the default logger, ignored when Logger is set
+	Logger     Logger   // receives the logs, a StdLogger at LogLevel by default
+	Proxy      *Proxy   // socks5, socks4 or http CONNECT proxy, MTProxy connections go through it too
+	Dialer     Dialer   // opens the connections instead of net.Dialer, e.g. for tunnels or tests
+
+	TransportMode TransportMode
+	Obfuscated    bool     // obfuscated2 protocol, not available for TransportFull
+	MTProxy       *MTProxy // connect through an MTProxy, implies obfuscation
+	Connection    ConnectionType
+
+	PFS        bool          // encrypt messages with temporary keys bound to the permanent one
+	TempKeyTTL time.Duration // lifetime of temporary keys, 24 hours by default
+
+	PingInterval time.Duration // how often the connection is checked with a ping, 60 seconds by default
+
+	Recorder        TrafficRecorder // gets every message sent and received, e.g. a TrafficLog
+	Instrumentation Instrumentation // gets requests, traffic and reconnects, e.g. NewMetrics or NewTracing
+}
+
+// ConnectionType selects what carries the mtproto packets
+type ConnectionType uint8
+
+const (
+	ConnectionTCP       ConnectionType = iota // plain tcp, the default
+	ConnectionWebSocket                       // wss://<dc>.web.telegram.org/apiws, always obfuscated
+	ConnectionHTTP                            // http://<dc>:80/api with http_wait long polling
+)
+
+func (c ConnectionType) String() string {
+	switch c {
+	case ConnectionWebSocket:
+		return "WebSocket"
+	case ConnectionHTTP:
+		return "HTTP"
+	default:
+		return "TCP"
+	}
+}
+
+// webSocketDCs are the names of data centers in websocket endpoints
+var webSocketDCs = map[int]string{1: "pluto", 2: "venus", 3: "aurora", 4: "vesta", 5: "flora"}
+
+func (m *MTProto) webSocketURL(name string) string {
+	if m.dcs.Test() {
+		return "wss://" + name + ".web.telegram.org/apiws_test"
+	}
+	return "wss://" + name + ".web.telegram.org/apiws"
+}
+
+// Proxy is a socks5, socks4 or http CONNECT proxy, with optional username and password
+type Proxy = transport.Proxy
+
+// ProxyType is the protocol spoken to a Proxy
+type ProxyType = transport.ProxyType
+
+const (
+	ProxySocks5 = transport.ProxySocks5
+	ProxySocks4 = transport.ProxySocks4
+	ProxyHTTP   = transport.ProxyHTTP
+)
+
+// Dialer opens network connections, it has the signature of net.Dialer.DialContext
+type Dialer = transport.Dialer
+
+// MTProxy is an MTProto proxy server
+type MTProxy struct {
+	Host string
+	Port int
+	// Secret is the hex (or base64) encoded secret, dd secrets enable padding,
+	// ee secrets (with the domain appended) disguise the connection as TLS
+	Secret string
+}
+
+func (p *MTProxy) addr() string {
+	return net.JoinHostPort(p.Host, strconv.Itoa(p.Port))
+}
+
+// TransportMode selects how packets are framed on the wire
+type TransportMode uint8
+
+const (
+	TransportIntermediate       TransportMode = iota // 4 byte length before every packet, the default
+	TransportAbridged                                // 1 or 4 byte length, the smallest overhead
+	TransportPaddedIntermediate                      // intermediate with random padding, harder to fingerprint
+	TransportFull                                    // length, seqno and crc32, can't be obfuscated
+)
+
+func (t TransportMode) variant() mode.Variant {
+	switch t {
+	case TransportAbridged:
+		return mode.Abridged
+	case TransportPaddedIntermediate:
+		return mode.PaddedIntermediate
+	case TransportFull:
+		return mode.Full
+	default:
+		return mode.Intermediate
+	}
+}
+
+func (t TransportMode) String() string {
+	return "TCP" + t.variant().String()
 }
 
 func NewMTProto(c Config) (*MTProto, error) {
+	if (c.Obfuscated || c.MTProxy != nil || c.Connection == ConnectionWebSocket) && c.TransportMode == TransportFull {
+		return nil, errors.New("full transport mode can't be obfuscated")
+	}
+	if c.Connection != ConnectionTCP && c.MTProxy != nil {
+		return nil, errors.New("mtproxy works over tcp only")
+	}
+	if c.Connection == ConnectionHTTP && c.Obfuscated {
+		return nil, errors.New("http connection can't be obfuscated")
+	}
+	var mtProxySecret *transport.MTProxySecret
+	if c.MTProxy != nil {
+		var err error
+		if mtProxySecret, err = transport.ParseMTProxySecret(c.MTProxy.Secret); err != nil {
+			return nil, errors.Wrap(err, "parsing mtproxy secret")
+		}
+		if mtProxySecret.Padded {
+			c.TransportMode = TransportPaddedIntermediate
+		}
+	}
 	if c.SessionStorage == nil {
 		if c.MemorySession {
 			c.SessionStorage = session.NewInMemory()
+		} else if c.Passphrase != "" {
+			c.SessionStorage = session.NewFromFileWithPassphrase(c.AuthKeyFile, c.Passphrase)
 		} else {
 			c.SessionStorage = session.NewFromFile(c.AuthKeyFile)
 		}
@@ -95,7 +256,10 @@ func NewMTProto(c Config) (*MTProto, error) {
 
 	loaded, err := c.SessionStorage.Load()
 	if err != nil {
-		if !(strings.Contains(err.Error(), session.ErrFileNotExists) || strings.Contains(err.Error(), session.ErrPathNotFound)) {
+		if errors.Is(err, ige.ErrPassphraseMismatch) || errors.Is(err, ige.ErrPassphraseNotGiven) {
+			return nil, errors.Wrap(err, "loading session")
+		}
+		if !(errors.Is(err, session.ErrSessionNotFound) || strings.Contains(err.Error(), session.ErrFileNotExists) || strings.Contains(err.Error(), session.ErrPathNotFound)) {
 			// if the error is not because of file not found or path not found, return the error
 			// else, continue with the execution
 			// check if have write permission in the directory
@@ -106,13 +270,41 @@ func NewMTProto(c Config) (*MTProto, error) {
 		}
 	}
 
-	mtproto := &MTProto{sessionStorage: c.SessionStorage, Addr: c.ServerHost, encrypted: false, sessionId: utils.GenerateSessionID(), serviceChannel: make(chan tl.Object), PublicKey: c.PublicKey, responseChannels: utils.NewSyncIntObjectChan(), expectedTypes: utils.NewSyncIntReflectTypes(), serverRequestHandlers: make([]func(i any) bool, 0), Logger: utils.NewLogger("gogram - mtproto").SetLevel(c.LogLevel), memorySession: c.MemorySession, appID: c.AppID}
+	if c.PublicKey != nil {
+		c.PublicKeys = append([]*rsa.PublicKey{c.PublicKey}, c.PublicKeys...)
+	}
+	if len(c.PublicKeys) == 0 {
+		return nil, errors.New("no public keys provided")
+	}
+	if c.DCs == nil {
+		c.DCs = NewDCTable(c.TestMode)
+	}
+	if c.ServerHost == "" {
+		option, ok := c.DCs.Lookup(c.DataCenter, DCPreference{IPv6: c.IPv6, Obfuscated: c.Obfuscated || c.MTProxy != nil || c.Connection == ConnectionWebSocket, Proxy: c.Proxy != nil || c.MTProxy != nil})
+		if !ok {
+			return nil, errors.Errorf("no address for dc %d", c.DataCenter)
+		}
+		c.ServerHost = option.Addr()
+	}
+
+	if c.TempKeyTTL <= 0 {
+		c.TempKeyTTL = defaultTempKeyTTL
+	}
+	if c.PingInterval <= 0 {
+		c.PingInterval = pingInterval
+	}
+
+	mtproto := &MTProto{sessionStorage: c.SessionStorage, Addr: c.ServerHost, encrypted: false, sessionId: utils.GenerateSessionID(), serviceChannel: make(chan tl.Object), PublicKey: c.PublicKeys[0], publicKeys: c.PublicKeys, responseChannels: utils.NewSyncIntObjectChan(), sendQueue: newSendQueue(), received: newReceivedMessages(), pings: make(map[int64]chan struct{}), expectedTypes: utils.NewSyncIntReflectTypes(), serverRequestHandlers: make([]func(i any) bool, 0), memorySession: c.MemorySession, passphrase: c.Passphrase, transportMode: c.TransportMode, obfuscated: c.Obfuscated, mtProxy: c.MTProxy, mtProxySecret: mtProxySecret, connection: c.Connection, proxy: c.Proxy, dialer: c.Dialer, dcs: c.DCs, ipv6: c.IPv6, pfs: c.PFS, tempKeyTTL: c.TempKeyTTL, pingInterval: c.PingInterval, retryPolicy: c.RetryPolicy.withDefaults(), appID: c.AppID, recorder: c.Recorder, instrumentation: c.instrumentation()}
+	mtproto.Logger = utils.NewLogger(c.logHandler(), "mtproto").With("dc", func() any { return mtproto.GetDC() })
 	if loaded != nil || c.StringSession != "" {
 		mtproto.encrypted = true
 	}
 	if err := mtproto.loadAuth(c.StringSession, loaded); err != nil {
 		return nil, errors.Wrap(err, "loading auth")
 	}
+	if mtproto.dcID.Load() == 0 {
+		mtproto.dcID.Store(int32(c.DataCenter))
+	}
 	return mtproto, nil
 }
 
@@ -136,7 +328,8 @@ func (m *MTProto) ExportAuth() ([]byte, []byte, string, int, int32) {
 
 func (m *MTProto) ImportRawAuth(authKey []byte, authKeyHash []byte, addr string, dc int, appID int32) (bool, error) {
 	m.authKey, m.authKeyHash, m.Addr, m.appID = authKey, authKeyHash, addr, appID
-	m.Logger.Debug("imported auth key, auth key hash, addr, dc, appID")
+	m.dcID.Store(int32(dc))
+	m.Logger.Debug("imported auth key", "addr", addr)
 	if !m.memorySession {
 		if err := m.SaveSession(); err != nil {
 			return false, errors.Wrap(err, "saving session")
@@ -152,12 +345,13 @@ func (m *MTProto) ImportAuth(Session string) (bool, error) {
 	StringSession := &session.StringSession{
 		Encoded: Session,
 	}
-	AuthKey, AuthKeyHash, _, IpAddr, AppID, err := StringSession.Decode()
+	AuthKey, AuthKeyHash, DcID, IpAddr, AppID, err := StringSession.Decode()
 	if err != nil {
 		return false, fmt.Errorf("decoding string session: %w", err)
 	}
 	m.authKey, m.authKeyHash, m.Addr, m.appID = AuthKey, AuthKeyHash, IpAddr, AppID
-	m.Logger.Debug("imported auth key, auth key hash, addr, dc, appID from string session")
+	m.dcID.Store(int32(DcID))
+	m.Logger.Debug("imported auth key from string session", "addr", m.Addr)
 	if !m.memorySession {
 		if err := m.SaveSession(); err != nil {
 			return false, fmt.Errorf("saving session: %w", err)
@@ -166,14 +360,48 @@ func (m *MTProto) ImportAuth(Session string) (bool, error) {
 	return true, nil
 }
 
+// GetDC returns the data center of the address in use
 func (m *MTProto) GetDC() int {
-	addr := m.Addr
-	for k, v := range utils.DcList {
-		if v == addr {
-			return k
-		}
+	if option, ok := m.dcs.Find(m.Addr); ok {
+		return option.ID
+	}
+	return int(m.dcID.Load())
+}
+
+// DCs returns the address table, shared with the senders exported from this one
+func (m *MTProto) DCs() *DCTable {
+	return m.dcs
+}
+
+// UpdateDCs replaces the addresses with the list from help.getConfig, thisDC is the data center
+// the config came from
+func (m *MTProto) UpdateDCs(options []DCOption, thisDC int) {
+	m.dcs.Update(options)
+	if thisDC != 0 {
+		m.dcID.Store(int32(thisDC))
+	}
+}
+
+// dcPreference returns which addresses of data centers fit the connection settings
+func (m *MTProto) dcPreference(media bool) DCPreference {
+	return DCPreference{
+		Media:      media,
+		IPv6:       m.ipv6,
+		Obfuscated: m.obfuscated || m.mtProxy != nil || m.connection == ConnectionWebSocket,
+		Proxy:      m.proxy != nil || m.mtProxy != nil,
 	}
-	return 4
+}
+
+// wireDC is the dc id as servers and proxies expect it, negative for media addresses and shifted for test servers
+func (m *MTProto) wireDC() int16 {
+	dc := m.GetDC()
+	if m.dcs.Test() {
+		dc += testDCOffset
+	}
+	if option, ok := m.dcs.Find(m.Addr); ok && option.MediaOnly {
+		dc = -dc
+	}
+	return int16(dc)
 }
 
 func (m *MTProto) AppID() int32 {
@@ -181,20 +409,21 @@ func (m *MTProto) AppID() int32 {
 }
 
 func (m *MTProto) ReconnectToNewDC(dc int) (*MTProto, error) {
-	newAddr, isValid := utils.DcList[dc]
+	option, isValid := m.dcs.Lookup(dc, m.dcPreference(false))
 	if !isValid {
 		return nil, errors.New("invalid DC ID provided")
 	}
+	newAddr := option.Addr()
 	m.sessionStorage.Delete()
-	m.Logger.Debug("deleted old auth key file")
-	cfg := Config{DataCenter: dc, PublicKey: m.PublicKey, ServerHost: newAddr, AuthKeyFile: m.sessionStorage.Path(), MemorySession: false, LogLevel: m.Logger.Lev(), SocksProxy: m.socksProxy, AppID: m.appID}
+	m.Logger.Debug("deleted old auth key", "path", m.sessionStorage.Path())
+	cfg := Config{DataCenter: dc, PublicKeys: m.publicKeys, ServerHost: newAddr, SessionStorage: m.sessionStorage, MemorySession: m.memorySession, Passphrase: m.passphrase, RetryPolicy: m.retryPolicy, Logger: m.Logger.Handler(), Proxy: m.proxy, Dialer: m.dialer, DCs: m.dcs, IPv6: m.ipv6, PFS: m.pfs, TempKeyTTL: m.tempKeyTTL, PingInterval: m.pingInterval, TransportMode: m.transportMode, Obfuscated: m.obfuscated, MTProxy: m.mtProxy, Connection: m.connection, AppID: m.appID, Recorder: m.recorder, Instrumentation: m.instrumentation}
 	sender, err := NewMTProto(cfg)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating new MTProto")
 	}
 	sender.serverRequestHandlers = m.serverRequestHandlers
 	m.stopRoutines()
-	m.Logger.Info(fmt.Sprintf("user migrated to -> [DC %d]", dc))
+	m.Logger.Info("user migrated", "new_dc", dc)
 	m.Logger.Debug("reconnecting to new DC with new auth key")
 	errConn := sender.CreateConnection(true)
 	if errConn != nil {
@@ -204,18 +433,26 @@ func (m *MTProto) ReconnectToNewDC(dc int) (*MTProto, error) {
 }
 
 func (m *MTProto) ExportNewSender(dcID int, mem bool) (*MTProto, error) {
-	newAddr := utils.DcList[dcID]
+	// exported senders transfer files, so media addresses are preferred
+	option, ok := m.dcs.Lookup(dcID, m.dcPreference(true))
+	if !ok {
+		return nil, errors.Errorf("no address for dc %d", dcID)
+	}
+	newAddr := option.Addr()
 	execWorkDir, err := os.Executable()
 	if err != nil {
 		return nil, errors.Wrap(err, "getting executable directory")
 	}
 	wd := filepath.Dir(execWorkDir)
-	cfg := Config{DataCenter: dcID, PublicKey: m.PublicKey, ServerHost: newAddr, AuthKeyFile: filepath.Join(wd, "exported_sender"), MemorySession: mem, LogLevel: m.Logger.Lev(), SocksProxy: m.socksProxy, AppID: m.appID}
+	cfg := Config{DataCenter: dcID, PublicKeys: m.publicKeys, ServerHost: newAddr, AuthKeyFile: filepath.Join(wd, "exported_sender"), MemorySession: mem, Passphrase: m.passphrase, RetryPolicy: m.retryPolicy, Logger: m.Logger.Handler(), Proxy: m.proxy, Dialer: m.dialer, DCs: m.dcs, IPv6: m.ipv6, PFS: m.pfs, TempKeyTTL: m.tempKeyTTL, PingInterval: m.pingInterval, TransportMode: m.transportMode, Obfuscated: m.obfuscated, MTProxy: m.mtProxy, Connection: m.connection, AppID: m.appID, Recorder: m.recorder, Instrumentation: m.instrumentation}
 	if dcID == m.GetDC() {
 		cfg.SessionStorage = m.sessionStorage
 	}
-	sender, _ := NewMTProto(cfg)
-	m.Logger.Info("exporting new sender to [DC " + strconv.Itoa(dcID) + "]")
+	sender, err := NewMTProto(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating new MTProto")
+	}
+	m.Logger.Info("exporting new sender", "sender_dc", dcID)
 	err = sender.CreateConnection(true)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating connection")
@@ -225,18 +462,30 @@ func (m *MTProto) ExportNewSender(dcID int, mem bool) (*MTProto, error) {
 }
 
 func (m *MTProto) CreateConnection(withLog bool) error {
+	if m.State() != StateReconnecting {
+		m.setState(StateConnecting)
+	}
+	if err := m.createConnection(withLog); err != nil {
+		m.setState(StateDisconnected)
+		return err
+	}
+	m.setState(StateConnected)
+	return nil
+}
+
+func (m *MTProto) createConnection(withLog bool) error {
 	ctx, cancelfunc := context.WithCancel(context.Background())
 	m.stopRoutines = cancelfunc
 	if withLog {
-		m.Logger.Info("Connecting to <" + m.Addr + "> - <TCPFull>...")
+		m.Logger.Info("connecting", "addr", m.Addr, "transport", m.transportName())
 	}
 	err := m.connect(ctx)
 	if err != nil {
 		return err
 	}
-	m.tcpActive = true
+	m.tcpActive.Store(true)
 	if withLog {
-		m.Logger.Info("Connection to <" + m.Addr + "> - <TCPFull> established!")
+		m.Logger.Info("connection established", "addr", m.Addr, "transport", m.transportName())
 	}
 	m.startReadingResponses(ctx)
 	if !m.encrypted {
@@ -247,68 +496,193 @@ func (m *MTProto) CreateConnection(withLog bool) error {
 		}
 		m.Logger.Debug("authKey created and saved")
 	}
+	if m.pfs {
+		if err := m.ensureTempKey(); err != nil {
+			return err
+		}
+		m.rotateTempKey(ctx)
+	}
+	m.startSending(ctx)
+	if m.connection == ConnectionHTTP {
+		// handshake responses come in the bodies of its requests, long polling needs the auth key
+		m.startLongPolling(ctx)
+	}
+	m.startKeepalive(ctx)
 
 	return nil
 }
 
+// transportName returns the transport for logs, e.g. TCPIntermediate (obfuscated)
+func (m *MTProto) transportName() string {
+	switch m.connection {
+	case ConnectionHTTP:
+		return "HTTP"
+	case ConnectionWebSocket:
+		return "WebSocket" + m.transportMode.variant().String()
+	}
+	if m.mtProxy != nil {
+		return m.transportMode.String() + " via MTProxy " + m.mtProxy.addr()
+	}
+	if m.obfuscated {
+		return m.transportMode.String() + " (obfuscated)"
+	}
+	return m.transportMode.String()
+}
+
 func (m *MTProto) connect(ctx context.Context) error {
-	var err error
-	m.transport, err = transport.NewTransport(
-		m,
-		transport.TCPConnConfig{
+	host := m.Addr
+	var obfuscation *transport.Obfuscation
+	if m.obfuscated {
+		obfuscation = &transport.Obfuscation{DC: m.wireDC()}
+	}
+	if m.mtProxy != nil {
+		host = m.mtProxy.addr()
+		obfuscation = &transport.Obfuscation{Secret: m.mtProxySecret.Key, DC: m.wireDC(), FakeTLS: m.mtProxySecret.Domain}
+	}
+	var conn transport.ConnConfig = transport.TCPConnConfig{
+		Ctx:     ctx,
+		Host:    host,
+		Timeout: defaultTimeout,
+		Proxy:   m.proxy,
+		Dialer:  m.dialer,
+	}
+	switch m.connection {
+	case ConnectionWebSocket:
+		name, ok := webSocketDCs[m.GetDC()]
+		if !ok {
+			return fmt.Errorf("no websocket endpoint for dc %d", m.GetDC())
+		}
+		conn = transport.WebSocketConnConfig{
 			Ctx:     ctx,
-			Host:    m.Addr,
+			URL:     m.webSocketURL(name),
 			Timeout: defaultTimeout,
-			Socks:   m.socksProxy,
-		},
-		mode.Intermediate,
-	)
+			Proxy:   m.proxy,
+			Dialer:  m.dialer,
+		}
+		obfuscation = &transport.Obfuscation{DC: m.wireDC()}
+	case ConnectionHTTP:
+		ip, _, err := net.SplitHostPort(m.Addr)
+		if err != nil {
+			return fmt.Errorf("parsing dc address: %w", err)
+		}
+		conn = transport.HTTPConnConfig{Ctx: ctx, Host: net.JoinHostPort(ip, "80"), Proxy: m.proxy, Dialer: m.dialer}
+	}
+	t, err := transport.NewTransport(m, conn, m.transportMode.variant(), obfuscation)
 	if err != nil {
 		return fmt.Errorf("creating transport: %w", err)
 	}
-
-	closeOnCancel(ctx, m.transport)
+	closeOnCancel(ctx, t)
+	m.transportMutex.Lock()
+	m.transport = t
+	m.transportMutex.Unlock()
 	return nil
 }
 
+// currentTransport returns the transport of the latest connection, the goroutines of the previous one
+// may still be running while a reconnect replaces it
+func (m *MTProto) currentTransport() transport.Transport {
+	m.transportMutex.Lock()
+	defer m.transportMutex.Unlock()
+	return m.transport
+}
+
 func (m *MTProto) makeRequest(data tl.Object, expectedTypes ...reflect.Type) (any, error) {
+	return m.makeRequestCtx(context.Background(), data, expectedTypes...)
+}
+
+func (m *MTProto) makeRequestCtx(ctx context.Context, data tl.Object, expectedTypes ...reflect.Type) (_ any, err error) {
+	policy := m.retryPolicy
+	info, start, attempt := RequestInfo{Method: requestName(data), DC: m.GetDC()}, time.Now(), 0
+	end := m.instrumentation.RequestStarted(ctx, info)
+	defer func() { end(RequestResult{Duration: time.Since(start), Err: err, Retries: attempt}) }()
+	for ; ; attempt++ {
+		response, err := m.sendAndWait(ctx, data, expectedTypes...)
+		if err != nil {
+			return nil, err
+		}
+		var (
+			cause error
+			delay time.Duration
+		)
+		switch r := response.(type) {
+		case *objects.RpcError:
+			realErr := RpcErrorToNative(r).(*ErrResponseCode)
+			if wait, ok := floodWait(realErr); ok {
+				cause = &FloodWaitError{WaitError{Wait: wait, Err: realErr}}
+				if wait > policy.MaxFloodWait {
+					return nil, cause
+				}
+				delay = wait
+				m.instrumentation.FloodWait(info, wait)
+				m.Logger.Info("flood wait, sleeping", "method", info.Method, "wait", wait)
+			} else if isTransientError(realErr) {
+				cause = realErr
+				delay = policy.backoff(attempt)
+			} else {
+				return nil, realErr
+			}
+
+		case *errorSessionConfigsChanged:
+			m.Logger.Debug("session configs changed, resending request", "method", info.Method)
+			cause = r
+
+		case *errorConnectionReset:
+			cause = r
+
+		case *BadMsgError:
+			return nil, r
+
+		default:
+			return tl.UnwrapNativeTypes(response), nil
+		}
+
+		if policy.MaxRetries < 0 || attempt >= policy.MaxRetries {
+			return nil, cause
+		}
+		if policy.OnRetry != nil {
+			policy.OnRetry(RetryEvent{Method: info.Method, Attempt: attempt + 1, Err: cause, Delay: delay})
+		}
+		if err := sleepCtx(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sendAndWait sends the request once and waits for its response, rpc errors are returned as a response
+func (m *MTProto) sendAndWait(ctx context.Context, data tl.Object, expectedTypes ...reflect.Type) (tl.Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	if !m.TcpActive() {
 		return nil, errors.New("Can't make request. Connection is not established")
 	}
-	resp, err := m.sendPacket(data, expectedTypes...)
+	resp, msgID, err := m.sendPacket(data, expectedTypes...)
 	if err != nil {
 		if strings.Contains(err.Error(), "use of closed network connection") || strings.Contains(err.Error(), "transport is closed") {
-			m.Logger.Info("connection closed due to broken pipe, reconnecting to <" + m.Addr + ">" + "<TCPFull>...")
+			m.Logger.Info("connection closed due to broken pipe, reconnecting", "addr", m.Addr, "transport", m.transportName())
 			err = m.Reconnect(false)
 			if err != nil {
-				m.Logger.Error("reconnecting: " + err.Error())
+				m.Logger.Error("reconnecting", "error", err)
 				return nil, errors.New("reconnecting: " + err.Error())
 			}
-			return m.makeRequest(data, expectedTypes...)
+			return &errorConnectionReset{}, nil
 		}
 		return nil, errors.Wrap(err, "sending packet")
 	}
-	response := <-resp
-	switch r := response.(type) {
-	case *objects.RpcError:
-		realErr := RpcErrorToNative(r).(*ErrResponseCode)
-		if strings.Contains(realErr.Message, "FLOOD_WAIT_") {
-			m.Logger.Info("flood wait detected on " + strings.ReplaceAll(reflect.TypeOf(data).Elem().Name(), "Params", "") + fmt.Sprintf(" request. sleeping for %d seconds", realErr.AdditionalInfo.(int)))
-			time.Sleep(time.Duration(realErr.AdditionalInfo.(int)) * time.Second)
-			return m.makeRequest(data, expectedTypes...)
-		}
-		return nil, realErr
-
-	case *errorSessionConfigsChanged:
-		m.Logger.Debug("session configs changed, resending request")
-		return m.makeRequest(data, expectedTypes...)
+	select {
+	case response := <-resp:
+		return response, nil
+	case <-ctx.Done():
+		// nobody is waiting anymore, drop the channel so a late response is discarded
+		m.responseChannels.Delete(int(msgID))
+		m.expectedTypes.Delete(int(msgID))
+		m.sendQueue.forget(msgID)
+		return nil, ctx.Err()
 	}
-
-	return tl.UnwrapNativeTypes(response), nil
 }
 
 func (m *MTProto) InvokeRequestWithoutUpdate(data tl.Object, expectedTypes ...reflect.Type) error {
-	_, err := m.sendPacket(data, expectedTypes...)
+	_, _, err := m.sendPacket(data, expectedTypes...)
 	if err != nil {
 		return errors.Wrap(err, "sending packet")
 	}
@@ -316,49 +690,68 @@ func (m *MTProto) InvokeRequestWithoutUpdate(data tl.Object, expectedTypes ...re
 }
 
 func (m *MTProto) TcpActive() bool {
-	return m.tcpActive
+	return m.tcpActive.Load()
 }
 
 func (m *MTProto) Disconnect() error {
-	m.stopRoutines()
-	m.tcpActive = false
-	// m.responseChannels.Close()
+	m.disconnect()
+	m.setState(StateDisconnected)
 	return nil
 }
 
+func (m *MTProto) disconnect() {
+	m.stopRoutines()
+	m.tcpActive.Store(false)
+}
+
 func (m *MTProto) Terminate() error {
 	m.stopRoutines()
 	m.responseChannels.Close()
-	m.Logger.Info("terminating connection to <" + m.Addr + "> - <TCPFull>...")
-	m.tcpActive = false
+	m.sendQueue.resetInFlight()
+	m.Logger.Info("terminating connection", "addr", m.Addr, "transport", m.transportName())
+	m.tcpActive.Store(false)
+	m.setState(StateDisconnected)
 	return nil
 }
 
 func (m *MTProto) Reconnect(WithLogs bool) error {
-	err := m.Disconnect()
-	if err != nil {
-		return errors.Wrap(err, "disconnecting")
-	}
+	m.disconnect()
+	m.setState(StateReconnecting)
 	if WithLogs {
-		m.Logger.Info("Reconnecting to <" + m.Addr + "> - <TCPFull>...")
+		m.Logger.Info("reconnecting", "addr", m.Addr, "transport", m.transportName())
 	}
 
-	err = m.CreateConnection(WithLogs)
+	start := time.Now()
+	err := m.CreateConnection(WithLogs)
+	m.instrumentation.Reconnected(m.GetDC(), time.Since(start), err)
 	if err == nil && WithLogs {
-		m.Logger.Info("Reconnected to <" + m.Addr + "> - <TCPFull>!")
+		m.Logger.Info("reconnected", "addr", m.Addr, "transport", m.transportName())
+	}
+	if err != nil {
+		m.failInFlight()
+	} else {
+		m.resendInFlight()
 	}
-	m.InvokeRequestWithoutUpdate(&utils.PingParams{
-		PingID: 123456789,
-	})
 	return errors.Wrap(err, "recreating connection")
 }
 
-func (m *MTProto) Ping() time.Duration {
-	start := time.Now()
-	m.InvokeRequestWithoutUpdate(&utils.PingParams{
-		PingID: 123456789,
-	})
-	return time.Since(start)
+// startLongPolling keeps an http_wait request open, so the server can push messages over the http connection
+func (m *MTProto) startLongPolling(ctx context.Context) {
+	m.routineswg.Add(1)
+	go func() {
+		defer m.routineswg.Done()
+		for ctx.Err() == nil {
+			if m.serviceModeActivated {
+				// a temporary key is being made
+				sleepCtx(ctx, time.Second)
+				continue
+			}
+			if _, _, err := m.sendPacket(&objects.HttpWaitParams{MaxDelay: 0, WaitAfter: 0, MaxWait: 25000}); err != nil {
+				m.Logger.Debug("long polling", "error", err)
+				sleepCtx(ctx, time.Second)
+			}
+		}
+	}()
 }
 
 func (m *MTProto) startReadingResponses(ctx context.Context) {
@@ -370,11 +763,15 @@ func (m *MTProto) startReadingResponses(ctx context.Context) {
 			case <-ctx.Done():
 				return
 			default:
-				if !m.tcpActive {
-					m.Logger.Warn("Connection is not established with <" + m.Addr + "> - <TCPFull>!")
+				if !m.tcpActive.Load() {
+					m.Logger.Warn("connection is not established", "addr", m.Addr, "transport", m.transportName())
 					return
 				}
 				err := m.readMsg()
+				if err != nil && ctx.Err() != nil {
+					// the connection was closed on purpose, by Disconnect, Terminate or another reconnect
+					return
+				}
 				switch err {
 				case nil:
 				case context.Canceled:
@@ -382,31 +779,35 @@ func (m *MTProto) startReadingResponses(ctx context.Context) {
 				case io.EOF:
 					err = m.Reconnect(false)
 					if err != nil {
-						m.Logger.Error(errors.Wrap(err, "reconnecting"))
+						m.Logger.Error("reconnecting", "error", err)
 					}
 					return
 
 				default:
+					if e, ok := err.(*ErrResponseCode); ok && int32(e.Code) == -404 && m.pfs {
+						// the server forgot the temporary key, a new one is bound on reconnect
+						m.tempKey.Store(nil)
+					}
 					if e, ok := err.(transport.ErrCode); ok {
 						if int(e) == 4294966892 {
 							err = m.makeAuthKey()
 							if err != nil {
-								m.Logger.Error(errors.Wrap(err, "making auth key"))
+								m.Logger.Error("making auth key", "error", err)
 							}
 						} else {
-							m.Logger.Error("Unhandled errorCode: " + fmt.Sprintf("%d", e))
+							m.Logger.Error("unhandled transport error", "code", int32(e))
 						}
 					}
 					if strings.Contains(err.Error(), "required to reconnect!") {
 						err = m.Reconnect(false)
 						if err != nil {
-							m.Logger.Error(errors.Wrap(err, "reconnecting error"))
+							m.Logger.Error("reconnecting", "error", err)
 						}
 						return
 					} else {
 						err = m.Reconnect(false)
 						if err != nil {
-							m.Logger.Error(errors.Wrap(err, "reconnecting error"))
+							m.Logger.Error("reconnecting", "error", err)
 						}
 					}
 				}
@@ -416,10 +817,11 @@ func (m *MTProto) startReadingResponses(ctx context.Context) {
 }
 
 func (m *MTProto) readMsg() error {
-	if m.transport == nil {
+	t := m.currentTransport()
+	if t == nil {
 		return errors.New("must setup connection before reading messages")
 	}
-	response, err := m.transport.ReadMsg()
+	response, err := t.ReadMsg()
 	if err != nil {
 		if e, ok := err.(transport.ErrCode); ok {
 			return &ErrResponseCode{Code: int(e)}
@@ -431,6 +833,7 @@ func (m *MTProto) readMsg() error {
 			return errors.Wrap(err, "reading message")
 		}
 	}
+	m.instrumentation.PacketReceived(m.GetDC(), len(response.GetMsg()))
 
 	if m.serviceModeActivated {
 		var obj tl.Object
@@ -438,6 +841,7 @@ func (m *MTProto) readMsg() error {
 		if err != nil {
 			return errors.Wrap(err, "parsing object")
 		}
+		m.record(TrafficIncoming, int64(response.GetMsgID()), int32(response.GetSeqNo()), obj, response.GetMsg())
 		m.serviceChannel <- obj
 		return nil
 	}
@@ -450,6 +854,7 @@ func (m *MTProto) readMsg() error {
 }
 
 func (m *MTProto) processResponse(msg messages.Common) error {
+	m.received.add(int64(msg.GetMsgID()))
 	var data tl.Object
 	var err error
 	if et, ok := m.expectedTypes.Get(msg.GetMsgID()); ok && len(et) > 0 {
@@ -460,6 +865,9 @@ func (m *MTProto) processResponse(msg messages.Common) error {
 	if err != nil {
 		return fmt.Errorf("unmarshalling response: %w", err)
 	}
+	if _, ok := data.(*objects.MessageContainer); !ok {
+		m.record(TrafficIncoming, int64(msg.GetMsgID()), int32(msg.GetSeqNo()), data, msg.GetMsg())
+	}
 
 messageTypeSwitching:
 	switch message := data.(type) {
@@ -479,40 +887,61 @@ messageTypeSwitching:
 				return errors.Wrap(err, "saving session")
 			}
 		}
-		m.Reconnect(false)
-
-		m.mutex.Lock()
-		for _, k := range m.responseChannels.Keys() {
-			v, _ := m.responseChannels.Get(k)
-			v <- &errorSessionConfigsChanged{}
+		// only the rejected message, or the messages of the rejected container, have to be repeated
+		for _, id := range m.sendQueue.contents(message.BadMsgID) {
+			m.failMessage(id, &errorSessionConfigsChanged{})
 		}
 
-		m.mutex.Unlock()
-
 	case *objects.NewSessionCreated:
+		m.syncTime(int64(msg.GetMsgID()))
 		m.serverSalt = message.ServerSalt
 		if !m.memorySession {
 			err := m.SaveSession()
 			if err != nil {
-				m.Logger.Error(errors.Wrap(err, "saving session"))
+				m.Logger.Error("saving session", "error", err)
 			}
 		}
 
-	case *objects.Pong, *objects.MsgsAck:
+	case *objects.Pong:
+		m.pongReceived(message.MsgID)
+
+	case *objects.MsgsAck:
+		m.sendQueue.acknowledged(message.MsgIDs)
+
+	case *objects.MsgsStateInfo, *objects.MsgsAllInfo:
 
 	case *objects.BadMsgNotification:
-		badMsg := BadMsgErrorFromNative(message)
-		m.Logger.Debug("BadMsgNotification: " + badMsg.Error())
-		return badMsg
+		m.handleBadMsg(message, int64(msg.GetMsgID()))
+
+	case *objects.MsgResendReq:
+		// the server lost the messages, they are sent again under the same ids
+		for _, msg := range m.sendQueue.sent(message.MsgIDs) {
+			m.sendQueue.push(msg)
+		}
+
+	case *objects.MsgsStateReq:
+		if _, _, err := m.sendPacket(&objects.MsgsStateInfo{ReqMsgID: int64(msg.GetMsgID()), Info: m.received.states(message.MsgIDs, m.serverNow())}); err != nil {
+			return errors.Wrap(err, "sending msgs_state_info")
+		}
+
+	case *objects.MsgsDetailedInfo:
+		m.answerDetailedInfo(message.AnswerMsgID)
+
+	case *objects.MsgsNewDetailedInfo:
+		m.answerDetailedInfo(message.AnswerMsgID)
 	case *objects.RpcResult:
 		obj := message.Obj
 		if v, ok := obj.(*objects.GzipPacked); ok {
 			obj = v.Obj
 		}
-		m.Logger.Debug("RPC response: " + fmt.Sprintf("%T", obj))
-		err := m.writeRPCResponse(int(message.ReqMsgID), obj)
-		if err != nil {
-			return errors.Wrap(err, "writing RPC response")
+		if pong, ok := obj.(*objects.Pong); ok {
+			// pings have no response channel, their pongs are matched by message id
+			m.pongReceived(pong.MsgID)
+			break
+		}
+		m.Logger.Debug("rpc response", "msg_id", message.ReqMsgID, "type", fmt.Sprintf("%T", obj))
+		if !m.writeRPCResponse(int(message.ReqMsgID), obj) {
+			m.Logger.Debug("dropping result of a canceled or failed request", "msg_id", message.ReqMsgID)
 		}
 
 	case *objects.GzipPacked:
@@ -522,28 +951,26 @@ messageTypeSwitching:
 		goto messageTypeSwitching
 
 	default:
-		processed := false
-		for _, f := range m.serverRequestHandlers {
-			processed = f(message)
-			if processed {
-				break
-			}
-		}
-		if !processed {
-			m.Logger.Warn("unhandled message: " + fmt.Sprintf("%T", message))
-		}
+		m.handleServerRequest(message)
 	}
 
 	if (msg.GetSeqNo() & 1) != 0 {
-		_, err := m.MakeRequest(&objects.MsgsAck{MsgIDs: []int64{int64(msg.GetMsgID())}})
-		if err != nil {
-			return errors.Wrap(err, "sending ack")
-		}
+		m.queueAck(int64(msg.GetMsgID()))
 	}
 
 	return nil
 }
 
+// handleServerRequest passes an object the server sent on its own, usually updates, to the first handler taking it
+func (m *MTProto) handleServerRequest(message tl.Object) {
+	for _, f := range m.serverRequestHandlers {
+		if f(message) {
+			return
+		}
+	}
+	m.Logger.Warn("unhandled message", "type", fmt.Sprintf("%T", message))
+}
+
 func MessageRequireToAck(msg tl.Object) bool {
 	switch msg.(type) {
 	case *objects.MsgsAck:
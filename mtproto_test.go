@@ -0,0 +1,584 @@
+package gogram_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mtproto "github.com/jwillp/gogram"
+	"github.com/jwillp/gogram/internal/encoding/tl"
+	"github.com/jwillp/gogram/internal/mtproto/objects"
+	"github.com/jwillp/gogram/internal/mtprototest"
+	"github.com/jwillp/gogram/internal/utils"
+)
+
+type echoParams struct {
+	Text string
+}
+
+func (*echoParams) CRC() uint32 {
+	return 0x1ec0ec01
+}
+
+type echoResult struct {
+	Text string
+}
+
+func (*echoResult) CRC() uint32 {
+	return 0x1ec0ec02
+}
+
+func init() {
+	tl.RegisterObjects(&echoParams{}, &echoResult{})
+}
+
+func echo(request tl.Object) (tl.Object, error) {
+	return &echoResult{Text: request.(*echoParams).Text}, nil
+}
+
+// connect creates an auth key with the server and returns the connected sender
+func connect(t *testing.T, srv *mtprototest.Server, cfg mtproto.Config) *mtproto.MTProto {
+	t.Helper()
+	cfg.MemorySession = true
+	cfg.PublicKeys = srv.PublicKeys()
+	cfg.DataCenter = 2
+	cfg.LogLevel = "disabled"
+	if cfg.ServerHost == "" {
+		cfg.Dialer = srv.Dial
+		cfg.ServerHost = "127.0.0.1:443"
+	}
+	m, err := mtproto.NewMTProto(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.CreateConnection(false); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { m.Terminate() })
+	return m
+}
+
+func makeRequest(t *testing.T, m *mtproto.MTProto, request tl.Object) (any, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return m.MakeRequestCtx(ctx, request)
+}
+
+func TestRequest(t *testing.T) {
+	srv := mtprototest.NewServer(t)
+	srv.Handle(&echoParams{}, echo)
+	m := connect(t, srv, mtproto.Config{})
+
+	resp, err := makeRequest(t, m, &echoParams{Text: "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := resp.(*echoResult); !ok || r.Text != "hello" {
+		t.Fatalf("got %#v, want the echo of hello", resp)
+	}
+	if m.State() != mtproto.StateConnected {
+		t.Errorf("state is %s, want connected", m.State())
+	}
+	if rtt, err := m.PingCtx(context.Background()); err != nil || rtt <= 0 {
+		t.Errorf("ping: rtt %s, error %v", rtt, err)
+	}
+}
+
+func TestRequestOverLoopback(t *testing.T) {
+	srv := mtprototest.NewServer(t)
+	srv.Handle(&echoParams{}, echo)
+	addr, err := srv.Listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := connect(t, srv, mtproto.Config{ServerHost: addr})
+
+	if _, err := makeRequest(t, m, &echoParams{Text: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRPCError(t *testing.T) {
+	srv := mtprototest.NewServer(t)
+	srv.Handle(&echoParams{}, func(tl.Object) (tl.Object, error) {
+		return nil, &mtprototest.RPCError{Code: 400, Message: "PEER_ID_INVALID"}
+	})
+	m := connect(t, srv, mtproto.Config{})
+
+	_, err := makeRequest(t, m, &echoParams{})
+	var rpcErr *mtproto.ErrResponseCode
+	if !errors.As(err, &rpcErr) || rpcErr.Code != 400 || rpcErr.Message != "PEER_ID_INVALID" {
+		t.Fatalf("got error %v, want PEER_ID_INVALID", err)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	srv := mtprototest.NewServer(t)
+	srv.Handle(&echoParams{}, func(request tl.Object) (tl.Object, error) {
+		if request.(*echoParams).Text == "fail" {
+			return nil, &mtprototest.RPCError{Code: 400, Message: "PEER_ID_INVALID"}
+		}
+		return echo(request)
+	})
+	metrics := mtproto.NewMetrics()
+	m := connect(t, srv, mtproto.Config{Instrumentation: metrics})
+
+	for _, text := range []string{"hello", "hello", "fail"} {
+		makeRequest(t, m, &echoParams{Text: text})
+	}
+	var out bytes.Buffer
+	if _, err := metrics.WriteTo(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`gogram_requests_total{dc="2",method="echo",code="ok"} 2`,
+		`gogram_requests_total{dc="2",method="echo",code="400"} 1`,
+		`gogram_rpc_errors_total{dc="2",method="echo",code="400",message="PEER_ID_INVALID"} 1`,
+		`gogram_request_duration_seconds_count{dc="2",method="echo"} 3`,
+		`gogram_requests_in_flight{dc="2"} 0`,
+		`gogram_sent_bytes_total{dc="2"} `,
+		`gogram_received_bytes_total{dc="2"} `,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("metrics don't contain %s:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestLateResponseAfterCancel(t *testing.T) {
+	srv := mtprototest.NewServer(t)
+	answered := make(chan struct{}, 1)
+	srv.Handle(&echoParams{}, func(request tl.Object) (tl.Object, error) {
+		if request.(*echoParams).Text == "slow" {
+			defer func() { answered <- struct{}{} }()
+			time.Sleep(300 * time.Millisecond)
+		}
+		return echo(request)
+	})
+	metrics := mtproto.NewMetrics()
+	m := connect(t, srv, mtproto.Config{Instrumentation: metrics})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := m.MakeRequestCtx(ctx, &echoParams{Text: "slow"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want the deadline error", err)
+	}
+	<-answered
+	// the result nobody waits for is dropped, the connection keeps working
+	if _, err := makeRequest(t, m, &echoParams{Text: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	metrics.WriteTo(&out)
+	if strings.Contains(out.String(), "gogram_reconnects_total{") {
+		t.Errorf("the late result caused a reconnect:\n%s", out.String())
+	}
+}
+
+func TestResendAfterReconnect(t *testing.T) {
+	srv := mtprototest.NewServer(t)
+	var calls atomic.Int32
+	received := make(chan struct{})
+	srv.Handle(&echoParams{}, func(request tl.Object) (tl.Object, error) {
+		if calls.Add(1) == 1 {
+			// the connection breaks before the request is answered
+			close(received)
+			srv.DropConnections()
+		}
+		return echo(request)
+	})
+	m := connect(t, srv, mtproto.Config{})
+
+	resp, err := makeRequest(t, m, &echoParams{Text: "again"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-received
+	if r, ok := resp.(*echoResult); !ok || r.Text != "again" {
+		t.Fatalf("got %#v, want the echo of again", resp)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("the request reached the server %d times, want 2", n)
+	}
+}
+
+// msgIDRecorder keeps the ids of the sent messages of one type
+type msgIDRecorder struct {
+	typ   string
+	mutex sync.Mutex
+	ids   []int64
+}
+
+func (r *msgIDRecorder) Record(record *mtproto.TrafficRecord) {
+	if record.Direction != mtproto.TrafficOutgoing || record.Type != r.typ {
+		return
+	}
+	r.mutex.Lock()
+	r.ids = append(r.ids, record.MsgID)
+	r.mutex.Unlock()
+}
+
+func TestResendRequested(t *testing.T) {
+	srv := mtprototest.NewServer(t)
+	var calls atomic.Int32
+	srv.Handle(&echoParams{}, func(request tl.Object) (tl.Object, error) {
+		if calls.Add(1) == 1 {
+			return nil, mtprototest.ErrResend
+		}
+		return echo(request)
+	})
+	sent := &msgIDRecorder{typ: "echoParams"}
+	m := connect(t, srv, mtproto.Config{Recorder: sent})
+
+	resp, err := makeRequest(t, m, &echoParams{Text: "again"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := resp.(*echoResult); !ok || r.Text != "again" {
+		t.Fatalf("got %#v, want the echo of again", resp)
+	}
+	sent.mutex.Lock()
+	defer sent.mutex.Unlock()
+	if len(sent.ids) != 2 || sent.ids[0] != sent.ids[1] {
+		t.Errorf("sent the request as messages %v, want the same message twice", sent.ids)
+	}
+}
+
+func TestPush(t *testing.T) {
+	// over http the server holds an http_wait request until it has something to send
+	for _, connection := range []mtproto.ConnectionType{mtproto.ConnectionTCP, mtproto.ConnectionHTTP} {
+		t.Run(connection.String(), func(t *testing.T) {
+			srv := mtprototest.NewServer(t)
+			srv.Handle(&echoParams{}, echo)
+			m := connect(t, srv, mtproto.Config{Connection: connection})
+			pushed := make(chan string, 1)
+			m.AddCustomServerRequestHandler(func(i any) bool {
+				if r, ok := i.(*echoResult); ok {
+					pushed <- r.Text
+					return true
+				}
+				return false
+			})
+
+			// the server learns the session from the first request
+			if _, err := makeRequest(t, m, &echoParams{}); err != nil {
+				t.Fatal(err)
+			}
+			if err := srv.Push(&echoResult{Text: "update"}); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case text := <-pushed:
+				if text != "update" {
+					t.Errorf("got %q pushed, want update", text)
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatal("the pushed object wasn't handled")
+			}
+		})
+	}
+}
+
+func TestTrafficRecordAndReplay(t *testing.T) {
+	srv := mtprototest.NewServer(t)
+	srv.Handle(&echoParams{}, echo)
+	var traffic bytes.Buffer
+	log := mtproto.NewTrafficLogWriter(&traffic)
+	m := connect(t, srv, mtproto.Config{Recorder: log})
+
+	if _, err := makeRequest(t, m, &echoParams{Text: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	pushed := make(chan string, 10)
+	m.AddCustomServerRequestHandler(func(i any) bool {
+		pushed <- i.(*echoResult).Text
+		return true
+	})
+	if err := srv.Push(&echoResult{Text: "update"}); err != nil {
+		t.Fatal(err)
+	}
+	<-pushed
+	m.Terminate()
+	if err := log.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[string]mtproto.TrafficDirection)
+	reader := mtproto.NewTrafficLogReader(bytes.NewReader(traffic.Bytes()))
+	for {
+		r, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[r.Type] = r.Direction
+	}
+	for typ, direction := range map[string]mtproto.TrafficDirection{
+		"ReqPQParams":           mtproto.TrafficOutgoing,
+		"ResPQ":                 mtproto.TrafficIncoming,
+		"echoParams":            mtproto.TrafficOutgoing,
+		"RpcResult(echoResult)": mtproto.TrafficIncoming,
+		"echoResult":            mtproto.TrafficIncoming,
+	} {
+		if seen[typ] != direction {
+			t.Errorf("%s was recorded as %s, want %s", typ, seen[typ], direction)
+		}
+	}
+
+	// only the pushed object reaches the handlers again
+	if err := m.ReplayTraffic(bytes.NewReader(traffic.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	close(pushed)
+	var replayed []string
+	for text := range pushed {
+		replayed = append(replayed, text)
+	}
+	if len(replayed) != 1 || replayed[0] != "update" {
+		t.Errorf("replayed %q, want the update", replayed)
+	}
+}
+
+// memoryLogger keeps the messages logged at info level and above
+type memoryLogger struct {
+	mutex    sync.Mutex
+	messages []map[string]any
+}
+
+func (l *memoryLogger) Enabled(level mtproto.LogLevel) bool {
+	return level >= mtproto.LevelInfo
+}
+
+func (l *memoryLogger) Log(level mtproto.LogLevel, msg string, fields ...any) {
+	m := map[string]any{"msg": msg}
+	for i := 0; i+1 < len(fields); i += 2 {
+		m[fields[i].(string)] = fields[i+1]
+	}
+	l.mutex.Lock()
+	l.messages = append(l.messages, m)
+	l.mutex.Unlock()
+}
+
+func TestLoggerFields(t *testing.T) {
+	srv := mtprototest.NewServer(t)
+	logger := &memoryLogger{}
+	connect(t, srv, mtproto.Config{Logger: logger}).Terminate()
+
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	for _, m := range logger.messages {
+		if m["msg"] == "terminating connection" {
+			if m["component"] != "mtproto" || m["dc"] != 2 {
+				t.Errorf("logged %v, want the component and dc", m)
+			}
+			return
+		}
+	}
+	t.Errorf("terminating the connection wasn't logged, got %v", logger.messages)
+}
+
+func keyID(key []byte) int64 {
+	return int64(binary.LittleEndian.Uint64(utils.AuthKeyHash(key)))
+}
+
+func TestPFS(t *testing.T) {
+	srv := mtprototest.NewServer(t)
+	srv.Handle(&echoParams{}, echo)
+	// rotated when a tenth of the lifetime is left, after 2.7s
+	m := connect(t, srv, mtproto.Config{PFS: true, TempKeyTTL: 3 * time.Second})
+	rebound := make(chan struct{}, 1)
+	m.OnTempKeyBound(func() { rebound <- struct{}{} })
+	permKey, _, _, _, _ := m.ExportAuth()
+
+	if _, err := makeRequest(t, m, &echoParams{Text: "first key"}); err != nil {
+		t.Fatal(err)
+	}
+	keys := srv.TempKeys()
+	if len(keys) != 1 {
+		t.Fatalf("%d temp keys were created, want 1", len(keys))
+	}
+	first := keys[0]
+	if first.ID != keyID(m.GetAuthKey()) || first.ID == keyID(permKey) {
+		t.Error("messages aren't encrypted with the temp key")
+	}
+	if first.PermID != keyID(permKey) {
+		t.Errorf("the temp key is bound to %d, want the permanent key %d", first.PermID, keyID(permKey))
+	}
+	if first.Requests != 1 {
+		t.Errorf("%d requests were sent with the temp key, want 1", first.Requests)
+	}
+
+	select {
+	case <-rebound:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the temp key wasn't rotated")
+	}
+	if _, err := makeRequest(t, m, &echoParams{Text: "second key"}); err != nil {
+		t.Fatal(err)
+	}
+	keys = srv.TempKeys()
+	if len(keys) != 2 {
+		t.Fatalf("%d temp keys were created, want 2", len(keys))
+	}
+	if second := keys[1]; second.ID != keyID(m.GetAuthKey()) || second.PermID != keyID(permKey) || second.Requests != 1 {
+		t.Errorf("after the rotation the temp key is %+v, want a new one bound to %d with the request", second, keyID(permKey))
+	}
+}
+
+func TestBadMsgIDTooLow(t *testing.T) {
+	srv := mtprototest.NewServer(t)
+	srv.Handle(&echoParams{}, echo)
+	var dials atomic.Int32
+	m := connect(t, srv, mtproto.Config{
+		ServerHost: "127.0.0.1:443",
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dials.Add(1)
+			return srv.Dial(ctx, network, addr)
+		},
+	})
+
+	// the server clock jumps ahead after the key exchange, so the ids of the client are too low
+	srv.SetClock(10 * time.Minute)
+	resp, err := makeRequest(t, m, &echoParams{Text: "late"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := resp.(*echoResult); !ok || r.Text != "late" {
+		t.Fatalf("got %#v, want the echo of late", resp)
+	}
+	if offset := m.ServerTimeOffset(); (offset - 10*time.Minute).Abs() > 5*time.Second {
+		t.Errorf("server time offset is %s, want 10m", offset)
+	}
+	if n := dials.Load(); n != 1 || m.State() != mtproto.StateConnected {
+		t.Errorf("the connection was reset, %d dials and state %s", n, m.State())
+	}
+}
+
+// echoAll makes the requests at the same time and checks their answers
+func echoAll(t *testing.T, m *mtproto.MTProto, texts ...string) {
+	t.Helper()
+	var wg sync.WaitGroup
+	errs := make(chan error, len(texts))
+	for _, text := range texts {
+		wg.Add(1)
+		go func(text string) {
+			defer wg.Done()
+			resp, err := makeRequest(t, m, &echoParams{Text: text})
+			if r, ok := resp.(*echoResult); err == nil && (!ok || r.Text != text) {
+				err = fmt.Errorf("got %#v, want the echo of %s", resp, text)
+			}
+			errs <- err
+		}(text)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// countContents returns how many echo requests and acks the container holds
+func countContents(batch []tl.Object) (echoes, acks int) {
+	for _, obj := range batch {
+		switch obj.(type) {
+		case *echoParams:
+			echoes++
+		case *objects.MsgsAck:
+			acks++
+		}
+	}
+	return echoes, acks
+}
+
+func TestContainer(t *testing.T) {
+	srv := mtprototest.NewServer(t)
+	srv.Handle(&echoParams{}, echo)
+	m := connect(t, srv, mtproto.Config{})
+
+	// the answer is acknowledged with the next batch
+	if _, err := makeRequest(t, m, &echoParams{Text: "first"}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	echoAll(t, m, "a", "b", "c")
+
+	for _, batch := range srv.Containers() {
+		if echoes, acks := countContents(batch); echoes == 3 && acks == 1 {
+			return
+		}
+	}
+	t.Errorf("no container holds the three requests and the ack, got %v", srv.Containers())
+}
+
+func TestBadServerSaltForContainer(t *testing.T) {
+	srv := mtprototest.NewServer(t)
+	var calls atomic.Int32
+	srv.Handle(&echoParams{}, func(request tl.Object) (tl.Object, error) {
+		calls.Add(1)
+		return echo(request)
+	})
+	m := connect(t, srv, mtproto.Config{})
+	if _, err := makeRequest(t, m, &echoParams{Text: "first"}); err != nil {
+		t.Fatal(err)
+	}
+	sent := len(srv.Containers())
+
+	// the container is rejected as a whole, every request inside is repeated with the new salt
+	srv.SetSalt(42)
+	echoAll(t, m, "a", "b", "c")
+	if m.GetServerSalt() != 42 {
+		t.Errorf("server salt is %d, want 42", m.GetServerSalt())
+	}
+	if n := calls.Load(); n != 4 {
+		t.Errorf("the server answered %d requests, want 4", n)
+	}
+	if batches := srv.Containers(); len(batches) == sent {
+		t.Error("the requests weren't sent in a container")
+	} else if echoes, _ := countContents(batches[sent]); echoes != 3 {
+		t.Errorf("the rejected container holds %d requests, want 3", echoes)
+	}
+}
+
+func TestReconnectWithoutPong(t *testing.T) {
+	srv := mtprototest.NewServer(t)
+	srv.Handle(&echoParams{}, echo)
+	m := connect(t, srv, mtproto.Config{PingInterval: 200 * time.Millisecond})
+	states := make(chan mtproto.ConnectionState, 16)
+	m.OnStateChange(func(state mtproto.ConnectionState) {
+		select {
+		case states <- state:
+		default:
+		}
+	})
+
+	// the pong of the keepalive ping doesn't come back, so the connection is considered dead
+	srv.DropPongs(true)
+	for _, want := range []mtproto.ConnectionState{mtproto.StateReconnecting, mtproto.StateConnected} {
+		select {
+		case state := <-states:
+			if state != want {
+				t.Fatalf("state changed to %s, want %s", state, want)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("the state didn't change to %s", want)
+		}
+		srv.DropPongs(false)
+	}
+	if _, err := makeRequest(t, m, &echoParams{Text: "again"}); err != nil {
+		t.Fatal(err)
+	}
+}
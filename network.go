@@ -3,29 +3,40 @@
 package gogram
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/jwillp/gogram/internal/encoding/tl"
 	"github.com/jwillp/gogram/internal/mtproto/messages"
 	"github.com/jwillp/gogram/internal/mtproto/objects"
-	"github.com/jwillp/gogram/internal/session"
 	"github.com/jwillp/gogram/internal/utils"
+	"github.com/jwillp/gogram/session"
 	"github.com/pkg/errors"
 )
 
-func (m *MTProto) sendPacket(request tl.Object, expectedTypes ...reflect.Type) (chan tl.Object, error) {
+// sendPacket writes the request and returns the channel its response will be delivered to,
+// along with the message id the channel is registered under
+func (m *MTProto) sendPacket(request tl.Object, expectedTypes ...reflect.Type) (chan tl.Object, int64, error) {
+	return m.sendPacketWithID(request, m.nextMessageID(), expectedTypes...)
+}
+
+// nextMessageID returns a message id greater than all the previous ones
+func (m *MTProto) nextMessageID() int64 {
+	m.lastMessageIDMutex.Lock()
+	defer m.lastMessageIDMutex.Unlock()
+	m.lastMessageID = utils.GenerateMessageId(m.lastMessageID, time.Duration(m.timeOffset.Load()))
+	return m.lastMessageID
+}
+
+// sendPacketWithID is sendPacket with the message id chosen by the caller
+func (m *MTProto) sendPacketWithID(request tl.Object, msgID int64, expectedTypes ...reflect.Type) (chan tl.Object, int64, error) {
 	msg, err := tl.Marshal(request)
 	if err != nil {
-		return nil, errors.Wrap(err, "marshaling request")
+		return nil, 0, errors.Wrap(err, "marshaling request")
 	}
-	m.lastMessageIDMutex.Lock()
-	var (
-		data  messages.Common
-		msgID = utils.GenerateMessageId(m.lastMessageID)
-	)
-	m.lastMessageIDMutex.Unlock()
-	m.lastMessageID = msgID
+	var data messages.Common
 
 	// adding types for parser if required
 	if len(expectedTypes) > 0 {
@@ -40,7 +51,9 @@ func (m *MTProto) sendPacket(request tl.Object, expectedTypes ...reflect.Type) (
 		m.responseChannels.Add(int(msgID), resp)
 	}
 
-	if m.encrypted {
+	// key exchange messages are never encrypted, even when a key already exists
+	encrypted := m.encrypted && !m.serviceModeActivated
+	if encrypted {
 		data = &messages.Encrypted{
 			Msg:         msg,
 			MsgID:       msgID,
@@ -53,40 +66,58 @@ func (m *MTProto) sendPacket(request tl.Object, expectedTypes ...reflect.Type) (
 		}
 	}
 	seqNo := m.UpdateSeqNo()
-	if !m.encrypted {
+	if !encrypted {
 		seqNo = 0
 	}
-	if m.transport == nil {
-		return nil, errors.New("transport is nil, please use SetTransport")
+	t := m.currentTransport()
+	if t == nil {
+		return nil, 0, errors.New("transport is nil, please use SetTransport")
+	}
+	if encrypted && !sentAlone(request) {
+		outgoing := &outgoingMessage{msgID: msgID, seqNo: seqNo, body: msg, content: MessageRequireToAck(request), request: request}
+		if !isNullableResponse(request) {
+			m.sendQueue.track(outgoing)
+		}
+		m.sendQueue.push(outgoing)
+		return resp, msgID, nil
 	}
-	errorSendPacket := m.transport.WriteMsg(data, MessageRequireToAck(request), seqNo)
+	errorSendPacket := t.WriteMsg(data, MessageRequireToAck(request), seqNo)
 	if errorSendPacket != nil {
-		return nil, fmt.Errorf("writing message: %w", errorSendPacket)
+		return nil, 0, fmt.Errorf("writing message: %w", errorSendPacket)
 	}
-	return resp, nil
+	if encrypted && MessageRequireToAck(request) {
+		seqNo |= 1
+	}
+	m.instrumentation.PacketSent(m.GetDC(), len(msg))
+	m.record(TrafficOutgoing, msgID, seqNo, request, msg)
+	return resp, msgID, nil
 }
 
-func (m *MTProto) writeRPCResponse(msgID int, data tl.Object) error {
+// writeRPCResponse delivers the result to the caller waiting for it, it reports false when nobody
+// waits anymore, because the request was canceled or failed before the result came
+func (m *MTProto) writeRPCResponse(msgID int, data tl.Object) bool {
 	v, ok := m.responseChannels.Get(msgID)
 	if !ok {
-		return errors.New("no response channel found for messageId " + fmt.Sprint(msgID))
+		return false
 	}
 	v <- data
 	m.responseChannels.Delete(msgID)
 	m.expectedTypes.Delete(msgID)
-	return nil
+	m.sendQueue.forget(int64(msgID))
+	return true
 }
 
 func (m *MTProto) getRespChannel() chan tl.Object {
 	if m.serviceModeActivated {
 		return m.serviceChannel
 	}
-	return make(chan tl.Object)
+	// buffered, so a response arriving after the caller gave up doesn't block the reader
+	return make(chan tl.Object, 1)
 }
 
 func isNullableResponse(t tl.Object) bool {
 	switch t.(type) {
-	case *objects.Pong, *objects.MsgsAck:
+	case *objects.PingParams, *objects.PingDelayDisconnectParams, *objects.MsgsAck, *objects.HttpWaitParams, *objects.MsgsStateInfo, *objects.MsgResendReq:
 		return true
 	default:
 		return false
@@ -99,6 +130,8 @@ func (m *MTProto) GetSessionID() int64 {
 
 // GetSeqNo returns seqno 🧐
 func (m *MTProto) GetSeqNo() int32 {
+	m.seqNoMutex.Lock()
+	defer m.seqNoMutex.Unlock()
 	return m.seqNo
 }
 
@@ -115,8 +148,11 @@ func (m *MTProto) GetServerSalt() int64 {
 	return m.serverSalt
 }
 
-// GetAuthKey returns decryption key of current session salt 🧐
+// GetAuthKey returns the key messages are encrypted with, the temporary key when PFS is on
 func (m *MTProto) GetAuthKey() []byte {
+	if temp := m.tempKey.Load(); temp != nil {
+		return temp.key
+	}
 	return m.authKey
 }
 
@@ -129,6 +165,12 @@ func (m *MTProto) MakeRequest(msg tl.Object) (any, error) {
 	return m.makeRequest(msg)
 }
 
+// MakeRequestCtx is like MakeRequest, but stops waiting for the response once ctx is done,
+// in which case ctx.Err() is returned
+func (m *MTProto) MakeRequestCtx(ctx context.Context, msg tl.Object) (any, error) {
+	return m.makeRequestCtx(ctx, msg)
+}
+
 func (m *MTProto) MakeRequestWithHintToDecoder(msg tl.Object, expectedTypes ...reflect.Type) (any, error) {
 	if len(expectedTypes) == 0 {
 		return nil, errors.New("expected a few hints. If you don't need it, use m.MakeRequest")
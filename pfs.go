@@ -0,0 +1,137 @@
+package gogram
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"time"
+
+	ige "github.com/jwillp/gogram/internal/aes_ige"
+	"github.com/jwillp/gogram/internal/encoding/tl"
+	"github.com/jwillp/gogram/internal/mtproto/objects"
+	"github.com/jwillp/gogram/internal/utils"
+	"github.com/pkg/errors"
+)
+
+// perfect forward secrecy: messages are encrypted with a temporary key bound to the permanent one,
+// so a leaked permanent key doesn't reveal past traffic
+// https://core.telegram.org/api/pfs
+
+const defaultTempKeyTTL = 24 * time.Hour
+
+type tempAuthKey struct {
+	key       []byte
+	expiresAt time.Time
+}
+
+// expiring reports whether the key should be replaced, the last tenth of its lifetime is left for that
+func (k *tempAuthKey) expiring(ttl time.Duration) bool {
+	return time.Until(k.expiresAt) < ttl/10
+}
+
+// ensureTempKey creates and binds a new temporary key when there is none or the current one is expiring,
+// it's called on every connect, so reconnects re-bind transparently
+func (m *MTProto) ensureTempKey() error {
+	if temp := m.tempKey.Load(); temp != nil && !temp.expiring(m.tempKeyTTL) {
+		return nil
+	}
+	m.tempKey.Store(nil)
+
+	ttl := m.tempKeyTTL
+	key, salt, err := m.exchangeKey(int32(ttl.Seconds()))
+	if err != nil {
+		return errors.Wrap(err, "creating temp auth key")
+	}
+	temp := &tempAuthKey{key: key, expiresAt: time.Now().Add(ttl)}
+	m.tempKey.Store(temp)
+	m.serverSalt = salt
+
+	if err := m.bindTempKey(temp); err != nil {
+		m.tempKey.Store(nil)
+		return errors.Wrap(err, "binding temp auth key")
+	}
+	m.Logger.Debug("temp auth key bound", "expires_at", temp.expiresAt.Format(time.RFC3339))
+	if handler := m.tempKeyHandler.Load(); handler != nil && m.tempKeyBound {
+		go (*handler)()
+	}
+	m.tempKeyBound = true
+	return nil
+}
+
+// OnTempKeyBound sets a function called after a new temporary key replaced the previous one,
+// the connection has to be initialized again then (initConnection)
+func (m *MTProto) OnTempKeyBound(handler func()) {
+	m.tempKeyHandler.Store(&handler)
+}
+
+// bindTempKey sends auth.bindTempAuthKey, encrypted with the temporary key
+func (m *MTProto) bindTempKey(temp *tempAuthKey) error {
+	nonceBytes := make([]byte, 8)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return errors.Wrap(err, "generating nonce")
+	}
+	nonce := int64(binary.LittleEndian.Uint64(nonceBytes))
+	permKeyID := int64(binary.LittleEndian.Uint64(utils.AuthKeyHash(m.authKey)))
+	expiresAt := int32(temp.expiresAt.Unix())
+
+	inner, err := tl.Marshal(&objects.BindAuthKeyInner{
+		Nonce:         nonce,
+		TempAuthKeyID: int64(binary.LittleEndian.Uint64(utils.AuthKeyHash(temp.key))),
+		PermAuthKeyID: permKeyID,
+		TempSessionID: m.sessionId,
+		ExpiresAt:     expiresAt,
+	})
+	if err != nil {
+		return errors.Wrap(err, "marshaling bind_auth_key_inner")
+	}
+	msgID := m.nextMessageID()
+	encrypted, err := ige.EncryptBindMessage(inner, m.authKey, msgID)
+	if err != nil {
+		return errors.Wrap(err, "encrypting bind_auth_key_inner")
+	}
+
+	resp, _, err := m.sendPacketWithID(&objects.BindTempAuthKeyParams{
+		PermAuthKeyID:    permKeyID,
+		Nonce:            nonce,
+		ExpiresAt:        expiresAt,
+		EncryptedMessage: encrypted,
+	}, msgID)
+	if err != nil {
+		return errors.Wrap(err, "sending auth.bindTempAuthKey")
+	}
+
+	select {
+	case response := <-resp:
+		switch r := response.(type) {
+		case *objects.RpcError:
+			return RpcErrorToNative(r)
+		case *errorSessionConfigsChanged:
+			return errors.New("session changed while binding")
+		}
+		return nil
+	case <-time.After(defaultTimeout):
+		m.responseChannels.Delete(int(msgID))
+		return errors.New("auth.bindTempAuthKey timed out")
+	}
+}
+
+// rotateTempKey reconnects with a new temporary key shortly before the current one expires
+func (m *MTProto) rotateTempKey(ctx context.Context) {
+	temp := m.tempKey.Load()
+	if temp == nil {
+		return
+	}
+	m.routineswg.Add(1)
+	go func() {
+		defer m.routineswg.Done()
+		wait := time.Until(temp.expiresAt) - m.tempKeyTTL/10
+		if err := sleepCtx(ctx, wait); err != nil {
+			return
+		}
+		m.Logger.Debug("temp auth key expires soon, rotating", "expires_in", time.Until(temp.expiresAt).Round(time.Second))
+		m.tempKey.Store(nil)
+		if err := m.Reconnect(false); err != nil {
+			m.Logger.Error("rotating temp auth key", "error", err)
+		}
+	}()
+}
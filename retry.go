@@ -0,0 +1,121 @@
+// Copyright (c) 2023 RoseLoverX
+
+package gogram
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxFloodWait = 60 * time.Second
+	defaultMaxRetries   = 5
+	defaultBackoff      = 500 * time.Millisecond
+	defaultMaxBackoff   = 10 * time.Second
+)
+
+// RetryPolicy controls how requests which failed with a flood wait or a transient
+// server error are retried. Zero fields take their defaults.
+type RetryPolicy struct {
+	// MaxFloodWait is the longest FLOOD_WAIT_X slept through before retrying, longer waits
+	// are returned as *FloodWaitError. 60 seconds by default, negative never sleeps.
+	MaxFloodWait time.Duration
+	// MaxRetries caps the retries of a single request, 5 by default, negative disables retries.
+	MaxRetries int
+	// Backoff is the delay before the first retry of a transient error (INTERDC_X_CALL_ERROR,
+	// internal server errors, timeouts), doubled on every next retry. 500ms by default.
+	Backoff time.Duration
+	// MaxBackoff caps the backoff, 10 seconds by default.
+	MaxBackoff time.Duration
+	// OnRetry, if set, is called before every retry, e.g. to record metrics.
+	OnRetry func(RetryEvent)
+}
+
+// RetryEvent describes a retry which is about to happen
+type RetryEvent struct {
+	Method  string        // name of the request, e.g. MessagesSendMessage
+	Attempt int           // number of the retry, starting from 1
+	Err     error         // error which caused the retry
+	Delay   time.Duration // time slept before the retry
+}
+
+// FloodWaitError is returned when the server asks to wait longer than RetryPolicy.MaxFloodWait,
+// or when the request kept hitting flood waits after all retries.
+type FloodWaitError struct{ WaitError }
+
+func (e *FloodWaitError) Error() string {
+	return fmt.Sprintf("flood wait of %s required: %s", e.Wait, e.Err.Error())
+}
+
+// withDefaults returns a copy of the policy with zero fields set to their defaults
+func (p *RetryPolicy) withDefaults() *RetryPolicy {
+	r := RetryPolicy{}
+	if p != nil {
+		r = *p
+	}
+	if r.MaxFloodWait == 0 {
+		r.MaxFloodWait = defaultMaxFloodWait
+	}
+	if r.MaxRetries == 0 {
+		r.MaxRetries = defaultMaxRetries
+	}
+	if r.Backoff <= 0 {
+		r.Backoff = defaultBackoff
+	}
+	if r.MaxBackoff <= 0 {
+		r.MaxBackoff = defaultMaxBackoff
+	}
+	return &r
+}
+
+// backoff returns the delay before n-th (starting from 0) retry of a transient error
+func (p *RetryPolicy) backoff(n int) time.Duration {
+	d := p.Backoff
+	for i := 0; i < n && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// floodWait returns the wait requested by a flood wait error
+func floodWait(err *ErrResponseCode) (time.Duration, bool) {
+	if fw, ok := err.typed().(*FloodWaitError); ok {
+		return fw.Wait, true
+	}
+	return 0, false
+}
+
+// isTransientError reports whether the request may succeed if it's simply sent again
+func isTransientError(err *ErrResponseCode) bool {
+	return err.Code >= 500 || err.Code == -503 || strings.HasPrefix(err.Message, "INTERDC_")
+}
+
+// requestName returns a readable name of the request for logs
+func requestName(data any) string {
+	t := reflect.TypeOf(data)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return strings.TrimSuffix(t.Name(), "Params")
+}
+
+// sleepCtx sleeps for d, returning early with ctx.Err() if ctx is done
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
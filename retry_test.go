@@ -0,0 +1,134 @@
+package gogram_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mtproto "github.com/jwillp/gogram"
+	"github.com/jwillp/gogram/internal/encoding/tl"
+	"github.com/jwillp/gogram/internal/mtprototest"
+)
+
+// failing answers the first n echo requests with err, the later ones with their echo
+func failing(n int32, err error) (mtprototest.HandlerFunc, *atomic.Int32) {
+	calls := new(atomic.Int32)
+	return func(request tl.Object) (tl.Object, error) {
+		if calls.Add(1) <= n {
+			return nil, err
+		}
+		return echo(request)
+	}, calls
+}
+
+// retryRecorder keeps the retry events of a policy
+type retryRecorder struct {
+	mutex  sync.Mutex
+	events []mtproto.RetryEvent
+}
+
+func (r *retryRecorder) record(e mtproto.RetryEvent) {
+	r.mutex.Lock()
+	r.events = append(r.events, e)
+	r.mutex.Unlock()
+}
+
+func (r *retryRecorder) retries() []mtproto.RetryEvent {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]mtproto.RetryEvent(nil), r.events...)
+}
+
+func TestFloodWaitIsSleptThrough(t *testing.T) {
+	srv := mtprototest.NewServer(t)
+	handler, calls := failing(1, &mtprototest.RPCError{Code: 420, Message: "FLOOD_WAIT_1"})
+	srv.Handle(&echoParams{}, handler)
+	var retries retryRecorder
+	m := connect(t, srv, mtproto.Config{RetryPolicy: &mtproto.RetryPolicy{MaxFloodWait: 2 * time.Second, OnRetry: retries.record}})
+
+	start := time.Now()
+	resp, err := makeRequest(t, m, &echoParams{Text: "later"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := resp.(*echoResult); !ok || r.Text != "later" {
+		t.Fatalf("got %#v, want the echo of later", resp)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %s, before the flood wait was over", elapsed)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("the request reached the server %d times, want 2", n)
+	}
+	events := retries.retries()
+	var floodErr *mtproto.FloodWaitError
+	if len(events) != 1 || events[0].Delay != time.Second || events[0].Method != "echo" || !errors.As(events[0].Err, &floodErr) {
+		t.Errorf("retries %+v, want one after the flood wait of echo", events)
+	}
+}
+
+func TestFloodWaitOverThreshold(t *testing.T) {
+	srv := mtprototest.NewServer(t)
+	handler, calls := failing(1, &mtprototest.RPCError{Code: 420, Message: "FLOOD_WAIT_30"})
+	srv.Handle(&echoParams{}, handler)
+	m := connect(t, srv, mtproto.Config{RetryPolicy: &mtproto.RetryPolicy{MaxFloodWait: time.Second}})
+
+	start := time.Now()
+	_, err := makeRequest(t, m, &echoParams{})
+	var floodErr *mtproto.FloodWaitError
+	if !errors.As(err, &floodErr) || floodErr.Wait != 30*time.Second {
+		t.Fatalf("got %v, want the flood wait of 30s", err)
+	}
+	if !errors.Is(err, mtproto.ErrFloodWait) {
+		t.Errorf("%v isn't ErrFloodWait", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the error came after %s, the wait shouldn't be slept through", elapsed)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("the request reached the server %d times, want 1", n)
+	}
+}
+
+func TestRetriesStopAtMaxRetries(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		maxRetries int
+		wantCalls  int32
+	}{
+		{"capped", 2, 3},
+		{"disabled", -1, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := mtprototest.NewServer(t)
+			handler, calls := failing(100, &mtprototest.RPCError{Code: 500, Message: "INTERNAL"})
+			srv.Handle(&echoParams{}, handler)
+			var retries retryRecorder
+			m := connect(t, srv, mtproto.Config{RetryPolicy: &mtproto.RetryPolicy{
+				MaxRetries: tc.maxRetries,
+				Backoff:    time.Millisecond,
+				OnRetry:    retries.record,
+			}})
+
+			_, err := makeRequest(t, m, &echoParams{})
+			var rpcErr *mtproto.ErrResponseCode
+			if !errors.As(err, &rpcErr) || rpcErr.Code != 500 {
+				t.Fatalf("got %v, want the internal error", err)
+			}
+			if n := calls.Load(); n != tc.wantCalls {
+				t.Errorf("the request reached the server %d times, want %d", n, tc.wantCalls)
+			}
+			events := retries.retries()
+			if len(events) != int(tc.wantCalls)-1 {
+				t.Fatalf("%d retries, want %d", len(events), tc.wantCalls-1)
+			}
+			for i, e := range events {
+				if e.Attempt != i+1 {
+					t.Errorf("retry %d has attempt %d", i, e.Attempt)
+				}
+			}
+		})
+	}
+}
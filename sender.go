@@ -0,0 +1,421 @@
+package gogram
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jwillp/gogram/internal/encoding/tl"
+	"github.com/jwillp/gogram/internal/mtproto/messages"
+	"github.com/jwillp/gogram/internal/mtproto/objects"
+	"github.com/pkg/errors"
+)
+
+// encrypted messages are queued and written by one goroutine per connection, messages sent at about
+// the same time go out in a single msg_container together with the pending acknowledgements
+// https://core.telegram.org/mtproto/service_messages#containers
+
+const (
+	sendBatchDelay    = 2 * time.Millisecond   // how long the first queued message waits for others
+	ackFlushDelay     = 200 * time.Millisecond // acknowledgements alone may wait longer, they usually ride along
+	maxContainerSize  = 1020                   // messages in a container, limited by the server
+	maxContainerBytes = 64 * 1024              // bigger batches are split, bigger messages are sent alone
+	maxAcksPerMessage = 8192
+	containerLifetime = 5 * time.Minute // the server rejects older message ids anyway
+	maxResendAge      = 4 * time.Minute // older requests are repeated with new ids instead of being resent
+)
+
+type outgoingMessage struct {
+	msgID   int64
+	seqNo   int32
+	body    []byte
+	content bool      // requires an acknowledgement, odd seqno
+	queued  bool      // waiting in the queue
+	acked   bool      // the server confirmed it received the message
+	request tl.Object // what body holds, for the traffic recorder
+}
+
+// sendQueue holds the messages waiting to be written and remembers which messages went out in which container
+type sendQueue struct {
+	mutex      sync.Mutex
+	messages   []*outgoingMessage
+	size       int
+	acks       []int64
+	wake       chan struct{}
+	containers map[int64][]int64          // container msg id -> ids of the messages inside
+	inFlight   map[int64]*outgoingMessage // sent requests waiting for their response
+}
+
+func newSendQueue() *sendQueue {
+	return &sendQueue{
+		wake:       make(chan struct{}, 1),
+		containers: make(map[int64][]int64),
+		inFlight:   make(map[int64]*outgoingMessage),
+	}
+}
+
+func (q *sendQueue) push(msg *outgoingMessage) {
+	q.mutex.Lock()
+	msg.queued = true
+	q.messages = append(q.messages, msg)
+	q.size += len(msg.body)
+	q.mutex.Unlock()
+	q.signal()
+}
+
+func (q *sendQueue) pushAck(msgID int64) {
+	q.mutex.Lock()
+	q.acks = append(q.acks, msgID)
+	q.mutex.Unlock()
+	q.signal()
+}
+
+func (q *sendQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// full reports whether the queue holds enough to fill a container
+func (q *sendQueue) full() bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return len(q.messages) >= maxContainerSize-1 || q.size >= maxContainerBytes
+}
+
+// delay returns how long the queue may wait before it's flushed, false if it's empty
+func (q *sendQueue) delay() (time.Duration, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	switch {
+	case len(q.messages) > 0:
+		return sendBatchDelay, true
+	case len(q.acks) > 0:
+		return ackFlushDelay, true
+	}
+	return 0, false
+}
+
+// take removes the next batch from the queue, one place in the container is left for the acknowledgements
+func (q *sendQueue) take() ([]*outgoingMessage, []int64) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	n, size := 0, 0
+	for n < len(q.messages) && n < maxContainerSize-1 {
+		s := len(q.messages[n].body)
+		if n > 0 && size+s > maxContainerBytes {
+			break
+		}
+		size += s
+		q.messages[n].queued = false
+		n++
+	}
+	msgs := q.messages[:n:n]
+	if q.messages = q.messages[n:]; len(q.messages) == 0 {
+		q.messages = nil
+	}
+	q.size -= size
+
+	acks := q.acks
+	if len(acks) > maxAcksPerMessage {
+		acks, q.acks = acks[:maxAcksPerMessage:maxAcksPerMessage], acks[maxAcksPerMessage:]
+	} else {
+		q.acks = nil
+	}
+	return msgs, acks
+}
+
+func (q *sendQueue) remember(containerID int64, ids []int64) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	// message ids start with the unix time they were made at
+	cutoff := time.Now().Add(-containerLifetime).Unix() << 32
+	for id := range q.containers {
+		if id < cutoff {
+			delete(q.containers, id)
+		}
+	}
+	q.containers[containerID] = ids
+}
+
+// contents returns the ids of the messages sent in the container, or just msgID if it isn't a container
+func (q *sendQueue) contents(msgID int64) []int64 {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.contentsLocked(msgID)
+}
+
+func (q *sendQueue) contentsLocked(msgID int64) []int64 {
+	if ids, ok := q.containers[msgID]; ok {
+		return ids
+	}
+	return []int64{msgID}
+}
+
+// track remembers the request until its response arrives, to send it again after a reconnect
+func (q *sendQueue) track(msg *outgoingMessage) {
+	q.mutex.Lock()
+	q.inFlight[msg.msgID] = msg
+	q.mutex.Unlock()
+}
+
+func (q *sendQueue) forget(msgID int64) {
+	q.mutex.Lock()
+	delete(q.inFlight, msgID)
+	q.mutex.Unlock()
+}
+
+// acknowledged marks the messages as received by the server, they aren't sent again
+func (q *sendQueue) acknowledged(msgIDs []int64) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for _, id := range msgIDs {
+		for _, inner := range q.contentsLocked(id) {
+			if msg, ok := q.inFlight[inner]; ok {
+				msg.acked = true
+			}
+		}
+	}
+}
+
+// unconfirmed returns the requests the server didn't confirm, which aren't queued already,
+// and the ids of the requests made before tooOld, whether confirmed or not
+func (q *sendQueue) unconfirmed(tooOld int64) (resend []*outgoingMessage, stale []int64) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for id, msg := range q.inFlight {
+		switch {
+		case id < tooOld:
+			stale = append(stale, id)
+		case !msg.acked && !msg.queued:
+			resend = append(resend, msg)
+		}
+	}
+	sort.Slice(resend, func(i, j int) bool { return resend[i].msgID < resend[j].msgID })
+	return resend, stale
+}
+
+// sent returns the requests which went out as the messages or inside the containers of msgIDs
+// and aren't queued already, ids of messages already answered are skipped
+func (q *sendQueue) sent(msgIDs []int64) []*outgoingMessage {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	var msgs []*outgoingMessage
+	for _, id := range msgIDs {
+		for _, inner := range q.contentsLocked(id) {
+			if msg, ok := q.inFlight[inner]; ok && !msg.queued {
+				msgs = append(msgs, msg)
+			}
+		}
+	}
+	return msgs
+}
+
+// unanswered returns the ids of all requests waiting for their response
+func (q *sendQueue) unanswered() []int64 {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	ids := make([]int64, 0, len(q.inFlight))
+	for id := range q.inFlight {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (q *sendQueue) resetInFlight() {
+	q.mutex.Lock()
+	q.inFlight = make(map[int64]*outgoingMessage)
+	q.mutex.Unlock()
+}
+
+// sentAlone reports whether the request is written right away instead of being queued: http_wait holds
+// the http connection until the server has something to say, and the temporary key must be bound
+// before anything else is encrypted with it
+func sentAlone(request tl.Object) bool {
+	switch request.(type) {
+	case *objects.HttpWaitParams, *objects.BindTempAuthKeyParams:
+		return true
+	default:
+		return false
+	}
+}
+
+// queueAck acknowledges the message with the next batch
+func (m *MTProto) queueAck(msgID int64) {
+	m.sendQueue.pushAck(msgID)
+}
+
+// resendInFlight queues the requests the server didn't confirm again after a reconnect, under the same ids,
+// so the server recognizes repeats, the answers of confirmed ones arrive on the new connection anyway.
+// Requests too old to be resent are repeated by their callers with new ids.
+func (m *MTProto) resendInFlight() {
+	resend, stale := m.sendQueue.unconfirmed(m.serverNow().Add(-maxResendAge).Unix() << 32)
+	for _, id := range stale {
+		m.failMessage(id, &errorConnectionReset{})
+	}
+	for _, msg := range resend {
+		m.sendQueue.push(msg)
+	}
+	if len(resend) > 0 {
+		m.Logger.Debug("resending unconfirmed requests", "count", len(resend))
+	}
+}
+
+// failInFlight makes the callers of all sent requests repeat them, used when the connection couldn't be restored,
+// so they get the error of the connection instead of waiting forever
+func (m *MTProto) failInFlight() {
+	for _, id := range m.sendQueue.unanswered() {
+		m.failMessage(id, &errorConnectionReset{})
+	}
+}
+
+// startSending writes the queued messages until ctx is done, a write error makes it reconnect
+func (m *MTProto) startSending(ctx context.Context) {
+	q := m.sendQueue
+	m.routineswg.Add(1)
+	go func() {
+		defer m.routineswg.Done()
+		timer := time.NewTimer(time.Hour)
+		timer.Stop()
+		var deadline time.Time // zero while the timer isn't running
+		schedule := func(delay time.Duration) {
+			at := time.Now().Add(delay)
+			if !deadline.IsZero() {
+				if !at.Before(deadline) {
+					return
+				}
+				if !timer.Stop() {
+					<-timer.C
+				}
+			}
+			timer.Reset(delay)
+			deadline = at
+		}
+
+		q.signal() // messages queued while disconnected go out right away
+		for {
+			flush := false
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-q.wake:
+				flush = q.full()
+			case <-timer.C:
+				deadline = time.Time{}
+				flush = true
+			}
+			if m.serviceModeActivated {
+				// a key exchange is in progress, nothing encrypted may be sent until it's done
+				schedule(100 * time.Millisecond)
+				continue
+			}
+			if flush {
+				if err := m.flushQueue(); err != nil {
+					// the requests of the failed batch are resent after the reconnect
+					m.Logger.Info("writing failed, reconnecting", "error", err, "addr", m.Addr, "transport", m.transportName())
+					if err := m.Reconnect(false); err != nil {
+						m.Logger.Error("reconnecting", "error", err)
+					}
+					return
+				}
+			}
+			if delay, ok := q.delay(); ok {
+				schedule(delay)
+			}
+		}
+	}()
+}
+
+// flushQueue writes everything queued
+func (m *MTProto) flushQueue() error {
+	for {
+		msgs, acks := m.sendQueue.take()
+		if len(msgs) == 0 && len(acks) == 0 {
+			return nil
+		}
+		if err := m.writeBatch(msgs, acks); err != nil {
+			return err
+		}
+	}
+}
+
+// writeBatch writes a single message as is and more of them in a container
+func (m *MTProto) writeBatch(msgs []*outgoingMessage, acks []int64) error {
+	if len(acks) > 0 {
+		body, err := tl.Marshal(&objects.MsgsAck{MsgIDs: acks})
+		if err != nil {
+			return errors.Wrap(err, "marshaling acks")
+		}
+		msgs = append(msgs, &outgoingMessage{msgID: m.nextMessageID(), seqNo: m.GetSeqNo(), body: body, request: &objects.MsgsAck{MsgIDs: acks}})
+	}
+	if len(msgs) == 1 {
+		if err := m.writeEncrypted(msgs[0].msgID, msgs[0].body, msgs[0].content, msgs[0].seqNo); err != nil {
+			return err
+		}
+		m.recordBatch(msgs)
+		return nil
+	}
+
+	container := make(objects.MessageContainer, len(msgs))
+	ids := make([]int64, len(msgs))
+	for i, msg := range msgs {
+		seqNo := msg.seqNo
+		if msg.content {
+			seqNo |= 1
+		}
+		container[i] = &messages.Encrypted{MsgID: msg.msgID, SeqNo: seqNo, Msg: msg.body}
+		ids[i] = msg.msgID
+	}
+	body, err := tl.Marshal(&container)
+	if err != nil {
+		return errors.Wrap(err, "marshaling container")
+	}
+	// the container id must be greater than the ids inside
+	containerID := m.nextMessageID()
+	m.sendQueue.remember(containerID, ids)
+	if err := m.writeEncrypted(containerID, body, false, m.GetSeqNo()); err != nil {
+		return err
+	}
+	m.recordBatch(msgs)
+	return nil
+}
+
+func (m *MTProto) recordBatch(msgs []*outgoingMessage) {
+	for _, msg := range msgs {
+		seqNo := msg.seqNo
+		if msg.content {
+			seqNo |= 1
+		}
+		m.record(TrafficOutgoing, msg.msgID, seqNo, msg.request, msg.body)
+	}
+}
+
+func (m *MTProto) writeEncrypted(msgID int64, body []byte, content bool, seqNo int32) error {
+	t := m.currentTransport()
+	if t == nil {
+		return errors.New("transport is nil")
+	}
+	if err := t.WriteMsg(&messages.Encrypted{Msg: body, MsgID: msgID, AuthKeyHash: m.authKeyHash}, content, seqNo); err != nil {
+		return err
+	}
+	m.instrumentation.PacketSent(m.GetDC(), len(body))
+	return nil
+}
+
+// failMessage answers the request with a pseudo response, telling the caller to repeat it
+func (m *MTProto) failMessage(msgID int64, reason tl.Object) {
+	ch, ok := m.responseChannels.Get(int(msgID))
+	if !ok {
+		return
+	}
+	m.responseChannels.Delete(int(msgID))
+	m.expectedTypes.Delete(int(msgID))
+	m.sendQueue.forget(msgID)
+	select {
+	case ch <- reason:
+	default:
+	}
+}
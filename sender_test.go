@@ -0,0 +1,79 @@
+package gogram
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSendQueueTake(t *testing.T) {
+	q := newSendQueue()
+	for i := int64(1); i <= 3; i++ {
+		q.push(&outgoingMessage{msgID: i * 4, body: make([]byte, 16)})
+	}
+	q.pushAck(101)
+	q.pushAck(105)
+
+	msgs, acks := q.take()
+	if len(msgs) != 3 || len(acks) != 2 {
+		t.Fatalf("took %d messages and %d acks, want 3 and 2", len(msgs), len(acks))
+	}
+	for i, msg := range msgs {
+		if msg.msgID != int64(i+1)*4 || msg.queued {
+			t.Errorf("message %d: id %d, queued %t", i, msg.msgID, msg.queued)
+		}
+	}
+	if msgs, acks := q.take(); len(msgs) != 0 || len(acks) != 0 {
+		t.Errorf("the queue isn't empty after taking everything: %d messages, %d acks", len(msgs), len(acks))
+	}
+	if _, ok := q.delay(); ok {
+		t.Error("an empty queue asks to be flushed")
+	}
+}
+
+func TestSendQueueTakeSplits(t *testing.T) {
+	q := newSendQueue()
+	third := maxContainerBytes / 3
+	for i := int64(1); i <= 4; i++ {
+		q.push(&outgoingMessage{msgID: i * 4, body: make([]byte, third)})
+	}
+	q.push(&outgoingMessage{msgID: 20, body: make([]byte, maxContainerBytes+1)})
+	q.push(&outgoingMessage{msgID: 24, body: make([]byte, 16)})
+	if !q.full() {
+		t.Error("the queue isn't full with more than a container of bytes")
+	}
+
+	// the batches stay under the limit, a bigger message goes alone
+	for _, want := range [][]int64{{4, 8, 12}, {16}, {20}, {24}} {
+		msgs, _ := q.take()
+		var ids []int64
+		for _, msg := range msgs {
+			ids = append(ids, msg.msgID)
+		}
+		if len(ids) != len(want) || ids[0] != want[0] || ids[len(ids)-1] != want[len(want)-1] {
+			t.Errorf("took %v, want %v", ids, want)
+		}
+	}
+	if q.size != 0 {
+		t.Errorf("%d bytes left in an empty queue", q.size)
+	}
+}
+
+func TestSendQueueContents(t *testing.T) {
+	q := newSendQueue()
+	now := time.Now().Unix() << 32
+	q.remember(now|4, []int64{now - 8, now - 4})
+	if ids := q.contents(now | 4); len(ids) != 2 || ids[0] != now-8 || ids[1] != now-4 {
+		t.Errorf("container holds %v, want the two messages", ids)
+	}
+	if ids := q.contents(now - 8); len(ids) != 1 || ids[0] != now-8 {
+		t.Errorf("a plain message holds %v, want itself", ids)
+	}
+
+	// containers older than the server accepts are forgotten
+	old := time.Now().Add(-containerLifetime-time.Minute).Unix() << 32
+	q.remember(old, []int64{old - 4})
+	q.remember(now|8, []int64{now})
+	if ids := q.contents(old); len(ids) != 1 || ids[0] != old {
+		t.Errorf("an old container still holds %v", ids)
+	}
+}
@@ -0,0 +1,140 @@
+package gogram
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jwillp/gogram/internal/encoding/tl"
+	"github.com/jwillp/gogram/internal/mtproto/objects"
+)
+
+// handling of the service messages about messages
+// https://core.telegram.org/mtproto/service_messages_about_messages
+
+const receivedHistory = 1024 // incoming message ids remembered for msgs_state_req
+
+// receivedMessages remembers the ids of the latest incoming messages
+type receivedMessages struct {
+	mutex     sync.Mutex
+	ids       map[int64]bool
+	order     []int64
+	forgotten int64 // the greatest id dropped from the history
+}
+
+func newReceivedMessages() *receivedMessages {
+	return &receivedMessages{ids: make(map[int64]bool)}
+}
+
+func (r *receivedMessages) add(msgID int64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.ids[msgID] {
+		return
+	}
+	r.ids[msgID] = true
+	r.order = append(r.order, msgID)
+	if len(r.order) > receivedHistory {
+		oldest := r.order[0]
+		r.order = r.order[1:]
+		delete(r.ids, oldest)
+		if oldest > r.forgotten {
+			r.forgotten = oldest
+		}
+	}
+}
+
+func (r *receivedMessages) has(msgID int64) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.ids[msgID]
+}
+
+// states returns the msgs_state_info byte of every id: 1 nothing is known, 2 not received,
+// 3 not received and the id is too high, 4 received
+func (r *receivedMessages) states(msgIDs []int64, now time.Time) []byte {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	future := now.Add(30*time.Second).Unix() << 32
+	info := make([]byte, len(msgIDs))
+	for i, id := range msgIDs {
+		switch {
+		case r.ids[id]:
+			info[i] = 4
+		case id <= r.forgotten:
+			info[i] = 1
+		case id > future:
+			info[i] = 3
+		default:
+			info[i] = 2
+		}
+	}
+	return info
+}
+
+// serverNow returns the time by the server clock
+func (m *MTProto) serverNow() time.Time {
+	return time.Now().Add(m.ServerTimeOffset())
+}
+
+// ServerTimeOffset returns how far the server clock is ahead of the local one, message ids are made with it
+func (m *MTProto) ServerTimeOffset() time.Duration {
+	return time.Duration(m.timeOffset.Load())
+}
+
+func (m *MTProto) setTimeOffset(offset time.Duration) {
+	if old := time.Duration(m.timeOffset.Swap(int64(offset))); (old - offset).Abs() > time.Second {
+		m.Logger.Debug("server time offset changed", "offset", offset.Round(time.Millisecond))
+	}
+}
+
+// syncTime takes the server time from the id of a message the server just sent
+func (m *MTProto) syncTime(serverMsgID int64) {
+	// the lower half of a message id is the fraction of the second
+	nanos := int64(uint32(serverMsgID)) * int64(time.Second) >> 32
+	m.setTimeOffset(time.Until(time.Unix(serverMsgID>>32, nanos)))
+}
+
+// handleBadMsg fixes what the server complained about and makes the callers of the rejected messages
+// send their requests again, with new ids; errors that can't be fixed are returned to the callers
+func (m *MTProto) handleBadMsg(notification *objects.BadMsgNotification, serverMsgID int64) {
+	badMsg := BadMsgErrorFromNative(notification)
+	m.Logger.Debug("bad_msg_notification", "msg_id", notification.BadMsgID, "error", badMsg)
+
+	var reason tl.Object = &errorSessionConfigsChanged{}
+	switch BadSystemMessageCode(notification.Code) {
+	case ErrBadMsgIdTooLow, ErrBadMsgIdTooHigh:
+		m.syncTime(serverMsgID)
+	case ErrBadMsgSeqNoTooLow:
+		m.shiftSeqNo(64)
+	case ErrBadMsgSeqNoTooHigh:
+		m.shiftSeqNo(-16)
+	case ErrBadMsgMessageTooOld:
+		// repeated with a new id
+	case ErrBadMsgServerSaltIncorrect:
+		// the new salt comes with bad_server_salt
+	default:
+		reason = badMsg
+	}
+	for _, id := range m.sendQueue.contents(notification.BadMsgID) {
+		m.failMessage(id, reason)
+	}
+}
+
+func (m *MTProto) shiftSeqNo(by int32) {
+	m.seqNoMutex.Lock()
+	defer m.seqNoMutex.Unlock()
+	if m.seqNo += by; m.seqNo < 0 {
+		m.seqNo = 0
+	}
+}
+
+// answerDetailedInfo acknowledges the answer the server tells about, or asks for it when it never arrived
+func (m *MTProto) answerDetailedInfo(answerMsgID int64) {
+	if m.received.has(answerMsgID) {
+		m.queueAck(answerMsgID)
+		return
+	}
+	if _, _, err := m.sendPacket(&objects.MsgResendReq{MsgIDs: []int64{answerMsgID}}); err != nil {
+		m.Logger.Debug("requesting resend", "msg_id", answerMsgID, "error", err)
+	}
+}
@@ -0,0 +1,39 @@
+package gogram
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReceivedMessagesStates(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	id := func(at time.Time) int64 {
+		return at.Unix()<<32 | 1
+	}
+	r := newReceivedMessages()
+	first := id(now.Add(-time.Hour))
+	r.add(first)
+	for i := int64(1); i <= receivedHistory; i++ {
+		r.add(first + 4*i)
+	}
+	received := first + 4*receivedHistory
+
+	for _, tc := range []struct {
+		name  string
+		msgID int64
+		want  byte
+	}{
+		{"forgotten", first, 1},
+		{"older than the history", first - 4, 1},
+		{"received", received, 4},
+		{"not received", id(now), 2},
+		{"too high", id(now.Add(time.Minute)), 3},
+	} {
+		if got := r.states([]int64{tc.msgID}, now); len(got) != 1 || got[0] != tc.want {
+			t.Errorf("%s: state %v, want %d", tc.name, got, tc.want)
+		}
+	}
+	if got := r.states([]int64{received, id(now)}, now); string(got) != string([]byte{4, 2}) {
+		t.Errorf("states of two ids are %v, want [4 2]", got)
+	}
+}
@@ -21,6 +21,7 @@ type genericFileSessionLoader struct {
 	path       string
 	lastEdited time.Time
 	cached     *Session
+	sealer     *aes.Sealer
 }
 
 var _ SessionLoader = (*genericFileSessionLoader)(nil)
@@ -29,6 +30,12 @@ func NewFromFile(path string) SessionLoader {
 	return &genericFileSessionLoader{path: path}
 }
 
+// NewFromFileWithPassphrase is like NewFromFile, but the file is encrypted with a key derived from
+// passphrase. Sessions stored in the legacy format are read once and rewritten encrypted.
+func NewFromFileWithPassphrase(path, passphrase string) SessionLoader {
+	return &genericFileSessionLoader{path: path, sealer: aes.NewSealer(passphrase)}
+}
+
 func (l *genericFileSessionLoader) Path() string {
 	return l.path
 }
@@ -48,11 +55,22 @@ func (l *genericFileSessionLoader) Load() (*Session, error) {
 	}
 
 	data, err := os.ReadFile(l.path)
-	data = decodeBytes(data)
 	if err != nil {
 		return nil, errors.Wrap(err, "reading file")
 	}
 
+	legacy := !aes.IsSealed(data)
+	switch {
+	case legacy:
+		data = decodeBytes(data)
+	case l.sealer == nil:
+		return nil, aes.ErrPassphraseNotGiven
+	default:
+		if data, err = l.sealer.Open(data); err != nil {
+			return nil, errors.Wrap(err, "decrypting file")
+		}
+	}
+
 	file := new(tokenStorageFormat)
 	err = json.Unmarshal(data, file)
 	if err != nil {
@@ -64,6 +82,13 @@ func (l *genericFileSessionLoader) Load() (*Session, error) {
 		return nil, err
 	}
 
+	if legacy && l.sealer != nil {
+		if err := l.Store(s); err != nil {
+			return nil, errors.Wrap(err, "migrating legacy session")
+		}
+		return s, nil
+	}
+
 	l.cached = s
 	l.lastEdited = info.ModTime()
 
@@ -81,7 +106,14 @@ func (l *genericFileSessionLoader) Store(s *Session) error {
 	file.writeSession(s)
 	data, _ := json.Marshal(file)
 
-	return os.WriteFile(l.path, encodeBytes(data), 0600)
+	if l.sealer == nil {
+		return os.WriteFile(l.path, encodeBytes(data), 0600)
+	}
+	data, err := l.sealer.Seal(data)
+	if err != nil {
+		return errors.Wrap(err, "encrypting session")
+	}
+	return os.WriteFile(l.path, data, 0600)
 }
 
 func (l *genericFileSessionLoader) Delete() error {
@@ -139,6 +171,7 @@ func decodeInt64ToBase64(i string) (int64, error) {
 	return int64(binary.LittleEndian.Uint64(buf)), nil
 }
 
+// SessionAESKey is the key of the legacy (obfuscation only) session format, used when no passphrase is given.
 const (
 	SessionAESKey = "1234567890123456"
 )
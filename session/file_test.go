@@ -0,0 +1,46 @@
+package session_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	aes "github.com/jwillp/gogram/internal/aes_ige"
+	"github.com/jwillp/gogram/session"
+)
+
+var testSession = &session.Session{Key: []byte{1, 2, 3}, Hash: []byte{4, 5}, Salt: 42, Hostname: "149.154.167.50:443", AppID: 7}
+
+func TestFileSessionMigratesLegacyFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.session")
+	if err := session.NewFromFile(path).Store(testSession); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := session.NewFromFileWithPassphrase(path, "secret").Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s, testSession) {
+		t.Errorf("loaded %+v, want %+v", s, testSession)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !aes.IsSealed(data) {
+		t.Fatal("legacy session wasn't rewritten encrypted")
+	}
+
+	if s, err := session.NewFromFileWithPassphrase(path, "secret").Load(); err != nil || !reflect.DeepEqual(s, testSession) {
+		t.Errorf("loading the migrated session: %+v, %v", s, err)
+	}
+	if _, err := session.NewFromFileWithPassphrase(path, "wrong").Load(); !errors.Is(err, aes.ErrPassphraseMismatch) {
+		t.Errorf("loading with a wrong passphrase: %v, want ErrPassphraseMismatch", err)
+	}
+	if _, err := session.NewFromFile(path).Load(); !errors.Is(err, aes.ErrPassphraseNotGiven) {
+		t.Errorf("loading without a passphrase: %v, want ErrPassphraseNotGiven", err)
+	}
+}
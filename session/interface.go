@@ -4,6 +4,9 @@ import "errors"
 
 // SessionLoader is the interface which allows you to access sessions from different storages (like
 // filesystem, database, s3 storage, etc.)
+//
+// Load returns (nil, nil) or ErrSessionNotFound when nothing was stored yet, Store replaces the stored
+// session, Delete removes it and Path returns a human readable location of the storage.
 type SessionLoader interface {
 	Load() (*Session, error)
 	Store(*Session) error
@@ -0,0 +1,126 @@
+package session
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const defaultSQLTable = "gogram_sessions"
+
+// SQLOptions configures sql session storage
+type SQLOptions struct {
+	// Table is the name of the table which keeps the sessions, "gogram_sessions" by default
+	Table string
+	// DollarPlaceholders makes queries use $1, $2 ... instead of ? (PostgreSQL style drivers)
+	DollarPlaceholders bool
+}
+
+type sqlSessionLoader struct {
+	db     *sql.DB
+	name   string
+	table  string
+	dollar bool
+
+	mu     sync.Mutex // guards inited, a failed create is retried on the next call
+	inited bool
+}
+
+var _ SessionLoader = (*sqlSessionLoader)(nil)
+
+// NewFromSQL returns a SessionLoader which keeps sessions in any database/sql compatible database
+// (sqlite, postgres, mysql, ...), one row per session name. So many accounts can share one database,
+// every account just needs its own name. The table is created on first use.
+func NewFromSQL(db *sql.DB, name string, opts ...*SQLOptions) SessionLoader {
+	l := &sqlSessionLoader{db: db, name: name, table: defaultSQLTable}
+	if len(opts) > 0 && opts[0] != nil {
+		if opts[0].Table != "" {
+			l.table = opts[0].Table
+		}
+		l.dollar = opts[0].DollarPlaceholders
+	}
+	return l
+}
+
+func (l *sqlSessionLoader) Path() string {
+	return "sql://" + l.table + "/" + l.name
+}
+
+// arg returns the placeholder for n-th (starting from 1) query argument
+func (l *sqlSessionLoader) arg(n int) string {
+	if l.dollar {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+func (l *sqlSessionLoader) init() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inited {
+		return nil
+	}
+	_, err := l.db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (name VARCHAR(255) PRIMARY KEY, data TEXT NOT NULL)", l.table))
+	if err != nil {
+		return errors.Wrap(err, "creating sessions table")
+	}
+	l.inited = true
+	return nil
+}
+
+func (l *sqlSessionLoader) Load() (*Session, error) {
+	if err := l.init(); err != nil {
+		return nil, err
+	}
+	var data string
+	err := l.db.QueryRow(fmt.Sprintf("SELECT data FROM %s WHERE name = %s", l.table, l.arg(1)), l.name).Scan(&data)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	default:
+		return nil, errors.Wrap(err, "querying session")
+	}
+
+	file := new(tokenStorageFormat)
+	if err := json.Unmarshal([]byte(data), file); err != nil {
+		return nil, errors.Wrap(err, "parsing session")
+	}
+	return file.readSession()
+}
+
+func (l *sqlSessionLoader) Store(s *Session) error {
+	if err := l.init(); err != nil {
+		return err
+	}
+	file := new(tokenStorageFormat)
+	file.writeSession(s)
+	data, _ := json.Marshal(file)
+
+	// delete + insert in one transaction instead of upsert, cause every database spells upsert differently
+	tx, err := l.db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "starting transaction")
+	}
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE name = %s", l.table, l.arg(1)), l.name); err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "replacing session")
+	}
+	if _, err := tx.Exec(fmt.Sprintf("INSERT INTO %s (name, data) VALUES (%s, %s)", l.table, l.arg(1), l.arg(2)), l.name, string(data)); err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "inserting session")
+	}
+	return errors.Wrap(tx.Commit(), "committing session")
+}
+
+func (l *sqlSessionLoader) Delete() error {
+	if err := l.init(); err != nil {
+		return err
+	}
+	_, err := l.db.Exec(fmt.Sprintf("DELETE FROM %s WHERE name = %s", l.table, l.arg(1)), l.name)
+	return errors.Wrap(err, "deleting session")
+}
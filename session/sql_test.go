@@ -0,0 +1,85 @@
+package session_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/jwillp/gogram/session"
+)
+
+// openSQLite opens a database in a temporary file, the test is skipped when the driver isn't usable,
+// it needs cgo
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err == nil {
+		err = db.Ping()
+	}
+	if err != nil {
+		t.Skip("sqlite is not available:", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSQLSession(t *testing.T) {
+	db := openSQLite(t)
+	loader := session.NewFromSQL(db, "main")
+
+	s, err := loader.Load()
+	if s != nil || err != nil {
+		t.Fatalf("loading a session which isn't stored: %+v, %v", s, err)
+	}
+
+	if err := loader.Store(testSession); err != nil {
+		t.Fatal(err)
+	}
+	changed := *testSession
+	changed.Salt = 43
+	if err := loader.Store(&changed); err != nil {
+		t.Fatal("replacing the session:", err)
+	}
+	// a new loader hasn't created the table yet, it must find the stored row anyway
+	if s, err := session.NewFromSQL(db, "main").Load(); err != nil || !reflect.DeepEqual(s, &changed) {
+		t.Errorf("loaded %+v, %v, want %+v", s, err, &changed)
+	}
+	if s, err := session.NewFromSQL(db, "other").Load(); s != nil || err != nil {
+		t.Errorf("session of another name: %+v, %v", s, err)
+	}
+
+	if err := loader.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := loader.Load(); s != nil || err != nil {
+		t.Errorf("loading a deleted session: %+v, %v", s, err)
+	}
+}
+
+func TestSQLSessionConcurrentFirstUse(t *testing.T) {
+	db := openSQLite(t)
+	db.SetMaxOpenConns(1)
+	loader := session.NewFromSQL(db, "main", &session.SQLOptions{Table: "sessions"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := loader.Load(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := loader.Store(testSession); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := loader.Load(); err != nil || !reflect.DeepEqual(s, testSession) {
+		t.Errorf("loaded %+v, %v, want %+v", s, err, testSession)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"syscall"
 	"time"
 
+	mtproto "github.com/jwillp/gogram"
 	"github.com/pkg/errors"
 )
 
@@ -60,19 +61,13 @@ func (c *Client) LoginBot(botToken string) error {
 	if au, _ := c.IsAuthorized(); au {
 		return nil
 	}
+	// USER_MIGRATE_X is handled by MakeRequest, the request is repeated in the right data center
 	_, err := c.AuthImportBotAuthorization(1, c.AppID(), c.AppHash(), botToken)
-	if err == nil {
-		c.clientData.botAcc = true
-	}
-	if au, e := c.IsAuthorized(); !au {
-		if dc, code := getErrorCode(e); code == 303 {
-			err = c.switchDC(dc)
-			if err != nil {
-				return err
-			}
-			return c.LoginBot(botToken)
-		}
+	if err != nil {
+		return err
 	}
+	c.clientData.botAcc = true
+	_, err = c.IsAuthorized()
 	return err
 }
 
@@ -83,13 +78,6 @@ func (c *Client) SendCode(phoneNumber string) (hash string, err error) {
 		CurrentNumber: true,
 	})
 	if err != nil {
-		if dc, code := getErrorCode(err); code == 303 {
-			err = c.switchDC(dc)
-			if err != nil {
-				return "", err
-			}
-			return c.SendCode(phoneNumber)
-		}
 		return "", err
 	}
 	return resp.PhoneCodeHash, nil
@@ -135,10 +123,10 @@ func (c *Client) Login(phoneNumber string, options ...*LoginOptions) (bool, erro
 				if err == nil {
 					break
 				}
-				if matchError(err, "The phone code entered was invalid") {
+				if errors.Is(err, mtproto.ErrPhoneCodeInvalid) {
 					fmt.Println("The phone code entered was invalid, please try again!")
 					continue
-				} else if matchError(err, "Two-steps verification is enabled") {
+				} else if errors.Is(err, mtproto.ErrSessionPasswordNeeded) {
 					var passwordInput string
 					fmt.Println("Two-steps verification is enabled")
 					for {
@@ -166,7 +154,7 @@ func (c *Client) Login(phoneNumber string, options ...*LoginOptions) (bool, erro
 						return false, err
 					}
 					break
-				} else if matchError(err, "The code is valid but no user with the given number") {
+				} else if errors.Is(err, mtproto.ErrPhoneNumberUnoccupied) {
 					return false, errors.New("Since Feb 2023, Telegram does not allow to create new accounts using API. Please use Telegram app to create an account and then use this library to login.")
 					// c.AcceptTOS()
 					// _, err = c.AuthSignUp(phoneNumber, opts.CodeHash, opts.FirstName, opts.LastName)
@@ -288,7 +276,7 @@ func (c *Client) Edit2FA(currPwd string, newPwd string, opts ...*PasswordOptions
 		NewSecureSettings: &SecureSecretSettings{},
 	})
 	if err != nil {
-		if matchError(err, "Email unconfirmed") {
+		if errors.Is(err, mtproto.ErrEmailUnconfirmed) {
 			if opt.EmailCodeCallback == nil {
 				return false, errors.New("email_code_callback is nil")
 			}
@@ -416,7 +404,7 @@ func (c *Client) QRLogin(IgnoreIDs ...int64) (*QrToken, error) {
 func (c *Client) LogOut() error {
 	_, err := c.AuthLogOut()
 	// c.bot = false
-	c.MTProto.DeleteSession()
+	c.MTProto().DeleteSession()
 	return err
 }
 
@@ -3,10 +3,9 @@ package telegram
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unsafe"
 
@@ -17,18 +16,64 @@ import (
 const (
 	// CacheUpdateInterval is the interval in seconds at which the cache is updated
 	CacheUpdateInterval = 60
-	// AesKey is the key used to encrypt the cache file
+	// AesKey is the key of the legacy cache file format, used when no passphrase is set
 	AesKey = "12345678901234567890123456789012"
+
+	defaultCacheJournal = "cache.journal"
 )
 
+// PeerCache keeps the users, chats and channels seen by a client, above all their access hashes,
+// which are needed to address them in requests. Every client owns its cache.
+type PeerCache interface {
+	UpdateUser(user *UserObj)
+	UpdateChat(chat *ChatObj)
+	UpdateChannel(channel *Channel)
+	UpdatePeersToCache(users []User, chats []Chat)
+
+	GetUser(userID int64) (*UserObj, bool)
+	GetChat(chatID int64) (*ChatObj, bool)
+	GetChannel(channelID int64) (*Channel, bool)
+	// GetInputPeer returns the input peer of a marked id, see MarkPeerID, users and chats of the
+	// same id are told apart by the mark. A positive id which isn't a known user is looked up as
+	// the raw id of a chat, then of a channel
+	GetInputPeer(peerID int64) (InputPeer, error)
+	// LookupUsername returns the marked id of the user or channel owning username
+	LookupUsername(username string) (int64, bool)
+
+	Purge()
+}
+
+// CacheOptions configures the default in-memory cache
+type CacheOptions struct {
+	// JournalPath is the file the cache is persisted to, "cache.journal" in the working directory by default
+	JournalPath string
+	// NoJournal keeps the cache in memory only
+	NoJournal bool
+	// Passphrase encrypts the journal, see ClientConfig.Passphrase
+	Passphrase string
+	LogLevel   string
+	// Logger receives the errors of the cache, a StdLogger at LogLevel by default
+	Logger Logger
+}
+
+// CACHE is the default PeerCache, kept in memory and periodically written to an encrypted journal file
 type CACHE struct {
+	mutex      sync.RWMutex
 	chats      map[int64]*ChatObj
 	users      map[int64]*UserObj
 	channels   map[int64]*Channel
-	InputPeers *InputPeerCache `json:"input_peers,omitempty"`
+	InputPeers *InputPeerCache  `json:"input_peers,omitempty"`
+	Usernames  map[string]int64 `json:"usernames,omitempty"`
 	logger     *utils.Logger
+	journal    string
+	sealer     *aes.Sealer
+	locked     bool
+	stop       chan struct{}
+	stopOnce   sync.Once
 }
 
+var _ PeerCache = (*CACHE)(nil)
+
 type InputPeerCache struct {
 	InputChannels map[int64]*InputPeerChannel `json:"channels,omitempty"`
 	InputUsers    map[int64]*InputPeerUser    `json:"users,omitempty"`
@@ -36,54 +81,87 @@ type InputPeerCache struct {
 }
 
 func (c *CACHE) periodicallyFlushToFile() {
+	ticker := time.NewTicker(time.Duration(CacheUpdateInterval) * time.Second)
+	defer ticker.Stop()
 	for {
-		time.Sleep(time.Duration(CacheUpdateInterval) * time.Second)
-		c.flushToFile()
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.flushToFile()
+		}
 	}
 }
 
 func (c *CACHE) flushToFile() {
-	f, err := os.OpenFile("cache.journal", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
-	if err != nil {
-		log.Println(err)
+	if c.journal == "" || c.locked {
+		// journal disabled, or sealed with a passphrase we don't know: don't overwrite it
+		return
 	}
-	// encode to aes encrypted json file
-	var b []byte
-	b, err = json.Marshal(c)
+	c.mutex.RLock()
+	b, err := json.Marshal(c)
+	c.mutex.RUnlock()
 	if err != nil {
-		c.logger.Error("Error while marshalling cache.journal: %v", err)
+		c.logger.Error("marshalling cache.journal", "error", err)
+		return
 	}
-	b, err = aes.EncryptAES(b, AesKey)
-	if err != nil {
-		c.logger.Error("Error while encrypting cache.journal: %v", err)
+	if c.sealer != nil {
+		b, err = c.sealer.Seal(b)
+	} else {
+		b, err = aes.EncryptAES(b, AesKey)
 	}
-	_, err = f.Write(b)
 	if err != nil {
-		c.logger.Error("Error while writing to cache.journal: %v", err)
+		c.logger.Error("encrypting cache.journal", "error", err)
+		return
+	}
+	if err = os.WriteFile(c.journal, b, 0600); err != nil {
+		c.logger.Error("writing to cache.journal", "error", err)
 	}
 }
 
 func (c *CACHE) loadFromFile() {
-	var b []byte
-	b, err := os.ReadFile("cache.journal")
+	b, err := os.ReadFile(c.journal)
 	if err != nil {
 		return
 	}
-	b, err = aes.DecryptAES(b, AesKey)
-	if err != nil {
-		c.logger.Error("Error while decrypting cache.journal: %v", err)
+	switch {
+	case !aes.IsSealed(b):
+		// legacy journal, rewritten sealed on next flush if a passphrase is set
+		b, err = aes.DecryptAES(b, AesKey)
+	case c.sealer == nil:
+		c.locked = true
+		c.logger.Warn("cache.journal is encrypted but no passphrase was given, journal is disabled")
+		return
+	default:
+		b, err = c.sealer.Open(b)
 	}
-	err = json.Unmarshal(b, c)
 	if err != nil {
-		c.logger.Error("Error while unmarshalling cache.journal: %v", err)
+		c.logger.Error("decrypting cache.journal", "error", err)
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err = json.Unmarshal(b, c); err != nil {
+		c.logger.Error("unmarshalling cache.journal", "error", err)
+	}
+	if c.Usernames == nil {
+		c.Usernames = make(map[string]int64)
+	}
+	for username, id := range c.Usernames {
+		// older journals kept the raw ids of channels
+		_, channel := c.InputPeers.InputChannels[id]
+		if _, user := c.InputPeers.InputUsers[id]; id > 0 && channel && !user {
+			c.Usernames[username] = markPeerID(peerKindChannel, id)
+		}
 	}
 }
 
-var (
-	cache = NewCache()
-)
-
-func NewCache() *CACHE {
+// NewCache creates an in-memory cache, persisted to a journal file unless disabled
+func NewCache(opts ...*CacheOptions) *CACHE {
+	opt := &CacheOptions{}
+	if len(opts) > 0 && opts[0] != nil {
+		opt = opts[0]
+	}
 	c := &CACHE{
 		chats:    make(map[int64]*ChatObj),
 		users:    make(map[int64]*UserObj),
@@ -93,64 +171,195 @@ func NewCache() *CACHE {
 			InputUsers:    make(map[int64]*InputPeerUser),
 			InputChats:    make(map[int64]*InputPeerChat),
 		},
-		logger: utils.NewLogger("cache").SetLevel(LIB_LOG_LEVEL),
+		Usernames: make(map[string]int64),
+		logger:    utils.NewLogger(logHandler(opt.Logger, opt.LogLevel), "cache"),
+		stop:      make(chan struct{}),
+	}
+	if opt.NoJournal {
+		return c
+	}
+	c.journal = getStr(opt.JournalPath, defaultCacheJournal)
+	if opt.Passphrase != "" {
+		c.sealer = aes.NewSealer(opt.Passphrase)
 	}
 	c.loadFromFile()
 	go c.periodicallyFlushToFile()
 	return c
 }
 
-func (c *CACHE) getUserPeer(userID int64) (InputUser, error) {
-	for _, user := range c.InputPeers.InputUsers {
-		if user.UserID == userID {
-			return &InputUserObj{UserID: user.UserID, AccessHash: user.AccessHash}, nil
+// Close stops the periodic flushing and writes the journal one last time
+func (c *CACHE) Close() error {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+		c.flushToFile()
+	})
+	return nil
+}
+
+func (c *CACHE) GetInputPeer(peerID int64) (InputPeer, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	for _, kind := range peerKinds(peerID) {
+		if peer, ok := c.inputPeer(kind, peerID); ok {
+			return peer, nil
 		}
 	}
-	return nil, fmt.Errorf("no user with id %d or missing from cache", userID)
+	return nil, fmt.Errorf("no peer with id %d", peerID)
 }
 
-func (c *CACHE) getChannelPeer(channelID int64) (InputChannel, error) {
-	for _, channel := range c.InputPeers.InputChannels {
-		if channel.ChannelID == channelID {
-			return &InputChannelObj{ChannelID: channel.ChannelID, AccessHash: channel.AccessHash}, nil
+func (c *CACHE) inputPeer(kind int, peerID int64) (InputPeer, bool) {
+	id := rawPeerID(kind, peerID)
+	switch kind {
+	case peerKindUser:
+		if user, ok := c.InputPeers.InputUsers[id]; ok {
+			return &InputPeerUser{UserID: user.UserID, AccessHash: user.AccessHash}, true
+		}
+	case peerKindChat:
+		if chat, ok := c.InputPeers.InputChats[id]; ok {
+			return &InputPeerChat{ChatID: chat.ChatID}, true
+		}
+	case peerKindChannel:
+		if channel, ok := c.InputPeers.InputChannels[id]; ok {
+			return &InputPeerChannel{ChannelID: channel.ChannelID, AccessHash: channel.AccessHash}, true
 		}
 	}
-	return nil, fmt.Errorf("no channel with id %d or missing from cache", channelID)
+	return nil, false
 }
 
-func (c *CACHE) GetInputPeer(peerID int64) (InputPeer, error) {
+func (c *CACHE) GetUser(userID int64) (*UserObj, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	user, ok := c.users[userID]
+	return user, ok
+}
+
+func (c *CACHE) GetChat(chatID int64) (*ChatObj, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	chat, ok := c.chats[chatID]
+	return chat, ok
+}
 
-	if strings.HasPrefix(strconv.Itoa(int(peerID)), "-100") {
-		peerID = peerID - 1000000000000
+func (c *CACHE) GetChannel(channelID int64) (*Channel, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	channel, ok := c.channels[channelID]
+	return channel, ok
+}
+
+func (c *CACHE) LookupUsername(username string) (int64, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	id, ok := c.Usernames[normalizeUsername(username)]
+	return id, ok
+}
+
+// kinds of peers, told apart by the mark of their ids
+const (
+	peerKindUser    = 1
+	peerKindChat    = 2
+	peerKindChannel = 3
+)
+
+// channelIDOffset is subtracted from the negated ids of channels, to mark them as -100<id>
+const channelIDOffset = 1000000000000
+
+// MarkPeerID returns the id of the peer in the bot api form, unique across kinds: users are
+// positive, chats are -id and channels are -100<id>
+func MarkPeerID(peer Peer) int64 {
+	switch p := peer.(type) {
+	case *PeerUser:
+		return p.UserID
+	case *PeerChat:
+		return markPeerID(peerKindChat, p.ChatID)
+	case *PeerChannel:
+		return markPeerID(peerKindChannel, p.ChannelID)
+	}
+	return 0
+}
+
+func markPeerID(kind int, id int64) int64 {
+	switch kind {
+	case peerKindChat:
+		return -id
+	case peerKindChannel:
+		return -channelIDOffset - id
 	}
-	for _, user := range c.InputPeers.InputUsers {
-		if user.UserID == peerID {
-			return &InputPeerUser{UserID: user.UserID, AccessHash: user.AccessHash}, nil
-		}
+	return id
+}
+
+// splitPeerID returns the kind and the id of a marked peer id
+func splitPeerID(peerID int64) (int, int64) {
+	switch {
+	case peerID < -channelIDOffset:
+		return peerKindChannel, -(peerID + channelIDOffset)
+	case peerID < 0:
+		return peerKindChat, -peerID
 	}
-	for _, chat := range c.InputPeers.InputChats {
-		if chat.ChatID == peerID {
-			return &InputPeerChat{ChatID: chat.ChatID}, nil
-		}
+	return peerKindUser, peerID
+}
+
+// peerKinds returns the kinds a peer id may be of, in the order to look them up: a marked id has
+// one kind, a positive one is a user, or the raw id of a chat or a channel, as ChatID returns them
+func peerKinds(peerID int64) []int {
+	if kind, _ := splitPeerID(peerID); kind != peerKindUser {
+		return []int{kind}
 	}
-	for _, channel := range c.InputPeers.InputChannels {
-		if channel.ChannelID == peerID {
-			return &InputPeerChannel{ChannelID: channel.ChannelID, AccessHash: channel.AccessHash}, nil
-		}
+	return []int{peerKindUser, peerKindChat, peerKindChannel}
+}
+
+// rawPeerID returns the id of a peer of the given kind, from either its marked or its raw id
+func rawPeerID(kind int, peerID int64) int64 {
+	if peerID > 0 {
+		return peerID
 	}
-	return nil, fmt.Errorf("no peer with id %d", peerID)
+	_, id := splitPeerID(peerID)
+	return id
+}
+
+// channelPeerID marks the id of a channel, unless it's marked already
+func channelPeerID(channelID int64) int64 {
+	if channelID > 0 {
+		return markPeerID(peerKindChannel, channelID)
+	}
+	return channelID
+}
+
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimPrefix(username, "@"))
 }
 
 // ------------------ Get Chat/Channel/User From Cache/Telgram ------------------
 
-func (c *Client) getUserFromCache(userID int64) (*UserObj, error) {
+func (c *Client) getUserPeer(userID int64) (InputUser, error) {
+	peer, err := c.Cache.GetInputPeer(userID)
+	if err != nil {
+		return nil, err
+	}
+	user, ok := peer.(*InputPeerUser)
+	if !ok {
+		return nil, fmt.Errorf("no user with id %d or missing from cache", userID)
+	}
+	return &InputUserObj{UserID: user.UserID, AccessHash: user.AccessHash}, nil
+}
 
-	for _, user := range c.Cache.users {
-		if user.ID == userID {
-			return user, nil
-		}
+func (c *Client) getChannelPeer(channelID int64) (InputChannel, error) {
+	peer, err := c.Cache.GetInputPeer(channelPeerID(channelID))
+	if err != nil {
+		return nil, err
+	}
+	channel, ok := peer.(*InputPeerChannel)
+	if !ok {
+		return nil, fmt.Errorf("no channel with id %d or missing from cache", channelID)
+	}
+	return &InputChannelObj{ChannelID: channel.ChannelID, AccessHash: channel.AccessHash}, nil
+}
+
+func (c *Client) getUserFromCache(userID int64) (*UserObj, error) {
+	if user, ok := c.Cache.GetUser(userID); ok {
+		return user, nil
 	}
-	userPeer, err := c.Cache.getUserPeer(userID)
+	userPeer, err := c.getUserPeer(userID)
 	if err != nil {
 		return nil, err
 	}
@@ -169,13 +378,10 @@ func (c *Client) getUserFromCache(userID int64) (*UserObj, error) {
 }
 
 func (c *Client) getChannelFromCache(channelID int64) (*Channel, error) {
-
-	for _, channel := range c.Cache.channels {
-		if channel.ID == channelID {
-			return channel, nil
-		}
+	if channel, ok := c.Cache.GetChannel(channelID); ok {
+		return channel, nil
 	}
-	channelPeer, err := c.Cache.getChannelPeer(channelID)
+	channelPeer, err := c.getChannelPeer(channelID)
 	if err != nil {
 		return nil, err
 	}
@@ -198,10 +404,8 @@ func (c *Client) getChannelFromCache(channelID int64) (*Channel, error) {
 }
 
 func (c *Client) getChatFromCache(chatID int64) (*ChatObj, error) {
-	for _, chat := range c.Cache.chats {
-		if chat.ID == chatID {
-			return chat, nil
-		}
+	if chat, ok := c.Cache.GetChat(chatID); ok {
+		return chat, nil
 	}
 	chat, err := c.MessagesGetChats([]int64{chatID})
 	if err != nil {
@@ -250,24 +454,37 @@ func (c *Client) GetChat(chatID int64) (*ChatObj, error) {
 // ----------------- Update User/Channel/Chat in cache -----------------
 
 func (c *CACHE) UpdateUser(user *UserObj) {
-
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 	c.users[user.ID] = user
-	peerUser := &InputPeerUser{UserID: user.ID, AccessHash: user.AccessHash}
-	c.InputPeers.InputUsers[user.ID] = peerUser
+	if _, known := c.InputPeers.InputUsers[user.ID]; user.Min && known {
+		// access hash of a min constructor can't be used in requests, keep the one we have
+		return
+	}
+	c.InputPeers.InputUsers[user.ID] = &InputPeerUser{UserID: user.ID, AccessHash: user.AccessHash}
+	if user.Username != "" {
+		c.Usernames[normalizeUsername(user.Username)] = user.ID
+	}
 }
 
 func (c *CACHE) UpdateChannel(channel *Channel) {
-
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 	c.channels[channel.ID] = channel
-	peerChannel := &InputPeerChannel{ChannelID: channel.ID, AccessHash: channel.AccessHash}
-	c.InputPeers.InputChannels[channel.ID] = peerChannel
+	if _, known := c.InputPeers.InputChannels[channel.ID]; channel.Min && known {
+		return
+	}
+	c.InputPeers.InputChannels[channel.ID] = &InputPeerChannel{ChannelID: channel.ID, AccessHash: channel.AccessHash}
+	if channel.Username != "" {
+		c.Usernames[normalizeUsername(channel.Username)] = markPeerID(peerKindChannel, channel.ID)
+	}
 }
 
 func (c *CACHE) UpdateChat(chat *ChatObj) {
-
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 	c.chats[chat.ID] = chat
-	peerChat := &InputPeerChat{ChatID: chat.ID}
-	c.InputPeers.InputChats[chat.ID] = peerChat
+	c.InputPeers.InputChats[chat.ID] = &InputPeerChat{ChatID: chat.ID}
 }
 
 func (cache *CACHE) UpdatePeersToCache(u []User, c []Chat) {
@@ -288,18 +505,23 @@ func (cache *CACHE) UpdatePeersToCache(u []User, c []Chat) {
 			}
 		}
 	}
-	go cache.flushToFile()
+	if cache.journal != "" {
+		go cache.flushToFile()
+	}
 }
 
 // ----------------- Cache Misc Functions -----------------
 
 func (c *CACHE) GetSize() uintptr {
-
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
 	return unsafe.Sizeof(c.users) + unsafe.Sizeof(c.chats) + unsafe.Sizeof(c.channels)
 }
 
+// Purge drops the cached objects, access hashes and usernames are kept
 func (c *CACHE) Purge() {
-
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 	c.users = make(map[int64]*UserObj)
 	c.chats = make(map[int64]*ChatObj)
 	c.channels = make(map[int64]*Channel)
@@ -308,19 +530,19 @@ func (c *CACHE) Purge() {
 // ----------------- Custom Peer Types -----------------
 
 func (c *Client) GetPeerUser(userID int64) (*InputPeerUser, error) {
-
-	if peer, ok := c.Cache.InputPeers.InputUsers[userID]; ok {
-		return peer, nil
+	if peer, err := c.Cache.GetInputPeer(userID); err == nil {
+		if user, ok := peer.(*InputPeerUser); ok {
+			return user, nil
+		}
 	}
 	return nil, fmt.Errorf("no user with id %d or missing from cache", userID)
 }
 
 func (c *Client) GetPeerChannel(channelID int64) (*InputPeerChannel, error) {
-
-	if peer, ok := c.Cache.InputPeers.InputChannels[channelID]; ok {
-		return peer, nil
+	if peer, err := c.Cache.GetInputPeer(channelPeerID(channelID)); err == nil {
+		if channel, ok := peer.(*InputPeerChannel); ok {
+			return channel, nil
+		}
 	}
 	return nil, fmt.Errorf("no channel with id %d or missing from cache", channelID)
 }
-
-// ----------------- AES Encryption -----------------
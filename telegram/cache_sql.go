@@ -0,0 +1,254 @@
+package telegram
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const defaultSQLCacheTable = "gogram_peers"
+
+// SQLCacheOptions configures the sql peer cache
+type SQLCacheOptions struct {
+	// Table is the name of the table which keeps the peers, "gogram_peers" by default
+	Table string
+	// DollarPlaceholders makes queries use $1, $2 ... instead of ? (PostgreSQL style drivers)
+	DollarPlaceholders bool
+	LogLevel           string
+	// Logger receives the errors of the cache, see ClientConfig.Logger
+	Logger Logger
+}
+
+// sqlCache keeps access hashes and usernames in a database, full objects are only cached in memory
+type sqlCache struct {
+	*CACHE
+	db     *sql.DB
+	table  string
+	dollar bool
+
+	initMutex sync.Mutex
+	inited    bool
+}
+
+var _ PeerCache = (*sqlCache)(nil)
+
+// NewSQLCache returns a PeerCache which persists access hashes and usernames in any database/sql
+// compatible database, so they survive restarts and can be shared by replicas of the same account.
+// Access hashes are only valid for the account that received them, use a table per account.
+// The table is created on first use.
+func NewSQLCache(db *sql.DB, opts ...*SQLCacheOptions) PeerCache {
+	s := &sqlCache{db: db, table: defaultSQLCacheTable}
+	cacheOpts := &CacheOptions{NoJournal: true}
+	if len(opts) > 0 && opts[0] != nil {
+		if opts[0].Table != "" {
+			s.table = opts[0].Table
+		}
+		s.dollar = opts[0].DollarPlaceholders
+		cacheOpts.LogLevel, cacheOpts.Logger = opts[0].LogLevel, opts[0].Logger
+	}
+	s.CACHE = NewCache(cacheOpts)
+	return s
+}
+
+// arg returns the placeholder for n-th (starting from 1) query argument
+func (s *sqlCache) arg(n int) string {
+	if s.dollar {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+func (s *sqlCache) init() error {
+	s.initMutex.Lock()
+	defer s.initMutex.Unlock()
+	if s.inited {
+		return nil
+	}
+	_, err := s.db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (kind SMALLINT NOT NULL, id BIGINT NOT NULL, access_hash BIGINT NOT NULL, username VARCHAR(64), PRIMARY KEY (kind, id))", s.table))
+	if err != nil {
+		return errors.Wrap(err, "creating peers table")
+	}
+	s.inited = true
+	return nil
+}
+
+type sqlPeer struct {
+	id         int64
+	kind       int
+	accessHash int64
+	username   string
+}
+
+// store writes peers whose access hash or username changed
+func (s *sqlCache) store(peers []sqlPeer) {
+	if len(peers) == 0 {
+		return
+	}
+	if err := s.init(); err != nil {
+		s.logger.Error("creating table", "error", err)
+		return
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error("starting transaction", "error", err)
+		return
+	}
+	for _, p := range peers {
+		// delete + insert instead of upsert, cause every database spells upsert differently
+		if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE kind = %s AND id = %s", s.table, s.arg(1), s.arg(2)), p.kind, p.id); err != nil {
+			tx.Rollback()
+			s.logger.Error("replacing peer", "error", err, "peer", p.id)
+			return
+		}
+		var username any
+		if p.username != "" {
+			username = p.username
+		}
+		if _, err := tx.Exec(fmt.Sprintf("INSERT INTO %s (id, kind, access_hash, username) VALUES (%s, %s, %s, %s)", s.table, s.arg(1), s.arg(2), s.arg(3), s.arg(4)), p.id, p.kind, p.accessHash, username); err != nil {
+			tx.Rollback()
+			s.logger.Error("inserting peer", "error", err, "peer", p.id)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("committing peers", "error", err)
+	}
+}
+
+// changed returns the sql row of a peer if it differs from what is cached in memory
+func (s *sqlCache) changed(id int64, kind int, accessHash int64, username string, min bool) (sqlPeer, bool) {
+	known, err := s.CACHE.GetInputPeer(markPeerID(kind, id))
+	if err == nil && min {
+		return sqlPeer{}, false
+	}
+	if err == nil {
+		var knownHash int64
+		switch p := known.(type) {
+		case *InputPeerUser:
+			knownHash = p.AccessHash
+		case *InputPeerChannel:
+			knownHash = p.AccessHash
+		}
+		knownID, _ := s.CACHE.LookupUsername(username)
+		if knownHash == accessHash && (username == "" || knownID == markPeerID(kind, id)) {
+			return sqlPeer{}, false
+		}
+	}
+	return sqlPeer{id: id, kind: kind, accessHash: accessHash, username: normalizeUsername(username)}, true
+}
+
+func (s *sqlCache) userRow(user *UserObj) (sqlPeer, bool) {
+	return s.changed(user.ID, peerKindUser, user.AccessHash, user.Username, user.Min)
+}
+
+func (s *sqlCache) channelRow(channel *Channel) (sqlPeer, bool) {
+	return s.changed(channel.ID, peerKindChannel, channel.AccessHash, channel.Username, channel.Min)
+}
+
+func (s *sqlCache) chatRow(chat *ChatObj) (sqlPeer, bool) {
+	return s.changed(chat.ID, peerKindChat, 0, "", false)
+}
+
+func (s *sqlCache) UpdateUser(user *UserObj) {
+	row, ok := s.userRow(user)
+	s.CACHE.UpdateUser(user)
+	if ok {
+		s.store([]sqlPeer{row})
+	}
+}
+
+func (s *sqlCache) UpdateChat(chat *ChatObj) {
+	row, ok := s.chatRow(chat)
+	s.CACHE.UpdateChat(chat)
+	if ok {
+		s.store([]sqlPeer{row})
+	}
+}
+
+func (s *sqlCache) UpdateChannel(channel *Channel) {
+	row, ok := s.channelRow(channel)
+	s.CACHE.UpdateChannel(channel)
+	if ok {
+		s.store([]sqlPeer{row})
+	}
+}
+
+func (s *sqlCache) UpdatePeersToCache(users []User, chats []Chat) {
+	var rows []sqlPeer
+	for _, user := range users {
+		if u, ok := user.(*UserObj); ok {
+			if row, ok := s.userRow(u); ok {
+				rows = append(rows, row)
+			}
+		}
+	}
+	for _, chat := range chats {
+		switch ch := chat.(type) {
+		case *ChatObj:
+			if row, ok := s.chatRow(ch); ok {
+				rows = append(rows, row)
+			}
+		case *Channel:
+			if row, ok := s.channelRow(ch); ok {
+				rows = append(rows, row)
+			}
+		}
+	}
+	s.CACHE.UpdatePeersToCache(users, chats)
+	s.store(rows)
+}
+
+func (s *sqlCache) GetInputPeer(peerID int64) (InputPeer, error) {
+	if peer, err := s.CACHE.GetInputPeer(peerID); err == nil {
+		return peer, nil
+	}
+	if err := s.init(); err != nil {
+		return nil, err
+	}
+	for _, kind := range peerKinds(peerID) {
+		id := rawPeerID(kind, peerID)
+		var accessHash int64
+		err := s.db.QueryRow(fmt.Sprintf("SELECT access_hash FROM %s WHERE kind = %s AND id = %s", s.table, s.arg(1), s.arg(2)), kind, id).Scan(&accessHash)
+		switch {
+		case err == nil:
+		case errors.Is(err, sql.ErrNoRows):
+			continue
+		default:
+			return nil, errors.Wrap(err, "querying peer")
+		}
+		switch kind {
+		case peerKindUser:
+			return &InputPeerUser{UserID: id, AccessHash: accessHash}, nil
+		case peerKindChat:
+			return &InputPeerChat{ChatID: id}, nil
+		default:
+			return &InputPeerChannel{ChannelID: id, AccessHash: accessHash}, nil
+		}
+	}
+	return nil, fmt.Errorf("no peer with id %d", peerID)
+}
+
+func (s *sqlCache) LookupUsername(username string) (int64, bool) {
+	if id, ok := s.CACHE.LookupUsername(username); ok {
+		return id, true
+	}
+	if err := s.init(); err != nil {
+		s.logger.Error("creating table", "error", err)
+		return 0, false
+	}
+	var (
+		kind int
+		id   int64
+	)
+	err := s.db.QueryRow(fmt.Sprintf("SELECT kind, id FROM %s WHERE username = %s", s.table, s.arg(1)), normalizeUsername(username)).Scan(&kind, &id)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.logger.Error("querying username", "error", err, "username", username)
+		}
+		return 0, false
+	}
+	return markPeerID(kind, id), true
+}
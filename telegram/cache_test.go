@@ -0,0 +1,155 @@
+package telegram_test
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	aes "github.com/jwillp/gogram/internal/aes_ige"
+	"github.com/jwillp/gogram/telegram"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// fillCache caches a user, a chat and a channel which all have the id 123
+func fillCache(cache telegram.PeerCache) {
+	cache.UpdatePeersToCache(
+		[]telegram.User{&telegram.UserObj{ID: 123, AccessHash: 1, Username: "someone"}},
+		[]telegram.Chat{&telegram.ChatObj{ID: 123}, &telegram.Channel{ID: 123, AccessHash: 3, Username: "news"}},
+	)
+}
+
+// checkPeers checks that the marked ids of the peers cached by fillCache resolve to the right kinds
+func checkPeers(t *testing.T, cache telegram.PeerCache) {
+	t.Helper()
+	for _, tc := range []struct {
+		id   int64
+		want telegram.InputPeer
+	}{
+		{123, &telegram.InputPeerUser{UserID: 123, AccessHash: 1}},
+		{-123, &telegram.InputPeerChat{ChatID: 123}},
+		{-1000000000123, &telegram.InputPeerChannel{ChannelID: 123, AccessHash: 3}},
+	} {
+		peer, err := cache.GetInputPeer(tc.id)
+		if err != nil {
+			t.Errorf("peer %d: %v", tc.id, err)
+			continue
+		}
+		if !samePeer(peer, tc.want) {
+			t.Errorf("peer %d is %#v, want %#v", tc.id, peer, tc.want)
+		}
+	}
+	if _, err := cache.GetInputPeer(-456); err == nil {
+		t.Error("found a chat which isn't cached")
+	}
+	for username, want := range map[string]int64{"someone": 123, "@News": -1000000000123} {
+		if id, ok := cache.LookupUsername(username); !ok || id != want {
+			t.Errorf("username %s is %d, want %d", username, id, want)
+		}
+	}
+}
+
+func samePeer(a, b telegram.InputPeer) bool {
+	switch a := a.(type) {
+	case *telegram.InputPeerUser:
+		b, ok := b.(*telegram.InputPeerUser)
+		return ok && *a == *b
+	case *telegram.InputPeerChat:
+		b, ok := b.(*telegram.InputPeerChat)
+		return ok && *a == *b
+	case *telegram.InputPeerChannel:
+		b, ok := b.(*telegram.InputPeerChannel)
+		return ok && *a == *b
+	}
+	return false
+}
+
+func TestCachePeerKinds(t *testing.T) {
+	cache := telegram.NewCache(&telegram.CacheOptions{NoJournal: true})
+	fillCache(cache)
+	checkPeers(t, cache)
+}
+
+// checkRawIDs checks that the raw ids of a chat and a channel, as NewMessage.ChatID returns them,
+// resolve while no user has them
+func checkRawIDs(t *testing.T, cache telegram.PeerCache) {
+	t.Helper()
+	for _, tc := range []struct {
+		id   int64
+		want telegram.InputPeer
+	}{
+		{456, &telegram.InputPeerChat{ChatID: 456}},
+		{789, &telegram.InputPeerChannel{ChannelID: 789, AccessHash: 7}},
+	} {
+		peer, err := cache.GetInputPeer(tc.id)
+		if err != nil {
+			t.Errorf("raw id %d: %v", tc.id, err)
+			continue
+		}
+		if !samePeer(peer, tc.want) {
+			t.Errorf("raw id %d is %#v, want %#v", tc.id, peer, tc.want)
+		}
+	}
+	if _, err := cache.GetInputPeer(999); err == nil {
+		t.Error("found a raw id which isn't cached")
+	}
+}
+
+func fillRawIDs(cache telegram.PeerCache) {
+	cache.UpdatePeersToCache(nil, []telegram.Chat{&telegram.ChatObj{ID: 456}, &telegram.Channel{ID: 789, AccessHash: 7}})
+}
+
+func TestCacheRawIDs(t *testing.T) {
+	cache := telegram.NewCache(&telegram.CacheOptions{NoJournal: true})
+	fillRawIDs(cache)
+	checkRawIDs(t, cache)
+}
+
+func TestCacheJournalMigratesLegacyFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.journal")
+	legacy := telegram.NewCache(&telegram.CacheOptions{JournalPath: path})
+	fillCache(legacy)
+	legacy.Close()
+
+	cache := telegram.NewCache(&telegram.CacheOptions{JournalPath: path, Passphrase: "secret"})
+	checkPeers(t, cache)
+	cache.Close()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !aes.IsSealed(data) {
+		t.Fatal("legacy journal wasn't rewritten encrypted")
+	}
+	reopened := telegram.NewCache(&telegram.CacheOptions{JournalPath: path, Passphrase: "secret"})
+	defer reopened.Close()
+	checkPeers(t, reopened)
+}
+
+// openSQLite opens a database in a temporary file, the test is skipped when the driver isn't usable,
+// it needs cgo
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err == nil {
+		err = db.Ping()
+	}
+	if err != nil {
+		t.Skip("sqlite is not available:", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSQLCachePeerKinds(t *testing.T) {
+	db := openSQLite(t)
+	fillCache(telegram.NewSQLCache(db))
+	// a new cache has nothing in memory, so the peers come from the table
+	checkPeers(t, telegram.NewSQLCache(db))
+}
+
+func TestSQLCacheRawIDs(t *testing.T) {
+	db := openSQLite(t)
+	fillRawIDs(telegram.NewSQLCache(db))
+	checkRawIDs(t, telegram.NewSQLCache(db))
+}
@@ -3,20 +3,25 @@
 package telegram
 
 import (
+	"context"
 	"crypto/rsa"
+	"io"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	mtproto "github.com/jwillp/gogram"
 	"github.com/pkg/errors"
 
+	"github.com/jwillp/gogram/internal/encoding/tl"
 	"github.com/jwillp/gogram/internal/keys"
-	"github.com/jwillp/gogram/internal/session"
 	"github.com/jwillp/gogram/internal/utils"
+	"github.com/jwillp/gogram/session"
 )
 
 const (
@@ -28,6 +33,54 @@ const (
 	DisconnectExportedAfter = 60 * time.Second
 )
 
+type (
+	// RetryPolicy controls how requests failed with flood waits and transient errors are retried
+	RetryPolicy = mtproto.RetryPolicy
+	// RetryEvent is passed to RetryPolicy.OnRetry before every retry
+	RetryEvent = mtproto.RetryEvent
+	// FloodWaitError is returned when a flood wait is longer than RetryPolicy.MaxFloodWait
+	FloodWaitError = mtproto.FloodWaitError
+	// MigrateError is returned when a request must be repeated in another data center
+	MigrateError = mtproto.MigrateError
+	// WaitError tells how long to wait before a request may succeed
+	WaitError = mtproto.WaitError
+	// UnexpectedResponseError is returned by generated methods when the response has a type they don't expect
+	UnexpectedResponseError = mtproto.UnexpectedResponseError
+	// TransportMode selects how packets are framed on the wire
+	TransportMode = mtproto.TransportMode
+	// MTProxy is an MTProto proxy server, with a plain, dd or ee secret
+	MTProxy = mtproto.MTProxy
+	// ConnectionType selects what carries the mtproto packets
+	ConnectionType = mtproto.ConnectionType
+	// Proxy is a socks5, socks4 or http CONNECT proxy, with optional username and password
+	Proxy = mtproto.Proxy
+	// Dialer opens network connections, it has the signature of net.Dialer.DialContext
+	Dialer = mtproto.Dialer
+	// DCOption is an address of a data center, as listed by help.getConfig
+	DCOption = mtproto.DCOption
+	// TrafficRecorder gets every message the client sends and receives, see ClientConfig.Recorder
+	TrafficRecorder = mtproto.TrafficRecorder
+	// TrafficRecord is a message recorded by a TrafficRecorder
+	TrafficRecord = mtproto.TrafficRecord
+	// TrafficLog writes traffic records to a file, which Client.ReplayTraffic reads
+	TrafficLog = mtproto.TrafficLog
+)
+
+const (
+	TransportIntermediate       = mtproto.TransportIntermediate
+	TransportAbridged           = mtproto.TransportAbridged
+	TransportPaddedIntermediate = mtproto.TransportPaddedIntermediate
+	TransportFull               = mtproto.TransportFull
+
+	ConnectionTCP       = mtproto.ConnectionTCP
+	ConnectionWebSocket = mtproto.ConnectionWebSocket
+	ConnectionHTTP      = mtproto.ConnectionHTTP
+
+	ProxySocks5 = mtproto.ProxySocks5
+	ProxySocks4 = mtproto.ProxySocks4
+	ProxyHTTP   = mtproto.ProxyHTTP
+)
+
 type clientData struct {
 	appID         int32
 	appHash       string
@@ -47,41 +100,71 @@ type cachedExportedSenders struct {
 
 // Client is the main struct of the library
 type Client struct {
-	*mtproto.MTProto
-	Cache           *CACHE
-	exportedSenders cachedExportedSenders
+	sender          *atomic.Pointer[mtproto.MTProto] // shared with WithContext copies, replaced when migrating
+	Cache           PeerCache
+	exportedSenders *cachedExportedSenders
 	clientData      clientData
-	wg              sync.WaitGroup
+	wg              *sync.WaitGroup
+	dcMutex         *sync.Mutex
 	stopCh          chan struct{}
+	dispatcher      *UpdateDispatcher
+	connHandlers    *connectionHandlers
+	updates         *updatesManager
 	Log             *utils.Logger
+
+	ctx    context.Context // set on copies made by WithContext
+	parent *Client         // the client a WithContext copy was made from
 }
 
 type ClientConfig struct {
-	AppID         int32
-	AppHash       string
-	DeviceModel   string
-	SystemVersion string
-	AppVersion    string
-	Session       string
-	StringSession string
-	LangCode      string
-	ParseMode     string
-	DataCenter    int
-	PublicKeys    []*rsa.PublicKey
-	NoUpdates     bool
-	LogLevel      string
+	AppID           int32
+	AppHash         string
+	DeviceModel     string
+	SystemVersion   string
+	AppVersion      string
+	Session         string
+	StringSession   string
+	SessionStorage  session.SessionLoader // custom session storage (database, secret manager, etc.), takes precedence over Session
+	Passphrase      string                // encrypts the session file and cache journal (AES-256-GCM), legacy files are migrated on load
+	LangCode        string
+	ParseMode       string
+	DataCenter      int
+	PublicKeys      []*rsa.PublicKey // added to the built in keys, for private or test servers, see ParsePublicKeys
+	NoUpdates       bool
+	UpdatesStorage  UpdatesStorage  // persists pts/qts/seq to catch up after restarts, defaults to a file next to the Session file, or memory without one
+	Cache           PeerCache       // custom peer cache (e.g. NewSQLCache), takes precedence over CacheFile
+	CacheFile       string          // path of the cache journal, defaults to cache.journal next to the session
+	NoCacheFile     bool            // keep the peer cache in memory only
+	RetryPolicy     *RetryPolicy    // flood wait and transient error handling, see RetryPolicy for the defaults
+	TransportMode   TransportMode   // framing of packets on the wire, TransportIntermediate by default
+	Obfuscated      bool            // obfuscated2 protocol for networks where DPI blocks mtproto
+	MTProxy         *MTProxy        // connect through an MTProxy, exported senders use it too
+	Connection      ConnectionType  // tcp (default), websocket or http
+	Proxy           *Proxy          // socks5, socks4 or http CONNECT proxy, used by exported senders too
+	Dialer          Dialer          // custom connection factory, proxies are dialed with it as well
+	TestMode        bool            // use the test servers, add their key to PublicKeys
+	IPv6            bool            // prefer ipv6 addresses of data centers
+	PFS             bool            // perfect forward secrecy, messages are encrypted with temporary keys
+	Recorder        TrafficRecorder // records the decrypted traffic, e.g. a TrafficLog made by NewTrafficLog
+	Instrumentation Instrumentation // metrics and tracing of requests, e.g. NewMetrics, NewTracing or both with MultiInstrumentation
+	LogLevel        string          // level of the default logger, ignored when Logger is set
+	Logger          Logger          // receives the logs of the client, e.g. NewSlogLogger, a StdLogger at LogLevel by default
 }
 
 func NewClient(config ClientConfig) (*Client, error) {
-	client := &Client{wg: sync.WaitGroup{}, Cache: cache, Log: utils.NewLogger("gogram"), stopCh: make(chan struct{})}
+	// the state is kept next to the session only if the session is a file of its own
+	fileSession := config.Session != "" && config.SessionStorage == nil && config.StringSession == ""
+	client := &Client{sender: newSender(nil), wg: &sync.WaitGroup{}, dcMutex: &sync.Mutex{}, exportedSenders: &cachedExportedSenders{}, connHandlers: newConnectionHandlers(), stopCh: make(chan struct{})}
 	config = cleanClientConfig(config)
+	client.dispatcher = newUpdateDispatcher(client)
 	client.setupClientData(config)
-	client.setupLogging()
+	client.setupLogging(config)
+	client.setupCache(config)
 	if err := client.setupMTProto(config); err != nil {
 		return nil, err
 	}
 	if !config.NoUpdates {
-		client.setupDispatcher()
+		client.setupDispatcher(config, fileSession)
 	}
 	if err := client.clientWarnings(config); err != nil {
 		return nil, err
@@ -89,12 +172,27 @@ func NewClient(config ClientConfig) (*Client, error) {
 	return client, nil
 }
 
+func (c *Client) setupCache(config ClientConfig) {
+	if config.Cache != nil {
+		c.Cache = config.Cache
+		return
+	}
+	c.Cache = NewCache(&CacheOptions{
+		JournalPath: getStr(config.CacheFile, filepath.Join(filepath.Dir(config.Session), defaultCacheJournal)),
+		NoJournal:   config.NoCacheFile,
+		Passphrase:  config.Passphrase,
+		Logger:      c.Log.Handler(),
+	})
+}
+
 func (c *Client) setupMTProto(config ClientConfig) error {
-	mtproto, err := mtproto.NewMTProto(mtproto.Config{AppID: config.AppID, AuthKeyFile: config.Session, ServerHost: GetHostIp(config.DataCenter), PublicKey: config.PublicKeys[0], DataCenter: config.DataCenter, LogLevel: LIB_LOG_LEVEL, StringSession: config.StringSession})
+	mtproto, err := mtproto.NewMTProto(mtproto.Config{AppID: config.AppID, AuthKeyFile: config.Session, SessionStorage: config.SessionStorage, Passphrase: config.Passphrase, RetryPolicy: config.RetryPolicy, TransportMode: config.TransportMode, Obfuscated: config.Obfuscated, MTProxy: config.MTProxy, Connection: config.Connection, Proxy: config.Proxy, Dialer: config.Dialer, TestMode: config.TestMode, IPv6: config.IPv6, PFS: config.PFS, Recorder: config.Recorder, Instrumentation: config.Instrumentation, PublicKeys: config.PublicKeys, DataCenter: config.DataCenter, Logger: c.Log.Handler(), StringSession: config.StringSession})
 	if err != nil {
 		return errors.Wrap(err, "creating mtproto client")
 	}
-	c.MTProto = mtproto
+	c.sender.Store(mtproto)
+	c.watchTempKeys()
+	c.watchConnection()
 	c.clientData.appID = mtproto.AppID() // in case the app id was not provided in the config but was in the session
 	return nil
 }
@@ -103,7 +201,7 @@ func (c *Client) clientWarnings(config ClientConfig) error {
 	if config.NoUpdates {
 		c.Log.Warn("client is running in no updates mode, some features may not work")
 	}
-	if !doesSessionFileExist(config.Session) && config.StringSession == "" && (c.AppID() == 0 || c.AppHash() == "") {
+	if !sessionExists(config) && config.StringSession == "" && (c.AppID() == 0 || c.AppHash() == "") {
 		return errors.New("your app id or app hash is empty, please provide them")
 	}
 	if config.AppHash == "" {
@@ -116,15 +214,25 @@ func (c *Client) clientWarnings(config ClientConfig) error {
 	return nil
 }
 
-func (c *Client) setupDispatcher() {
-	UpdateHandleDispatcher.client = c
-	c.AddCustomServerRequestHandler(HandleIncomingUpdates)
+func (c *Client) setupDispatcher(config ClientConfig, fileSession bool) {
+	storage := config.UpdatesStorage
+	switch {
+	case storage != nil:
+	case fileSession:
+		storage = NewUpdatesFileStorage(strings.TrimSuffix(config.Session, filepath.Ext(config.Session)) + ".updates")
+	default:
+		// the default session path is shared by every client, so are files next to it
+		storage = NewUpdatesMemoryStorage()
+	}
+	c.updates = newUpdatesManager(c, storage)
+	c.MTProto().AddCustomServerRequestHandler(c.HandleIncomingUpdates)
 }
 
 func cleanClientConfig(config ClientConfig) ClientConfig {
 	config.Session = getStr(config.Session, filepath.Join(getAbsWorkingDir(), "session.session"))
 	config.DataCenter = getInt(config.DataCenter, DefaultDataCenter)
-	config.PublicKeys, _ = keys.GetRSAKeys()
+	builtin, _ := keys.GetRSAKeys()
+	config.PublicKeys = append(config.PublicKeys, builtin...)
 	return config
 }
 
@@ -140,16 +248,15 @@ func (c *Client) setupClientData(cnf ClientConfig) {
 	c.clientData.parseMode = getStr(cnf.ParseMode, "HTML")
 }
 
-// set the log level of the library
-func (c *Client) setupLogging() {
-	c.Log.SetLevel(c.clientData.logLevel)
-	LIB_LOG_LEVEL = c.clientData.logLevel
+// setupLogging creates the logger shared by the client, its senders, updates and cache
+func (c *Client) setupLogging(config ClientConfig) {
+	c.Log = utils.NewLogger(logHandler(config.Logger, c.clientData.logLevel), "")
 }
 
 // initialRequest sends the initial initConnection request
 func (c *Client) InitialRequest() error {
 	c.Log.Debug("sending initial invokeWithLayer request")
-	_, err := c.InvokeWithLayer(ApiVersion, &InitConnectionParams{
+	resp, err := c.InvokeWithLayer(ApiVersion, &InitConnectionParams{
 		ApiID:          c.clientData.appID,
 		DeviceModel:    c.clientData.deviceModel,
 		SystemVersion:  c.clientData.systemVersion,
@@ -161,12 +268,45 @@ func (c *Client) InitialRequest() error {
 	if err != nil {
 		return errors.Wrap(err, "sending invokeWithLayer")
 	}
+	if config, ok := resp.(*Config); ok {
+		c.updateDCs(config)
+	}
 	return nil
 }
 
+// watchTempKeys initializes the connection again whenever pfs replaces the temporary key
+func (c *Client) watchTempKeys() {
+	c.MTProto().OnTempKeyBound(func() {
+		if err := c.InitialRequest(); err != nil {
+			c.Log.Error("initializing connection after temp key rotation", "error", err)
+		}
+	})
+}
+
+// updateDCs caches the data center addresses from help.getConfig, they are used for migrations,
+// exported senders and reconnects of all senders
+func (c *Client) updateDCs(config *Config) {
+	options := make([]DCOption, 0, len(config.DcOptions))
+	for _, o := range config.DcOptions {
+		options = append(options, DCOption{
+			ID:        int(o.ID),
+			IP:        o.IpAddress,
+			Port:      int(o.Port),
+			IPv6:      o.Ipv6,
+			MediaOnly: o.MediaOnly,
+			TCPOOnly:  o.TcpoOnly,
+			CDN:       o.Cdn,
+			Static:    o.Static,
+			Secret:    o.Secret,
+		})
+	}
+	c.MTProto().UpdateDCs(options, int(config.ThisDc))
+	c.Log.Debug("updated data center list", "addresses", len(options))
+}
+
 // Establish connection to telegram servers
 func (c *Client) Connect() error {
-	err := c.MTProto.CreateConnection(true)
+	err := c.MTProto().CreateConnection(true)
 	if err != nil {
 		return errors.Wrap(err, "creating connection")
 	}
@@ -174,9 +314,163 @@ func (c *Client) Connect() error {
 	return c.InitialRequest()
 }
 
+// WithContext returns a shallow copy of the client whose requests are bound to ctx,
+// they are aborted with ctx.Err() once ctx is cancelled or its deadline passes.
+// The copy shares the connection, cache and handlers with the original client.
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	me, err := client.WithContext(ctx).GetMe()
+func (c *Client) WithContext(ctx context.Context) *Client {
+	if ctx == nil {
+		panic("nil context")
+	}
+	cc := *c
+	cc.ctx = ctx
+	cc.parent = c.base()
+	return &cc
+}
+
+// Context returns the context requests of the client are bound to
+func (c *Client) Context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+	return context.Background()
+}
+
+// base returns the client WithContext copies were made from,
+// objects which outlive a request (messages, etc.) keep a reference to it instead of the copy
+func (c *Client) base() *Client {
+	if c.parent != nil {
+		return c.parent
+	}
+	return c
+}
+
+// MakeRequest sends a request, updates returned as its result advance the update state
+func (c *Client) MakeRequest(msg tl.Object) (any, error) {
+	return c.MakeRequestCtx(c.Context(), msg)
+}
+
+// MakeRequestCtx is like MakeRequest, but gives up waiting for the response once ctx is done
+func (c *Client) MakeRequestCtx(ctx context.Context, msg tl.Object) (any, error) {
+	resp, err := c.MTProto().MakeRequestCtx(ctx, msg)
+	var migrate *MigrateError
+	if errors.As(err, &migrate) {
+		resp, err = c.migrate(ctx, msg, migrate, err)
+	}
+	if err == nil && c.updates != nil {
+		if _, ok := resp.(Updates); ok {
+			c.updates.process(resp, true)
+		}
+	}
+	return resp, err
+}
+
+// MTProto returns the connection of the client, it's replaced when the client migrates to another data center
+func (c *Client) MTProto() *mtproto.MTProto {
+	return c.sender.Load()
+}
+
+func newSender(m *mtproto.MTProto) *atomic.Pointer[mtproto.MTProto] {
+	p := &atomic.Pointer[mtproto.MTProto]{}
+	if m != nil {
+		p.Store(m)
+	}
+	return p
+}
+
+// the methods below forward to the current connection, see MTProto, they were promoted
+// from it when the client embedded it
+
+func (c *Client) ExportAuth() ([]byte, []byte, string, int, int32) {
+	return c.MTProto().ExportAuth()
+}
+
+func (c *Client) ImportRawAuth(authKey []byte, authKeyHash []byte, addr string, dc int, appID int32) (bool, error) {
+	return c.MTProto().ImportRawAuth(authKey, authKeyHash, addr, dc, appID)
+}
+
+func (c *Client) ImportAuth(Session string) (bool, error) {
+	return c.MTProto().ImportAuth(Session)
+}
+
+// ReconnectToNewDC returns a new connection to dc, the client keeps using its own, see MakeRequest
+// for the automatic migration
+func (c *Client) ReconnectToNewDC(dc int) (*mtproto.MTProto, error) {
+	return c.MTProto().ReconnectToNewDC(dc)
+}
+
+func (c *Client) ExportNewSender(dcID int, mem bool) (*mtproto.MTProto, error) {
+	return c.MTProto().ExportNewSender(dcID, mem)
+}
+
+func (c *Client) CreateConnection(withLog bool) error {
+	return c.MTProto().CreateConnection(withLog)
+}
+
+func (c *Client) InvokeRequestWithoutUpdate(data tl.Object, expectedTypes ...reflect.Type) error {
+	return c.MTProto().InvokeRequestWithoutUpdate(data, expectedTypes...)
+}
+
+func (c *Client) TcpActive() bool {
+	return c.MTProto().TcpActive()
+}
+
+func (c *Client) Reconnect(WithLogs bool) error {
+	return c.MTProto().Reconnect(WithLogs)
+}
+
+func (c *Client) GetSessionID() int64 {
+	return c.MTProto().GetSessionID()
+}
+
+func (c *Client) GetSeqNo() int32 {
+	return c.MTProto().GetSeqNo()
+}
+
+func (c *Client) UpdateSeqNo() int32 {
+	return c.MTProto().UpdateSeqNo()
+}
+
+func (c *Client) GetServerSalt() int64 {
+	return c.MTProto().GetServerSalt()
+}
+
+func (c *Client) GetAuthKey() []byte {
+	return c.MTProto().GetAuthKey()
+}
+
+func (c *Client) SetAuthKey(key []byte) {
+	c.MTProto().SetAuthKey(key)
+}
+
+func (c *Client) MakeRequestWithHintToDecoder(msg tl.Object, expectedTypes ...reflect.Type) (any, error) {
+	return c.MTProto().MakeRequestWithHintToDecoder(msg, expectedTypes...)
+}
+
+// AddCustomServerRequestHandler adds a handler of the objects sent by the server, the handlers are
+// kept when the client migrates
+func (c *Client) AddCustomServerRequestHandler(handler func(i any) bool) {
+	c.MTProto().AddCustomServerRequestHandler(handler)
+}
+
+func (c *Client) SaveSession() error {
+	return c.MTProto().SaveSession()
+}
+
+func (c *Client) DeleteSession() error {
+	return c.MTProto().DeleteSession()
+}
+
+func (c *Client) LoadSession(s *session.Session) {
+	c.MTProto().LoadSession(s)
+}
+
 // Returns true if the client is connected to telegram servers
 func (c *Client) IsConnected() bool {
-	return c.MTProto.TcpActive()
+	return c.MTProto().TcpActive()
 }
 
 func (c *Client) Start() error {
@@ -198,27 +492,58 @@ func (c *Client) Start() error {
 // Returns true if the client is authorized as a user or a bot
 func (c *Client) IsAuthorized() (bool, error) {
 	c.Log.Debug("sending updates.getState request")
-	_, err := c.UpdatesGetState()
+	state, err := c.UpdatesGetState()
 	if err != nil {
 		return false, err
 	}
+	if c.updates != nil {
+		c.updates.start(state)
+	}
 	return true, nil
 }
 
 // Disconnect from telegram servers
 func (c *Client) Disconnect() error {
 	go c.cleanExportedSenders()
-	return c.MTProto.Disconnect()
+	return c.MTProto().Disconnect()
+}
+
+// migrate repeats a request in the data center the server redirected it to,
+// the main connection is moved for PHONE, USER and NETWORK migrations,
+// file and stats requests are sent through an exported sender of that data center
+func (c *Client) migrate(ctx context.Context, msg tl.Object, to *MigrateError, err error) (any, error) {
+	switch to.Kind {
+	case "FILE", "STATS":
+		if to.DC == c.GetDC() {
+			return nil, err
+		}
+		sender, err := c.borrowSender(to.DC)
+		if err != nil {
+			return nil, errors.Wrap(err, "borrowing sender for migration")
+		}
+		return sender.MTProto().MakeRequestCtx(ctx, msg)
+	}
+	if err := c.base().switchDC(to.DC); err != nil {
+		return nil, errors.Wrap(err, "migrating to dc "+strconv.Itoa(to.DC))
+	}
+	return c.MTProto().MakeRequestCtx(ctx, msg)
 }
 
 // switchDC permanently switches the data center
 func (c *Client) switchDC(dcID int) error {
-	c.Log.Debug("switching data center to [" + strconv.Itoa(dcID) + "]")
-	newDcSender, err := c.MTProto.ReconnectToNewDC(dcID)
+	c.dcMutex.Lock()
+	defer c.dcMutex.Unlock()
+	if c.MTProto().GetDC() == dcID {
+		return nil // already switched by a concurrent request
+	}
+	c.Log.Debug("switching data center", "new_dc", dcID)
+	newDcSender, err := c.MTProto().ReconnectToNewDC(dcID)
 	if err != nil {
 		return errors.Wrap(err, "reconnecting to new dc")
 	}
-	c.MTProto = newDcSender
+	c.sender.Store(newDcSender)
+	c.watchTempKeys()
+	c.watchConnection()
 	return c.InitialRequest()
 }
 
@@ -234,22 +559,23 @@ func (c *Client) GetCachedExportedSenders(dcID int) []*Client {
 
 // createExportedSender creates a new exported sender
 func (c *Client) createExportedSender(dcID int) (*Client, error) {
-	c.Log.Debug("creating exported sender for DC ", dcID)
-	exported, err := c.MTProto.ExportNewSender(dcID, true)
+	c.Log.Debug("creating exported sender", "sender_dc", dcID)
+	exported, err := c.MTProto().ExportNewSender(dcID, true)
 	if err != nil {
 		return nil, errors.Wrap(err, "exporting new sender")
 	}
-	exportedSender := &Client{MTProto: exported, Cache: c.Cache, Log: utils.NewLogger("gogram - sender").SetLevel(c.Log.Lev()), wg: sync.WaitGroup{}, clientData: c.clientData, stopCh: make(chan struct{})}
+	exportedSender := &Client{sender: newSender(exported), Cache: c.Cache, Log: c.Log.Named("sender").With("dc", dcID), wg: &sync.WaitGroup{}, dcMutex: &sync.Mutex{}, exportedSenders: &cachedExportedSenders{}, clientData: c.clientData, connHandlers: newConnectionHandlers(), stopCh: make(chan struct{})}
+	exportedSender.watchTempKeys()
 	err = exportedSender.InitialRequest()
 	if err != nil {
 		return nil, errors.Wrap(err, "initial request")
 	}
-	if c.MTProto.GetDC() != exported.GetDC() {
-		if err := exportedSender.shareAuthWithTimeout(c, exportedSender.MTProto.GetDC()); err != nil {
+	if c.MTProto().GetDC() != exported.GetDC() {
+		if err := exportedSender.shareAuthWithTimeout(c, exportedSender.MTProto().GetDC()); err != nil {
 			return nil, errors.Wrap(err, "sharing auth")
 		}
 	}
-	c.Log.Debug("exported sender for DC ", exported.GetDC(), " is ready")
+	c.Log.Debug("exported sender is ready", "sender_dc", exported.GetDC())
 	return exportedSender, nil
 }
 
@@ -304,28 +630,35 @@ func (c *Client) BorrowExportedSenders(dcID int, count ...int) ([]*Client, error
 	returned := make([]*Client, 0, countInt)
 	if c.exportedSenders.senders[dcID] == nil || len(c.exportedSenders.senders[dcID]) == 0 {
 		c.exportedSenders.senders[dcID] = make([]*Client, 0, countInt)
-		exportWaitGroup := sync.WaitGroup{}
+		var (
+			exportWaitGroup sync.WaitGroup
+			mutex           sync.Mutex
+			errs            []error
+		)
 		for i := 0; i < countInt; i++ {
 			exportWaitGroup.Add(1)
 			go func() {
 				defer exportWaitGroup.Done()
 				exportedSender, err := c.createExportedSender(dcID)
+				if errors.Is(err, mtproto.ErrAuthBytesInvalid) {
+					exportedSender, err = c.createExportedSender(dcID)
+				}
+				mutex.Lock()
+				defer mutex.Unlock()
 				if err != nil {
-					const AuthInvalidError = "The provided authorization is invalid"
-					if strings.Contains(err.Error(), AuthInvalidError) {
-						exportedSender, err = c.createExportedSender(dcID)
-						if err != nil {
-							return
-						}
-					} else {
-						c.Log.Error("error creating exported sender: ", err)
-					}
+					c.Log.Error("creating exported sender", "error", err, "sender_dc", dcID)
+					errs = append(errs, err)
+					return
 				}
 				returned = append(returned, exportedSender)
 				c.exportedSenders.senders[dcID] = append(c.exportedSenders.senders[dcID], exportedSender)
 			}()
 		}
 		exportWaitGroup.Wait()
+		if len(returned) < countInt {
+			// the created senders stay cached for the next borrow
+			return nil, errors.Wrapf(errs[0], "created %d of %d exported senders", len(returned), countInt)
+		}
 	} else {
 		total := len(c.exportedSenders.senders[dcID])
 		if total < countInt {
@@ -374,27 +707,28 @@ func (c *Client) cleanExportedSenders() {
 	}
 }
 
-// setLogLevel sets the log level for all loggers
+// SetLogLevel sets the level of the default logger, shared by the client, its senders, updates and cache,
+// loggers given in ClientConfig.Logger keep their own level
 func (c *Client) SetLogLevel(level string) {
-	c.Log.Debug("setting library log level to ", level)
+	c.Log.Debug("setting log level", "level", level)
 	c.Log.SetLevel(level)
 }
 
-// Ping telegram server TCP connection
+// Ping sends a ping and returns the round trip time, zero if no pong came back in time
 func (c *Client) Ping() time.Duration {
-	return c.MTProto.Ping()
+	return c.MTProto().Ping()
 }
 
 // Gets the connected DC-ID
 func (c *Client) GetDC() int {
-	return c.MTProto.GetDC()
+	return c.MTProto().GetDC()
 }
 
 // ExportSession exports the current session to a string,
 // This string can be used to import the session later
 func (c *Client) ExportSession() string {
-	authKey, authKeyHash, IpAddr, DcID, AppID := c.MTProto.ExportAuth()
-	c.Log.Debug("exporting session: ", authKey, authKeyHash, IpAddr, DcID, AppID)
+	authKey, authKeyHash, IpAddr, DcID, AppID := c.MTProto().ExportAuth()
+	c.Log.Debug("exporting session", "addr", IpAddr, "session_dc", DcID, "app_id", AppID)
 	return session.StringSession{AuthKey: authKey, AuthKeyHash: authKeyHash, IpAddr: IpAddr, DCID: DcID, AppID: AppID}.EncodeToString()
 }
 
@@ -403,8 +737,8 @@ func (c *Client) ExportSession() string {
 //	Params:
 //	  sessionString: The sessionString to authenticate with
 func (c *Client) ImportSession(sessionString string) (bool, error) {
-	c.Log.Debug("importing session: ", sessionString)
-	return c.MTProto.ImportAuth(sessionString)
+	c.Log.Debug("importing session")
+	return c.MTProto().ImportAuth(sessionString)
 }
 
 // ImportRawSession imports a session from raw TData
@@ -416,7 +750,7 @@ func (c *Client) ImportSession(sessionString string) (bool, error) {
 //	  DcID: The DC ID to connect to
 //	  AppID: The App ID to use
 func (c *Client) ImportRawSession(authKey, authKeyHash []byte, IpAddr string, DcID int, AppID int32) (bool, error) {
-	return c.MTProto.ImportRawAuth(authKey, authKeyHash, IpAddr, DcID, AppID)
+	return c.MTProto().ImportRawAuth(authKey, authKeyHash, IpAddr, DcID, AppID)
 }
 
 // ExportRawSession exports a session to raw TData
@@ -428,7 +762,7 @@ func (c *Client) ImportRawSession(authKey, authKeyHash []byte, IpAddr string, Dc
 //	  DcID: The DC ID to connect to
 //	  AppID: The App ID to use
 func (c *Client) ExportRawSession() ([]byte, []byte, string, int, int32) {
-	return c.MTProto.ExportAuth()
+	return c.MTProto().ExportAuth()
 }
 
 // returns the AppID (api_id) of the client
@@ -450,7 +784,7 @@ func (c *Client) ParseMode() string {
 // Terminate client and disconnect from telegram server
 func (c *Client) Terminate() error {
 	go c.cleanExportedSenders()
-	return c.MTProto.Terminate()
+	return c.MTProto().Terminate()
 }
 
 // Idle blocks the current goroutine until the client is stopped/terminated
@@ -463,5 +797,11 @@ func (c *Client) Idle() {
 // Stop stops the client and disconnects from telegram server
 func (c *Client) Stop() error {
 	close(c.stopCh)
-	return c.MTProto.Terminate()
+	if c.updates != nil {
+		c.updates.flush()
+	}
+	if closer, ok := c.Cache.(io.Closer); ok {
+		closer.Close()
+	}
+	return c.MTProto().Terminate()
 }
@@ -0,0 +1,312 @@
+package telegram_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jwillp/gogram/internal/encoding/tl"
+	"github.com/jwillp/gogram/internal/mtprototest"
+	"github.com/jwillp/gogram/session"
+	"github.com/jwillp/gogram/telegram"
+)
+
+// newClient returns a client connected to a fake server which answers help.getConfig and updates.getState
+func newClient(t *testing.T) (*telegram.Client, *mtprototest.Server) {
+	t.Helper()
+	srv := newServer(t)
+	return connectClient(t, srv, telegram.ClientConfig{
+		Session:        filepath.Join(t.TempDir(), "test.session"),
+		SessionStorage: session.NewInMemory(),
+	}), srv
+}
+
+func newServer(t *testing.T) *mtprototest.Server {
+	srv := mtprototest.NewServer(t)
+	srv.Respond(&telegram.HelpGetConfigParams{}, &telegram.Config{ThisDc: 2, Date: int32(time.Now().Unix())})
+	srv.Respond(&telegram.UpdatesGetStateParams{}, &telegram.UpdatesState{Pts: 10, Date: int32(time.Now().Unix())})
+	return srv
+}
+
+// connectClient connects a client with the session settings of config to srv, it's stopped when the test ends
+func connectClient(t *testing.T, srv *mtprototest.Server, config telegram.ClientConfig) *telegram.Client {
+	t.Helper()
+	config.AppID, config.AppHash = 1, "hash"
+	config.NoCacheFile = true
+	config.DataCenter = 2
+	config.PublicKeys = srv.PublicKeys()
+	config.Dialer = srv.Dial
+	config.LogLevel = "disabled"
+	client, err := telegram.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Stop() })
+	return client
+}
+
+func TestSendMessage(t *testing.T) {
+	client, srv := newClient(t)
+	srv.Handle(&telegram.MessagesSendMessageParams{}, func(request tl.Object) (tl.Object, error) {
+		req := request.(*telegram.MessagesSendMessageParams)
+		if peer, ok := req.Peer.(*telegram.InputPeerChat); !ok || peer.ChatID != 42 {
+			return nil, &mtprototest.RPCError{Code: 400, Message: "PEER_ID_INVALID"}
+		}
+		return &telegram.UpdatesObj{
+			Updates: []telegram.Update{&telegram.UpdateNewMessage{
+				Message:  &telegram.MessageObj{ID: 7, Out: true, Message: req.Message, PeerID: &telegram.PeerChat{ChatID: 42}},
+				Pts:      11,
+				PtsCount: 1,
+			}},
+			Date: int32(time.Now().Unix()),
+		}, nil
+	})
+
+	msg, err := client.SendMessage(&telegram.InputPeerChat{ChatID: 42}, "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.ID != 7 || msg.Text() != "hello" {
+		t.Errorf("sent message %d %q, want 7 hello", msg.ID, msg.Text())
+	}
+
+	if _, err := client.SendMessage(&telegram.InputPeerChat{ChatID: 1}, "hello"); err == nil {
+		t.Error("sending to an invalid peer succeeded")
+	}
+}
+
+func TestUpdatesAreDispatched(t *testing.T) {
+	client, srv := newClient(t)
+	// the update with pts 12 is missing, it's fetched with updates.getDifference
+	srv.Respond(&telegram.UpdatesGetDifferenceParams{}, &telegram.UpdatesDifferenceObj{
+		NewMessages: []telegram.Message{&telegram.MessageObj{ID: 2, Message: "second", PeerID: &telegram.PeerChat{ChatID: 42}}},
+		State:       &telegram.UpdatesState{Pts: 12, Date: int32(time.Now().Unix())},
+	})
+	received := make(chan string, 3)
+	client.AddMessageHandler(telegram.OnNewMessage, func(m *telegram.NewMessage) error {
+		received <- m.Text()
+		return nil
+	})
+	if _, err := client.IsAuthorized(); err != nil {
+		t.Fatal(err)
+	}
+
+	now := int32(time.Now().Unix())
+	for _, u := range []*telegram.UpdateShortChatMessage{
+		{ID: 1, FromID: 5, ChatID: 42, Message: "first", Pts: 11, PtsCount: 1, Date: now},
+		{ID: 3, FromID: 5, ChatID: 42, Message: "third", Pts: 13, PtsCount: 1, Date: now},
+	} {
+		if err := srv.Push(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// handlers run concurrently, so the messages may arrive in any order
+	got := make(map[string]bool)
+	for len(got) < 3 {
+		select {
+		case text := <-received:
+			got[text] = true
+		case <-time.After(10 * time.Second):
+			t.Fatalf("only %v were dispatched", got)
+		}
+	}
+	for _, want := range []string{"first", "second", "third"} {
+		if !got[want] {
+			t.Errorf("%q wasn't dispatched, got %v", want, got)
+		}
+	}
+}
+
+func TestMigrateWhileRequesting(t *testing.T) {
+	client, srv := newClient(t)
+	var migrated atomic.Bool
+	srv.Handle(&telegram.HelpGetNearestDcParams{}, func(tl.Object) (tl.Object, error) {
+		if migrated.CompareAndSwap(false, true) {
+			return nil, &mtprototest.RPCError{Code: 303, Message: "USER_MIGRATE_4"}
+		}
+		return &telegram.NearestDc{Country: "NL", ThisDc: 4, NearestDc: 4}, nil
+	})
+
+	sessionID := client.GetSessionID()
+
+	// requests made while the client switches its sender, run with -race
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			client.WithContext(ctx).HelpGetConfig()
+			client.GetDC()
+			cancel()
+		}
+	}()
+
+	nearest, err := client.HelpGetNearestDc()
+	close(done)
+	wg.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nearest.ThisDc != 4 || !migrated.Load() {
+		t.Errorf("got %+v, want the answer after the migration", nearest)
+	}
+	if _, err := client.HelpGetConfig(); err != nil {
+		t.Errorf("request after the migration: %v", err)
+	}
+	// the forwarding methods follow the connection the client migrated to
+	if client.GetSessionID() == sessionID || client.GetSessionID() != client.MTProto().GetSessionID() {
+		t.Error("GetSessionID returns the session of the old connection")
+	}
+}
+
+func TestUpdateStateIsPerClient(t *testing.T) {
+	dir := t.TempDir()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// checked once the clients are stopped and stored their state
+	t.Cleanup(func() {
+		files, _ := filepath.Glob(filepath.Join(dir, "*.updates"))
+		shared, _ := filepath.Glob(filepath.Join(filepath.Dir(exe), "*.updates"))
+		files = append(files, shared...)
+		sort.Strings(files)
+		want := []string{filepath.Join(dir, "a.updates"), filepath.Join(dir, "b.updates")}
+		if !reflect.DeepEqual(files, want) {
+			t.Errorf("update states were stored in %v, want %v", files, want)
+		}
+	})
+
+	srv := newServer(t)
+	for _, config := range []telegram.ClientConfig{
+		// sessions which aren't files keep the state in memory, the default session path is shared
+		{SessionStorage: session.NewInMemory()},
+		{SessionStorage: session.NewInMemory()},
+		// file sessions keep it next to them
+		{Session: filepath.Join(dir, "a.session")},
+		{Session: filepath.Join(dir, "b.session")},
+	} {
+		client := connectClient(t, srv, config)
+		if _, err := client.IsAuthorized(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestInvokeWithTakeout(t *testing.T) {
+	client, srv := newClient(t)
+	srv.Handle(&telegram.InvokeWithTakeoutParams{}, func(request tl.Object) (tl.Object, error) {
+		req, ok := request.(*telegram.InvokeWithTakeoutParams)
+		if !ok || req.TakeoutID != 5 {
+			return nil, &mtprototest.RPCError{Code: 400, Message: "TAKEOUT_INVALID"}
+		}
+		if _, ok := req.Query.(*telegram.HelpGetConfigParams); !ok {
+			return nil, &mtprototest.RPCError{Code: 400, Message: "INPUT_METHOD_INVALID"}
+		}
+		return &telegram.Config{ThisDc: 5}, nil
+	})
+
+	resp, err := client.InvokeWithTakeout(5, &telegram.HelpGetConfigParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config, ok := resp.(*telegram.Config); !ok || config.ThisDc != 5 {
+		t.Errorf("got %#v, want the config of the takeout handler", resp)
+	}
+}
+
+func TestClientsHaveTheirOwnDispatchers(t *testing.T) {
+	type received struct {
+		mutex    sync.Mutex
+		messages []string
+		albums   [][]string
+	}
+	texts := map[string][]string{"a": {"a1", "a2"}, "b": {"b1", "b2", "b3"}}
+	got := make(map[string]*received)
+	for name, want := range texts {
+		// a server each, so the updates of one client never reach the other
+		srv := newServer(t)
+		client := connectClient(t, srv, telegram.ClientConfig{SessionStorage: session.NewInMemory()})
+		r := &received{}
+		got[name] = r
+		client.AddMessageHandler(telegram.OnNewMessage, func(m *telegram.NewMessage) error {
+			r.mutex.Lock()
+			r.messages = append(r.messages, m.Text())
+			r.mutex.Unlock()
+			return nil
+		})
+		client.AddAlbumHandler(func(a *telegram.Album) error {
+			var album []string
+			for _, m := range a.Messages {
+				album = append(album, m.Text())
+			}
+			sort.Strings(album)
+			r.mutex.Lock()
+			r.albums = append(r.albums, album)
+			r.mutex.Unlock()
+			return nil
+		})
+		if _, err := client.IsAuthorized(); err != nil {
+			t.Fatal(err)
+		}
+
+		// both albums have the same grouped id
+		updates := &telegram.UpdatesObj{Date: int32(time.Now().Unix())}
+		for i, text := range want {
+			updates.Updates = append(updates.Updates, &telegram.UpdateNewMessage{
+				Message:  &telegram.MessageObj{ID: int32(i + 1), Message: text, GroupedID: 99, PeerID: &telegram.PeerChat{ChatID: 42}},
+				Pts:      int32(11 + i),
+				PtsCount: 1,
+			})
+		}
+		if err := srv.Push(updates); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for name, want := range texts {
+		r := got[name]
+		for {
+			r.mutex.Lock()
+			done := len(r.messages) >= len(want) && len(r.albums) >= 1
+			r.mutex.Unlock()
+			if done || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	// anything leaking to the other client would arrive by now
+	time.Sleep(100 * time.Millisecond)
+
+	for name, want := range texts {
+		r := got[name]
+		r.mutex.Lock()
+		sort.Strings(r.messages)
+		if !reflect.DeepEqual(r.messages, want) {
+			t.Errorf("client %s got the messages %v, want %v", name, r.messages, want)
+		}
+		if !reflect.DeepEqual(r.albums, [][]string{want}) {
+			t.Errorf("client %s got the albums %v, want %v", name, r.albums, [][]string{want})
+		}
+		r.mutex.Unlock()
+	}
+}
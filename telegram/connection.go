@@ -0,0 +1,85 @@
+package telegram
+
+import (
+	"sync"
+
+	mtproto "github.com/jwillp/gogram"
+)
+
+// ConnectionState is the state of the connection to telegram
+type ConnectionState = mtproto.ConnectionState
+
+const (
+	StateDisconnected = mtproto.StateDisconnected
+	StateConnecting   = mtproto.StateConnecting
+	StateConnected    = mtproto.StateConnected
+	StateReconnecting = mtproto.StateReconnecting
+)
+
+// connectionHandlers delivers connection state changes to the handlers, in order and outside of the
+// connection goroutines
+type connectionHandlers struct {
+	sync.Mutex
+	handlers []func(ConnectionState)
+	events   chan ConnectionState
+	last     ConnectionState
+	started  sync.Once
+}
+
+func newConnectionHandlers() *connectionHandlers {
+	return &connectionHandlers{events: make(chan ConnectionState, 16)}
+}
+
+func (h *connectionHandlers) push(state ConnectionState) {
+	h.Lock()
+	defer h.Unlock()
+	if state == h.last {
+		return
+	}
+	h.last = state
+	select {
+	case h.events <- state:
+	default: // the handlers are stuck, they'll get the next change
+	}
+}
+
+func (h *connectionHandlers) run(stop chan struct{}) {
+	for {
+		select {
+		case state := <-h.events:
+			h.Lock()
+			handlers := append([]func(ConnectionState){}, h.handlers...)
+			h.Unlock()
+			for _, handler := range handlers {
+				handler(state)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// AddConnectionHandler registers a function called whenever the connection state changes
+// (connecting, connected, reconnecting, disconnected), handlers are called one after another in a separate goroutine
+//
+//	client.AddConnectionHandler(func(state telegram.ConnectionState) {
+//		log.Println("connection is", state)
+//	})
+func (c *Client) AddConnectionHandler(handler func(state ConnectionState)) {
+	h := c.connHandlers
+	h.Lock()
+	h.handlers = append(h.handlers, handler)
+	h.Unlock()
+	h.started.Do(func() { go h.run(c.stopCh) })
+}
+
+// ConnectionState returns the current state of the connection
+func (c *Client) ConnectionState() ConnectionState {
+	return c.MTProto().State()
+}
+
+// watchConnection forwards the state changes of the current sender to the connection handlers
+func (c *Client) watchConnection() {
+	c.MTProto().OnStateChange(c.connHandlers.push)
+	c.connHandlers.push(c.MTProto().State())
+}
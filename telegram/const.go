@@ -32,6 +32,7 @@ const (
 )
 
 var (
+	// Deprecated: not used anymore, set ClientConfig.LogLevel or ClientConfig.Logger, every client has its own
 	LIB_LOG_LEVEL = LogInfo
 )
 
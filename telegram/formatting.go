@@ -17,7 +17,7 @@ type Formatter struct {
 
 func NewFormatter() *Formatter {
 	return &Formatter{
-		Log: utils.NewLogger("gogram - formatter - "),
+		Log: utils.NewLogger(nil, "formatter"),
 	}
 }
 
@@ -125,7 +125,7 @@ func (f *Formatter) parseHTMLRune(in []rune, prevO int32) ([]MessageEntity, stri
 			case "span":
 				// NOTE: All span tags are currently spoiler tags. This may change in the future.
 				if len(tagFields) < 2 {
-					f.Log.Warn("no closing tag for HTML tag started at ", i)
+					f.Log.Warn("no closing tag for HTML tag", "offset", i)
 					return entities, string(in)
 				}
 
@@ -133,7 +133,7 @@ func (f *Formatter) parseHTMLRune(in []rune, prevO int32) ([]MessageEntity, stri
 				case "class=\"tg-spoiler\"":
 					out.WriteString(html.UnescapeString(string(in[closeTag+1 : closingOpen])))
 				default:
-					f.Log.Warn("unknown span type ", spanType)
+					f.Log.Warn("unknown span type", "type", spanType)
 					return entities, string(in)
 				}
 			case "a":
@@ -152,10 +152,10 @@ func (f *Formatter) parseHTMLRune(in []rune, prevO int32) ([]MessageEntity, stri
 						URL:    link2.FindStringSubmatch(tagContent)[1],
 					})
 				} else {
-					f.Log.Warn("unknown link format ", tagContent)
+					f.Log.Warn("unknown link format", "link", tagContent)
 				}
 			default:
-				f.Log.Warn("unknown HTML tag ", tag)
+				f.Log.Warn("unknown HTML tag", "tag", tag)
 				return entities, string(in)
 			}
 
@@ -2,6 +2,7 @@ package telegram
 
 import (
 	"bytes"
+	"crypto/rsa"
 	"fmt"
 	"math/rand"
 	"os"
@@ -11,6 +12,8 @@ import (
 	"strconv"
 	"strings"
 
+	mtproto "github.com/jwillp/gogram"
+	"github.com/jwillp/gogram/internal/keys"
 	"github.com/pkg/errors"
 )
 
@@ -29,6 +32,16 @@ func FileExists(path string) bool {
 	return err == nil
 }
 
+// ParsePublicKeys parses PEM encoded server keys, for ClientConfig.PublicKeys
+func ParsePublicKeys(pemData []byte) ([]*rsa.PublicKey, error) {
+	return keys.ParseRSAKeys(pemData)
+}
+
+// NewTrafficLog creates a file recording the traffic of the client, for ClientConfig.Recorder
+func NewTrafficLog(path string) (*TrafficLog, error) {
+	return mtproto.NewTrafficLog(path)
+}
+
 func GetHostIp(dcID int) string {
 	if ip, ok := DataCenters[dcID]; ok {
 		return ip
@@ -193,7 +206,7 @@ func processUpdates(updates Updates) []*MessageObj {
 	return messages
 }
 
-func processUpdate(upd Updates) *MessageObj {
+func (c *Client) processUpdate(upd Updates) *MessageObj {
 	if upd == nil {
 		return nil
 	}
@@ -228,7 +241,7 @@ updateTypeSwitch:
 				ID: upd.ID,
 			}
 		default:
-			fmt.Println("unknown update type", reflect.TypeOf(upd).String())
+			c.Log.Debug("unknown update type", "type", reflect.TypeOf(upd).String())
 		}
 	case *UpdateShortMessage:
 		return &MessageObj{Out: update.Out, ID: update.ID, PeerID: &PeerUser{}, Date: update.Date, Entities: update.Entities, TtlPeriod: update.TtlPeriod}
@@ -238,7 +251,7 @@ updateTypeSwitch:
 		upd = &UpdatesObj{Updates: []Update{update.Update}}
 		goto updateTypeSwitch
 	default:
-		fmt.Println("unknown update type", reflect.TypeOf(update).String())
+		c.Log.Debug("unknown update type", "type", reflect.TypeOf(update).String())
 	}
 	return nil
 }
@@ -305,6 +318,11 @@ PeerSwitch:
 		if Peer == "me" || Peer == "self" {
 			return &InputPeerSelf{}, nil
 		}
+		if id, ok := c.Cache.LookupUsername(Peer); ok {
+			if peer, err := c.Cache.GetInputPeer(id); err == nil {
+				return peer, nil
+			}
+		}
 		peerEntity, err := c.ResolveUsername(Peer)
 		if err != nil {
 			return nil, err
@@ -595,6 +613,7 @@ func (c *Client) ResolveUsername(username string) (interface{}, error) {
 }
 
 func packMessage(c *Client, message Message) *NewMessage {
+	c = c.base() // messages outlive the context of the request which returned them
 	var (
 		m = &NewMessage{}
 	)
@@ -124,7 +124,7 @@ PhotoTypeSwitch:
 		Photo, _ = b.Client.getSendableMedia(media, &MediaMetadata{})
 		goto PhotoTypeSwitch
 	default:
-		b.Client.Logger.Warn("InlineBuilder.Photo: Photo is not a InputMediaPhoto, its a %T", p)
+		b.Client.Log.Warn("InlineBuilder.Photo: photo is not an InputMediaPhoto", "type", fmt.Sprintf("%T", p))
 		Image = &InputPhotoEmpty{}
 	}
 	e, text := b.Client.FormatMessage(opts.Caption, getValue(opts.ParseMode, b.Client.ParseMode).(string))
@@ -180,7 +180,7 @@ DocTypeSwitch:
 		Document, _ = b.Client.getSendableMedia(media, &MediaMetadata{})
 		goto DocTypeSwitch
 	default:
-		b.Client.Logger.Warn("InlineBuilder.Document: Document is not a InputMediaDocument")
+		b.Client.Log.Warn("InlineBuilder.Document: document is not an InputMediaDocument")
 		Doc = &InputDocumentEmpty{}
 	}
 	e, text := b.Client.FormatMessage(opts.Caption, getValue(opts.ParseMode, b.Client.ParseMode).(string))
@@ -0,0 +1,35 @@
+package telegram
+
+import (
+	mtproto "github.com/jwillp/gogram"
+)
+
+type (
+	// Instrumentation gets the requests, traffic and reconnects of the client and its senders, see ClientConfig.Instrumentation
+	Instrumentation = mtproto.Instrumentation
+	// RequestInfo describes a request made by the client
+	RequestInfo = mtproto.RequestInfo
+	// RequestResult describes how a request ended
+	RequestResult = mtproto.RequestResult
+	// Metrics counts requests, errors, flood waits, reconnects and traffic, served in the Prometheus text format
+	Metrics = mtproto.Metrics
+	// Tracer starts spans, an OpenTelemetry tracer fits it with a small adapter
+	Tracer = mtproto.Tracer
+	// Span is a span started by a Tracer
+	Span = mtproto.Span
+)
+
+// NewMetrics returns metrics for ClientConfig.Instrumentation, mount them as an http.Handler at /metrics
+func NewMetrics() *Metrics {
+	return mtproto.NewMetrics()
+}
+
+// NewTracing returns an Instrumentation making a span of every request of the client
+func NewTracing(t Tracer) Instrumentation {
+	return mtproto.NewTracing(t)
+}
+
+// MultiInstrumentation passes the events to every one of list, e.g. to both metrics and tracing
+func MultiInstrumentation(list ...Instrumentation) Instrumentation {
+	return mtproto.MultiInstrumentation(list...)
+}
@@ -0,0 +1,35 @@
+package telegram
+
+import (
+	mtproto "github.com/jwillp/gogram"
+)
+
+type (
+	// Logger receives the log messages of the client, its senders, updates and cache, see ClientConfig.Logger
+	Logger = mtproto.Logger
+	// LogLevel is the severity of a log message
+	LogLevel = mtproto.LogLevel
+	// StdLogger is the default Logger, writing with the standard log package
+	StdLogger = mtproto.StdLogger
+)
+
+const (
+	LevelDebug    = mtproto.LevelDebug
+	LevelInfo     = mtproto.LevelInfo
+	LevelWarn     = mtproto.LevelWarn
+	LevelError    = mtproto.LevelError
+	LevelDisabled = mtproto.LevelDisabled
+)
+
+// NewStdLogger returns a logger writing the messages of the level and above with the standard log package
+func NewStdLogger(level LogLevel) *StdLogger {
+	return mtproto.NewStdLogger(level)
+}
+
+// logHandler returns logger, or a StdLogger at level if it's nil
+func logHandler(logger Logger, level string) Logger {
+	if logger != nil {
+		return logger
+	}
+	return NewStdLogger(mtproto.ParseLogLevel(getStr(level, LogInfo)))
+}
@@ -0,0 +1,23 @@
+//go:build go1.21
+
+package telegram
+
+import (
+	"log/slog"
+
+	mtproto "github.com/jwillp/gogram"
+)
+
+// NewSlogLogger returns a Logger writing to l, fields become attributes
+//
+//	client, err := telegram.NewClient(telegram.ClientConfig{
+//		Logger: telegram.NewSlogLogger(slog.Default().With("account", "bot")),
+//	})
+func NewSlogLogger(l *slog.Logger) Logger {
+	return mtproto.NewSlogLogger(l)
+}
+
+// NewSlogHandler returns an slog handler writing to l
+func NewSlogHandler(l Logger) slog.Handler {
+	return mtproto.NewSlogHandler(l)
+}
@@ -2,8 +2,8 @@ package telegram
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
-	"fmt"
 	"hash"
 	"io"
 	"io/fs"
@@ -46,6 +46,11 @@ func (c *Client) UploadFile(file interface{}, Opts ...*UploadOptions) (InputFile
 	return u.Upload()
 }
 
+// UploadFileCtx is like UploadFile, but stops uploading parts and returns ctx.Err() once ctx is done
+func (c *Client) UploadFileCtx(ctx context.Context, file interface{}, Opts ...*UploadOptions) (InputFile, error) {
+	return c.WithContext(ctx).UploadFile(file, Opts...)
+}
+
 type (
 	Uploader struct {
 		*Client
@@ -69,6 +74,9 @@ func (u *Uploader) Upload() (InputFile, error) {
 	u.Init()
 	u.Start()
 	u.closeWorkers()
+	if err := u.Context().Err(); err != nil {
+		return nil, err
+	}
 	return u.saveFile()
 }
 
@@ -131,7 +139,7 @@ func (u *Uploader) allocateWorkers() {
 	}
 	u.Workers = borrowedSenders
 
-	u.Client.Log.Debug("Allocated workers: ", len(u.Workers))
+	u.Client.Log.Debug("allocated upload workers", "workers", len(u.Workers))
 }
 
 func (u *Uploader) closeWorkers() {}
@@ -251,10 +259,14 @@ func (u *Uploader) readPart(part int32) ([]byte, error) {
 
 func (u *Uploader) uploadParts(w *Client, parts []int32) {
 	defer u.wg.Done()
+	w = w.WithContext(u.Context())
 	for i := parts[0]; i < parts[1]; i++ {
+		if w.Context().Err() != nil {
+			return
+		}
 		buf, err := u.readPart(i)
 		if err != nil {
-			u.Client.Log.Error(err)
+			u.Client.Log.Error("reading file part", "error", err, "part", i)
 			continue
 		}
 		if u.Meta.Big {
@@ -263,8 +275,11 @@ func (u *Uploader) uploadParts(w *Client, parts []int32) {
 			u.Meta.Hash.Write(buf)
 			_, err = w.UploadSaveFilePart(u.FileID, i, buf)
 		}
-		w.Logger.Debug(fmt.Sprintf("uploaded part %d of %d", i, u.Parts))
+		w.Log.Debug("uploaded part", "part", i, "parts", u.Parts)
 		if err != nil {
+			if w.Context().Err() != nil {
+				return
+			}
 			panic(err)
 		}
 	}
@@ -305,6 +320,12 @@ func (c *Client) DownloadMedia(file interface{}, Opts ...*DownloadOptions) (stri
 	return d.Download()
 }
 
+// DownloadMediaCtx is like DownloadMedia, but stops downloading parts, removes the partial file
+// and returns ctx.Err() once ctx is done
+func (c *Client) DownloadMediaCtx(ctx context.Context, file interface{}, Opts ...*DownloadOptions) (string, error) {
+	return c.WithContext(ctx).DownloadMedia(file, Opts...)
+}
+
 type (
 	Downloader struct {
 		*Client
@@ -366,7 +387,7 @@ func (d *Downloader) allocateWorkers() {
 	if d.Worker == 1 {
 		wNew, err := d.Client.borrowSender(int(d.DcID))
 		if err != nil {
-			d.Client.Log.Error(err)
+			d.Client.Log.Error("borrowing sender", "error", err, "sender_dc", d.DcID)
 		}
 		d.Workers = []*Client{wNew}
 		return
@@ -374,7 +395,7 @@ func (d *Downloader) allocateWorkers() {
 	wg := &sync.WaitGroup{}
 	bs, err := d.Client.BorrowExportedSenders(int(d.DcID), d.Worker)
 	if err != nil {
-		d.Client.Log.Error(err)
+		d.Client.Log.Error("borrowing senders", "error", err, "sender_dc", d.DcID)
 	}
 	d.Workers = bs
 	wg.Wait()
@@ -423,6 +444,10 @@ func (d *Downloader) Start() (string, error) {
 	}
 	d.wg.Wait()
 	d.closeWorkers()
+	if err := d.Context().Err(); err != nil {
+		d.onError()
+		return "", err
+	}
 	return d.FileName, nil
 }
 
@@ -447,7 +472,11 @@ func (d *Downloader) calcOffset(part int32) int64 {
 
 func (d *Downloader) downloadParts(w *Client, parts []int32) {
 	defer d.wg.Done()
+	w = w.WithContext(d.Context())
 	for i := parts[0]; i < parts[1]; i++ {
+		if w.Context().Err() != nil {
+			return
+		}
 		buf, err := w.UploadGetFile(&UploadGetFileParams{
 			Location:     d.Source,
 			Offset:       d.calcOffset(i),
@@ -455,10 +484,10 @@ func (d *Downloader) downloadParts(w *Client, parts []int32) {
 			CdnSupported: false,
 		})
 		if err != nil || buf == nil {
-			w.Logger.Warn(err)
+			w.Log.Warn("downloading part", "error", err, "part", i)
 			continue
 		}
-		w.Logger.Debug(fmt.Sprintf("downloaded part %d of %d", i, d.Parts))
+		w.Log.Debug("downloaded part", "part", i, "parts", d.Parts)
 		var buffer []byte
 		switch v := buf.(type) {
 		case *UploadFileObj:
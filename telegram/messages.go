@@ -1,6 +1,7 @@
 package telegram
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"reflect"
@@ -28,6 +29,11 @@ type SendOptions struct {
 	Entites       []MessageEntity
 }
 
+// SendMessageCtx is like SendMessage, but aborts with ctx.Err() once ctx is done
+func (c *Client) SendMessageCtx(ctx context.Context, peerID interface{}, message interface{}, opts ...*SendOptions) (*NewMessage, error) {
+	return c.WithContext(ctx).SendMessage(peerID, message, opts...)
+}
+
 // SendMessage sends a message.
 // This method is a wrapper for messages.sendMessage.
 //
@@ -106,11 +112,16 @@ func (c *Client) sendMessage(Peer InputPeer, Message string, entities []MessageE
 		return nil, err
 	}
 	if updateResp != nil {
-		return packMessage(c, processUpdate(updateResp)), nil
+		return packMessage(c, c.processUpdate(updateResp)), nil
 	}
 	return nil, errors.New("no response")
 }
 
+// EditMessageCtx is like EditMessage, but aborts with ctx.Err() once ctx is done
+func (c *Client) EditMessageCtx(ctx context.Context, peerID interface{}, id int32, message interface{}, opts ...*SendOptions) (*NewMessage, error) {
+	return c.WithContext(ctx).EditMessage(peerID, id, message, opts...)
+}
+
 // EditMessage edits a message.
 // This method is a wrapper for messages.editMessage.
 func (c *Client) EditMessage(peerID interface{}, id int32, message interface{}, opts ...*SendOptions) (*NewMessage, error) {
@@ -179,7 +190,7 @@ func (c *Client) editMessage(Peer InputPeer, id int32, Message string, entities
 		return nil, err
 	}
 	if updateResp != nil {
-		return packMessage(c, processUpdate(updateResp)), nil
+		return packMessage(c, c.processUpdate(updateResp)), nil
 	}
 	return nil, errors.New("no response")
 }
@@ -265,6 +276,11 @@ type MediaMetadata struct {
 	TTL           int32               `json:"ttl,omitempty"`
 }
 
+// SendMediaCtx is like SendMedia, but aborts with ctx.Err() once ctx is done
+func (c *Client) SendMediaCtx(ctx context.Context, peerID interface{}, Media interface{}, opts ...*MediaOptions) (*NewMessage, error) {
+	return c.WithContext(ctx).SendMedia(peerID, Media, opts...)
+}
+
 // SendMedia sends a media message.
 // This method is a wrapper for messages.sendMedia.
 //
@@ -328,11 +344,16 @@ func (c *Client) sendMedia(Peer InputPeer, Media InputMedia, Caption string, ent
 		return nil, err
 	}
 	if updateResp != nil {
-		return packMessage(c, processUpdate(updateResp)), nil
+		return packMessage(c, c.processUpdate(updateResp)), nil
 	}
 	return nil, errors.New("no response")
 }
 
+// SendAlbumCtx is like SendAlbum, but aborts with ctx.Err() once ctx is done
+func (c *Client) SendAlbumCtx(ctx context.Context, peerID interface{}, Album interface{}, opts ...*MediaOptions) ([]*NewMessage, error) {
+	return c.WithContext(ctx).SendAlbum(peerID, Album, opts...)
+}
+
 // SendAlbum sends a media album.
 // This method is a wrapper for messages.sendMultiMedia.
 //
@@ -620,6 +641,11 @@ type SearchOption struct {
 	MinDate  int32          `json:"min_date,omitempty"`
 }
 
+// GetMessagesCtx is like GetMessages, but aborts with ctx.Err() once ctx is done
+func (c *Client) GetMessagesCtx(ctx context.Context, PeerID interface{}, Opts ...*SearchOption) ([]NewMessage, error) {
+	return c.WithContext(ctx).GetMessages(PeerID, Opts...)
+}
+
 func (c *Client) GetMessages(PeerID interface{}, Opts ...*SearchOption) ([]NewMessage, error) {
 	opt := getVariadic(Opts, &SearchOption{
 		Filter: &InputMessagesFilterEmpty{},
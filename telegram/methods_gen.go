@@ -3,8 +3,8 @@
 package telegram
 
 import (
+	mtproto "github.com/jwillp/gogram"
 	errors "github.com/pkg/errors"
-	"reflect"
 )
 
 type AccountAcceptAuthorizationParams struct {
@@ -33,7 +33,7 @@ func (c *Client) AccountAcceptAuthorization(botID int64, scope, publicKey string
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountAcceptAuthorization", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -52,7 +52,7 @@ func (c *Client) AccountCancelPasswordEmail() (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountCancelPasswordEmail", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -83,7 +83,7 @@ func (c *Client) AccountChangeAuthorizationSettings(hash int64, encryptedRequest
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountChangeAuthorizationSettings", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -110,7 +110,7 @@ func (c *Client) AccountChangePhone(phoneNumber, phoneCodeHash, phoneCode string
 
 	resp, ok := responseData.(User)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountChangePhone", "User", responseData)
 	}
 	return resp, nil
 }
@@ -131,7 +131,7 @@ func (c *Client) AccountCheckUsername(username string) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountCheckUsername", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -150,7 +150,7 @@ func (c *Client) AccountClearRecentEmojiStatuses() (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountClearRecentEmojiStatuses", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -171,7 +171,7 @@ func (c *Client) AccountConfirmPasswordEmail(code string) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountConfirmPasswordEmail", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -196,7 +196,7 @@ func (c *Client) AccountConfirmPhone(phoneCodeHash, phoneCode string) (bool, err
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountConfirmPhone", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -229,7 +229,7 @@ func (c *Client) AccountCreateTheme(slug, title string, document InputDocument,
 
 	resp, ok := responseData.(*Theme)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountCreateTheme", "*Theme", responseData)
 	}
 	return resp, nil
 }
@@ -248,7 +248,7 @@ func (c *Client) AccountDeclinePasswordReset() (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountDeclinePasswordReset", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -277,7 +277,7 @@ func (c *Client) AccountDeleteAccount(reason string, password InputCheckPassword
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountDeleteAccount", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -298,7 +298,7 @@ func (c *Client) AccountDeleteSecureValue(types []SecureValueType) (bool, error)
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountDeleteSecureValue", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -323,7 +323,7 @@ func (c *Client) AccountFinishTakeoutSession(success bool) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountFinishTakeoutSession", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -342,7 +342,7 @@ func (c *Client) AccountGetAccountTtl() (*AccountDaysTtl, error) {
 
 	resp, ok := responseData.(*AccountDaysTtl)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetAccountTtl", "*AccountDaysTtl", responseData)
 	}
 	return resp, nil
 }
@@ -361,7 +361,7 @@ func (c *Client) AccountGetAllSecureValues() ([]*SecureValue, error) {
 
 	resp, ok := responseData.([]*SecureValue)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetAllSecureValues", "[]*SecureValue", responseData)
 	}
 	return resp, nil
 }
@@ -388,7 +388,7 @@ func (c *Client) AccountGetAuthorizationForm(botID int64, scope, publicKey strin
 
 	resp, ok := responseData.(*AccountAuthorizationForm)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetAuthorizationForm", "*AccountAuthorizationForm", responseData)
 	}
 	return resp, nil
 }
@@ -407,7 +407,7 @@ func (c *Client) AccountGetAuthorizations() (*AccountAuthorizations, error) {
 
 	resp, ok := responseData.(*AccountAuthorizations)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetAuthorizations", "*AccountAuthorizations", responseData)
 	}
 	return resp, nil
 }
@@ -426,7 +426,7 @@ func (c *Client) AccountGetAutoDownloadSettings() (*AccountAutoDownloadSettings,
 
 	resp, ok := responseData.(*AccountAutoDownloadSettings)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetAutoDownloadSettings", "*AccountAutoDownloadSettings", responseData)
 	}
 	return resp, nil
 }
@@ -447,7 +447,7 @@ func (c *Client) AccountGetChatThemes(hash int64) (AccountThemes, error) {
 
 	resp, ok := responseData.(AccountThemes)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetChatThemes", "AccountThemes", responseData)
 	}
 	return resp, nil
 }
@@ -466,7 +466,7 @@ func (c *Client) AccountGetContactSignUpNotification() (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountGetContactSignUpNotification", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -485,7 +485,7 @@ func (c *Client) AccountGetContentSettings() (*AccountContentSettings, error) {
 
 	resp, ok := responseData.(*AccountContentSettings)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetContentSettings", "*AccountContentSettings", responseData)
 	}
 	return resp, nil
 }
@@ -506,7 +506,7 @@ func (c *Client) AccountGetDefaultEmojiStatuses(hash int64) (AccountEmojiStatuse
 
 	resp, ok := responseData.(AccountEmojiStatuses)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetDefaultEmojiStatuses", "AccountEmojiStatuses", responseData)
 	}
 	return resp, nil
 }
@@ -525,7 +525,7 @@ func (c *Client) AccountGetGlobalPrivacySettings() (*GlobalPrivacySettings, erro
 
 	resp, ok := responseData.(*GlobalPrivacySettings)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetGlobalPrivacySettings", "*GlobalPrivacySettings", responseData)
 	}
 	return resp, nil
 }
@@ -546,7 +546,7 @@ func (c *Client) AccountGetMultiWallPapers(wallpapers []InputWallPaper) ([]WallP
 
 	resp, ok := responseData.([]WallPaper)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetMultiWallPapers", "[]WallPaper", responseData)
 	}
 	return resp, nil
 }
@@ -575,7 +575,7 @@ func (c *Client) AccountGetNotifyExceptions(compareSound bool, peer InputNotifyP
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetNotifyExceptions", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -596,7 +596,7 @@ func (c *Client) AccountGetNotifySettings(peer InputNotifyPeer) (*PeerNotifySett
 
 	resp, ok := responseData.(*PeerNotifySettings)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetNotifySettings", "*PeerNotifySettings", responseData)
 	}
 	return resp, nil
 }
@@ -615,7 +615,7 @@ func (c *Client) AccountGetPassword() (*AccountPassword, error) {
 
 	resp, ok := responseData.(*AccountPassword)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetPassword", "*AccountPassword", responseData)
 	}
 	return resp, nil
 }
@@ -636,7 +636,7 @@ func (c *Client) AccountGetPasswordSettings(password InputCheckPasswordSRP) (*Ac
 
 	resp, ok := responseData.(*AccountPasswordSettings)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetPasswordSettings", "*AccountPasswordSettings", responseData)
 	}
 	return resp, nil
 }
@@ -657,7 +657,7 @@ func (c *Client) AccountGetPrivacy(key InputPrivacyKey) (*AccountPrivacyRules, e
 
 	resp, ok := responseData.(*AccountPrivacyRules)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetPrivacy", "*AccountPrivacyRules", responseData)
 	}
 	return resp, nil
 }
@@ -678,7 +678,7 @@ func (c *Client) AccountGetRecentEmojiStatuses(hash int64) (AccountEmojiStatuses
 
 	resp, ok := responseData.(AccountEmojiStatuses)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetRecentEmojiStatuses", "AccountEmojiStatuses", responseData)
 	}
 	return resp, nil
 }
@@ -699,7 +699,7 @@ func (c *Client) AccountGetSavedRingtones(hash int64) (AccountSavedRingtones, er
 
 	resp, ok := responseData.(AccountSavedRingtones)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetSavedRingtones", "AccountSavedRingtones", responseData)
 	}
 	return resp, nil
 }
@@ -720,7 +720,7 @@ func (c *Client) AccountGetSecureValue(types []SecureValueType) ([]*SecureValue,
 
 	resp, ok := responseData.([]*SecureValue)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetSecureValue", "[]*SecureValue", responseData)
 	}
 	return resp, nil
 }
@@ -747,7 +747,7 @@ func (c *Client) AccountGetTheme(format string, theme InputTheme, documentID int
 
 	resp, ok := responseData.(*Theme)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetTheme", "*Theme", responseData)
 	}
 	return resp, nil
 }
@@ -772,7 +772,7 @@ func (c *Client) AccountGetThemes(format string, hash int64) (AccountThemes, err
 
 	resp, ok := responseData.(AccountThemes)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetThemes", "AccountThemes", responseData)
 	}
 	return resp, nil
 }
@@ -797,7 +797,7 @@ func (c *Client) AccountGetTmpPassword(password InputCheckPasswordSRP, period in
 
 	resp, ok := responseData.(*AccountTmpPassword)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetTmpPassword", "*AccountTmpPassword", responseData)
 	}
 	return resp, nil
 }
@@ -818,7 +818,7 @@ func (c *Client) AccountGetWallPaper(wallpaper InputWallPaper) (WallPaper, error
 
 	resp, ok := responseData.(WallPaper)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetWallPaper", "WallPaper", responseData)
 	}
 	return resp, nil
 }
@@ -839,7 +839,7 @@ func (c *Client) AccountGetWallPapers(hash int64) (AccountWallPapers, error) {
 
 	resp, ok := responseData.(AccountWallPapers)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetWallPapers", "AccountWallPapers", responseData)
 	}
 	return resp, nil
 }
@@ -858,7 +858,7 @@ func (c *Client) AccountGetWebAuthorizations() (*AccountWebAuthorizations, error
 
 	resp, ok := responseData.(*AccountWebAuthorizations)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountGetWebAuthorizations", "*AccountWebAuthorizations", responseData)
 	}
 	return resp, nil
 }
@@ -889,7 +889,7 @@ func (c *Client) AccountInitTakeoutSession(params *AccountInitTakeoutSessionPara
 
 	resp, ok := responseData.(*AccountTakeout)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountInitTakeoutSession", "*AccountTakeout", responseData)
 	}
 	return resp, nil
 }
@@ -922,7 +922,7 @@ func (c *Client) AccountInstallTheme(dark bool, theme InputTheme, format string,
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountInstallTheme", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -947,7 +947,7 @@ func (c *Client) AccountInstallWallPaper(wallpaper InputWallPaper, settings *Wal
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountInstallWallPaper", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -977,7 +977,7 @@ func (c *Client) AccountRegisterDevice(params *AccountRegisterDeviceParams) (boo
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountRegisterDevice", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1004,7 +1004,7 @@ func (c *Client) AccountReportPeer(peer InputPeer, reason ReportReason, message
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountReportPeer", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1033,7 +1033,7 @@ func (c *Client) AccountReportProfilePhoto(peer InputPeer, photoID InputPhoto, r
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountReportProfilePhoto", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1052,7 +1052,7 @@ func (c *Client) AccountResendPasswordEmail() (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountResendPasswordEmail", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1073,7 +1073,7 @@ func (c *Client) AccountResetAuthorization(hash int64) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountResetAuthorization", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1092,7 +1092,7 @@ func (c *Client) AccountResetNotifySettings() (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountResetNotifySettings", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1111,7 +1111,7 @@ func (c *Client) AccountResetPassword() (AccountResetPasswordResult, error) {
 
 	resp, ok := responseData.(AccountResetPasswordResult)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountResetPassword", "AccountResetPasswordResult", responseData)
 	}
 	return resp, nil
 }
@@ -1130,7 +1130,7 @@ func (c *Client) AccountResetWallPapers() (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountResetWallPapers", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1151,7 +1151,7 @@ func (c *Client) AccountResetWebAuthorization(hash int64) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountResetWebAuthorization", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1170,7 +1170,7 @@ func (c *Client) AccountResetWebAuthorizations() (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountResetWebAuthorizations", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1201,7 +1201,7 @@ func (c *Client) AccountSaveAutoDownloadSettings(low, high bool, settings *AutoD
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountSaveAutoDownloadSettings", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1226,7 +1226,7 @@ func (c *Client) AccountSaveRingtone(id InputDocument, unsave bool) (AccountSave
 
 	resp, ok := responseData.(AccountSavedRingtone)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountSaveRingtone", "AccountSavedRingtone", responseData)
 	}
 	return resp, nil
 }
@@ -1251,7 +1251,7 @@ func (c *Client) AccountSaveSecureValue(value *InputSecureValue, secureSecretID
 
 	resp, ok := responseData.(*SecureValue)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountSaveSecureValue", "*SecureValue", responseData)
 	}
 	return resp, nil
 }
@@ -1276,7 +1276,7 @@ func (c *Client) AccountSaveTheme(theme InputTheme, unsave bool) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountSaveTheme", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1303,7 +1303,7 @@ func (c *Client) AccountSaveWallPaper(wallpaper InputWallPaper, unsave bool, set
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountSaveWallPaper", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1328,7 +1328,7 @@ func (c *Client) AccountSendChangePhoneCode(phoneNumber string, settings *CodeSe
 
 	resp, ok := responseData.(*AuthSentCode)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountSendChangePhoneCode", "*AuthSentCode", responseData)
 	}
 	return resp, nil
 }
@@ -1353,7 +1353,7 @@ func (c *Client) AccountSendConfirmPhoneCode(hash string, settings *CodeSettings
 
 	resp, ok := responseData.(*AuthSentCode)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountSendConfirmPhoneCode", "*AuthSentCode", responseData)
 	}
 	return resp, nil
 }
@@ -1378,7 +1378,7 @@ func (c *Client) AccountSendVerifyEmailCode(purpose EmailVerifyPurpose, email st
 
 	resp, ok := responseData.(*AccountSentEmailCode)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountSendVerifyEmailCode", "*AccountSentEmailCode", responseData)
 	}
 	return resp, nil
 }
@@ -1403,7 +1403,7 @@ func (c *Client) AccountSendVerifyPhoneCode(phoneNumber string, settings *CodeSe
 
 	resp, ok := responseData.(*AuthSentCode)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountSendVerifyPhoneCode", "*AuthSentCode", responseData)
 	}
 	return resp, nil
 }
@@ -1424,7 +1424,7 @@ func (c *Client) AccountSetAccountTtl(ttl *AccountDaysTtl) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountSetAccountTtl", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1445,7 +1445,7 @@ func (c *Client) AccountSetAuthorizationTtl(authorizationTtlDays int32) (bool, e
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountSetAuthorizationTtl", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1466,7 +1466,7 @@ func (c *Client) AccountSetContactSignUpNotification(silent bool) (bool, error)
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountSetContactSignUpNotification", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1491,7 +1491,7 @@ func (c *Client) AccountSetContentSettings(sensitiveEnabled bool) (bool, error)
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountSetContentSettings", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1512,7 +1512,7 @@ func (c *Client) AccountSetGlobalPrivacySettings(settings *GlobalPrivacySettings
 
 	resp, ok := responseData.(*GlobalPrivacySettings)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountSetGlobalPrivacySettings", "*GlobalPrivacySettings", responseData)
 	}
 	return resp, nil
 }
@@ -1537,7 +1537,7 @@ func (c *Client) AccountSetPrivacy(key InputPrivacyKey, rules []InputPrivacyRule
 
 	resp, ok := responseData.(*AccountPrivacyRules)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountSetPrivacy", "*AccountPrivacyRules", responseData)
 	}
 	return resp, nil
 }
@@ -1564,7 +1564,7 @@ func (c *Client) AccountUnregisterDevice(tokenType int32, token string, otherUid
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountUnregisterDevice", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1585,7 +1585,7 @@ func (c *Client) AccountUpdateDeviceLocked(period int32) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountUpdateDeviceLocked", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1606,7 +1606,7 @@ func (c *Client) AccountUpdateEmojiStatus(emojiStatus EmojiStatus) (bool, error)
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountUpdateEmojiStatus", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1631,7 +1631,7 @@ func (c *Client) AccountUpdateNotifySettings(peer InputNotifyPeer, settings *Inp
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountUpdateNotifySettings", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1656,7 +1656,7 @@ func (c *Client) AccountUpdatePasswordSettings(password InputCheckPasswordSRP, n
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountUpdatePasswordSettings", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1687,7 +1687,7 @@ func (c *Client) AccountUpdateProfile(firstName, lastName, about string) (User,
 
 	resp, ok := responseData.(User)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountUpdateProfile", "User", responseData)
 	}
 	return resp, nil
 }
@@ -1708,7 +1708,7 @@ func (c *Client) AccountUpdateStatus(offline bool) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountUpdateStatus", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1738,7 +1738,7 @@ func (c *Client) AccountUpdateTheme(params *AccountUpdateThemeParams) (*Theme, e
 
 	resp, ok := responseData.(*Theme)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountUpdateTheme", "*Theme", responseData)
 	}
 	return resp, nil
 }
@@ -1759,7 +1759,7 @@ func (c *Client) AccountUpdateUsername(username string) (User, error) {
 
 	resp, ok := responseData.(User)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountUpdateUsername", "User", responseData)
 	}
 	return resp, nil
 }
@@ -1786,7 +1786,7 @@ func (c *Client) AccountUploadRingtone(file InputFile, fileName, mimeType string
 
 	resp, ok := responseData.(Document)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountUploadRingtone", "Document", responseData)
 	}
 	return resp, nil
 }
@@ -1819,7 +1819,7 @@ func (c *Client) AccountUploadTheme(file, thumb InputFile, fileName, mimeType st
 
 	resp, ok := responseData.(Document)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountUploadTheme", "Document", responseData)
 	}
 	return resp, nil
 }
@@ -1846,7 +1846,7 @@ func (c *Client) AccountUploadWallPaper(file InputFile, mimeType string, setting
 
 	resp, ok := responseData.(WallPaper)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountUploadWallPaper", "WallPaper", responseData)
 	}
 	return resp, nil
 }
@@ -1871,7 +1871,7 @@ func (c *Client) AccountVerifyEmail(purpose EmailVerifyPurpose, verification Ema
 
 	resp, ok := responseData.(AccountEmailVerified)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AccountVerifyEmail", "AccountEmailVerified", responseData)
 	}
 	return resp, nil
 }
@@ -1898,7 +1898,7 @@ func (c *Client) AccountVerifyPhone(phoneNumber, phoneCodeHash, phoneCode string
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AccountVerifyPhone", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1919,7 +1919,7 @@ func (c *Client) AuthAcceptLoginToken(token []byte) (*Authorization, error) {
 
 	resp, ok := responseData.(*Authorization)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AuthAcceptLoginToken", "*Authorization", responseData)
 	}
 	return resp, nil
 }
@@ -1948,7 +1948,7 @@ func (c *Client) AuthBindTempAuthKey(permAuthKeyID, nonce int64, expiresAt int32
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AuthBindTempAuthKey", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1973,7 +1973,7 @@ func (c *Client) AuthCancelCode(phoneNumber, phoneCodeHash string) (bool, error)
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AuthCancelCode", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -1994,7 +1994,7 @@ func (c *Client) AuthCheckPassword(password InputCheckPasswordSRP) (AuthAuthoriz
 
 	resp, ok := responseData.(AuthAuthorization)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AuthCheckPassword", "AuthAuthorization", responseData)
 	}
 	return resp, nil
 }
@@ -2015,7 +2015,7 @@ func (c *Client) AuthCheckRecoveryPassword(code string) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AuthCheckRecoveryPassword", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -2036,7 +2036,7 @@ func (c *Client) AuthDropTempAuthKeys(exceptAuthKeys []int64) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AuthDropTempAuthKeys", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -2057,7 +2057,7 @@ func (c *Client) AuthExportAuthorization(dcID int32) (*AuthExportedAuthorization
 
 	resp, ok := responseData.(*AuthExportedAuthorization)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AuthExportAuthorization", "*AuthExportedAuthorization", responseData)
 	}
 	return resp, nil
 }
@@ -2084,7 +2084,7 @@ func (c *Client) AuthExportLoginToken(apiID int32, apiHash string, exceptIds []i
 
 	resp, ok := responseData.(AuthLoginToken)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AuthExportLoginToken", "AuthLoginToken", responseData)
 	}
 	return resp, nil
 }
@@ -2109,7 +2109,7 @@ func (c *Client) AuthImportAuthorization(id int64, bytes []byte) (AuthAuthorizat
 
 	resp, ok := responseData.(AuthAuthorization)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AuthImportAuthorization", "AuthAuthorization", responseData)
 	}
 	return resp, nil
 }
@@ -2138,7 +2138,7 @@ func (c *Client) AuthImportBotAuthorization(flags, apiID int32, apiHash, botAuth
 
 	resp, ok := responseData.(AuthAuthorization)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AuthImportBotAuthorization", "AuthAuthorization", responseData)
 	}
 	return resp, nil
 }
@@ -2159,7 +2159,7 @@ func (c *Client) AuthImportLoginToken(token []byte) (AuthLoginToken, error) {
 
 	resp, ok := responseData.(AuthLoginToken)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AuthImportLoginToken", "AuthLoginToken", responseData)
 	}
 	return resp, nil
 }
@@ -2178,7 +2178,7 @@ func (c *Client) AuthLogOut() (*AuthLoggedOut, error) {
 
 	resp, ok := responseData.(*AuthLoggedOut)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AuthLogOut", "*AuthLoggedOut", responseData)
 	}
 	return resp, nil
 }
@@ -2207,7 +2207,7 @@ func (c *Client) AuthRecoverPassword(code string, newSettings *AccountPasswordIn
 
 	resp, ok := responseData.(AuthAuthorization)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AuthRecoverPassword", "AuthAuthorization", responseData)
 	}
 	return resp, nil
 }
@@ -2226,7 +2226,7 @@ func (c *Client) AuthRequestPasswordRecovery() (*AuthPasswordRecovery, error) {
 
 	resp, ok := responseData.(*AuthPasswordRecovery)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AuthRequestPasswordRecovery", "*AuthPasswordRecovery", responseData)
 	}
 	return resp, nil
 }
@@ -2251,7 +2251,7 @@ func (c *Client) AuthResendCode(phoneNumber, phoneCodeHash string) (*AuthSentCod
 
 	resp, ok := responseData.(*AuthSentCode)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AuthResendCode", "*AuthSentCode", responseData)
 	}
 	return resp, nil
 }
@@ -2270,7 +2270,7 @@ func (c *Client) AuthResetAuthorizations() (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("AuthResetAuthorizations", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -2299,7 +2299,7 @@ func (c *Client) AuthSendCode(phoneNumber string, apiID int32, apiHash string, s
 
 	resp, ok := responseData.(*AuthSentCode)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AuthSendCode", "*AuthSentCode", responseData)
 	}
 	return resp, nil
 }
@@ -2332,7 +2332,7 @@ func (c *Client) AuthSignIn(phoneNumber, phoneCodeHash, phoneCode string, emailV
 
 	resp, ok := responseData.(AuthAuthorization)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AuthSignIn", "AuthAuthorization", responseData)
 	}
 	return resp, nil
 }
@@ -2361,7 +2361,7 @@ func (c *Client) AuthSignUp(phoneNumber, phoneCodeHash, firstName, lastName stri
 
 	resp, ok := responseData.(AuthAuthorization)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("AuthSignUp", "AuthAuthorization", responseData)
 	}
 	return resp, nil
 }
@@ -2386,7 +2386,7 @@ func (c *Client) BotsAnswerWebhookJsonQuery(queryID int64, data *DataJson) (bool
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("BotsAnswerWebhookJsonQuery", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -2411,7 +2411,7 @@ func (c *Client) BotsGetBotCommands(scope BotCommandScope, langCode string) ([]*
 
 	resp, ok := responseData.([]*BotCommand)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("BotsGetBotCommands", "[]*BotCommand", responseData)
 	}
 	return resp, nil
 }
@@ -2432,7 +2432,7 @@ func (c *Client) BotsGetBotMenuButton(userID InputUser) (BotMenuButton, error) {
 
 	resp, ok := responseData.(BotMenuButton)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("BotsGetBotMenuButton", "BotMenuButton", responseData)
 	}
 	return resp, nil
 }
@@ -2457,7 +2457,7 @@ func (c *Client) BotsResetBotCommands(scope BotCommandScope, langCode string) (b
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("BotsResetBotCommands", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -2482,7 +2482,7 @@ func (c *Client) BotsSendCustomRequest(customMethod string, params *DataJson) (*
 
 	resp, ok := responseData.(*DataJson)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("BotsSendCustomRequest", "*DataJson", responseData)
 	}
 	return resp, nil
 }
@@ -2503,7 +2503,7 @@ func (c *Client) BotsSetBotBroadcastDefaultAdminRights(adminRights *ChatAdminRig
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("BotsSetBotBroadcastDefaultAdminRights", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -2530,7 +2530,7 @@ func (c *Client) BotsSetBotCommands(scope BotCommandScope, langCode string, comm
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("BotsSetBotCommands", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -2551,7 +2551,7 @@ func (c *Client) BotsSetBotGroupDefaultAdminRights(adminRights *ChatAdminRights)
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("BotsSetBotGroupDefaultAdminRights", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -2576,7 +2576,7 @@ func (c *Client) BotsSetBotMenuButton(userID InputUser, button BotMenuButton) (b
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("BotsSetBotMenuButton", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -2601,7 +2601,7 @@ func (c *Client) ChannelsCheckUsername(channel InputChannel, username string) (b
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("ChannelsCheckUsername", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -2622,7 +2622,7 @@ func (c *Client) ChannelsConvertToGigagroup(channel InputChannel) (Updates, erro
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsConvertToGigagroup", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -2653,7 +2653,7 @@ func (c *Client) ChannelsCreateChannel(params *ChannelsCreateChannelParams) (Upd
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsCreateChannel", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -2674,7 +2674,7 @@ func (c *Client) ChannelsDeleteChannel(channel InputChannel) (Updates, error) {
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsDeleteChannel", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -2705,7 +2705,7 @@ func (c *Client) ChannelsDeleteHistory(forEveryone bool, channel InputChannel, m
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsDeleteHistory", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -2730,7 +2730,7 @@ func (c *Client) ChannelsDeleteMessages(channel InputChannel, id []int32) (*Mess
 
 	resp, ok := responseData.(*MessagesAffectedMessages)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsDeleteMessages", "*MessagesAffectedMessages", responseData)
 	}
 	return resp, nil
 }
@@ -2755,7 +2755,7 @@ func (c *Client) ChannelsDeleteParticipantHistory(channel InputChannel, particip
 
 	resp, ok := responseData.(*MessagesAffectedHistory)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsDeleteParticipantHistory", "*MessagesAffectedHistory", responseData)
 	}
 	return resp, nil
 }
@@ -2784,7 +2784,7 @@ func (c *Client) ChannelsEditAdmin(channel InputChannel, userID InputUser, admin
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsEditAdmin", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -2811,7 +2811,7 @@ func (c *Client) ChannelsEditBanned(channel InputChannel, participant InputPeer,
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsEditBanned", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -2838,7 +2838,7 @@ func (c *Client) ChannelsEditCreator(channel InputChannel, userID InputUser, pas
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsEditCreator", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -2865,7 +2865,7 @@ func (c *Client) ChannelsEditLocation(channel InputChannel, geoPoint InputGeoPoi
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("ChannelsEditLocation", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -2890,7 +2890,7 @@ func (c *Client) ChannelsEditPhoto(channel InputChannel, photo InputChatPhoto) (
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsEditPhoto", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -2915,7 +2915,7 @@ func (c *Client) ChannelsEditTitle(channel InputChannel, title string) (Updates,
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsEditTitle", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -2948,7 +2948,7 @@ func (c *Client) ChannelsExportMessageLink(grouped, thread bool, channel InputCh
 
 	resp, ok := responseData.(*ExportedMessageLink)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsExportMessageLink", "*ExportedMessageLink", responseData)
 	}
 	return resp, nil
 }
@@ -2979,7 +2979,7 @@ func (c *Client) ChannelsGetAdminLog(params *ChannelsGetAdminLogParams) (*Channe
 
 	resp, ok := responseData.(*ChannelsAdminLogResults)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsGetAdminLog", "*ChannelsAdminLogResults", responseData)
 	}
 	return resp, nil
 }
@@ -3008,7 +3008,7 @@ func (c *Client) ChannelsGetAdminedPublicChannels(byLocation, checkLimit bool) (
 
 	resp, ok := responseData.(MessagesChats)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsGetAdminedPublicChannels", "MessagesChats", responseData)
 	}
 	return resp, nil
 }
@@ -3029,7 +3029,7 @@ func (c *Client) ChannelsGetChannels(id []InputChannel) (MessagesChats, error) {
 
 	resp, ok := responseData.(MessagesChats)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsGetChannels", "MessagesChats", responseData)
 	}
 	return resp, nil
 }
@@ -3050,7 +3050,7 @@ func (c *Client) ChannelsGetFullChannel(channel InputChannel) (*MessagesChatFull
 
 	resp, ok := responseData.(*MessagesChatFull)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsGetFullChannel", "*MessagesChatFull", responseData)
 	}
 	return resp, nil
 }
@@ -3069,7 +3069,7 @@ func (c *Client) ChannelsGetGroupsForDiscussion() (MessagesChats, error) {
 
 	resp, ok := responseData.(MessagesChats)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsGetGroupsForDiscussion", "MessagesChats", responseData)
 	}
 	return resp, nil
 }
@@ -3088,7 +3088,7 @@ func (c *Client) ChannelsGetInactiveChannels() (*MessagesInactiveChats, error) {
 
 	resp, ok := responseData.(*MessagesInactiveChats)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsGetInactiveChannels", "*MessagesInactiveChats", responseData)
 	}
 	return resp, nil
 }
@@ -3109,7 +3109,7 @@ func (c *Client) ChannelsGetLeftChannels(offset int32) (MessagesChats, error) {
 
 	resp, ok := responseData.(MessagesChats)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsGetLeftChannels", "MessagesChats", responseData)
 	}
 	return resp, nil
 }
@@ -3134,7 +3134,7 @@ func (c *Client) ChannelsGetMessages(channel InputChannel, id []InputMessage) (M
 
 	resp, ok := responseData.(MessagesMessages)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsGetMessages", "MessagesMessages", responseData)
 	}
 	return resp, nil
 }
@@ -3159,7 +3159,7 @@ func (c *Client) ChannelsGetParticipant(channel InputChannel, participant InputP
 
 	resp, ok := responseData.(*ChannelsChannelParticipant)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsGetParticipant", "*ChannelsChannelParticipant", responseData)
 	}
 	return resp, nil
 }
@@ -3190,7 +3190,7 @@ func (c *Client) ChannelsGetParticipants(channel InputChannel, filter ChannelPar
 
 	resp, ok := responseData.(ChannelsChannelParticipants)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsGetParticipants", "ChannelsChannelParticipants", responseData)
 	}
 	return resp, nil
 }
@@ -3211,7 +3211,7 @@ func (c *Client) ChannelsGetSendAs(peer InputPeer) (*ChannelsSendAsPeers, error)
 
 	resp, ok := responseData.(*ChannelsSendAsPeers)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsGetSendAs", "*ChannelsSendAsPeers", responseData)
 	}
 	return resp, nil
 }
@@ -3232,7 +3232,7 @@ func (c *Client) ChannelsGetSponsoredMessages(channel InputChannel) (*MessagesSp
 
 	resp, ok := responseData.(*MessagesSponsoredMessages)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsGetSponsoredMessages", "*MessagesSponsoredMessages", responseData)
 	}
 	return resp, nil
 }
@@ -3257,7 +3257,7 @@ func (c *Client) ChannelsInviteToChannel(channel InputChannel, users []InputUser
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsInviteToChannel", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -3278,7 +3278,7 @@ func (c *Client) ChannelsJoinChannel(channel InputChannel) (Updates, error) {
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsJoinChannel", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -3299,7 +3299,7 @@ func (c *Client) ChannelsLeaveChannel(channel InputChannel) (Updates, error) {
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsLeaveChannel", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -3324,7 +3324,7 @@ func (c *Client) ChannelsReadHistory(channel InputChannel, maxID int32) (bool, e
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("ChannelsReadHistory", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -3349,7 +3349,7 @@ func (c *Client) ChannelsReadMessageContents(channel InputChannel, id []int32) (
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("ChannelsReadMessageContents", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -3376,7 +3376,7 @@ func (c *Client) ChannelsReportSpam(channel InputChannel, participant InputPeer,
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("ChannelsReportSpam", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -3401,7 +3401,7 @@ func (c *Client) ChannelsSetDiscussionGroup(broadcast, group InputChannel) (bool
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("ChannelsSetDiscussionGroup", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -3426,7 +3426,7 @@ func (c *Client) ChannelsSetStickers(channel InputChannel, stickerset InputStick
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("ChannelsSetStickers", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -3451,7 +3451,7 @@ func (c *Client) ChannelsToggleJoinRequest(channel InputChannel, enabled bool) (
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsToggleJoinRequest", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -3476,7 +3476,7 @@ func (c *Client) ChannelsToggleJoinToSend(channel InputChannel, enabled bool) (U
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsToggleJoinToSend", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -3501,7 +3501,7 @@ func (c *Client) ChannelsTogglePreHistoryHidden(channel InputChannel, enabled bo
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsTogglePreHistoryHidden", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -3526,7 +3526,7 @@ func (c *Client) ChannelsToggleSignatures(channel InputChannel, enabled bool) (U
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsToggleSignatures", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -3551,7 +3551,7 @@ func (c *Client) ChannelsToggleSlowMode(channel InputChannel, seconds int32) (Up
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ChannelsToggleSlowMode", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -3576,7 +3576,7 @@ func (c *Client) ChannelsUpdateUsername(channel InputChannel, username string) (
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("ChannelsUpdateUsername", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -3601,7 +3601,7 @@ func (c *Client) ChannelsViewSponsoredMessage(channel InputChannel, randomID []b
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("ChannelsViewSponsoredMessage", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -3622,7 +3622,7 @@ func (c *Client) ContactsAcceptContact(id InputUser) (Updates, error) {
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ContactsAcceptContact", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -3651,7 +3651,7 @@ func (c *Client) ContactsAddContact(params *ContactsAddContactParams) (Updates,
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ContactsAddContact", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -3672,7 +3672,7 @@ func (c *Client) ContactsBlock(id InputPeer) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("ContactsBlock", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -3705,7 +3705,7 @@ func (c *Client) ContactsBlockFromReplies(deleteMessage, deleteHistory, reportSp
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ContactsBlockFromReplies", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -3726,7 +3726,7 @@ func (c *Client) ContactsDeleteByPhones(phones []string) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("ContactsDeleteByPhones", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -3747,7 +3747,7 @@ func (c *Client) ContactsDeleteContacts(id []InputUser) (Updates, error) {
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ContactsDeleteContacts", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -3772,7 +3772,7 @@ func (c *Client) ContactsGetBlocked(offset, limit int32) (ContactsBlocked, error
 
 	resp, ok := responseData.(ContactsBlocked)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ContactsGetBlocked", "ContactsBlocked", responseData)
 	}
 	return resp, nil
 }
@@ -3793,7 +3793,7 @@ func (c *Client) ContactsGetContactIDs(hash int64) ([]int32, error) {
 
 	resp, ok := responseData.([]int32)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ContactsGetContactIDs", "[]int32", responseData)
 	}
 	return resp, nil
 }
@@ -3814,7 +3814,7 @@ func (c *Client) ContactsGetContacts(hash int64) (ContactsContacts, error) {
 
 	resp, ok := responseData.(ContactsContacts)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ContactsGetContacts", "ContactsContacts", responseData)
 	}
 	return resp, nil
 }
@@ -3845,7 +3845,7 @@ func (c *Client) ContactsGetLocated(background bool, geoPoint InputGeoPoint, sel
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ContactsGetLocated", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -3864,7 +3864,7 @@ func (c *Client) ContactsGetSaved() ([]*SavedPhoneContact, error) {
 
 	resp, ok := responseData.([]*SavedPhoneContact)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ContactsGetSaved", "[]*SavedPhoneContact", responseData)
 	}
 	return resp, nil
 }
@@ -3883,7 +3883,7 @@ func (c *Client) ContactsGetStatuses() ([]*ContactStatus, error) {
 
 	resp, ok := responseData.([]*ContactStatus)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ContactsGetStatuses", "[]*ContactStatus", responseData)
 	}
 	return resp, nil
 }
@@ -3918,7 +3918,7 @@ func (c *Client) ContactsGetTopPeers(params *ContactsGetTopPeersParams) (Contact
 
 	resp, ok := responseData.(ContactsTopPeers)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ContactsGetTopPeers", "ContactsTopPeers", responseData)
 	}
 	return resp, nil
 }
@@ -3939,7 +3939,7 @@ func (c *Client) ContactsImportContacts(contacts []*InputPhoneContact) (*Contact
 
 	resp, ok := responseData.(*ContactsImportedContacts)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ContactsImportContacts", "*ContactsImportedContacts", responseData)
 	}
 	return resp, nil
 }
@@ -3958,7 +3958,7 @@ func (c *Client) ContactsResetSaved() (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("ContactsResetSaved", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -3983,7 +3983,7 @@ func (c *Client) ContactsResetTopPeerRating(category TopPeerCategory, peer Input
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("ContactsResetTopPeerRating", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -4004,7 +4004,7 @@ func (c *Client) ContactsResolvePhone(phone string) (*ContactsResolvedPeer, erro
 
 	resp, ok := responseData.(*ContactsResolvedPeer)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ContactsResolvePhone", "*ContactsResolvedPeer", responseData)
 	}
 	return resp, nil
 }
@@ -4025,7 +4025,7 @@ func (c *Client) ContactsResolveUsername(username string) (*ContactsResolvedPeer
 
 	resp, ok := responseData.(*ContactsResolvedPeer)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ContactsResolveUsername", "*ContactsResolvedPeer", responseData)
 	}
 	return resp, nil
 }
@@ -4050,7 +4050,7 @@ func (c *Client) ContactsSearch(q string, limit int32) (*ContactsFound, error) {
 
 	resp, ok := responseData.(*ContactsFound)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("ContactsSearch", "*ContactsFound", responseData)
 	}
 	return resp, nil
 }
@@ -4071,7 +4071,7 @@ func (c *Client) ContactsToggleTopPeers(enabled bool) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("ContactsToggleTopPeers", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -4092,7 +4092,7 @@ func (c *Client) ContactsUnblock(id InputPeer) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("ContactsUnblock", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -4113,7 +4113,7 @@ func (c *Client) FoldersDeleteFolder(folderID int32) (Updates, error) {
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("FoldersDeleteFolder", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -4134,7 +4134,7 @@ func (c *Client) FoldersEditPeerFolders(folderPeers []*InputFolderPeer) (Updates
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("FoldersEditPeerFolders", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -4155,7 +4155,7 @@ func (c *Client) HelpAcceptTermsOfService(id *DataJson) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("HelpAcceptTermsOfService", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -4180,7 +4180,7 @@ func (c *Client) HelpDismissSuggestion(peer InputPeer, suggestion string) (bool,
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("HelpDismissSuggestion", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -4207,7 +4207,7 @@ func (c *Client) HelpEditUserInfo(userID InputUser, message string, entities []M
 
 	resp, ok := responseData.(HelpUserInfo)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("HelpEditUserInfo", "HelpUserInfo", responseData)
 	}
 	return resp, nil
 }
@@ -4228,7 +4228,7 @@ func (c *Client) HelpGetAppChangelog(prevAppVersion string) (Updates, error) {
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("HelpGetAppChangelog", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -4247,7 +4247,7 @@ func (c *Client) HelpGetAppConfig() (JsonValue, error) {
 
 	resp, ok := responseData.(JsonValue)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("HelpGetAppConfig", "JsonValue", responseData)
 	}
 	return resp, nil
 }
@@ -4268,7 +4268,7 @@ func (c *Client) HelpGetAppUpdate(source string) (HelpAppUpdate, error) {
 
 	resp, ok := responseData.(HelpAppUpdate)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("HelpGetAppUpdate", "HelpAppUpdate", responseData)
 	}
 	return resp, nil
 }
@@ -4287,7 +4287,7 @@ func (c *Client) HelpGetCdnConfig() (*CdnConfig, error) {
 
 	resp, ok := responseData.(*CdnConfig)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("HelpGetCdnConfig", "*CdnConfig", responseData)
 	}
 	return resp, nil
 }
@@ -4306,7 +4306,7 @@ func (c *Client) HelpGetConfig() (*Config, error) {
 
 	resp, ok := responseData.(*Config)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("HelpGetConfig", "*Config", responseData)
 	}
 	return resp, nil
 }
@@ -4331,7 +4331,7 @@ func (c *Client) HelpGetCountriesList(langCode string, hash int32) (HelpCountrie
 
 	resp, ok := responseData.(HelpCountriesList)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("HelpGetCountriesList", "HelpCountriesList", responseData)
 	}
 	return resp, nil
 }
@@ -4352,7 +4352,7 @@ func (c *Client) HelpGetDeepLinkInfo(path string) (HelpDeepLinkInfo, error) {
 
 	resp, ok := responseData.(HelpDeepLinkInfo)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("HelpGetDeepLinkInfo", "HelpDeepLinkInfo", responseData)
 	}
 	return resp, nil
 }
@@ -4371,7 +4371,7 @@ func (c *Client) HelpGetInviteText() (*HelpInviteText, error) {
 
 	resp, ok := responseData.(*HelpInviteText)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("HelpGetInviteText", "*HelpInviteText", responseData)
 	}
 	return resp, nil
 }
@@ -4390,7 +4390,7 @@ func (c *Client) HelpGetNearestDc() (*NearestDc, error) {
 
 	resp, ok := responseData.(*NearestDc)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("HelpGetNearestDc", "*NearestDc", responseData)
 	}
 	return resp, nil
 }
@@ -4411,7 +4411,7 @@ func (c *Client) HelpGetPassportConfig(hash int32) (HelpPassportConfig, error) {
 
 	resp, ok := responseData.(HelpPassportConfig)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("HelpGetPassportConfig", "HelpPassportConfig", responseData)
 	}
 	return resp, nil
 }
@@ -4430,7 +4430,7 @@ func (c *Client) HelpGetPremiumPromo() (*HelpPremiumPromo, error) {
 
 	resp, ok := responseData.(*HelpPremiumPromo)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("HelpGetPremiumPromo", "*HelpPremiumPromo", responseData)
 	}
 	return resp, nil
 }
@@ -4449,7 +4449,7 @@ func (c *Client) HelpGetPromoData() (HelpPromoData, error) {
 
 	resp, ok := responseData.(HelpPromoData)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("HelpGetPromoData", "HelpPromoData", responseData)
 	}
 	return resp, nil
 }
@@ -4470,7 +4470,7 @@ func (c *Client) HelpGetRecentMeUrls(referer string) (*HelpRecentMeUrls, error)
 
 	resp, ok := responseData.(*HelpRecentMeUrls)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("HelpGetRecentMeUrls", "*HelpRecentMeUrls", responseData)
 	}
 	return resp, nil
 }
@@ -4489,7 +4489,7 @@ func (c *Client) HelpGetSupport() (*HelpSupport, error) {
 
 	resp, ok := responseData.(*HelpSupport)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("HelpGetSupport", "*HelpSupport", responseData)
 	}
 	return resp, nil
 }
@@ -4508,7 +4508,7 @@ func (c *Client) HelpGetSupportName() (*HelpSupportName, error) {
 
 	resp, ok := responseData.(*HelpSupportName)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("HelpGetSupportName", "*HelpSupportName", responseData)
 	}
 	return resp, nil
 }
@@ -4527,7 +4527,7 @@ func (c *Client) HelpGetTermsOfServiceUpdate() (HelpTermsOfServiceUpdate, error)
 
 	resp, ok := responseData.(HelpTermsOfServiceUpdate)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("HelpGetTermsOfServiceUpdate", "HelpTermsOfServiceUpdate", responseData)
 	}
 	return resp, nil
 }
@@ -4548,7 +4548,7 @@ func (c *Client) HelpGetUserInfo(userID InputUser) (HelpUserInfo, error) {
 
 	resp, ok := responseData.(HelpUserInfo)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("HelpGetUserInfo", "HelpUserInfo", responseData)
 	}
 	return resp, nil
 }
@@ -4569,7 +4569,7 @@ func (c *Client) HelpHidePromoData(peer InputPeer) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("HelpHidePromoData", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -4590,7 +4590,7 @@ func (c *Client) HelpSaveAppLog(events []*InputAppEvent) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("HelpSaveAppLog", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -4615,7 +4615,7 @@ func (c *Client) HelpSetBotUpdatesStatus(pendingUpdatesCount int32, message stri
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("HelpSetBotUpdatesStatus", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -4642,7 +4642,7 @@ func (c *Client) LangpackGetDifference(langPack, langCode string, fromVersion in
 
 	resp, ok := responseData.(*LangPackDifference)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("LangpackGetDifference", "*LangPackDifference", responseData)
 	}
 	return resp, nil
 }
@@ -4667,7 +4667,7 @@ func (c *Client) LangpackGetLangPack(langPack, langCode string) (*LangPackDiffer
 
 	resp, ok := responseData.(*LangPackDifference)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("LangpackGetLangPack", "*LangPackDifference", responseData)
 	}
 	return resp, nil
 }
@@ -4692,7 +4692,7 @@ func (c *Client) LangpackGetLanguage(langPack, langCode string) (*LangPackLangua
 
 	resp, ok := responseData.(*LangPackLanguage)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("LangpackGetLanguage", "*LangPackLanguage", responseData)
 	}
 	return resp, nil
 }
@@ -4713,7 +4713,7 @@ func (c *Client) LangpackGetLanguages(langPack string) ([]*LangPackLanguage, err
 
 	resp, ok := responseData.([]*LangPackLanguage)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("LangpackGetLanguages", "[]*LangPackLanguage", responseData)
 	}
 	return resp, nil
 }
@@ -4740,7 +4740,7 @@ func (c *Client) LangpackGetStrings(langPack, langCode string, keys []string) ([
 
 	resp, ok := responseData.([]LangPackString)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("LangpackGetStrings", "[]LangPackString", responseData)
 	}
 	return resp, nil
 }
@@ -4767,7 +4767,7 @@ func (c *Client) MessagesAcceptEncryption(peer *InputEncryptedChat, gB []byte, k
 
 	resp, ok := responseData.(EncryptedChat)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesAcceptEncryption", "EncryptedChat", responseData)
 	}
 	return resp, nil
 }
@@ -4796,7 +4796,7 @@ func (c *Client) MessagesAcceptURLAuth(params *MessagesAcceptURLAuthParams) (URL
 
 	resp, ok := responseData.(URLAuthResult)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesAcceptURLAuth", "URLAuthResult", responseData)
 	}
 	return resp, nil
 }
@@ -4823,7 +4823,7 @@ func (c *Client) MessagesAddChatUser(chatID int64, userID InputUser, fwdLimit in
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesAddChatUser", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -4844,7 +4844,7 @@ func (c *Client) MessagesCheckChatInvite(hash string) (ChatInvite, error) {
 
 	resp, ok := responseData.(ChatInvite)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesCheckChatInvite", "ChatInvite", responseData)
 	}
 	return resp, nil
 }
@@ -4865,7 +4865,7 @@ func (c *Client) MessagesCheckHistoryImport(importHead string) (*MessagesHistory
 
 	resp, ok := responseData.(*MessagesHistoryImportParsed)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesCheckHistoryImport", "*MessagesHistoryImportParsed", responseData)
 	}
 	return resp, nil
 }
@@ -4886,7 +4886,7 @@ func (c *Client) MessagesCheckHistoryImportPeer(peer InputPeer) (*MessagesChecke
 
 	resp, ok := responseData.(*MessagesCheckedHistoryImportPeer)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesCheckHistoryImportPeer", "*MessagesCheckedHistoryImportPeer", responseData)
 	}
 	return resp, nil
 }
@@ -4905,7 +4905,7 @@ func (c *Client) MessagesClearAllDrafts() (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesClearAllDrafts", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -4924,7 +4924,7 @@ func (c *Client) MessagesClearRecentReactions() (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesClearRecentReactions", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -4949,7 +4949,7 @@ func (c *Client) MessagesClearRecentStickers(attached bool) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesClearRecentStickers", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -4974,7 +4974,7 @@ func (c *Client) MessagesCreateChat(users []InputUser, title string) (Updates, e
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesCreateChat", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -4995,7 +4995,7 @@ func (c *Client) MessagesDeleteChat(chatID int64) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesDeleteChat", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -5026,7 +5026,7 @@ func (c *Client) MessagesDeleteChatUser(revokeHistory bool, chatID int64, userID
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesDeleteChatUser", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -5051,7 +5051,7 @@ func (c *Client) MessagesDeleteExportedChatInvite(peer InputPeer, link string) (
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesDeleteExportedChatInvite", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -5081,7 +5081,7 @@ func (c *Client) MessagesDeleteHistory(params *MessagesDeleteHistoryParams) (*Me
 
 	resp, ok := responseData.(*MessagesAffectedHistory)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesDeleteHistory", "*MessagesAffectedHistory", responseData)
 	}
 	return resp, nil
 }
@@ -5110,7 +5110,7 @@ func (c *Client) MessagesDeleteMessages(revoke bool, id []int32) (*MessagesAffec
 
 	resp, ok := responseData.(*MessagesAffectedMessages)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesDeleteMessages", "*MessagesAffectedMessages", responseData)
 	}
 	return resp, nil
 }
@@ -5135,7 +5135,7 @@ func (c *Client) MessagesDeletePhoneCallHistory(revoke bool) (*MessagesAffectedF
 
 	resp, ok := responseData.(*MessagesAffectedFoundMessages)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesDeletePhoneCallHistory", "*MessagesAffectedFoundMessages", responseData)
 	}
 	return resp, nil
 }
@@ -5160,7 +5160,7 @@ func (c *Client) MessagesDeleteRevokedExportedChatInvites(peer InputPeer, adminI
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesDeleteRevokedExportedChatInvites", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -5185,7 +5185,7 @@ func (c *Client) MessagesDeleteScheduledMessages(peer InputPeer, id []int32) (Up
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesDeleteScheduledMessages", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -5214,7 +5214,7 @@ func (c *Client) MessagesDiscardEncryption(deleteHistory bool, chatID int32) (bo
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesDiscardEncryption", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -5239,7 +5239,7 @@ func (c *Client) MessagesEditChatAbout(peer InputPeer, about string) (bool, erro
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesEditChatAbout", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -5266,7 +5266,7 @@ func (c *Client) MessagesEditChatAdmin(chatID int64, userID InputUser, isAdmin b
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesEditChatAdmin", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -5291,7 +5291,7 @@ func (c *Client) MessagesEditChatDefaultBannedRights(peer InputPeer, bannedRight
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesEditChatDefaultBannedRights", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -5316,7 +5316,7 @@ func (c *Client) MessagesEditChatPhoto(chatID int64, photo InputChatPhoto) (Upda
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesEditChatPhoto", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -5341,7 +5341,7 @@ func (c *Client) MessagesEditChatTitle(chatID int64, title string) (Updates, err
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesEditChatTitle", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -5372,7 +5372,7 @@ func (c *Client) MessagesEditExportedChatInvite(params *MessagesEditExportedChat
 
 	resp, ok := responseData.(MessagesExportedChatInvite)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesEditExportedChatInvite", "MessagesExportedChatInvite", responseData)
 	}
 	return resp, nil
 }
@@ -5402,7 +5402,7 @@ func (c *Client) MessagesEditInlineBotMessage(params *MessagesEditInlineBotMessa
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesEditInlineBotMessage", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -5434,7 +5434,7 @@ func (c *Client) MessagesEditMessage(params *MessagesEditMessageParams) (Updates
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesEditMessage", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -5464,7 +5464,7 @@ func (c *Client) MessagesExportChatInvite(params *MessagesExportChatInviteParams
 
 	resp, ok := responseData.(ExportedChatInvite)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesExportChatInvite", "ExportedChatInvite", responseData)
 	}
 	return resp, nil
 }
@@ -5489,7 +5489,7 @@ func (c *Client) MessagesFaveSticker(id InputDocument, unfave bool) (bool, error
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesFaveSticker", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -5525,7 +5525,7 @@ func (c *Client) MessagesForwardMessages(params *MessagesForwardMessagesParams)
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesForwardMessages", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -5546,7 +5546,7 @@ func (c *Client) MessagesGetAdminsWithInvites(peer InputPeer) (*MessagesChatAdmi
 
 	resp, ok := responseData.(*MessagesChatAdminsWithInvites)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetAdminsWithInvites", "*MessagesChatAdminsWithInvites", responseData)
 	}
 	return resp, nil
 }
@@ -5567,7 +5567,7 @@ func (c *Client) MessagesGetAllChats(exceptIds []int64) (MessagesChats, error) {
 
 	resp, ok := responseData.(MessagesChats)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetAllChats", "MessagesChats", responseData)
 	}
 	return resp, nil
 }
@@ -5586,7 +5586,7 @@ func (c *Client) MessagesGetAllDrafts() (Updates, error) {
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetAllDrafts", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -5607,7 +5607,7 @@ func (c *Client) MessagesGetAllStickers(hash int64) (MessagesAllStickers, error)
 
 	resp, ok := responseData.(MessagesAllStickers)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetAllStickers", "MessagesAllStickers", responseData)
 	}
 	return resp, nil
 }
@@ -5640,7 +5640,7 @@ func (c *Client) MessagesGetArchivedStickers(masks, emojis bool, offsetID int64,
 
 	resp, ok := responseData.(*MessagesArchivedStickers)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetArchivedStickers", "*MessagesArchivedStickers", responseData)
 	}
 	return resp, nil
 }
@@ -5661,7 +5661,7 @@ func (c *Client) MessagesGetAttachMenuBot(bot InputUser) (*AttachMenuBotsBot, er
 
 	resp, ok := responseData.(*AttachMenuBotsBot)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetAttachMenuBot", "*AttachMenuBotsBot", responseData)
 	}
 	return resp, nil
 }
@@ -5682,7 +5682,7 @@ func (c *Client) MessagesGetAttachMenuBots(hash int64) (AttachMenuBots, error) {
 
 	resp, ok := responseData.(AttachMenuBots)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetAttachMenuBots", "AttachMenuBots", responseData)
 	}
 	return resp, nil
 }
@@ -5703,7 +5703,7 @@ func (c *Client) MessagesGetAttachedStickers(media InputStickeredMedia) ([]Stick
 
 	resp, ok := responseData.([]StickerSetCovered)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetAttachedStickers", "[]StickerSetCovered", responseData)
 	}
 	return resp, nil
 }
@@ -5724,7 +5724,7 @@ func (c *Client) MessagesGetAvailableReactions(hash int32) (MessagesAvailableRea
 
 	resp, ok := responseData.(MessagesAvailableReactions)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetAvailableReactions", "MessagesAvailableReactions", responseData)
 	}
 	return resp, nil
 }
@@ -5753,7 +5753,7 @@ func (c *Client) MessagesGetBotCallbackAnswer(params *MessagesGetBotCallbackAnsw
 
 	resp, ok := responseData.(*MessagesBotCallbackAnswer)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetBotCallbackAnswer", "*MessagesBotCallbackAnswer", responseData)
 	}
 	return resp, nil
 }
@@ -5784,7 +5784,7 @@ func (c *Client) MessagesGetChatInviteImporters(params *MessagesGetChatInviteImp
 
 	resp, ok := responseData.(*MessagesChatInviteImporters)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetChatInviteImporters", "*MessagesChatInviteImporters", responseData)
 	}
 	return resp, nil
 }
@@ -5805,7 +5805,7 @@ func (c *Client) MessagesGetChats(id []int64) (MessagesChats, error) {
 
 	resp, ok := responseData.(MessagesChats)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetChats", "MessagesChats", responseData)
 	}
 	return resp, nil
 }
@@ -5832,7 +5832,7 @@ func (c *Client) MessagesGetCommonChats(userID InputUser, maxID int64, limit int
 
 	resp, ok := responseData.(MessagesChats)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetCommonChats", "MessagesChats", responseData)
 	}
 	return resp, nil
 }
@@ -5853,7 +5853,7 @@ func (c *Client) MessagesGetCustomEmojiDocuments(documentID []int64) ([]Document
 
 	resp, ok := responseData.([]Document)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetCustomEmojiDocuments", "[]Document", responseData)
 	}
 	return resp, nil
 }
@@ -5878,7 +5878,7 @@ func (c *Client) MessagesGetDhConfig(version, randomLength int32) (MessagesDhCon
 
 	resp, ok := responseData.(MessagesDhConfig)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetDhConfig", "MessagesDhConfig", responseData)
 	}
 	return resp, nil
 }
@@ -5897,7 +5897,7 @@ func (c *Client) MessagesGetDialogFilters() ([]DialogFilter, error) {
 
 	resp, ok := responseData.([]DialogFilter)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetDialogFilters", "[]DialogFilter", responseData)
 	}
 	return resp, nil
 }
@@ -5916,7 +5916,7 @@ func (c *Client) MessagesGetDialogUnreadMarks() ([]DialogPeer, error) {
 
 	resp, ok := responseData.([]DialogPeer)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetDialogUnreadMarks", "[]DialogPeer", responseData)
 	}
 	return resp, nil
 }
@@ -5947,7 +5947,7 @@ func (c *Client) MessagesGetDialogs(params *MessagesGetDialogsParams) (MessagesD
 
 	resp, ok := responseData.(MessagesDialogs)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetDialogs", "MessagesDialogs", responseData)
 	}
 	return resp, nil
 }
@@ -5972,7 +5972,7 @@ func (c *Client) MessagesGetDiscussionMessage(peer InputPeer, msgID int32) (*Mes
 
 	resp, ok := responseData.(*MessagesDiscussionMessage)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetDiscussionMessage", "*MessagesDiscussionMessage", responseData)
 	}
 	return resp, nil
 }
@@ -5999,7 +5999,7 @@ func (c *Client) MessagesGetDocumentByHash(sha256 []byte, size int64, mimeType s
 
 	resp, ok := responseData.(Document)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetDocumentByHash", "Document", responseData)
 	}
 	return resp, nil
 }
@@ -6020,7 +6020,7 @@ func (c *Client) MessagesGetEmojiKeywords(langCode string) (*EmojiKeywordsDiffer
 
 	resp, ok := responseData.(*EmojiKeywordsDifference)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetEmojiKeywords", "*EmojiKeywordsDifference", responseData)
 	}
 	return resp, nil
 }
@@ -6045,7 +6045,7 @@ func (c *Client) MessagesGetEmojiKeywordsDifference(langCode string, fromVersion
 
 	resp, ok := responseData.(*EmojiKeywordsDifference)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetEmojiKeywordsDifference", "*EmojiKeywordsDifference", responseData)
 	}
 	return resp, nil
 }
@@ -6066,7 +6066,7 @@ func (c *Client) MessagesGetEmojiKeywordsLanguages(langCodes []string) ([]*Emoji
 
 	resp, ok := responseData.([]*EmojiLanguage)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetEmojiKeywordsLanguages", "[]*EmojiLanguage", responseData)
 	}
 	return resp, nil
 }
@@ -6087,7 +6087,7 @@ func (c *Client) MessagesGetEmojiStickers(hash int64) (MessagesAllStickers, erro
 
 	resp, ok := responseData.(MessagesAllStickers)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetEmojiStickers", "MessagesAllStickers", responseData)
 	}
 	return resp, nil
 }
@@ -6108,7 +6108,7 @@ func (c *Client) MessagesGetEmojiURL(langCode string) (*EmojiURL, error) {
 
 	resp, ok := responseData.(*EmojiURL)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetEmojiURL", "*EmojiURL", responseData)
 	}
 	return resp, nil
 }
@@ -6133,7 +6133,7 @@ func (c *Client) MessagesGetExportedChatInvite(peer InputPeer, link string) (Mes
 
 	resp, ok := responseData.(MessagesExportedChatInvite)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetExportedChatInvite", "MessagesExportedChatInvite", responseData)
 	}
 	return resp, nil
 }
@@ -6163,7 +6163,7 @@ func (c *Client) MessagesGetExportedChatInvites(params *MessagesGetExportedChatI
 
 	resp, ok := responseData.(*MessagesExportedChatInvites)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetExportedChatInvites", "*MessagesExportedChatInvites", responseData)
 	}
 	return resp, nil
 }
@@ -6188,7 +6188,7 @@ func (c *Client) MessagesGetExtendedMedia(peer InputPeer, id []int32) (Updates,
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetExtendedMedia", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -6209,7 +6209,7 @@ func (c *Client) MessagesGetFavedStickers(hash int64) (MessagesFavedStickers, er
 
 	resp, ok := responseData.(MessagesFavedStickers)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetFavedStickers", "MessagesFavedStickers", responseData)
 	}
 	return resp, nil
 }
@@ -6230,7 +6230,7 @@ func (c *Client) MessagesGetFeaturedEmojiStickers(hash int64) (MessagesFeaturedS
 
 	resp, ok := responseData.(MessagesFeaturedStickers)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetFeaturedEmojiStickers", "MessagesFeaturedStickers", responseData)
 	}
 	return resp, nil
 }
@@ -6251,7 +6251,7 @@ func (c *Client) MessagesGetFeaturedStickers(hash int64) (MessagesFeaturedSticke
 
 	resp, ok := responseData.(MessagesFeaturedStickers)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetFeaturedStickers", "MessagesFeaturedStickers", responseData)
 	}
 	return resp, nil
 }
@@ -6272,7 +6272,7 @@ func (c *Client) MessagesGetFullChat(chatID int64) (*MessagesChatFull, error) {
 
 	resp, ok := responseData.(*MessagesChatFull)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetFullChat", "*MessagesChatFull", responseData)
 	}
 	return resp, nil
 }
@@ -6299,7 +6299,7 @@ func (c *Client) MessagesGetGameHighScores(peer InputPeer, id int32, userID Inpu
 
 	resp, ok := responseData.(*MessagesHighScores)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetGameHighScores", "*MessagesHighScores", responseData)
 	}
 	return resp, nil
 }
@@ -6327,7 +6327,7 @@ func (c *Client) MessagesGetHistory(params *MessagesGetHistoryParams) (MessagesM
 
 	resp, ok := responseData.(MessagesMessages)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetHistory", "MessagesMessages", responseData)
 	}
 	return resp, nil
 }
@@ -6356,7 +6356,7 @@ func (c *Client) MessagesGetInlineBotResults(params *MessagesGetInlineBotResults
 
 	resp, ok := responseData.(*MessagesBotResults)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetInlineBotResults", "*MessagesBotResults", responseData)
 	}
 	return resp, nil
 }
@@ -6381,7 +6381,7 @@ func (c *Client) MessagesGetInlineGameHighScores(id InputBotInlineMessageID, use
 
 	resp, ok := responseData.(*MessagesHighScores)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetInlineGameHighScores", "*MessagesHighScores", responseData)
 	}
 	return resp, nil
 }
@@ -6402,7 +6402,7 @@ func (c *Client) MessagesGetMaskStickers(hash int64) (MessagesAllStickers, error
 
 	resp, ok := responseData.(MessagesAllStickers)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetMaskStickers", "MessagesAllStickers", responseData)
 	}
 	return resp, nil
 }
@@ -6427,7 +6427,7 @@ func (c *Client) MessagesGetMessageEditData(peer InputPeer, id int32) (*Messages
 
 	resp, ok := responseData.(*MessagesMessageEditData)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetMessageEditData", "*MessagesMessageEditData", responseData)
 	}
 	return resp, nil
 }
@@ -6456,7 +6456,7 @@ func (c *Client) MessagesGetMessageReactionsList(params *MessagesGetMessageReact
 
 	resp, ok := responseData.(*MessagesMessageReactionsList)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetMessageReactionsList", "*MessagesMessageReactionsList", responseData)
 	}
 	return resp, nil
 }
@@ -6481,7 +6481,7 @@ func (c *Client) MessagesGetMessageReadParticipants(peer InputPeer, msgID int32)
 
 	resp, ok := responseData.([]int64)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetMessageReadParticipants", "[]int64", responseData)
 	}
 	return resp, nil
 }
@@ -6502,7 +6502,7 @@ func (c *Client) MessagesGetMessages(id []InputMessage) (MessagesMessages, error
 
 	resp, ok := responseData.(MessagesMessages)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetMessages", "MessagesMessages", responseData)
 	}
 	return resp, nil
 }
@@ -6527,7 +6527,7 @@ func (c *Client) MessagesGetMessagesReactions(peer InputPeer, id []int32) (Updat
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetMessagesReactions", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -6554,7 +6554,7 @@ func (c *Client) MessagesGetMessagesViews(peer InputPeer, id []int32, increment
 
 	resp, ok := responseData.(*MessagesMessageViews)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetMessagesViews", "*MessagesMessageViews", responseData)
 	}
 	return resp, nil
 }
@@ -6581,7 +6581,7 @@ func (c *Client) MessagesGetOldFeaturedStickers(offset, limit int32, hash int64)
 
 	resp, ok := responseData.(MessagesFeaturedStickers)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetOldFeaturedStickers", "MessagesFeaturedStickers", responseData)
 	}
 	return resp, nil
 }
@@ -6602,7 +6602,7 @@ func (c *Client) MessagesGetOnlines(peer InputPeer) (*ChatOnlines, error) {
 
 	resp, ok := responseData.(*ChatOnlines)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetOnlines", "*ChatOnlines", responseData)
 	}
 	return resp, nil
 }
@@ -6623,7 +6623,7 @@ func (c *Client) MessagesGetPeerDialogs(peers []InputDialogPeer) (*MessagesPeerD
 
 	resp, ok := responseData.(*MessagesPeerDialogs)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetPeerDialogs", "*MessagesPeerDialogs", responseData)
 	}
 	return resp, nil
 }
@@ -6644,7 +6644,7 @@ func (c *Client) MessagesGetPeerSettings(peer InputPeer) (*MessagesPeerSettings,
 
 	resp, ok := responseData.(*MessagesPeerSettings)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetPeerSettings", "*MessagesPeerSettings", responseData)
 	}
 	return resp, nil
 }
@@ -6665,7 +6665,7 @@ func (c *Client) MessagesGetPinnedDialogs(folderID int32) (*MessagesPeerDialogs,
 
 	resp, ok := responseData.(*MessagesPeerDialogs)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetPinnedDialogs", "*MessagesPeerDialogs", responseData)
 	}
 	return resp, nil
 }
@@ -6690,7 +6690,7 @@ func (c *Client) MessagesGetPollResults(peer InputPeer, msgID int32) (Updates, e
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetPollResults", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -6719,7 +6719,7 @@ func (c *Client) MessagesGetPollVotes(params *MessagesGetPollVotesParams) (*Mess
 
 	resp, ok := responseData.(*MessagesVotesList)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetPollVotes", "*MessagesVotesList", responseData)
 	}
 	return resp, nil
 }
@@ -6746,7 +6746,7 @@ func (c *Client) MessagesGetRecentLocations(peer InputPeer, limit int32, hash in
 
 	resp, ok := responseData.(MessagesMessages)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetRecentLocations", "MessagesMessages", responseData)
 	}
 	return resp, nil
 }
@@ -6771,7 +6771,7 @@ func (c *Client) MessagesGetRecentReactions(limit int32, hash int64) (MessagesRe
 
 	resp, ok := responseData.(MessagesReactions)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetRecentReactions", "MessagesReactions", responseData)
 	}
 	return resp, nil
 }
@@ -6800,7 +6800,7 @@ func (c *Client) MessagesGetRecentStickers(attached bool, hash int64) (MessagesR
 
 	resp, ok := responseData.(MessagesRecentStickers)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetRecentStickers", "MessagesRecentStickers", responseData)
 	}
 	return resp, nil
 }
@@ -6829,7 +6829,7 @@ func (c *Client) MessagesGetReplies(params *MessagesGetRepliesParams) (MessagesM
 
 	resp, ok := responseData.(MessagesMessages)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetReplies", "MessagesMessages", responseData)
 	}
 	return resp, nil
 }
@@ -6850,7 +6850,7 @@ func (c *Client) MessagesGetSavedGifs(hash int64) (MessagesSavedGifs, error) {
 
 	resp, ok := responseData.(MessagesSavedGifs)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetSavedGifs", "MessagesSavedGifs", responseData)
 	}
 	return resp, nil
 }
@@ -6875,7 +6875,7 @@ func (c *Client) MessagesGetScheduledHistory(peer InputPeer, hash int64) (Messag
 
 	resp, ok := responseData.(MessagesMessages)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetScheduledHistory", "MessagesMessages", responseData)
 	}
 	return resp, nil
 }
@@ -6900,7 +6900,7 @@ func (c *Client) MessagesGetScheduledMessages(peer InputPeer, id []int32) (Messa
 
 	resp, ok := responseData.(MessagesMessages)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetScheduledMessages", "MessagesMessages", responseData)
 	}
 	return resp, nil
 }
@@ -6925,7 +6925,7 @@ func (c *Client) MessagesGetSearchCounters(peer InputPeer, filters []MessagesFil
 
 	resp, ok := responseData.([]*MessagesSearchCounter)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetSearchCounters", "[]*MessagesSearchCounter", responseData)
 	}
 	return resp, nil
 }
@@ -6954,7 +6954,7 @@ func (c *Client) MessagesGetSearchResultsCalendar(peer InputPeer, filter Message
 
 	resp, ok := responseData.(*MessagesSearchResultsCalendar)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetSearchResultsCalendar", "*MessagesSearchResultsCalendar", responseData)
 	}
 	return resp, nil
 }
@@ -6983,7 +6983,7 @@ func (c *Client) MessagesGetSearchResultsPositions(peer InputPeer, filter Messag
 
 	resp, ok := responseData.(*MessagesSearchResultsPositions)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetSearchResultsPositions", "*MessagesSearchResultsPositions", responseData)
 	}
 	return resp, nil
 }
@@ -7002,7 +7002,7 @@ func (c *Client) MessagesGetSplitRanges() ([]*MessageRange, error) {
 
 	resp, ok := responseData.([]*MessageRange)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetSplitRanges", "[]*MessageRange", responseData)
 	}
 	return resp, nil
 }
@@ -7027,7 +7027,7 @@ func (c *Client) MessagesGetStickerSet(stickerset InputStickerSet, hash int32) (
 
 	resp, ok := responseData.(MessagesStickerSet)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetStickerSet", "MessagesStickerSet", responseData)
 	}
 	return resp, nil
 }
@@ -7052,7 +7052,7 @@ func (c *Client) MessagesGetStickers(emoticon string, hash int64) (MessagesStick
 
 	resp, ok := responseData.(MessagesStickers)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetStickers", "MessagesStickers", responseData)
 	}
 	return resp, nil
 }
@@ -7071,7 +7071,7 @@ func (c *Client) MessagesGetSuggestedDialogFilters() ([]*DialogFilterSuggested,
 
 	resp, ok := responseData.([]*DialogFilterSuggested)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetSuggestedDialogFilters", "[]*DialogFilterSuggested", responseData)
 	}
 	return resp, nil
 }
@@ -7096,7 +7096,7 @@ func (c *Client) MessagesGetTopReactions(limit int32, hash int64) (MessagesReact
 
 	resp, ok := responseData.(MessagesReactions)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetTopReactions", "MessagesReactions", responseData)
 	}
 	return resp, nil
 }
@@ -7122,7 +7122,7 @@ func (c *Client) MessagesGetUnreadMentions(params *MessagesGetUnreadMentionsPara
 
 	resp, ok := responseData.(MessagesMessages)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetUnreadMentions", "MessagesMessages", responseData)
 	}
 	return resp, nil
 }
@@ -7148,7 +7148,7 @@ func (c *Client) MessagesGetUnreadReactions(params *MessagesGetUnreadReactionsPa
 
 	resp, ok := responseData.(MessagesMessages)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetUnreadReactions", "MessagesMessages", responseData)
 	}
 	return resp, nil
 }
@@ -7173,7 +7173,7 @@ func (c *Client) MessagesGetWebPage(url string, hash int32) (WebPage, error) {
 
 	resp, ok := responseData.(WebPage)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetWebPage", "WebPage", responseData)
 	}
 	return resp, nil
 }
@@ -7202,7 +7202,7 @@ func (c *Client) MessagesGetWebPagePreview(message string, entities []MessageEnt
 
 	resp, ok := responseData.(MessageMedia)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesGetWebPagePreview", "MessageMedia", responseData)
 	}
 	return resp, nil
 }
@@ -7233,7 +7233,7 @@ func (c *Client) MessagesHideAllChatJoinRequests(approved bool, peer InputPeer,
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesHideAllChatJoinRequests", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -7264,7 +7264,7 @@ func (c *Client) MessagesHideChatJoinRequest(approved bool, peer InputPeer, user
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesHideChatJoinRequest", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -7285,7 +7285,7 @@ func (c *Client) MessagesHidePeerSettingsBar(peer InputPeer) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesHidePeerSettingsBar", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -7306,7 +7306,7 @@ func (c *Client) MessagesImportChatInvite(hash string) (Updates, error) {
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesImportChatInvite", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -7333,7 +7333,7 @@ func (c *Client) MessagesInitHistoryImport(peer InputPeer, file InputFile, media
 
 	resp, ok := responseData.(*MessagesHistoryImport)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesInitHistoryImport", "*MessagesHistoryImport", responseData)
 	}
 	return resp, nil
 }
@@ -7358,7 +7358,7 @@ func (c *Client) MessagesInstallStickerSet(stickerset InputStickerSet, archived
 
 	resp, ok := responseData.(MessagesStickerSetInstallResult)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesInstallStickerSet", "MessagesStickerSetInstallResult", responseData)
 	}
 	return resp, nil
 }
@@ -7387,7 +7387,7 @@ func (c *Client) MessagesMarkDialogUnread(unread bool, peer InputDialogPeer) (bo
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesMarkDialogUnread", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -7408,7 +7408,7 @@ func (c *Client) MessagesMigrateChat(chatID int64) (Updates, error) {
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesMigrateChat", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -7438,7 +7438,7 @@ func (c *Client) MessagesProlongWebView(params *MessagesProlongWebViewParams) (b
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesProlongWebView", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -7467,7 +7467,7 @@ func (c *Client) MessagesRateTranscribedAudio(peer InputPeer, msgID int32, trans
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesRateTranscribedAudio", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -7494,7 +7494,7 @@ func (c *Client) MessagesReadDiscussion(peer InputPeer, msgID, readMaxID int32)
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesReadDiscussion", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -7519,7 +7519,7 @@ func (c *Client) MessagesReadEncryptedHistory(peer *InputEncryptedChat, maxDate
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesReadEncryptedHistory", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -7540,7 +7540,7 @@ func (c *Client) MessagesReadFeaturedStickers(id []int64) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesReadFeaturedStickers", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -7565,7 +7565,7 @@ func (c *Client) MessagesReadHistory(peer InputPeer, maxID int32) (*MessagesAffe
 
 	resp, ok := responseData.(*MessagesAffectedMessages)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesReadHistory", "*MessagesAffectedMessages", responseData)
 	}
 	return resp, nil
 }
@@ -7586,7 +7586,7 @@ func (c *Client) MessagesReadMentions(peer InputPeer) (*MessagesAffectedHistory,
 
 	resp, ok := responseData.(*MessagesAffectedHistory)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesReadMentions", "*MessagesAffectedHistory", responseData)
 	}
 	return resp, nil
 }
@@ -7607,7 +7607,7 @@ func (c *Client) MessagesReadMessageContents(id []int32) (*MessagesAffectedMessa
 
 	resp, ok := responseData.(*MessagesAffectedMessages)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesReadMessageContents", "*MessagesAffectedMessages", responseData)
 	}
 	return resp, nil
 }
@@ -7628,7 +7628,7 @@ func (c *Client) MessagesReadReactions(peer InputPeer) (*MessagesAffectedHistory
 
 	resp, ok := responseData.(*MessagesAffectedHistory)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesReadReactions", "*MessagesAffectedHistory", responseData)
 	}
 	return resp, nil
 }
@@ -7649,7 +7649,7 @@ func (c *Client) MessagesReceivedMessages(maxID int32) ([]*ReceivedNotifyMessage
 
 	resp, ok := responseData.([]*ReceivedNotifyMessage)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesReceivedMessages", "[]*ReceivedNotifyMessage", responseData)
 	}
 	return resp, nil
 }
@@ -7670,7 +7670,7 @@ func (c *Client) MessagesReceivedQueue(maxQts int32) ([]int64, error) {
 
 	resp, ok := responseData.([]int64)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesReceivedQueue", "[]int64", responseData)
 	}
 	return resp, nil
 }
@@ -7701,7 +7701,7 @@ func (c *Client) MessagesReorderPinnedDialogs(force bool, folderID int32, order
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesReorderPinnedDialogs", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -7732,7 +7732,7 @@ func (c *Client) MessagesReorderStickerSets(masks, emojis bool, order []int64) (
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesReorderStickerSets", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -7761,7 +7761,7 @@ func (c *Client) MessagesReport(peer InputPeer, id []int32, reason ReportReason,
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesReport", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -7782,7 +7782,7 @@ func (c *Client) MessagesReportEncryptedSpam(peer *InputEncryptedChat) (bool, er
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesReportEncryptedSpam", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -7809,7 +7809,7 @@ func (c *Client) MessagesReportReaction(peer InputPeer, id int32, reactionPeer I
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesReportReaction", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -7830,7 +7830,7 @@ func (c *Client) MessagesReportSpam(peer InputPeer) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesReportSpam", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -7857,7 +7857,7 @@ func (c *Client) MessagesRequestEncryption(userID InputUser, randomID int32, gA
 
 	resp, ok := responseData.(EncryptedChat)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesRequestEncryption", "EncryptedChat", responseData)
 	}
 	return resp, nil
 }
@@ -7890,7 +7890,7 @@ func (c *Client) MessagesRequestSimpleWebView(bot InputUser, url string, themePa
 
 	resp, ok := responseData.(*SimpleWebViewResultURL)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesRequestSimpleWebView", "*SimpleWebViewResultURL", responseData)
 	}
 	return resp, nil
 }
@@ -7923,7 +7923,7 @@ func (c *Client) MessagesRequestURLAuth(peer InputPeer, msgID, buttonID int32, u
 
 	resp, ok := responseData.(URLAuthResult)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesRequestURLAuth", "URLAuthResult", responseData)
 	}
 	return resp, nil
 }
@@ -7957,7 +7957,7 @@ func (c *Client) MessagesRequestWebView(params *MessagesRequestWebViewParams) (*
 
 	resp, ok := responseData.(*WebViewResultURL)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesRequestWebView", "*WebViewResultURL", responseData)
 	}
 	return resp, nil
 }
@@ -7982,7 +7982,7 @@ func (c *Client) MessagesSaveDefaultSendAs(peer, sendAs InputPeer) (bool, error)
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesSaveDefaultSendAs", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -8011,7 +8011,7 @@ func (c *Client) MessagesSaveDraft(params *MessagesSaveDraftParams) (bool, error
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesSaveDraft", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -8036,7 +8036,7 @@ func (c *Client) MessagesSaveGif(id InputDocument, unsave bool) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesSaveGif", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -8067,7 +8067,7 @@ func (c *Client) MessagesSaveRecentSticker(attached bool, id InputDocument, unsa
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesSaveRecentSticker", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -8104,7 +8104,7 @@ func (c *Client) MessagesSearch(params *MessagesSearchParams) (MessagesMessages,
 
 	resp, ok := responseData.(MessagesMessages)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesSearch", "MessagesMessages", responseData)
 	}
 	return resp, nil
 }
@@ -8137,7 +8137,7 @@ func (c *Client) MessagesSearchGlobal(params *MessagesSearchGlobalParams) (Messa
 
 	resp, ok := responseData.(MessagesMessages)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesSearchGlobal", "MessagesMessages", responseData)
 	}
 	return resp, nil
 }
@@ -8164,7 +8164,7 @@ func (c *Client) MessagesSearchSentMedia(q string, filter MessagesFilter, limit
 
 	resp, ok := responseData.(MessagesMessages)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesSearchSentMedia", "MessagesMessages", responseData)
 	}
 	return resp, nil
 }
@@ -8195,7 +8195,7 @@ func (c *Client) MessagesSearchStickerSets(excludeFeatured bool, q string, hash
 
 	resp, ok := responseData.(MessagesFoundStickerSets)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesSearchStickerSets", "MessagesFoundStickerSets", responseData)
 	}
 	return resp, nil
 }
@@ -8228,7 +8228,7 @@ func (c *Client) MessagesSendEncrypted(silent bool, peer *InputEncryptedChat, ra
 
 	resp, ok := responseData.(MessagesSentEncryptedMessage)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesSendEncrypted", "MessagesSentEncryptedMessage", responseData)
 	}
 	return resp, nil
 }
@@ -8257,7 +8257,7 @@ func (c *Client) MessagesSendEncryptedFile(params *MessagesSendEncryptedFilePara
 
 	resp, ok := responseData.(MessagesSentEncryptedMessage)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesSendEncryptedFile", "MessagesSentEncryptedMessage", responseData)
 	}
 	return resp, nil
 }
@@ -8284,7 +8284,7 @@ func (c *Client) MessagesSendEncryptedService(peer *InputEncryptedChat, randomID
 
 	resp, ok := responseData.(MessagesSentEncryptedMessage)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesSendEncryptedService", "MessagesSentEncryptedMessage", responseData)
 	}
 	return resp, nil
 }
@@ -8319,7 +8319,7 @@ func (c *Client) MessagesSendInlineBotResult(params *MessagesSendInlineBotResult
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesSendInlineBotResult", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -8357,7 +8357,7 @@ func (c *Client) MessagesSendMedia(params *MessagesSendMediaParams) (Updates, er
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesSendMedia", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -8395,7 +8395,7 @@ func (c *Client) MessagesSendMessage(params *MessagesSendMessageParams) (Updates
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesSendMessage", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -8429,7 +8429,7 @@ func (c *Client) MessagesSendMultiMedia(params *MessagesSendMultiMediaParams) (U
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesSendMultiMedia", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -8458,7 +8458,7 @@ func (c *Client) MessagesSendReaction(params *MessagesSendReactionParams) (Updat
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesSendReaction", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -8483,7 +8483,7 @@ func (c *Client) MessagesSendScheduledMessages(peer InputPeer, id []int32) (Upda
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesSendScheduledMessages", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -8510,7 +8510,7 @@ func (c *Client) MessagesSendScreenshotNotification(peer InputPeer, replyToMsgID
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesSendScreenshotNotification", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -8537,7 +8537,7 @@ func (c *Client) MessagesSendVote(peer InputPeer, msgID int32, options [][]byte)
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesSendVote", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -8566,7 +8566,7 @@ func (c *Client) MessagesSendWebViewData(bot InputUser, randomID int64, buttonTe
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesSendWebViewData", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -8591,7 +8591,7 @@ func (c *Client) MessagesSendWebViewResultMessage(botQueryID string, result Inpu
 
 	resp, ok := responseData.(*WebViewMessageSent)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesSendWebViewResultMessage", "*WebViewMessageSent", responseData)
 	}
 	return resp, nil
 }
@@ -8620,7 +8620,7 @@ func (c *Client) MessagesSetBotCallbackAnswer(params *MessagesSetBotCallbackAnsw
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesSetBotCallbackAnswer", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -8651,7 +8651,7 @@ func (c *Client) MessagesSetBotPrecheckoutResults(success bool, queryID int64, e
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesSetBotPrecheckoutResults", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -8682,7 +8682,7 @@ func (c *Client) MessagesSetBotShippingResults(queryID int64, error string, ship
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesSetBotShippingResults", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -8707,7 +8707,7 @@ func (c *Client) MessagesSetChatAvailableReactions(peer InputPeer, availableReac
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesSetChatAvailableReactions", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -8732,7 +8732,7 @@ func (c *Client) MessagesSetChatTheme(peer InputPeer, emoticon string) (Updates,
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesSetChatTheme", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -8753,7 +8753,7 @@ func (c *Client) MessagesSetDefaultReaction(reaction Reaction) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesSetDefaultReaction", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -8778,7 +8778,7 @@ func (c *Client) MessagesSetEncryptedTyping(peer *InputEncryptedChat, typing boo
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesSetEncryptedTyping", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -8808,7 +8808,7 @@ func (c *Client) MessagesSetGameScore(params *MessagesSetGameScoreParams) (Updat
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesSetGameScore", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -8833,7 +8833,7 @@ func (c *Client) MessagesSetHistoryTtl(peer InputPeer, period int32) (Updates, e
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesSetHistoryTtl", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -8864,7 +8864,7 @@ func (c *Client) MessagesSetInlineBotResults(params *MessagesSetInlineBotResults
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesSetInlineBotResults", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -8893,7 +8893,7 @@ func (c *Client) MessagesSetInlineGameScore(params *MessagesSetInlineGameScorePa
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesSetInlineGameScore", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -8924,7 +8924,7 @@ func (c *Client) MessagesSetTyping(peer InputPeer, topMsgID int32, action SendMe
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesSetTyping", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -8953,7 +8953,7 @@ func (c *Client) MessagesStartBot(bot InputUser, peer InputPeer, randomID int64,
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesStartBot", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -8978,7 +8978,7 @@ func (c *Client) MessagesStartHistoryImport(peer InputPeer, importID int64) (boo
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesStartHistoryImport", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -9003,7 +9003,7 @@ func (c *Client) MessagesToggleBotInAttachMenu(bot InputUser, enabled bool) (boo
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesToggleBotInAttachMenu", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -9032,7 +9032,7 @@ func (c *Client) MessagesToggleDialogPin(pinned bool, peer InputDialogPeer) (boo
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesToggleDialogPin", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -9057,7 +9057,7 @@ func (c *Client) MessagesToggleNoForwards(peer InputPeer, enabled bool) (Updates
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesToggleNoForwards", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -9090,7 +9090,7 @@ func (c *Client) MessagesToggleStickerSets(uninstall, archive, unarchive bool, s
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesToggleStickerSets", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -9115,7 +9115,7 @@ func (c *Client) MessagesTranscribeAudio(peer InputPeer, msgID int32) (*Messages
 
 	resp, ok := responseData.(*MessagesTranscribedAudio)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesTranscribeAudio", "*MessagesTranscribedAudio", responseData)
 	}
 	return resp, nil
 }
@@ -9144,7 +9144,7 @@ func (c *Client) MessagesTranslateText(params *MessagesTranslateTextParams) (Mes
 
 	resp, ok := responseData.(MessagesTranslatedText)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesTranslateText", "MessagesTranslatedText", responseData)
 	}
 	return resp, nil
 }
@@ -9165,7 +9165,7 @@ func (c *Client) MessagesUninstallStickerSet(stickerset InputStickerSet) (bool,
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesUninstallStickerSet", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -9186,7 +9186,7 @@ func (c *Client) MessagesUnpinAllMessages(peer InputPeer) (*MessagesAffectedHist
 
 	resp, ok := responseData.(*MessagesAffectedHistory)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesUnpinAllMessages", "*MessagesAffectedHistory", responseData)
 	}
 	return resp, nil
 }
@@ -9215,7 +9215,7 @@ func (c *Client) MessagesUpdateDialogFilter(id int32, filter DialogFilter) (bool
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesUpdateDialogFilter", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -9236,7 +9236,7 @@ func (c *Client) MessagesUpdateDialogFiltersOrder(order []int32) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("MessagesUpdateDialogFiltersOrder", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -9265,7 +9265,7 @@ func (c *Client) MessagesUpdatePinnedMessage(params *MessagesUpdatePinnedMessage
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesUpdatePinnedMessage", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -9290,7 +9290,7 @@ func (c *Client) MessagesUploadEncryptedFile(peer *InputEncryptedChat, file Inpu
 
 	resp, ok := responseData.(EncryptedFile)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesUploadEncryptedFile", "EncryptedFile", responseData)
 	}
 	return resp, nil
 }
@@ -9319,7 +9319,7 @@ func (c *Client) MessagesUploadImportedMedia(peer InputPeer, importID int64, fil
 
 	resp, ok := responseData.(MessageMedia)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesUploadImportedMedia", "MessageMedia", responseData)
 	}
 	return resp, nil
 }
@@ -9344,7 +9344,7 @@ func (c *Client) MessagesUploadMedia(peer InputPeer, media InputMedia) (MessageM
 
 	resp, ok := responseData.(MessageMedia)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("MessagesUploadMedia", "MessageMedia", responseData)
 	}
 	return resp, nil
 }
@@ -9369,7 +9369,7 @@ func (c *Client) PaymentsAssignAppStoreTransaction(receipt []byte, purpose Input
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PaymentsAssignAppStoreTransaction", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -9394,7 +9394,7 @@ func (c *Client) PaymentsAssignPlayMarketTransaction(receipt *DataJson, purpose
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PaymentsAssignPlayMarketTransaction", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -9415,7 +9415,7 @@ func (c *Client) PaymentsCanPurchasePremium(purpose InputStorePaymentPurpose) (b
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("PaymentsCanPurchasePremium", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -9444,7 +9444,7 @@ func (c *Client) PaymentsClearSavedInfo(credentials, info bool) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("PaymentsClearSavedInfo", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -9465,7 +9465,7 @@ func (c *Client) PaymentsExportInvoice(invoiceMedia InputMedia) (*PaymentsExport
 
 	resp, ok := responseData.(*PaymentsExportedInvoice)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PaymentsExportInvoice", "*PaymentsExportedInvoice", responseData)
 	}
 	return resp, nil
 }
@@ -9486,7 +9486,7 @@ func (c *Client) PaymentsGetBankCardData(number string) (*PaymentsBankCardData,
 
 	resp, ok := responseData.(*PaymentsBankCardData)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PaymentsGetBankCardData", "*PaymentsBankCardData", responseData)
 	}
 	return resp, nil
 }
@@ -9515,7 +9515,7 @@ func (c *Client) PaymentsGetPaymentForm(invoice InputInvoice, themeParams *DataJ
 
 	resp, ok := responseData.(*PaymentsPaymentForm)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PaymentsGetPaymentForm", "*PaymentsPaymentForm", responseData)
 	}
 	return resp, nil
 }
@@ -9540,7 +9540,7 @@ func (c *Client) PaymentsGetPaymentReceipt(peer InputPeer, msgID int32) (*Paymen
 
 	resp, ok := responseData.(*PaymentsPaymentReceipt)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PaymentsGetPaymentReceipt", "*PaymentsPaymentReceipt", responseData)
 	}
 	return resp, nil
 }
@@ -9559,7 +9559,7 @@ func (c *Client) PaymentsGetSavedInfo() (*PaymentsSavedInfo, error) {
 
 	resp, ok := responseData.(*PaymentsSavedInfo)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PaymentsGetSavedInfo", "*PaymentsSavedInfo", responseData)
 	}
 	return resp, nil
 }
@@ -9589,7 +9589,7 @@ func (c *Client) PaymentsSendPaymentForm(params *PaymentsSendPaymentFormParams)
 
 	resp, ok := responseData.(PaymentsPaymentResult)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PaymentsSendPaymentForm", "PaymentsPaymentResult", responseData)
 	}
 	return resp, nil
 }
@@ -9620,7 +9620,7 @@ func (c *Client) PaymentsValidateRequestedInfo(save bool, invoice InputInvoice,
 
 	resp, ok := responseData.(*PaymentsValidatedRequestedInfo)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PaymentsValidateRequestedInfo", "*PaymentsValidatedRequestedInfo", responseData)
 	}
 	return resp, nil
 }
@@ -9647,7 +9647,7 @@ func (c *Client) PhoneAcceptCall(peer *InputPhoneCall, gB []byte, protocol *Phon
 
 	resp, ok := responseData.(*PhonePhoneCall)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneAcceptCall", "*PhonePhoneCall", responseData)
 	}
 	return resp, nil
 }
@@ -9672,7 +9672,7 @@ func (c *Client) PhoneCheckGroupCall(call *InputGroupCall, sources []int32) ([]i
 
 	resp, ok := responseData.([]int32)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneCheckGroupCall", "[]int32", responseData)
 	}
 	return resp, nil
 }
@@ -9701,7 +9701,7 @@ func (c *Client) PhoneConfirmCall(peer *InputPhoneCall, gA []byte, keyFingerprin
 
 	resp, ok := responseData.(*PhonePhoneCall)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneConfirmCall", "*PhonePhoneCall", responseData)
 	}
 	return resp, nil
 }
@@ -9730,7 +9730,7 @@ func (c *Client) PhoneCreateGroupCall(params *PhoneCreateGroupCallParams) (Updat
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneCreateGroupCall", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -9759,7 +9759,7 @@ func (c *Client) PhoneDiscardCall(params *PhoneDiscardCallParams) (Updates, erro
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneDiscardCall", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -9780,7 +9780,7 @@ func (c *Client) PhoneDiscardGroupCall(call *InputGroupCall) (Updates, error) {
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneDiscardGroupCall", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -9812,7 +9812,7 @@ func (c *Client) PhoneEditGroupCallParticipant(params *PhoneEditGroupCallPartici
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneEditGroupCallParticipant", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -9837,7 +9837,7 @@ func (c *Client) PhoneEditGroupCallTitle(call *InputGroupCall, title string) (Up
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneEditGroupCallTitle", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -9866,7 +9866,7 @@ func (c *Client) PhoneExportGroupCallInvite(canSelfUnmute bool, call *InputGroup
 
 	resp, ok := responseData.(*PhoneExportedGroupCallInvite)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneExportGroupCallInvite", "*PhoneExportedGroupCallInvite", responseData)
 	}
 	return resp, nil
 }
@@ -9885,7 +9885,7 @@ func (c *Client) PhoneGetCallConfig() (*DataJson, error) {
 
 	resp, ok := responseData.(*DataJson)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneGetCallConfig", "*DataJson", responseData)
 	}
 	return resp, nil
 }
@@ -9910,7 +9910,7 @@ func (c *Client) PhoneGetGroupCall(call *InputGroupCall, limit int32) (*PhoneGro
 
 	resp, ok := responseData.(*PhoneGroupCall)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneGetGroupCall", "*PhoneGroupCall", responseData)
 	}
 	return resp, nil
 }
@@ -9931,7 +9931,7 @@ func (c *Client) PhoneGetGroupCallJoinAs(peer InputPeer) (*PhoneJoinAsPeers, err
 
 	resp, ok := responseData.(*PhoneJoinAsPeers)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneGetGroupCallJoinAs", "*PhoneJoinAsPeers", responseData)
 	}
 	return resp, nil
 }
@@ -9952,7 +9952,7 @@ func (c *Client) PhoneGetGroupCallStreamChannels(call *InputGroupCall) (*PhoneGr
 
 	resp, ok := responseData.(*PhoneGroupCallStreamChannels)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneGetGroupCallStreamChannels", "*PhoneGroupCallStreamChannels", responseData)
 	}
 	return resp, nil
 }
@@ -9977,7 +9977,7 @@ func (c *Client) PhoneGetGroupCallStreamRtmpURL(peer InputPeer, revoke bool) (*P
 
 	resp, ok := responseData.(*PhoneGroupCallStreamRtmpURL)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneGetGroupCallStreamRtmpURL", "*PhoneGroupCallStreamRtmpURL", responseData)
 	}
 	return resp, nil
 }
@@ -10008,7 +10008,7 @@ func (c *Client) PhoneGetGroupParticipants(call *InputGroupCall, ids []InputPeer
 
 	resp, ok := responseData.(*PhoneGroupParticipants)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneGetGroupParticipants", "*PhoneGroupParticipants", responseData)
 	}
 	return resp, nil
 }
@@ -10033,7 +10033,7 @@ func (c *Client) PhoneInviteToGroupCall(call *InputGroupCall, users []InputUser)
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneInviteToGroupCall", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -10063,7 +10063,7 @@ func (c *Client) PhoneJoinGroupCall(params *PhoneJoinGroupCallParams) (Updates,
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneJoinGroupCall", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -10088,7 +10088,7 @@ func (c *Client) PhoneJoinGroupCallPresentation(call *InputGroupCall, params *Da
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneJoinGroupCallPresentation", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -10113,7 +10113,7 @@ func (c *Client) PhoneLeaveGroupCall(call *InputGroupCall, source int32) (Update
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneLeaveGroupCall", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -10134,7 +10134,7 @@ func (c *Client) PhoneLeaveGroupCallPresentation(call *InputGroupCall) (Updates,
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneLeaveGroupCallPresentation", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -10155,7 +10155,7 @@ func (c *Client) PhoneReceivedCall(peer *InputPhoneCall) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("PhoneReceivedCall", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -10184,7 +10184,7 @@ func (c *Client) PhoneRequestCall(params *PhoneRequestCallParams) (*PhonePhoneCa
 
 	resp, ok := responseData.(*PhonePhoneCall)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneRequestCall", "*PhonePhoneCall", responseData)
 	}
 	return resp, nil
 }
@@ -10209,7 +10209,7 @@ func (c *Client) PhoneSaveCallDebug(peer *InputPhoneCall, debug *DataJson) (bool
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("PhoneSaveCallDebug", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -10234,7 +10234,7 @@ func (c *Client) PhoneSaveCallLog(peer *InputPhoneCall, file InputFile) (bool, e
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("PhoneSaveCallLog", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -10259,7 +10259,7 @@ func (c *Client) PhoneSaveDefaultGroupCallJoinAs(peer, joinAs InputPeer) (bool,
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("PhoneSaveDefaultGroupCallJoinAs", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -10284,7 +10284,7 @@ func (c *Client) PhoneSendSignalingData(peer *InputPhoneCall, data []byte) (bool
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("PhoneSendSignalingData", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -10317,7 +10317,7 @@ func (c *Client) PhoneSetCallRating(userInitiative bool, peer *InputPhoneCall, r
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneSetCallRating", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -10338,7 +10338,7 @@ func (c *Client) PhoneStartScheduledGroupCall(call *InputGroupCall) (Updates, er
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneStartScheduledGroupCall", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -10367,7 +10367,7 @@ func (c *Client) PhoneToggleGroupCallRecord(params *PhoneToggleGroupCallRecordPa
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneToggleGroupCallRecord", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -10398,7 +10398,7 @@ func (c *Client) PhoneToggleGroupCallSettings(resetInviteHash bool, call *InputG
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneToggleGroupCallSettings", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -10423,7 +10423,7 @@ func (c *Client) PhoneToggleGroupCallStartSubscription(call *InputGroupCall, sub
 
 	resp, ok := responseData.(Updates)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhoneToggleGroupCallStartSubscription", "Updates", responseData)
 	}
 	return resp, nil
 }
@@ -10444,7 +10444,7 @@ func (c *Client) PhotosDeletePhotos(id []InputPhoto) ([]int64, error) {
 
 	resp, ok := responseData.([]int64)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhotosDeletePhotos", "[]int64", responseData)
 	}
 	return resp, nil
 }
@@ -10473,7 +10473,7 @@ func (c *Client) PhotosGetUserPhotos(userID InputUser, offset int32, maxID int64
 
 	resp, ok := responseData.(PhotosPhotos)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhotosGetUserPhotos", "PhotosPhotos", responseData)
 	}
 	return resp, nil
 }
@@ -10494,7 +10494,7 @@ func (c *Client) PhotosUpdateProfilePhoto(id InputPhoto) (*PhotosPhoto, error) {
 
 	resp, ok := responseData.(*PhotosPhoto)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhotosUpdateProfilePhoto", "*PhotosPhoto", responseData)
 	}
 	return resp, nil
 }
@@ -10525,7 +10525,7 @@ func (c *Client) PhotosUploadProfilePhoto(file, video InputFile, videoStartTs fl
 
 	resp, ok := responseData.(*PhotosPhoto)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("PhotosUploadProfilePhoto", "*PhotosPhoto", responseData)
 	}
 	return resp, nil
 }
@@ -10554,7 +10554,7 @@ func (c *Client) StatsGetBroadcastStats(dark bool, channel InputChannel) (*Stats
 
 	resp, ok := responseData.(*StatsBroadcastStats)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("StatsGetBroadcastStats", "*StatsBroadcastStats", responseData)
 	}
 	return resp, nil
 }
@@ -10583,7 +10583,7 @@ func (c *Client) StatsGetMegagroupStats(dark bool, channel InputChannel) (*Stats
 
 	resp, ok := responseData.(*StatsMegagroupStats)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("StatsGetMegagroupStats", "*StatsMegagroupStats", responseData)
 	}
 	return resp, nil
 }
@@ -10609,7 +10609,7 @@ func (c *Client) StatsGetMessagePublicForwards(params *StatsGetMessagePublicForw
 
 	resp, ok := responseData.(MessagesMessages)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("StatsGetMessagePublicForwards", "MessagesMessages", responseData)
 	}
 	return resp, nil
 }
@@ -10640,7 +10640,7 @@ func (c *Client) StatsGetMessageStats(dark bool, channel InputChannel, msgID int
 
 	resp, ok := responseData.(*StatsMessageStats)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("StatsGetMessageStats", "*StatsMessageStats", responseData)
 	}
 	return resp, nil
 }
@@ -10669,7 +10669,7 @@ func (c *Client) StatsLoadAsyncGraph(token string, x int64) (StatsGraph, error)
 
 	resp, ok := responseData.(StatsGraph)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("StatsLoadAsyncGraph", "StatsGraph", responseData)
 	}
 	return resp, nil
 }
@@ -10694,7 +10694,7 @@ func (c *Client) StickersAddStickerToSet(stickerset InputStickerSet, sticker *In
 
 	resp, ok := responseData.(MessagesStickerSet)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("StickersAddStickerToSet", "MessagesStickerSet", responseData)
 	}
 	return resp, nil
 }
@@ -10719,7 +10719,7 @@ func (c *Client) StickersChangeStickerPosition(sticker InputDocument, position i
 
 	resp, ok := responseData.(MessagesStickerSet)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("StickersChangeStickerPosition", "MessagesStickerSet", responseData)
 	}
 	return resp, nil
 }
@@ -10740,7 +10740,7 @@ func (c *Client) StickersCheckShortName(shortName string) (bool, error) {
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("StickersCheckShortName", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -10773,7 +10773,7 @@ func (c *Client) StickersCreateStickerSet(params *StickersCreateStickerSetParams
 
 	resp, ok := responseData.(MessagesStickerSet)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("StickersCreateStickerSet", "MessagesStickerSet", responseData)
 	}
 	return resp, nil
 }
@@ -10794,7 +10794,7 @@ func (c *Client) StickersRemoveStickerFromSet(sticker InputDocument) (MessagesSt
 
 	resp, ok := responseData.(MessagesStickerSet)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("StickersRemoveStickerFromSet", "MessagesStickerSet", responseData)
 	}
 	return resp, nil
 }
@@ -10819,7 +10819,7 @@ func (c *Client) StickersSetStickerSetThumb(stickerset InputStickerSet, thumb In
 
 	resp, ok := responseData.(MessagesStickerSet)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("StickersSetStickerSetThumb", "MessagesStickerSet", responseData)
 	}
 	return resp, nil
 }
@@ -10840,7 +10840,7 @@ func (c *Client) StickersSuggestShortName(title string) (*StickersSuggestedShort
 
 	resp, ok := responseData.(*StickersSuggestedShortName)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("StickersSuggestShortName", "*StickersSuggestedShortName", responseData)
 	}
 	return resp, nil
 }
@@ -10869,7 +10869,7 @@ func (c *Client) UpdatesGetChannelDifference(params *UpdatesGetChannelDifference
 
 	resp, ok := responseData.(UpdatesChannelDifference)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("UpdatesGetChannelDifference", "UpdatesChannelDifference", responseData)
 	}
 	return resp, nil
 }
@@ -10902,7 +10902,7 @@ func (c *Client) UpdatesGetDifference(pts, ptsTotalLimit, date, qts int32) (Upda
 
 	resp, ok := responseData.(UpdatesDifference)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("UpdatesGetDifference", "UpdatesDifference", responseData)
 	}
 	return resp, nil
 }
@@ -10921,7 +10921,7 @@ func (c *Client) UpdatesGetState() (*UpdatesState, error) {
 
 	resp, ok := responseData.(*UpdatesState)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("UpdatesGetState", "*UpdatesState", responseData)
 	}
 	return resp, nil
 }
@@ -10948,7 +10948,7 @@ func (c *Client) UploadGetCdnFile(fileToken []byte, offset int64, limit int32) (
 
 	resp, ok := responseData.(UploadCdnFile)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("UploadGetCdnFile", "UploadCdnFile", responseData)
 	}
 	return resp, nil
 }
@@ -10973,7 +10973,7 @@ func (c *Client) UploadGetCdnFileHashes(fileToken []byte, offset int64) ([]*File
 
 	resp, ok := responseData.([]*FileHash)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("UploadGetCdnFileHashes", "[]*FileHash", responseData)
 	}
 	return resp, nil
 }
@@ -11002,7 +11002,7 @@ func (c *Client) UploadGetFile(params *UploadGetFileParams) (UploadFile, error)
 
 	resp, ok := responseData.(UploadFile)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("UploadGetFile", "UploadFile", responseData)
 	}
 	return resp, nil
 }
@@ -11027,7 +11027,7 @@ func (c *Client) UploadGetFileHashes(location InputFileLocation, offset int64) (
 
 	resp, ok := responseData.([]*FileHash)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("UploadGetFileHashes", "[]*FileHash", responseData)
 	}
 	return resp, nil
 }
@@ -11054,7 +11054,7 @@ func (c *Client) UploadGetWebFile(location InputWebFileLocation, offset, limit i
 
 	resp, ok := responseData.(*UploadWebFile)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("UploadGetWebFile", "*UploadWebFile", responseData)
 	}
 	return resp, nil
 }
@@ -11079,7 +11079,7 @@ func (c *Client) UploadReuploadCdnFile(fileToken, requestToken []byte) ([]*FileH
 
 	resp, ok := responseData.([]*FileHash)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("UploadReuploadCdnFile", "[]*FileHash", responseData)
 	}
 	return resp, nil
 }
@@ -11108,7 +11108,7 @@ func (c *Client) UploadSaveBigFilePart(fileID int64, filePart, fileTotalParts in
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("UploadSaveBigFilePart", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -11135,7 +11135,7 @@ func (c *Client) UploadSaveFilePart(fileID int64, filePart int32, bytes []byte)
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("UploadSaveFilePart", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -11156,7 +11156,7 @@ func (c *Client) UsersGetFullUser(id InputUser) (*UsersUserFull, error) {
 
 	resp, ok := responseData.(*UsersUserFull)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("UsersGetFullUser", "*UsersUserFull", responseData)
 	}
 	return resp, nil
 }
@@ -11177,7 +11177,7 @@ func (c *Client) UsersGetUsers(id []InputUser) ([]User, error) {
 
 	resp, ok := responseData.([]User)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return nil, mtproto.NewUnexpectedResponseError("UsersGetUsers", "[]User", responseData)
 	}
 	return resp, nil
 }
@@ -11191,9 +11191,9 @@ func (*UsersSetSecureValueErrorsParams) CRC() uint32 {
 	return 0x90c894b5
 }
 
-func (c *Client) UsersSetSecureValueErrors(id InputUser, secureErrors []SecureValueError) (bool, error) {
+func (c *Client) UsersSetSecureValueErrors(id InputUser, errs []SecureValueError) (bool, error) {
 	responseData, err := c.MakeRequest(&UsersSetSecureValueErrorsParams{
-		Errors: secureErrors,
+		Errors: errs,
 		ID:     id,
 	})
 	if err != nil {
@@ -11202,7 +11202,7 @@ func (c *Client) UsersSetSecureValueErrors(id InputUser, secureErrors []SecureVa
 
 	resp, ok := responseData.(bool)
 	if !ok {
-		panic("got invalid response type: " + reflect.TypeOf(responseData).String())
+		return false, mtproto.NewUnexpectedResponseError("UsersSetSecureValueErrors", "bool", responseData)
 	}
 	return resp, nil
 }
@@ -11,6 +11,11 @@ import (
 	"github.com/jwillp/gogram/internal/encoding/tl"
 )
 
+func init() {
+	// the wrappers are registered so they can be decoded as well, by fake servers in tests for instance
+	tl.RegisterObjects(&InitConnectionParams{}, &InvokeWithLayerParams{}, &InvokeWithTakeoutParams{})
+}
+
 //invokeAfterMsg#cb9f372d {X:Type} msg_id:long query:!X = X;
 //invokeAfterMsgs#3dc4b4f0 {X:Type} msg_ids:Vector<long> query:!X = X;
 
@@ -74,7 +79,7 @@ type InvokeWithTakeoutParams struct {
 }
 
 func (*InvokeWithTakeoutParams) CRC() uint32 {
-	return 0xda9b0d0d //nolint:gomnd not magic
+	return 0xaca9fd2e //nolint:gomnd not magic
 }
 
 func (m *Client) InvokeWithTakeout(takeoutID int, query tl.Object) (tl.Object, error) {
@@ -83,7 +88,7 @@ func (m *Client) InvokeWithTakeout(takeoutID int, query tl.Object) (tl.Object, e
 		Query:     query,
 	})
 	if err != nil {
-		return nil, errors.Wrap(err, "sending InvokeWithLayer")
+		return nil, errors.Wrap(err, "sending InvokeWithTakeout")
 	}
 
 	return data.(tl.Object), nil
@@ -0,0 +1,682 @@
+// Copyright (c) 2022, jwillp
+
+package telegram
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jwillp/gogram/internal/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	// time to wait for a missing update before asking the server for the difference
+	updatesGapTimeout = 500 * time.Millisecond
+	// difference is fetched after this long without any update, as recommended by telegram
+	updatesIdleTimeout = 15 * time.Minute
+	// interval at which a changed update state is persisted
+	updatesFlushInterval = 5 * time.Second
+
+	channelDifferenceLimit = 100
+)
+
+// sequence boxes of the update state, channel boxes are keyed by the (positive) channel id
+const (
+	boxCommon int64 = 0
+	boxQts    int64 = -1
+)
+
+// UpdatesSnapshot is the persisted update state of an account, Channels maps channel id to the channel pts
+type UpdatesSnapshot struct {
+	Pts      int32           `json:"pts"`
+	Qts      int32           `json:"qts"`
+	Seq      int32           `json:"seq"`
+	Date     int32           `json:"date"`
+	Channels map[int64]int32 `json:"channels,omitempty"`
+}
+
+// UpdatesStorage persists the update state, so a restarted client can fetch the updates it missed.
+// Load returns (nil, nil) when nothing was stored yet.
+type UpdatesStorage interface {
+	Load() (*UpdatesSnapshot, error)
+	Store(s *UpdatesSnapshot) error
+}
+
+type fileUpdatesStorage struct {
+	path string
+}
+
+// NewUpdatesFileStorage stores the update state as json file at path
+func NewUpdatesFileStorage(path string) UpdatesStorage {
+	return &fileUpdatesStorage{path: path}
+}
+
+func (f *fileUpdatesStorage) Load() (*UpdatesSnapshot, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "reading updates state")
+	}
+	s := new(UpdatesSnapshot)
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, errors.Wrap(err, "parsing updates state")
+	}
+	return s, nil
+}
+
+func (f *fileUpdatesStorage) Store(s *UpdatesSnapshot) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return errors.Wrap(err, "marshalling updates state")
+	}
+	return os.WriteFile(f.path, data, 0600)
+}
+
+type memoryUpdatesStorage struct {
+	mutex    sync.Mutex
+	snapshot *UpdatesSnapshot
+}
+
+// NewUpdatesMemoryStorage keeps the update state in memory, a restarted client starts from the current state
+func NewUpdatesMemoryStorage() UpdatesStorage {
+	return &memoryUpdatesStorage{}
+}
+
+func (m *memoryUpdatesStorage) Load() (*UpdatesSnapshot, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.snapshot == nil {
+		return nil, nil
+	}
+	s := *m.snapshot
+	return &s, nil
+}
+
+func (m *memoryUpdatesStorage) Store(s *UpdatesSnapshot) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	stored := *s
+	m.snapshot = &stored
+	return nil
+}
+
+type pendingUpdate struct {
+	update   Update
+	box      int64
+	pts      int32
+	ptsCount int32
+	silent   bool
+}
+
+// updatesManager tracks pts, qts and seq of the account and pts of every channel. Updates arriving out of
+// order are held back until the gap is filled, either by the missing updates or by getDifference.
+type updatesManager struct {
+	client  *Client
+	storage UpdatesStorage
+	log     *utils.Logger
+
+	mutex      sync.Mutex
+	started    bool
+	selfID     int64
+	state      UpdatesSnapshot
+	dirty      bool
+	pending    []pendingUpdate
+	gapTimers  map[int64]*time.Timer
+	fetching   map[int64]bool
+	lastUpdate time.Time
+}
+
+func newUpdatesManager(c *Client, storage UpdatesStorage) *updatesManager {
+	return &updatesManager{
+		client:    c,
+		storage:   storage,
+		log:       c.Log.Named("updates"),
+		state:     UpdatesSnapshot{Channels: make(map[int64]int32)},
+		gapTimers: make(map[int64]*time.Timer),
+		fetching:  make(map[int64]bool),
+	}
+}
+
+// start restores the stored state and catches up with everything missed since, without a stored state
+// tracking begins at current
+func (m *updatesManager) start(current *UpdatesState) {
+	m.mutex.Lock()
+	if m.started {
+		m.mutex.Unlock()
+		return
+	}
+	m.started = true
+	m.lastUpdate = time.Now()
+
+	stored, err := m.storage.Load()
+	if err != nil {
+		m.log.Error("loading updates state", "error", err)
+	}
+	catchUp := stored != nil && stored.Pts != 0
+	if catchUp {
+		m.state = *stored
+		if m.state.Channels == nil {
+			m.state.Channels = make(map[int64]int32)
+		}
+	} else {
+		m.state.Pts, m.state.Qts, m.state.Seq, m.state.Date = current.Pts, current.Qts, current.Seq, current.Date
+		m.dirty = true
+	}
+	channels := make([]int64, 0, len(m.state.Channels))
+	for id := range m.state.Channels {
+		channels = append(channels, id)
+	}
+	m.mutex.Unlock()
+
+	go m.loop()
+	go func() {
+		if me, err := m.client.GetMe(); err == nil {
+			m.mutex.Lock()
+			m.selfID = me.ID
+			m.mutex.Unlock()
+		}
+		if !catchUp {
+			return
+		}
+		m.log.Debug("catching up", "pts", stored.Pts)
+		m.resolveGap(boxCommon)
+		for _, id := range channels {
+			m.resolveGap(id)
+		}
+	}()
+}
+
+func (m *updatesManager) loop() {
+	ticker := time.NewTicker(updatesFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.client.stopCh:
+			return
+		case <-ticker.C:
+			m.flush()
+			m.mutex.Lock()
+			idle := time.Since(m.lastUpdate) > updatesIdleTimeout
+			if idle {
+				m.lastUpdate = time.Now()
+			}
+			m.mutex.Unlock()
+			if idle {
+				go m.resolveGap(boxCommon)
+			}
+		}
+	}
+}
+
+// flush persists the state if it changed since the last flush
+func (m *updatesManager) flush() {
+	m.mutex.Lock()
+	if !m.dirty {
+		m.mutex.Unlock()
+		return
+	}
+	snapshot := m.state
+	snapshot.Channels = make(map[int64]int32, len(m.state.Channels))
+	for id, pts := range m.state.Channels {
+		snapshot.Channels[id] = pts
+	}
+	m.dirty = false
+	m.mutex.Unlock()
+
+	if err := m.storage.Store(&snapshot); err != nil {
+		m.log.Error("storing updates state", "error", err)
+		m.mutex.Lock()
+		m.dirty = true
+		m.mutex.Unlock()
+	}
+}
+
+// process handles an Updates object pushed by the server, silent is set for updates returned as result of a
+// request: they advance the state but are not dispatched to handlers
+func (m *updatesManager) process(u any, silent bool) {
+	m.mutex.Lock()
+	m.lastUpdate = time.Now()
+	selfID := m.selfID
+	m.mutex.Unlock()
+
+	switch upd := u.(type) {
+	case *UpdatesObj:
+		m.handleUpdates(upd.Updates, upd.Users, upd.Chats, upd.Seq, upd.Seq, upd.Date, silent)
+	case *UpdatesCombined:
+		m.handleUpdates(upd.Updates, upd.Users, upd.Chats, upd.SeqStart, upd.Seq, upd.Date, silent)
+	case *UpdateShort:
+		m.handleUpdates([]Update{upd.Update}, nil, nil, 0, 0, upd.Date, silent)
+	case *UpdateShortMessage:
+		msg := &MessageObj{Out: upd.Out, Mentioned: upd.Mentioned, MediaUnread: upd.MediaUnread, Silent: upd.Silent, ID: upd.ID, Message: upd.Message, FromID: getPeerUser(upd.UserID), PeerID: getPeerUser(upd.UserID), Date: upd.Date, FwdFrom: upd.FwdFrom, ViaBotID: upd.ViaBotID, ReplyTo: upd.ReplyTo, Entities: upd.Entities, TtlPeriod: upd.TtlPeriod}
+		if upd.Out && selfID != 0 {
+			msg.FromID = getPeerUser(selfID)
+		}
+		m.handleUpdates([]Update{&UpdateNewMessage{Message: msg, Pts: upd.Pts, PtsCount: upd.PtsCount}}, nil, nil, 0, 0, upd.Date, silent)
+	case *UpdateShortChatMessage:
+		msg := &MessageObj{Out: upd.Out, Mentioned: upd.Mentioned, MediaUnread: upd.MediaUnread, Silent: upd.Silent, ID: upd.ID, Message: upd.Message, FromID: getPeerUser(upd.FromID), PeerID: &PeerChat{ChatID: upd.ChatID}, Date: upd.Date, FwdFrom: upd.FwdFrom, ViaBotID: upd.ViaBotID, ReplyTo: upd.ReplyTo, Entities: upd.Entities, TtlPeriod: upd.TtlPeriod}
+		m.handleUpdates([]Update{&UpdateNewMessage{Message: msg, Pts: upd.Pts, PtsCount: upd.PtsCount}}, nil, nil, 0, 0, upd.Date, silent)
+	case *UpdateShortSentMessage:
+		msg := &MessageObj{Out: upd.Out, ID: upd.ID, Date: upd.Date, Media: upd.Media, Entities: upd.Entities, TtlPeriod: upd.TtlPeriod}
+		m.handleUpdates([]Update{&UpdateNewMessage{Message: msg, Pts: upd.Pts, PtsCount: upd.PtsCount}}, nil, nil, 0, 0, upd.Date, true)
+	case *UpdatesTooLong:
+		go m.resolveGap(boxCommon)
+	default:
+		m.log.Warn(ErrInvalidUpdateType.Error(), "type", fmt.Sprintf("%T", u))
+	}
+}
+
+func (m *updatesManager) handleUpdates(updates []Update, users []User, chats []Chat, seqStart, seq, date int32, silent bool) {
+	if len(users) > 0 || len(chats) > 0 {
+		m.client.Cache.UpdatePeersToCache(users, chats)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.started && seq != 0 {
+		switch {
+		case m.state.Seq+1 > seqStart:
+			// already applied
+			return
+		case m.state.Seq+1 < seqStart:
+			go m.resolveGap(boxCommon)
+		}
+	}
+	for _, update := range updates {
+		m.handle(update, silent)
+	}
+	if m.started && seq > m.state.Seq {
+		m.state.Seq, m.state.Date = seq, date
+		m.dirty = true
+	}
+}
+
+// handle applies a single update, must be called with the mutex held
+func (m *updatesManager) handle(update Update, silent bool) {
+	if !m.started {
+		m.dispatch(update, silent)
+		return
+	}
+	if upd, ok := update.(*UpdateChannelTooLong); ok {
+		if _, tracked := m.state.Channels[upd.ChannelID]; tracked {
+			go m.resolveGap(upd.ChannelID)
+		}
+		return
+	}
+
+	box, pts, ptsCount, ok := updateSequence(update)
+	if !ok {
+		m.dispatch(update, silent)
+		return
+	}
+	apply, gap := m.check(box, pts, ptsCount)
+	switch {
+	case apply:
+		m.setPts(box, pts)
+		m.dispatch(update, silent)
+		m.applyPending(box)
+	case gap:
+		m.pending = append(m.pending, pendingUpdate{update: update, box: box, pts: pts, ptsCount: ptsCount, silent: silent})
+		m.armGapTimer(box)
+	}
+}
+
+// check reports whether an update can be applied to box right now, updates that were already applied are
+// neither applicable nor a gap
+func (m *updatesManager) check(box int64, pts, ptsCount int32) (apply, gap bool) {
+	if m.fetching[fetchBox(box)] {
+		return false, true
+	}
+	local, ok := m.localPts(box)
+	switch {
+	case !ok:
+		// first update of a channel we didn't track yet
+		return true, false
+	case local+ptsCount == pts:
+		return true, false
+	case local+ptsCount > pts:
+		return false, false
+	default:
+		return false, true
+	}
+}
+
+func (m *updatesManager) localPts(box int64) (int32, bool) {
+	switch box {
+	case boxCommon:
+		return m.state.Pts, true
+	case boxQts:
+		return m.state.Qts, true
+	default:
+		pts, ok := m.state.Channels[box]
+		return pts, ok
+	}
+}
+
+func (m *updatesManager) setPts(box int64, pts int32) {
+	switch box {
+	case boxCommon:
+		m.state.Pts = pts
+	case boxQts:
+		m.state.Qts = pts
+	default:
+		m.state.Channels[box] = pts
+	}
+	m.dirty = true
+}
+
+// applyPending applies buffered updates of box that became applicable and drops the ones already applied
+func (m *updatesManager) applyPending(box int64) {
+	sort.SliceStable(m.pending, func(i, j int) bool { return m.pending[i].pts < m.pending[j].pts })
+	for progress := true; progress; {
+		progress = false
+		for i := 0; i < len(m.pending); i++ {
+			p := m.pending[i]
+			if p.box != box {
+				continue
+			}
+			apply, gap := m.check(box, p.pts, p.ptsCount)
+			if gap {
+				continue
+			}
+			m.pending = append(m.pending[:i], m.pending[i+1:]...)
+			i--
+			if apply {
+				m.setPts(box, p.pts)
+				m.dispatch(p.update, p.silent)
+				progress = true
+			}
+		}
+	}
+	if !m.hasPending(box) {
+		if timer, ok := m.gapTimers[box]; ok {
+			timer.Stop()
+			delete(m.gapTimers, box)
+		}
+	}
+}
+
+// forcePending applies all buffered updates of box in order, accepting the gap
+func (m *updatesManager) forcePending(box int64) {
+	sort.SliceStable(m.pending, func(i, j int) bool { return m.pending[i].pts < m.pending[j].pts })
+	rest := m.pending[:0]
+	for _, p := range m.pending {
+		if p.box != box {
+			rest = append(rest, p)
+			continue
+		}
+		if local, _ := m.localPts(box); p.pts > local {
+			m.setPts(box, p.pts)
+			m.dispatch(p.update, p.silent)
+		}
+	}
+	m.pending = rest
+}
+
+func (m *updatesManager) hasPending(box int64) bool {
+	for _, p := range m.pending {
+		if p.box == box {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *updatesManager) armGapTimer(box int64) {
+	if _, ok := m.gapTimers[box]; ok {
+		return
+	}
+	m.gapTimers[box] = time.AfterFunc(updatesGapTimeout, func() {
+		m.mutex.Lock()
+		delete(m.gapTimers, box)
+		stillMissing := m.hasPending(box)
+		m.mutex.Unlock()
+		if stillMissing {
+			m.log.Debug("gap in updates, fetching difference", "box", box)
+			m.resolveGap(box)
+		}
+	})
+}
+
+// fetchBox returns the box whose difference covers box, qts is part of the common difference
+func fetchBox(box int64) int64 {
+	if box == boxQts {
+		return boxCommon
+	}
+	return box
+}
+
+// resolveGap fetches the difference of box and afterwards applies what is left of the buffered updates
+func (m *updatesManager) resolveGap(box int64) {
+	box = fetchBox(box)
+	m.mutex.Lock()
+	if m.fetching[box] {
+		m.mutex.Unlock()
+		return
+	}
+	m.fetching[box] = true
+	m.mutex.Unlock()
+
+	var err error
+	if box == boxCommon {
+		err = m.getDifference()
+	} else {
+		err = m.getChannelDifference(box)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.fetching, box)
+	boxes := []int64{box}
+	if box == boxCommon {
+		boxes = append(boxes, boxQts)
+	}
+	for _, b := range boxes {
+		if err != nil {
+			m.forcePending(b)
+		} else {
+			m.applyPending(b)
+		}
+	}
+	if err != nil {
+		m.log.Error("fetching difference", "error", err, "box", box)
+	}
+}
+
+func (m *updatesManager) getDifference() error {
+	for {
+		m.mutex.Lock()
+		pts, qts, date := m.state.Pts, m.state.Qts, m.state.Date
+		m.mutex.Unlock()
+
+		diff, err := m.client.UpdatesGetDifference(pts, 0, date, qts)
+		if err != nil {
+			return err
+		}
+		switch d := diff.(type) {
+		case *UpdatesDifferenceEmpty:
+			m.mutex.Lock()
+			m.state.Date, m.state.Seq = d.Date, d.Seq
+			m.dirty = true
+			m.mutex.Unlock()
+			return nil
+		case *UpdatesDifferenceObj:
+			m.applyDifference(d.NewMessages, d.OtherUpdates, d.Users, d.Chats, d.State)
+			return nil
+		case *UpdatesDifferenceSlice:
+			m.applyDifference(d.NewMessages, d.OtherUpdates, d.Users, d.Chats, d.IntermediateState)
+		case *UpdatesDifferenceTooLong:
+			m.log.Warn("updates difference too long, some updates were skipped")
+			m.mutex.Lock()
+			m.state.Pts = d.Pts
+			m.dirty = true
+			m.mutex.Unlock()
+		default:
+			return errors.New("unexpected difference type: " + reflect.TypeOf(diff).String())
+		}
+	}
+}
+
+func (m *updatesManager) applyDifference(messages []Message, others []Update, users []User, chats []Chat, state *UpdatesState) {
+	m.client.Cache.UpdatePeersToCache(users, chats)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, msg := range messages {
+		m.dispatch(&UpdateNewMessage{Message: msg}, false)
+	}
+	for _, update := range others {
+		// channel updates are still checked against the channel pts
+		if box, _, _, ok := updateSequence(update); ok && box > 0 {
+			m.handle(update, false)
+			continue
+		}
+		if _, ok := update.(*UpdateChannelTooLong); ok {
+			m.handle(update, false)
+			continue
+		}
+		m.dispatch(update, false)
+	}
+	if state != nil {
+		m.state.Pts, m.state.Qts, m.state.Seq, m.state.Date = state.Pts, state.Qts, state.Seq, state.Date
+		m.dirty = true
+	}
+}
+
+func (m *updatesManager) getChannelDifference(channelID int64) error {
+	channel, err := m.client.getChannelPeer(channelID)
+	if err != nil {
+		return err
+	}
+	for {
+		m.mutex.Lock()
+		pts := m.state.Channels[channelID]
+		m.mutex.Unlock()
+
+		diff, err := m.client.UpdatesGetChannelDifference(&UpdatesGetChannelDifferenceParams{
+			Channel: channel,
+			Filter:  &ChannelMessagesFilterEmpty{},
+			Pts:     pts,
+			Limit:   channelDifferenceLimit,
+		})
+		if err != nil {
+			return err
+		}
+		switch d := diff.(type) {
+		case *UpdatesChannelDifferenceEmpty:
+			m.setChannelPts(channelID, d.Pts)
+			return nil
+		case *UpdatesChannelDifferenceObj:
+			m.client.Cache.UpdatePeersToCache(d.Users, d.Chats)
+			m.mutex.Lock()
+			for _, msg := range d.NewMessages {
+				m.dispatch(&UpdateNewChannelMessage{Message: msg}, false)
+			}
+			for _, update := range d.OtherUpdates {
+				m.dispatch(update, false)
+			}
+			m.state.Channels[channelID] = d.Pts
+			m.dirty = true
+			m.mutex.Unlock()
+			if d.Final {
+				return nil
+			}
+		case *UpdatesChannelDifferenceTooLong:
+			m.client.Cache.UpdatePeersToCache(d.Users, d.Chats)
+			m.log.Warn("channel difference too long, some updates were skipped", "peer", channelID)
+			if dialog, ok := d.Dialog.(*DialogObj); ok && dialog.Pts != 0 {
+				m.setChannelPts(channelID, dialog.Pts)
+			}
+			return nil
+		default:
+			return errors.New("unexpected channel difference type: " + reflect.TypeOf(diff).String())
+		}
+	}
+}
+
+func (m *updatesManager) setChannelPts(channelID int64, pts int32) {
+	m.mutex.Lock()
+	m.state.Channels[channelID] = pts
+	m.dirty = true
+	m.mutex.Unlock()
+}
+
+func (m *updatesManager) dispatch(update Update, silent bool) {
+	if !silent {
+		m.client.dispatcher.Dispatch(update)
+	}
+}
+
+// updateSequence returns the box and pts of an update, ok is false for updates which are not sequenced
+func updateSequence(update Update) (box int64, pts, ptsCount int32, ok bool) {
+	switch u := update.(type) {
+	case *UpdateNewMessage:
+		return boxCommon, u.Pts, u.PtsCount, true
+	case *UpdateEditMessage:
+		return boxCommon, u.Pts, u.PtsCount, true
+	case *UpdateDeleteMessages:
+		return boxCommon, u.Pts, u.PtsCount, true
+	case *UpdateReadHistoryInbox:
+		return boxCommon, u.Pts, u.PtsCount, true
+	case *UpdateReadHistoryOutbox:
+		return boxCommon, u.Pts, u.PtsCount, true
+	case *UpdateWebPage:
+		return boxCommon, u.Pts, u.PtsCount, true
+	case *UpdateReadMessagesContents:
+		return boxCommon, u.Pts, u.PtsCount, true
+	case *UpdatePinnedMessages:
+		return boxCommon, u.Pts, u.PtsCount, true
+	case *UpdateFolderPeers:
+		return boxCommon, u.Pts, u.PtsCount, true
+	case *UpdateNewChannelMessage:
+		if id := channelOf(u.Message); id != 0 {
+			return id, u.Pts, u.PtsCount, true
+		}
+	case *UpdateEditChannelMessage:
+		if id := channelOf(u.Message); id != 0 {
+			return id, u.Pts, u.PtsCount, true
+		}
+	case *UpdateDeleteChannelMessages:
+		return u.ChannelID, u.Pts, u.PtsCount, true
+	case *UpdateChannelWebPage:
+		return u.ChannelID, u.Pts, u.PtsCount, true
+	case *UpdatePinnedChannelMessages:
+		return u.ChannelID, u.Pts, u.PtsCount, true
+	case *UpdateNewEncryptedMessage:
+		return boxQts, u.Qts, 1, true
+	case *UpdateBotStopped:
+		return boxQts, u.Qts, 1, true
+	case *UpdateChatParticipant:
+		return boxQts, u.Qts, 1, true
+	case *UpdateChannelParticipant:
+		return boxQts, u.Qts, 1, true
+	case *UpdateBotChatInviteRequester:
+		return boxQts, u.Qts, 1, true
+	case *UpdateMessagePollVote:
+		return boxQts, u.Qts, 1, true
+	}
+	return 0, 0, 0, false
+}
+
+func channelOf(message Message) int64 {
+	var peer Peer
+	switch msg := message.(type) {
+	case *MessageObj:
+		peer = msg.PeerID
+	case *MessageService:
+		peer = msg.PeerID
+	case *MessageEmpty:
+		peer = msg.PeerID
+	}
+	if channel, ok := peer.(*PeerChannel); ok {
+		return channel.ChannelID
+	}
+	return 0
+}
@@ -14,14 +14,13 @@ import (
 
 const DEF_ALBUM_WAIT_TIME = 600 * time.Millisecond
 
-var (
-	UpdateHandleDispatcher = &UpdateDispatcher{}
-)
-
 type messageHandle struct {
 	Pattern interface{}
 	Handler func(m *NewMessage) error
 	Filters *Filters
+
+	id         int64
+	dispatcher *UpdateDispatcher
 }
 
 type albumBox struct {
@@ -43,33 +42,60 @@ func (a *albumBox) Add(m *NewMessage) {
 }
 
 func (h *messageHandle) Remove() {
-	for i, handle := range UpdateHandleDispatcher.messageHandles {
-		if reflect.DeepEqual(handle, h) {
-			UpdateHandleDispatcher.messageHandles = append(UpdateHandleDispatcher.messageHandles[:i], UpdateHandleDispatcher.messageHandles[i+1:]...)
+	u := h.dispatcher
+	if u == nil {
+		return
+	}
+	u.Lock()
+	defer u.Unlock()
+	for i, handle := range u.messageHandles {
+		if handle.id == h.id {
+			u.messageHandles = append(u.messageHandles[:i], u.messageHandles[i+1:]...)
+			break
 		}
 	}
 }
 
 type albumHandle struct {
 	Handler func(alb *Album) error
+
+	id         int64
+	dispatcher *UpdateDispatcher
 }
 
 func (h *albumHandle) Remove() {
-	for i, handle := range UpdateHandleDispatcher.albumHandles {
-		if reflect.DeepEqual(handle, h) {
-			UpdateHandleDispatcher.albumHandles = append(UpdateHandleDispatcher.albumHandles[:i], UpdateHandleDispatcher.albumHandles[i+1:]...)
+	u := h.dispatcher
+	if u == nil {
+		return
+	}
+	u.Lock()
+	defer u.Unlock()
+	for i, handle := range u.albumHandles {
+		if handle.id == h.id {
+			u.albumHandles = append(u.albumHandles[:i], u.albumHandles[i+1:]...)
+			break
 		}
 	}
 }
 
 type chatActionHandle struct {
 	Handler func(m *NewMessage) error
+
+	id         int64
+	dispatcher *UpdateDispatcher
 }
 
 func (h *chatActionHandle) Remove() {
-	for i, handle := range UpdateHandleDispatcher.actionHandles {
-		if reflect.DeepEqual(handle, h) {
-			UpdateHandleDispatcher.actionHandles = append(UpdateHandleDispatcher.actionHandles[:i], UpdateHandleDispatcher.actionHandles[i+1:]...)
+	u := h.dispatcher
+	if u == nil {
+		return
+	}
+	u.Lock()
+	defer u.Unlock()
+	for i, handle := range u.actionHandles {
+		if handle.id == h.id {
+			u.actionHandles = append(u.actionHandles[:i], u.actionHandles[i+1:]...)
+			break
 		}
 	}
 }
@@ -77,12 +103,22 @@ func (h *chatActionHandle) Remove() {
 type messageEditHandle struct {
 	Pattern interface{}
 	Handler func(m *NewMessage) error
+
+	id         int64
+	dispatcher *UpdateDispatcher
 }
 
 func (h *messageEditHandle) Remove() {
-	for i, handle := range UpdateHandleDispatcher.messageEditHandles {
-		if reflect.DeepEqual(handle, h) {
-			UpdateHandleDispatcher.messageEditHandles = append(UpdateHandleDispatcher.messageEditHandles[:i], UpdateHandleDispatcher.messageEditHandles[i+1:]...)
+	u := h.dispatcher
+	if u == nil {
+		return
+	}
+	u.Lock()
+	defer u.Unlock()
+	for i, handle := range u.messageEditHandles {
+		if handle.id == h.id {
+			u.messageEditHandles = append(u.messageEditHandles[:i], u.messageEditHandles[i+1:]...)
+			break
 		}
 	}
 }
@@ -90,12 +126,22 @@ func (h *messageEditHandle) Remove() {
 type messageDeleteHandle struct {
 	Pattern interface{}
 	Handler func(m *UpdateDeleteMessages) error
+
+	id         int64
+	dispatcher *UpdateDispatcher
 }
 
 func (h *messageDeleteHandle) Remove() {
-	for i, handle := range UpdateHandleDispatcher.messageDeleteHandles {
-		if reflect.DeepEqual(handle, h) {
-			UpdateHandleDispatcher.messageDeleteHandles = append(UpdateHandleDispatcher.messageDeleteHandles[:i], UpdateHandleDispatcher.messageDeleteHandles[i+1:]...)
+	u := h.dispatcher
+	if u == nil {
+		return
+	}
+	u.Lock()
+	defer u.Unlock()
+	for i, handle := range u.messageDeleteHandles {
+		if handle.id == h.id {
+			u.messageDeleteHandles = append(u.messageDeleteHandles[:i], u.messageDeleteHandles[i+1:]...)
+			break
 		}
 	}
 }
@@ -103,12 +149,22 @@ func (h *messageDeleteHandle) Remove() {
 type inlineHandle struct {
 	Pattern interface{}
 	Handler func(m *InlineQuery) error
+
+	id         int64
+	dispatcher *UpdateDispatcher
 }
 
 func (h *inlineHandle) Remove() {
-	for i, handle := range UpdateHandleDispatcher.inlineHandles {
-		if reflect.DeepEqual(handle, h) {
-			UpdateHandleDispatcher.inlineHandles = append(UpdateHandleDispatcher.inlineHandles[:i], UpdateHandleDispatcher.inlineHandles[i+1:]...)
+	u := h.dispatcher
+	if u == nil {
+		return
+	}
+	u.Lock()
+	defer u.Unlock()
+	for i, handle := range u.inlineHandles {
+		if handle.id == h.id {
+			u.inlineHandles = append(u.inlineHandles[:i], u.inlineHandles[i+1:]...)
+			break
 		}
 	}
 }
@@ -116,12 +172,22 @@ func (h *inlineHandle) Remove() {
 type callbackHandle struct {
 	Pattern interface{}
 	Handler func(m *CallbackQuery) error
+
+	id         int64
+	dispatcher *UpdateDispatcher
 }
 
 func (h *callbackHandle) Remove() {
-	for i, handle := range UpdateHandleDispatcher.callbackHandles {
-		if reflect.DeepEqual(handle, h) {
-			UpdateHandleDispatcher.callbackHandles = append(UpdateHandleDispatcher.callbackHandles[:i], UpdateHandleDispatcher.callbackHandles[i+1:]...)
+	u := h.dispatcher
+	if u == nil {
+		return
+	}
+	u.Lock()
+	defer u.Unlock()
+	for i, handle := range u.callbackHandles {
+		if handle.id == h.id {
+			u.callbackHandles = append(u.callbackHandles[:i], u.callbackHandles[i+1:]...)
+			break
 		}
 	}
 }
@@ -129,24 +195,44 @@ func (h *callbackHandle) Remove() {
 type inlineCallbackHandle struct {
 	Pattern interface{}
 	Handler func(m *InlineCallbackQuery) error
+
+	id         int64
+	dispatcher *UpdateDispatcher
 }
 
 func (h *inlineCallbackHandle) Remove() {
-	for i, handle := range UpdateHandleDispatcher.inlineCallbackHandles {
-		if reflect.DeepEqual(handle, h) {
-			UpdateHandleDispatcher.inlineCallbackHandles = append(UpdateHandleDispatcher.inlineCallbackHandles[:i], UpdateHandleDispatcher.inlineCallbackHandles[i+1:]...)
+	u := h.dispatcher
+	if u == nil {
+		return
+	}
+	u.Lock()
+	defer u.Unlock()
+	for i, handle := range u.inlineCallbackHandles {
+		if handle.id == h.id {
+			u.inlineCallbackHandles = append(u.inlineCallbackHandles[:i], u.inlineCallbackHandles[i+1:]...)
+			break
 		}
 	}
 }
 
 type participantHandle struct {
 	Handler func(p *ParticipantUpdate) error
+
+	id         int64
+	dispatcher *UpdateDispatcher
 }
 
 func (h *participantHandle) Remove() {
-	for i, handle := range UpdateHandleDispatcher.participantHandles {
-		if reflect.DeepEqual(handle, h) {
-			UpdateHandleDispatcher.participantHandles = append(UpdateHandleDispatcher.participantHandles[:i], UpdateHandleDispatcher.participantHandles[i+1:]...)
+	u := h.dispatcher
+	if u == nil {
+		return
+	}
+	u.Lock()
+	defer u.Unlock()
+	for i, handle := range u.participantHandles {
+		if handle.id == h.id {
+			u.participantHandles = append(u.participantHandles[:i], u.participantHandles[i+1:]...)
+			break
 		}
 	}
 }
@@ -154,18 +240,33 @@ func (h *participantHandle) Remove() {
 type rawHandle struct {
 	updateType Update
 	Handler    func(m Update) error
+
+	id         int64
+	dispatcher *UpdateDispatcher
 }
 
 func (h *rawHandle) Remove() {
-	for i, handle := range UpdateHandleDispatcher.rawHandles {
-		if reflect.DeepEqual(handle, h) {
-			UpdateHandleDispatcher.rawHandles = append(UpdateHandleDispatcher.rawHandles[:i], UpdateHandleDispatcher.rawHandles[i+1:]...)
+	u := h.dispatcher
+	if u == nil {
+		return
+	}
+	u.Lock()
+	defer u.Unlock()
+	for i, handle := range u.rawHandles {
+		if handle.id == h.id {
+			u.rawHandles = append(u.rawHandles[:i], u.rawHandles[i+1:]...)
+			break
 		}
 	}
 }
 
+// UpdateDispatcher holds the update handlers of a client and routes incoming updates to them
 type UpdateDispatcher struct {
+	sync.RWMutex
 	client                *Client
+	lastID                int64
+	albums                map[int64]*albumBox
+	albumsMutex           sync.Mutex
 	messageHandles        []messageHandle
 	inlineHandles         []inlineHandle
 	callbackHandles       []callbackHandle
@@ -178,57 +279,103 @@ type UpdateDispatcher struct {
 	rawHandles            []rawHandle
 }
 
+func newUpdateDispatcher(c *Client) *UpdateDispatcher {
+	return &UpdateDispatcher{client: c, albums: make(map[int64]*albumBox)}
+}
+
 func (u *UpdateDispatcher) AddM(m messageHandle) messageHandle {
+	u.Lock()
+	defer u.Unlock()
+	u.lastID++
+	m.id, m.dispatcher = u.lastID, u
 	u.messageHandles = append(u.messageHandles, m)
 	return m
 }
 
 func (u *UpdateDispatcher) AddAL(a albumHandle) albumHandle {
+	u.Lock()
+	defer u.Unlock()
+	u.lastID++
+	a.id, a.dispatcher = u.lastID, u
 	u.albumHandles = append(u.albumHandles, a)
 	return a
 }
 
 func (u *UpdateDispatcher) AddI(i inlineHandle) inlineHandle {
+	u.Lock()
+	defer u.Unlock()
+	u.lastID++
+	i.id, i.dispatcher = u.lastID, u
 	u.inlineHandles = append(u.inlineHandles, i)
 	return i
 }
 
 func (u *UpdateDispatcher) AddC(c callbackHandle) callbackHandle {
+	u.Lock()
+	defer u.Unlock()
+	u.lastID++
+	c.id, c.dispatcher = u.lastID, u
 	u.callbackHandles = append(u.callbackHandles, c)
 	return c
 }
 
 func (u *UpdateDispatcher) AddIC(ic inlineCallbackHandle) inlineCallbackHandle {
+	u.Lock()
+	defer u.Unlock()
+	u.lastID++
+	ic.id, ic.dispatcher = u.lastID, u
 	u.inlineCallbackHandles = append(u.inlineCallbackHandles, ic)
 	return ic
 }
 
 func (u *UpdateDispatcher) AddA(a chatActionHandle) chatActionHandle {
+	u.Lock()
+	defer u.Unlock()
+	u.lastID++
+	a.id, a.dispatcher = u.lastID, u
 	u.actionHandles = append(u.actionHandles, a)
 	return a
 }
 
 func (u *UpdateDispatcher) AddME(m messageEditHandle) messageEditHandle {
+	u.Lock()
+	defer u.Unlock()
+	u.lastID++
+	m.id, m.dispatcher = u.lastID, u
 	u.messageEditHandles = append(u.messageEditHandles, m)
 	return m
 }
 
 func (u *UpdateDispatcher) AddMD(m messageDeleteHandle) messageDeleteHandle {
+	u.Lock()
+	defer u.Unlock()
+	u.lastID++
+	m.id, m.dispatcher = u.lastID, u
 	u.messageDeleteHandles = append(u.messageDeleteHandles, m)
 	return m
 }
 
 func (u *UpdateDispatcher) AddP(p participantHandle) participantHandle {
+	u.Lock()
+	defer u.Unlock()
+	u.lastID++
+	p.id, p.dispatcher = u.lastID, u
 	u.participantHandles = append(u.participantHandles, p)
 	return p
 }
 
 func (u *UpdateDispatcher) AddR(r rawHandle) rawHandle {
+	u.Lock()
+	defer u.Unlock()
+	u.lastID++
+	r.id, r.dispatcher = u.lastID, u
 	u.rawHandles = append(u.rawHandles, r)
 	return r
 }
 
 func (u *UpdateDispatcher) HandleMessageUpdate(update Message) {
+	u.RLock()
+	defer u.RUnlock()
 	switch msg := update.(type) {
 	case *MessageObj:
 		if msg.GroupedID != 0 {
@@ -240,7 +387,7 @@ func (u *UpdateDispatcher) HandleMessageUpdate(update Message) {
 					m := packMessage(u.client, msg)
 					if h.runFilterChain(m) {
 						if err := h.Handler(m); err != nil {
-							u.client.Log.Error(err)
+							u.client.Log.Error("message handler", "error", err, "peer", u.client.GetPeerID(msg.PeerID), "message_id", msg.ID)
 						}
 					}
 				}(handle)
@@ -250,7 +397,7 @@ func (u *UpdateDispatcher) HandleMessageUpdate(update Message) {
 		for _, handle := range u.actionHandles {
 			go func(h chatActionHandle) {
 				if err := h.Handler(packMessage(u.client, msg)); err != nil {
-					u.client.Log.Error(err)
+					u.client.Log.Error("action handler", "error", err, "peer", u.client.GetPeerID(msg.PeerID), "message_id", msg.ID)
 				}
 			}(handle)
 		}
@@ -259,11 +406,12 @@ func (u *UpdateDispatcher) HandleMessageUpdate(update Message) {
 
 var (
 	ErrInvalidUpdateType = errors.New("invalid update type")
-	activeAlbums         = make(map[int64]*albumBox)
 )
 
 func (u *UpdateDispatcher) HandleAlbum(message MessageObj) {
-	if group, ok := activeAlbums[message.GroupedID]; ok {
+	u.albumsMutex.Lock()
+	defer u.albumsMutex.Unlock()
+	if group, ok := u.albums[message.GroupedID]; ok {
 		group.Add(packMessage(u.client, &message))
 	} else {
 		abox := &albumBox{
@@ -271,21 +419,29 @@ func (u *UpdateDispatcher) HandleAlbum(message MessageObj) {
 			messages:  []*NewMessage{packMessage(u.client, &message)},
 			groupedID: message.GroupedID,
 		}
-		activeAlbums[message.GroupedID] = abox
+		u.albums[message.GroupedID] = abox
 		go func() {
 			<-abox.waitExit
+			u.albumsMutex.Lock()
+			delete(u.albums, message.GroupedID)
+			u.albumsMutex.Unlock()
+
+			abox.Lock()
+			album := &Album{
+				GroupedID: abox.groupedID,
+				Messages:  abox.messages,
+				Client:    u.client,
+			}
+			abox.Unlock()
+			u.RLock()
+			defer u.RUnlock()
 			for _, handle := range u.albumHandles {
 				go func(h albumHandle) {
-					if err := h.Handler(&Album{
-						GroupedID: abox.groupedID,
-						Messages:  abox.messages,
-						Client:    u.client,
-					}); err != nil {
-						u.client.Log.Error("- updates.dispatcher.Album -", err)
+					if err := h.Handler(album); err != nil {
+						u.client.Log.Error("album handler", "error", err, "grouped_id", album.GroupedID)
 					}
 				}(handle)
 			}
-			delete(activeAlbums, message.GroupedID)
 		}()
 		go abox.Wait()
 	}
@@ -294,7 +450,7 @@ func (u *UpdateDispatcher) HandleAlbum(message MessageObj) {
 func (u *UpdateDispatcher) HandleMessageUpdateW(message Message, pts int32) {
 	m, err := u.client.GetDiffrence(pts, 1)
 	if err != nil {
-		u.client.Log.Error(err)
+		u.client.Log.Error("getting difference", "error", err, "pts", pts)
 	}
 	if m != nil {
 		u.HandleMessageUpdate(m)
@@ -302,13 +458,15 @@ func (u *UpdateDispatcher) HandleMessageUpdateW(message Message, pts int32) {
 }
 
 func (u *UpdateDispatcher) HandleEditUpdate(update Message) {
+	u.RLock()
+	defer u.RUnlock()
 	switch msg := update.(type) {
 	case *MessageObj:
 		for _, handle := range u.messageEditHandles {
 			if handle.IsMatch(msg.Message) {
 				go func(h messageEditHandle) {
 					if err := h.Handler(packMessage(u.client, msg)); err != nil {
-						u.client.Log.Error("- updates.dispatcher.EditUpdate -", err)
+						u.client.Log.Error("edit handler", "error", err, "peer", u.client.GetPeerID(msg.PeerID), "message_id", msg.ID)
 					}
 				}(handle)
 			}
@@ -317,11 +475,13 @@ func (u *UpdateDispatcher) HandleEditUpdate(update Message) {
 }
 
 func (u *UpdateDispatcher) HandleCallbackUpdate(update *UpdateBotCallbackQuery) {
+	u.RLock()
+	defer u.RUnlock()
 	for _, handle := range u.callbackHandles {
 		if handle.IsMatch(update.Data) {
 			go func(h callbackHandle) {
 				if err := h.Handler(packCallbackQuery(u.client, update)); err != nil {
-					u.client.Log.Error("- updates.dispatcher.CallbackUpdate -", err)
+					u.client.Log.Error("callback handler", "error", err, "peer", u.client.GetPeerID(update.Peer))
 				}
 			}(handle)
 		}
@@ -329,11 +489,13 @@ func (u *UpdateDispatcher) HandleCallbackUpdate(update *UpdateBotCallbackQuery)
 }
 
 func (u *UpdateDispatcher) HandleInlineCallbackUpdate(update *UpdateInlineBotCallbackQuery) {
+	u.RLock()
+	defer u.RUnlock()
 	for _, handle := range u.inlineCallbackHandles {
 		if handle.IsMatch(update.Data) {
 			go func(h inlineCallbackHandle) {
 				if err := h.Handler(packInlineCallbackQuery(u.client, update)); err != nil {
-					u.client.Log.Error("- updates.dispatcher.InlineCallbackUpdate -", err)
+					u.client.Log.Error("inline callback handler", "error", err, "peer", update.UserID)
 				}
 			}(handle)
 		}
@@ -341,21 +503,25 @@ func (u *UpdateDispatcher) HandleInlineCallbackUpdate(update *UpdateInlineBotCal
 }
 
 func (u *UpdateDispatcher) HandleParticipantUpdate(update *UpdateChannelParticipant) {
+	u.RLock()
+	defer u.RUnlock()
 	for _, handle := range u.participantHandles {
 		go func(h participantHandle) {
 			if err := h.Handler(packChannelParticipant(u.client, update)); err != nil {
-				u.client.Log.Error("- updates.dispatcher.ParticipantUpdate -", err)
+				u.client.Log.Error("participant handler", "error", err, "peer", update.ChannelID)
 			}
 		}(handle)
 	}
 }
 
 func (u *UpdateDispatcher) HandleInlineUpdate(update *UpdateBotInlineQuery) {
+	u.RLock()
+	defer u.RUnlock()
 	for _, handle := range u.inlineHandles {
 		if handle.IsMatch(update.Query) {
 			go func(h inlineHandle) {
 				if err := h.Handler(packInlineQuery(u.client, update)); err != nil {
-					u.client.Log.Error("- updates.dispatcher.InlineUpdate -", err)
+					u.client.Log.Error("inline handler", "error", err, "peer", update.UserID)
 				}
 			}(handle)
 		}
@@ -363,21 +529,25 @@ func (u *UpdateDispatcher) HandleInlineUpdate(update *UpdateBotInlineQuery) {
 }
 
 func (u *UpdateDispatcher) HandleDeleteUpdate(update *UpdateDeleteMessages) {
+	u.RLock()
+	defer u.RUnlock()
 	for _, handle := range u.messageDeleteHandles {
 		go func(h messageDeleteHandle) {
 			if err := h.Handler(update); err != nil {
-				u.client.Log.Error("- updates.dispatcher.DeleteUpdate -", err)
+				u.client.Log.Error("delete handler", "error", err)
 			}
 		}(handle)
 	}
 }
 
 func (u *UpdateDispatcher) HandleRawUpdate(update Update) {
+	u.RLock()
+	defer u.RUnlock()
 	for _, handle := range u.rawHandles {
 		if reflect.TypeOf(update) == reflect.TypeOf(handle.updateType) {
 			go func(h rawHandle) {
 				if err := h.Handler(update); err != nil {
-					u.client.Log.Error("- updates.dispatcher.RawUpdate -", err)
+					u.client.Log.Error("raw handler", "error", err, "update", reflect.TypeOf(update).String())
 				}
 			}(handle)
 		}
@@ -503,15 +673,15 @@ type Filters struct {
 }
 
 func (c *Client) AddMessageHandler(pattern interface{}, handler func(m *NewMessage) error, filters ...*Filters) messageHandle {
-	return UpdateHandleDispatcher.AddM(messageHandle{Pattern: pattern, Handler: handler, Filters: getVariadic(filters, &Filters{}).(*Filters)})
+	return c.dispatcher.AddM(messageHandle{Pattern: pattern, Handler: handler, Filters: getVariadic(filters, &Filters{}).(*Filters)})
 }
 
 func (c *Client) AddAlbumHandler(handler func(m *Album) error) albumHandle {
-	return UpdateHandleDispatcher.AddAL(albumHandle{Handler: handler})
+	return c.dispatcher.AddAL(albumHandle{Handler: handler})
 }
 
 func (c *Client) AddActionHandler(handler func(m *NewMessage) error) chatActionHandle {
-	return UpdateHandleDispatcher.AddA(chatActionHandle{Handler: handler})
+	return c.dispatcher.AddA(chatActionHandle{Handler: handler})
 }
 
 // Handle updates categorized as "UpdateMessageEdited"
@@ -520,7 +690,7 @@ func (c *Client) AddActionHandler(handler func(m *NewMessage) error) chatActionH
 //   - Message Edited
 //   - Channel Post Edited
 func (c *Client) AddEditHandler(pattern interface{}, handler func(m *NewMessage) error) messageEditHandle {
-	return UpdateHandleDispatcher.AddME(messageEditHandle{Pattern: pattern, Handler: handler})
+	return c.dispatcher.AddME(messageEditHandle{Pattern: pattern, Handler: handler})
 }
 
 // Handle updates categorized as "UpdateBotInlineQuery"
@@ -528,7 +698,7 @@ func (c *Client) AddEditHandler(pattern interface{}, handler func(m *NewMessage)
 // Included Updates:
 //   - Inline Query
 func (c *Client) AddInlineHandler(pattern interface{}, handler func(m *InlineQuery) error) inlineHandle {
-	return UpdateHandleDispatcher.AddI(inlineHandle{Pattern: pattern, Handler: handler})
+	return c.dispatcher.AddI(inlineHandle{Pattern: pattern, Handler: handler})
 }
 
 // Handle updates categorized as "UpdateBotCallbackQuery"
@@ -536,7 +706,7 @@ func (c *Client) AddInlineHandler(pattern interface{}, handler func(m *InlineQue
 // Included Updates:
 //   - Callback Query
 func (c *Client) AddCallbackHandler(pattern interface{}, handler func(m *CallbackQuery) error) callbackHandle {
-	return UpdateHandleDispatcher.AddC(callbackHandle{Pattern: pattern, Handler: handler})
+	return c.dispatcher.AddC(callbackHandle{Pattern: pattern, Handler: handler})
 }
 
 // Handle updates categorized as "UpdateInlineBotCallbackQuery"
@@ -544,7 +714,7 @@ func (c *Client) AddCallbackHandler(pattern interface{}, handler func(m *Callbac
 // Included Updates:
 //   - Inline Callback Query
 func (c *Client) AddInlineCallbackHandler(pattern interface{}, handler func(m *InlineCallbackQuery) error) inlineCallbackHandle {
-	return UpdateHandleDispatcher.AddIC(inlineCallbackHandle{Pattern: pattern, Handler: handler})
+	return c.dispatcher.AddIC(inlineCallbackHandle{Pattern: pattern, Handler: handler})
 }
 
 // Handle updates categorized as "UpdateChannelParticipant"
@@ -557,72 +727,50 @@ func (c *Client) AddInlineCallbackHandler(pattern interface{}, handler func(m *I
 //   - Channel Participant Admin
 //   - Channel Participant Creator
 func (c *Client) AddParticipantHandler(handler func(m *ParticipantUpdate) error) participantHandle {
-	return UpdateHandleDispatcher.AddP(participantHandle{Handler: handler})
+	return c.dispatcher.AddP(participantHandle{Handler: handler})
 }
 
 func (c *Client) AddRawHandler(updateType Update, handler func(m Update) error) rawHandle {
-	return UpdateHandleDispatcher.AddR(rawHandle{updateType: updateType, Handler: handler})
+	return c.dispatcher.AddR(rawHandle{updateType: updateType, Handler: handler})
 }
 
 // Sort and Handle all the Incoming Updates
 // Many more types to be added
-func HandleIncomingUpdates(u interface{}) bool {
-UpdateTypeSwitching:
-	switch upd := u.(type) {
-	case *UpdatesObj:
-		go cache.UpdatePeersToCache(upd.Users, upd.Chats)
-		for _, update := range upd.Updates {
-			switch update := update.(type) {
-			case *UpdateNewMessage:
-				go UpdateHandleDispatcher.HandleMessageUpdate(update.Message)
-			case *UpdateNewChannelMessage:
-				go UpdateHandleDispatcher.HandleMessageUpdate(update.Message)
-			case *UpdateNewScheduledMessage:
-				go UpdateHandleDispatcher.HandleMessageUpdate(update.Message)
-			case *UpdateEditMessage:
-				go UpdateHandleDispatcher.HandleEditUpdate(update.Message)
-			case *UpdateEditChannelMessage:
-				go UpdateHandleDispatcher.HandleEditUpdate(update.Message)
-			case *UpdateBotInlineQuery:
-				go UpdateHandleDispatcher.HandleInlineUpdate(update)
-			case *UpdateBotCallbackQuery:
-				go UpdateHandleDispatcher.HandleCallbackUpdate(update)
-			case *UpdateInlineBotCallbackQuery:
-				go UpdateHandleDispatcher.HandleInlineCallbackUpdate(update)
-			case *UpdateChannelParticipant:
-				go UpdateHandleDispatcher.HandleParticipantUpdate(update)
-			default:
-				go UpdateHandleDispatcher.HandleRawUpdate(update)
-			}
-		}
-	case *UpdateShort:
-		switch upd := upd.Update.(type) {
-		case *UpdateNewMessage:
-			go UpdateHandleDispatcher.HandleMessageUpdateW(upd.Message, upd.Pts)
-		case *UpdateNewChannelMessage:
-			go UpdateHandleDispatcher.HandleMessageUpdateW(upd.Message, upd.Pts)
-		default:
-			go UpdateHandleDispatcher.HandleRawUpdate(upd)
-		}
-	case *UpdateShortMessage:
-		go UpdateHandleDispatcher.HandleMessageUpdateW(&MessageObj{Out: upd.Out, Mentioned: upd.Mentioned, Message: upd.Message, MediaUnread: upd.MediaUnread, FromID: getPeerUser(upd.UserID), PeerID: getPeerUser(upd.UserID), Date: upd.Date, Entities: upd.Entities}, upd.Pts)
-	case *UpdateShortChatMessage:
-		go UpdateHandleDispatcher.HandleMessageUpdateW(&MessageObj{Out: upd.Out, Mentioned: upd.Mentioned, Message: upd.Message, MediaUnread: upd.MediaUnread, FromID: getPeerUser(upd.FromID), PeerID: getPeerUser(upd.ChatID), Date: upd.Date, Entities: upd.Entities}, upd.Pts)
-	case *UpdateShortSentMessage:
-		go UpdateHandleDispatcher.HandleMessageUpdateW(&MessageObj{Out: upd.Out, Date: upd.Date, Media: upd.Media, Entities: upd.Entities}, upd.Pts)
-	case *UpdatesCombined:
-		u = upd.Updates
-		go cache.UpdatePeersToCache(upd.Users, upd.Chats)
-		goto UpdateTypeSwitching
-	case *UpdatesTooLong:
+func (c *Client) HandleIncomingUpdates(u interface{}) bool {
+	c.updates.process(u, false)
+	return true
+}
+
+// Dispatch routes a single update to the matching handlers
+func (u *UpdateDispatcher) Dispatch(update Update) {
+	switch update := update.(type) {
+	case *UpdateNewMessage:
+		go u.HandleMessageUpdate(update.Message)
+	case *UpdateNewChannelMessage:
+		go u.HandleMessageUpdate(update.Message)
+	case *UpdateNewScheduledMessage:
+		go u.HandleMessageUpdate(update.Message)
+	case *UpdateEditMessage:
+		go u.HandleEditUpdate(update.Message)
+	case *UpdateEditChannelMessage:
+		go u.HandleEditUpdate(update.Message)
+	case *UpdateBotInlineQuery:
+		go u.HandleInlineUpdate(update)
+	case *UpdateBotCallbackQuery:
+		go u.HandleCallbackUpdate(update)
+	case *UpdateInlineBotCallbackQuery:
+		go u.HandleInlineCallbackUpdate(update)
+	case *UpdateChannelParticipant:
+		go u.HandleParticipantUpdate(update)
 	default:
-		UpdateHandleDispatcher.client.Log.Warn(ErrInvalidUpdateType, reflect.TypeOf(u))
+		go u.HandleRawUpdate(update)
 	}
-	return true
 }
 
+// Deprecated: missing updates are fetched automatically once a gap is detected,
+// GetDiffrence only returns the first new message.
 func (c *Client) GetDiffrence(Pts int32, Limit int32) (Message, error) {
-	c.Logger.Debug("Getting diffrence for", Pts)
+	c.Log.Debug("getting difference", "pts", Pts)
 	updates, err := c.UpdatesGetDifference(Pts-1, Limit, int32(time.Now().Unix()), 0)
 	if err != nil {
 		return nil, err
@@ -54,27 +54,6 @@ var (
 	}
 )
 
-func getErrorCode(err error) (int, int) {
-	datacenter := 0
-	code := 0
-	if err != nil {
-		if re := regexp.MustCompile(`DC (\d+)`); re.MatchString(err.Error()) {
-			datacenter, _ = strconv.Atoi(re.FindStringSubmatch(err.Error())[1])
-		}
-		if re := regexp.MustCompile(`code (\d+)`); re.MatchString(err.Error()) {
-			code, _ = strconv.Atoi(re.FindStringSubmatch(err.Error())[1])
-		}
-	}
-	return datacenter, code
-}
-
-func matchError(err error, str string) bool {
-	if err != nil {
-		return strings.Contains(err.Error(), str)
-	}
-	return false
-}
-
 func resolveMimeType(filePath string) (string, bool) {
 	if matchMimeType := matchMimeType(filePath); matchMimeType != "" {
 		return matchMimeType, mimeIsPhoto(matchMimeType)
@@ -519,6 +498,15 @@ func doesSessionFileExist(filePath string) bool {
 	return !os.IsNotExist(ol)
 }
 
+// sessionExists reports whether the configured session storage already holds a session
+func sessionExists(config ClientConfig) bool {
+	if config.SessionStorage != nil {
+		s, err := config.SessionStorage.Load()
+		return err == nil && s != nil
+	}
+	return doesSessionFileExist(config.Session)
+}
+
 func IsFfmpegInstalled() bool {
 	_, err := exec.LookPath("ffmpeg")
 	return err == nil
@@ -0,0 +1,284 @@
+package gogram
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/jwillp/gogram/internal/encoding/tl"
+	"github.com/jwillp/gogram/internal/mtproto/objects"
+	"github.com/pkg/errors"
+)
+
+// TrafficDirection tells whether a recorded message was sent or received
+type TrafficDirection uint8
+
+const (
+	TrafficOutgoing TrafficDirection = iota + 1
+	TrafficIncoming
+)
+
+func (d TrafficDirection) String() string {
+	switch d {
+	case TrafficOutgoing:
+		return "out"
+	case TrafficIncoming:
+		return "in"
+	}
+	return "unknown"
+}
+
+// TrafficRecord is a single message, as it was before encryption or after decryption
+type TrafficRecord struct {
+	Time      time.Time
+	Direction TrafficDirection
+	DC        int
+	MsgID     int64
+	SeqNo     int32
+	Type      string // name of the TL type, results name the type inside, e.g. RpcResult(Config)
+	Body      []byte // the serialized object
+}
+
+// Object decodes the body, the types of the telegram package are known once it's imported
+func (r *TrafficRecord) Object() (tl.Object, error) {
+	obj, err := tl.DecodeUnknownObject(r.Body)
+	return obj, errors.Wrap(err, "decoding "+r.Type)
+}
+
+// TrafficRecorder gets every message sent or received, containers are recorded as the messages inside.
+// It's called from the connection goroutines, so it must be safe for concurrent use and shouldn't block
+type TrafficRecorder interface {
+	Record(r *TrafficRecord)
+}
+
+// record passes the message to the recorder, if there is one
+func (m *MTProto) record(direction TrafficDirection, msgID int64, seqNo int32, obj tl.Object, body []byte) {
+	if m.recorder == nil {
+		return
+	}
+	m.recorder.Record(&TrafficRecord{
+		Time:      time.Now(),
+		Direction: direction,
+		DC:        m.GetDC(),
+		MsgID:     msgID,
+		SeqNo:     seqNo,
+		Type:      trafficTypeName(obj),
+		Body:      body,
+	})
+}
+
+func trafficTypeName(obj tl.Object) string {
+	switch o := obj.(type) {
+	case nil:
+		return "unknown"
+	case *objects.GzipPacked:
+		return trafficTypeName(o.Obj)
+	case *objects.RpcResult:
+		return "RpcResult(" + trafficTypeName(o.Obj) + ")"
+	}
+	t := reflect.TypeOf(obj)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// a traffic log starts with trafficLogMagic, followed by the records: the direction, the time in unix
+// nanoseconds, the dc, msg_id, seq_no, and the type name and the body prefixed with their lengths;
+// numbers other than msg_id are varints
+var trafficLogMagic = []byte("gogram traffic 1\n")
+
+// TrafficLog is a TrafficRecorder writing the records to a file in a compact binary format,
+// read it with NewTrafficLogReader
+type TrafficLog struct {
+	mutex  sync.Mutex
+	w      io.Writer
+	closer io.Closer
+	err    error
+}
+
+// NewTrafficLog creates the file, or truncates it, and writes the records to it
+func NewTrafficLog(path string) (*TrafficLog, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating traffic log")
+	}
+	l := NewTrafficLogWriter(f)
+	l.closer = f
+	if l.err != nil {
+		f.Close()
+		return nil, l.err
+	}
+	return l, nil
+}
+
+// NewTrafficLogWriter writes the records to w, every record with a single Write
+func NewTrafficLogWriter(w io.Writer) *TrafficLog {
+	l := &TrafficLog{w: w}
+	if _, err := w.Write(trafficLogMagic); err != nil {
+		l.err = errors.Wrap(err, "writing traffic log header")
+	}
+	return l
+}
+
+// Record appends the record to the log, after a write error the records are dropped, see Err
+func (l *TrafficLog) Record(r *TrafficRecord) {
+	buf := make([]byte, 0, 48+len(r.Type)+len(r.Body))
+	buf = append(buf, byte(r.Direction))
+	buf = binary.AppendVarint(buf, r.Time.UnixNano())
+	buf = binary.AppendVarint(buf, int64(r.DC))
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(r.MsgID))
+	buf = binary.AppendVarint(buf, int64(r.SeqNo))
+	buf = binary.AppendUvarint(buf, uint64(len(r.Type)))
+	buf = append(buf, r.Type...)
+	buf = binary.AppendUvarint(buf, uint64(len(r.Body)))
+	buf = append(buf, r.Body...)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.err != nil {
+		return
+	}
+	if _, err := l.w.Write(buf); err != nil {
+		l.err = errors.Wrap(err, "writing traffic log")
+	}
+}
+
+// Err returns the first error writing the log
+func (l *TrafficLog) Err() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.err
+}
+
+// Close closes the file of a log made by NewTrafficLog, records after it are dropped
+func (l *TrafficLog) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.err == nil {
+		l.err = errors.New("traffic log is closed")
+	}
+	if l.closer == nil {
+		return nil
+	}
+	return errors.Wrap(l.closer.Close(), "closing traffic log")
+}
+
+// TrafficLogReader reads the records of a traffic log
+type TrafficLogReader struct {
+	r      *bufio.Reader
+	header bool
+}
+
+func NewTrafficLogReader(r io.Reader) *TrafficLogReader {
+	return &TrafficLogReader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next record, or io.EOF after the last one
+func (l *TrafficLogReader) Next() (*TrafficRecord, error) {
+	if !l.header {
+		magic := make([]byte, len(trafficLogMagic))
+		if _, err := io.ReadFull(l.r, magic); err != nil || !bytes.Equal(magic, trafficLogMagic) {
+			return nil, errors.New("not a traffic log")
+		}
+		l.header = true
+	}
+	direction, err := l.r.ReadByte()
+	if err != nil {
+		return nil, err // io.EOF between records
+	}
+	r := &TrafficRecord{Direction: TrafficDirection(direction)}
+	var nanos, dc, seqNo int64
+	for _, v := range []*int64{&nanos, &dc} {
+		if *v, err = binary.ReadVarint(l.r); err != nil {
+			return nil, truncated(err)
+		}
+	}
+	var msgID [8]byte
+	if _, err := io.ReadFull(l.r, msgID[:]); err != nil {
+		return nil, truncated(err)
+	}
+	if seqNo, err = binary.ReadVarint(l.r); err != nil {
+		return nil, truncated(err)
+	}
+	typeName, err := l.readBytes()
+	if err != nil {
+		return nil, err
+	}
+	if r.Body, err = l.readBytes(); err != nil {
+		return nil, err
+	}
+	r.Time, r.DC, r.MsgID, r.SeqNo, r.Type = time.Unix(0, nanos), int(dc), int64(binary.LittleEndian.Uint64(msgID[:])), int32(seqNo), string(typeName)
+	return r, nil
+}
+
+func (l *TrafficLogReader) readBytes() ([]byte, error) {
+	size, err := binary.ReadUvarint(l.r)
+	if err != nil {
+		return nil, truncated(err)
+	}
+	if size > 1<<30 {
+		return nil, errors.Errorf("traffic record of %d bytes is too large", size)
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(l.r, b); err != nil {
+		return nil, truncated(err)
+	}
+	return b, nil
+}
+
+func truncated(err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return errors.Wrap(err, "reading traffic record")
+}
+
+// ReplayTraffic passes the received objects of a traffic log to the server request handlers in the order
+// they were received, as if they just arrived, so update handlers can be debugged offline. Results of
+// requests and service messages are skipped. A client should not be connected while it replays, its
+// update state would drop the updates it already has
+func (m *MTProto) ReplayTraffic(r io.Reader) error {
+	log := NewTrafficLogReader(r)
+	for {
+		record, err := log.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if record.Direction != TrafficIncoming {
+			continue
+		}
+		obj, err := record.Object()
+		if err != nil {
+			return errors.Wrapf(err, "replaying message %d", record.MsgID)
+		}
+		if gzipped, ok := obj.(*objects.GzipPacked); ok {
+			obj = gzipped.Obj
+		}
+		if handledByConnection(obj) {
+			continue
+		}
+		m.handleServerRequest(obj)
+	}
+}
+
+// handledByConnection reports whether the object is a service message or an answer of the key exchange,
+// which never reach the server request handlers
+func handledByConnection(obj tl.Object) bool {
+	switch obj.(type) {
+	case *objects.MessageContainer, *objects.RpcResult, *objects.BadServerSalt, *objects.NewSessionCreated, *objects.Pong,
+		*objects.MsgsAck, *objects.MsgsStateInfo, *objects.MsgsAllInfo, *objects.BadMsgNotification, *objects.MsgResendReq,
+		*objects.MsgsStateReq, *objects.MsgsDetailedInfo, *objects.MsgsNewDetailedInfo,
+		*objects.ResPQ, *objects.ServerDHParamsOk, *objects.ServerDHParamsFail, *objects.DHGenOk, *objects.DHGenRetry, *objects.DHGenFail:
+		return true
+	}
+	return false
+}